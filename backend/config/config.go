@@ -0,0 +1,569 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"school-system/backend/models"
+)
+
+// Драйверы хранилища
+const (
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
+// Config — полная конфигурация сервера
+type Config struct {
+	Server   ServerConfig
+	Database DatabaseConfig
+	Auth     AuthConfig
+	// Grading — веса видов отметок и порог округления итоговой оценки за период
+	Grading models.GradingRules
+
+	// Demo включает демонстрационный режим: хранилище в памяти с тестовыми данными
+	Demo bool
+
+	// Args — позиционные аргументы после флагов (например, подкоманда migrate)
+	Args []string
+}
+
+// ServerConfig — параметры HTTP-сервера
+type ServerConfig struct {
+	Port        int
+	CORSOrigins []string
+	CORSDebug   bool
+}
+
+// DatabaseConfig — параметры подключения к базе данных
+type DatabaseConfig struct {
+	Driver           string // postgres или memory
+	DSN              string
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	StatementTimeout time.Duration
+	AutoMigrate      bool
+}
+
+// AuthConfig — параметры аутентификации
+type AuthConfig struct {
+	JWTSecret        string
+	AccessTokenTTL   time.Duration
+	RefreshTokenTTL  time.Duration
+	PasswordResetTTL time.Duration
+	InvitationTTL    time.Duration
+
+	// Защита от подбора пароля: после LoginMaxFailures неудачных попыток подряд
+	// для одного логина (LoginIPMaxFailures — для одного IP) вход блокируется на LoginLockout.
+	// Счетчик сбрасывается, если неудачных попыток не было дольше LoginFailureWindow.
+	LoginMaxFailures   int
+	LoginIPMaxFailures int
+	LoginFailureWindow time.Duration
+	LoginLockout       time.Duration
+
+	// TOTPIssuer — название сервиса в приложении-аутентификаторе
+	TOTPIssuer string
+	// TOTPRequiredRoles — роли, для которых двухфакторная аутентификация обязательна
+	TOTPRequiredRoles []string
+}
+
+// TOTPRequired сообщает, обязательна ли двухфакторная аутентификация для роли
+func (c AuthConfig) TOTPRequired(role string) bool {
+	for _, r := range c.TOTPRequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Addr возвращает адрес, на котором слушает HTTP-сервер
+func (c ServerConfig) Addr() string {
+	return fmt.Sprintf(":%d", c.Port)
+}
+
+// Default возвращает конфигурацию со значениями по умолчанию
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:        8000,
+			CORSOrigins: []string{"http://localhost:3000"},
+		},
+		Database: DatabaseConfig{
+			Driver:           DriverPostgres,
+			MaxOpenConns:     25,
+			MaxIdleConns:     5,
+			ConnMaxLifetime:  30 * time.Minute,
+			ConnMaxIdleTime:  5 * time.Minute,
+			StatementTimeout: 30 * time.Second,
+			AutoMigrate:      true,
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:   15 * time.Minute,
+			RefreshTokenTTL:  30 * 24 * time.Hour,
+			PasswordResetTTL: 24 * time.Hour,
+			InvitationTTL:    7 * 24 * time.Hour,
+
+			LoginMaxFailures:   5,
+			LoginIPMaxFailures: 50,
+			LoginFailureWindow: 15 * time.Minute,
+			LoginLockout:       15 * time.Minute,
+
+			TOTPIssuer: "School System",
+		},
+		Grading: models.GradingRules{
+			KindWeights: map[string]float64{
+				models.MarkKindClasswork: 1,
+				models.MarkKindHomework:  1,
+				models.MarkKindTest:      2,
+				models.MarkKindExam:      3,
+			},
+			RoundingThreshold: 0.5,
+		},
+	}
+}
+
+// Error содержит все ошибки, найденные при загрузке и проверке конфигурации
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "некорректная конфигурация:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func (e *Error) add(format string, args ...interface{}) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+// setting описывает один параметр конфигурации и все его источники
+type setting struct {
+	key   string // ключ в файле конфигурации
+	env   string // переменная окружения
+	flag  string // флаг командной строки
+	usage string
+	apply func(c *Config, value string) error
+}
+
+// boolFlags — флаги, которые можно указать без значения (например, --demo)
+var boolFlags = map[string]bool{
+	"cors-debug":      true,
+	"demo":            true,
+	"db-auto-migrate": true,
+}
+
+// flagValue хранит строковое значение флага; булевы флаги допускают запись без значения
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (f *flagValue) String() string     { return f.value }
+func (f *flagValue) Set(v string) error { f.value = v; return nil }
+func (f *flagValue) IsBoolFlag() bool   { return f.isBool }
+
+var settings = []setting{
+	{"server.port", "SERVER_PORT", "port", "порт HTTP-сервера", func(c *Config, v string) error {
+		return parseInt(v, &c.Server.Port)
+	}},
+	{"server.cors_origins", "CORS_ORIGINS", "cors-origins", "разрешённые CORS источники через запятую", func(c *Config, v string) error {
+		c.Server.CORSOrigins = splitList(v)
+		return nil
+	}},
+	{"server.cors_debug", "CORS_DEBUG", "cors-debug", "отладочный вывод CORS", func(c *Config, v string) error {
+		return parseBool(v, &c.Server.CORSDebug)
+	}},
+	{"demo", "DEMO_MODE", "demo", "демонстрационный режим без внешних сервисов", func(c *Config, v string) error {
+		if err := parseBool(v, &c.Demo); err != nil {
+			return err
+		}
+		if c.Demo {
+			c.Database.Driver = DriverMemory
+		}
+		return nil
+	}},
+	{"database.driver", "DB_DRIVER", "db-driver", "драйвер хранилища: postgres или memory", func(c *Config, v string) error {
+		c.Database.Driver = strings.ToLower(strings.TrimSpace(v))
+		return nil
+	}},
+	{"database.dsn", "DB_DSN", "db-dsn", "строка подключения к PostgreSQL", func(c *Config, v string) error {
+		c.Database.DSN = v
+		return nil
+	}},
+	{"database.max_open_conns", "DB_MAX_OPEN_CONNS", "db-max-open-conns", "максимум открытых соединений", func(c *Config, v string) error {
+		return parseInt(v, &c.Database.MaxOpenConns)
+	}},
+	{"database.max_idle_conns", "DB_MAX_IDLE_CONNS", "db-max-idle-conns", "максимум простаивающих соединений", func(c *Config, v string) error {
+		return parseInt(v, &c.Database.MaxIdleConns)
+	}},
+	{"database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", "db-conn-max-lifetime", "максимальное время жизни соединения", func(c *Config, v string) error {
+		return parseDuration(v, &c.Database.ConnMaxLifetime)
+	}},
+	{"database.conn_max_idle_time", "DB_CONN_MAX_IDLE_TIME", "db-conn-max-idle-time", "максимальное время простоя соединения", func(c *Config, v string) error {
+		return parseDuration(v, &c.Database.ConnMaxIdleTime)
+	}},
+	{"database.statement_timeout", "DB_STATEMENT_TIMEOUT", "db-statement-timeout", "таймаут выполнения SQL-запроса", func(c *Config, v string) error {
+		return parseDuration(v, &c.Database.StatementTimeout)
+	}},
+	{"database.auto_migrate", "DB_AUTO_MIGRATE", "db-auto-migrate", "применять миграции при запуске", func(c *Config, v string) error {
+		return parseBool(v, &c.Database.AutoMigrate)
+	}},
+	{"auth.jwt_secret", "JWT_SECRET", "jwt-secret", "секретный ключ для подписи JWT", func(c *Config, v string) error {
+		c.Auth.JWTSecret = v
+		return nil
+	}},
+	{"auth.access_token_ttl", "ACCESS_TOKEN_TTL", "access-token-ttl", "время жизни токена доступа", func(c *Config, v string) error {
+		return parseDuration(v, &c.Auth.AccessTokenTTL)
+	}},
+	{"auth.refresh_token_ttl", "REFRESH_TOKEN_TTL", "refresh-token-ttl", "время жизни токена обновления", func(c *Config, v string) error {
+		return parseDuration(v, &c.Auth.RefreshTokenTTL)
+	}},
+	{"auth.password_reset_ttl", "PASSWORD_RESET_TTL", "password-reset-ttl", "время жизни токена сброса пароля", func(c *Config, v string) error {
+		return parseDuration(v, &c.Auth.PasswordResetTTL)
+	}},
+	{"auth.invitation_ttl", "INVITATION_TTL", "invitation-ttl", "срок действия приглашения на регистрацию по умолчанию", func(c *Config, v string) error {
+		return parseDuration(v, &c.Auth.InvitationTTL)
+	}},
+	{"auth.login_max_failures", "LOGIN_MAX_FAILURES", "login-max-failures", "неудачных попыток входа для одного логина до блокировки", func(c *Config, v string) error {
+		return parseInt(v, &c.Auth.LoginMaxFailures)
+	}},
+	{"auth.login_ip_max_failures", "LOGIN_IP_MAX_FAILURES", "login-ip-max-failures", "неудачных попыток входа с одного IP до блокировки", func(c *Config, v string) error {
+		return parseInt(v, &c.Auth.LoginIPMaxFailures)
+	}},
+	{"auth.login_failure_window", "LOGIN_FAILURE_WINDOW", "login-failure-window", "через сколько без ошибок сбрасывается счетчик неудачных попыток", func(c *Config, v string) error {
+		return parseDuration(v, &c.Auth.LoginFailureWindow)
+	}},
+	{"auth.login_lockout", "LOGIN_LOCKOUT", "login-lockout", "длительность блокировки входа", func(c *Config, v string) error {
+		return parseDuration(v, &c.Auth.LoginLockout)
+	}},
+	{"auth.totp_issuer", "TOTP_ISSUER", "totp-issuer", "название сервиса в приложении-аутентификаторе", func(c *Config, v string) error {
+		c.Auth.TOTPIssuer = v
+		return nil
+	}},
+	{"auth.totp_required_roles", "TOTP_REQUIRED_ROLES", "totp-required-roles", "роли с обязательной двухфакторной аутентификацией через запятую", func(c *Config, v string) error {
+		c.Auth.TOTPRequiredRoles = splitList(v)
+		return nil
+	}},
+	{"grading.weights.classwork", "GRADING_WEIGHT_CLASSWORK", "grading-weight-classwork", "вес отметки за работу на уроке", func(c *Config, v string) error {
+		return parseWeight(v, c.Grading.KindWeights, models.MarkKindClasswork)
+	}},
+	{"grading.weights.homework", "GRADING_WEIGHT_HOMEWORK", "grading-weight-homework", "вес отметки за домашнюю работу", func(c *Config, v string) error {
+		return parseWeight(v, c.Grading.KindWeights, models.MarkKindHomework)
+	}},
+	{"grading.weights.test", "GRADING_WEIGHT_TEST", "grading-weight-test", "вес отметки за контрольную работу", func(c *Config, v string) error {
+		return parseWeight(v, c.Grading.KindWeights, models.MarkKindTest)
+	}},
+	{"grading.weights.exam", "GRADING_WEIGHT_EXAM", "grading-weight-exam", "вес отметки за экзамен", func(c *Config, v string) error {
+		return parseWeight(v, c.Grading.KindWeights, models.MarkKindExam)
+	}},
+	{"grading.rounding_threshold", "GRADING_ROUNDING_THRESHOLD", "grading-rounding-threshold", "дробная часть среднего, с которой итоговая оценка округляется вверх", func(c *Config, v string) error {
+		return parseFloat(v, &c.Grading.RoundingThreshold)
+	}},
+}
+
+// Load собирает конфигурацию из значений по умолчанию, файла конфигурации,
+// переменных окружения и флагов командной строки (в порядке возрастания приоритета).
+// Файл задаётся флагом -config или переменной CONFIG_FILE и может быть в формате YAML или TOML.
+// Возвращает *Error со списком всех некорректных параметров.
+func Load(args []string) (*Config, error) {
+	return load(args, os.LookupEnv, os.ReadFile)
+}
+
+func load(args []string, lookupEnv func(string) (string, bool), readFile func(string) ([]byte, error)) (*Config, error) {
+	cfg := Default()
+	problems := &Error{}
+
+	fs := flag.NewFlagSet("school-system", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configPath := fs.String("config", "", "путь к файлу конфигурации (YAML или TOML)")
+	flagValues := make(map[string]*flagValue, len(settings))
+	for _, s := range settings {
+		flagValues[s.flag] = &flagValue{isBool: boolFlags[s.flag]}
+		fs.Var(flagValues[s.flag], s.flag, s.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		problems.add("флаги командной строки: %v", err)
+		return nil, problems
+	}
+
+	// Файл конфигурации
+	path := *configPath
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+	if path != "" {
+		values, err := readConfigFile(path, readFile)
+		if err != nil {
+			problems.add("файл %s: %v", path, err)
+		} else {
+			applyFileValues(cfg, path, values, problems)
+		}
+	}
+
+	// Переменные окружения
+	for _, s := range settings {
+		if v, ok := lookupEnv(s.env); ok && v != "" {
+			if err := s.apply(cfg, v); err != nil {
+				problems.add("переменная окружения %s (%s): %v", s.env, s.key, err)
+			}
+		}
+	}
+
+	// Флаги командной строки
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	for _, s := range settings {
+		if setFlags[s.flag] {
+			if err := s.apply(cfg, flagValues[s.flag].value); err != nil {
+				problems.add("флаг -%s (%s): %v", s.flag, s.key, err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		var verr *Error
+		if errors.As(err, &verr) {
+			problems.Problems = append(problems.Problems, verr.Problems...)
+		}
+	}
+
+	if len(problems.Problems) > 0 {
+		return nil, problems
+	}
+	cfg.Args = fs.Args()
+	return cfg, nil
+}
+
+// Validate проверяет согласованность значений конфигурации
+func (c *Config) Validate() error {
+	problems := &Error{}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		problems.add("server.port: порт должен быть в диапазоне 1..65535, получено %d", c.Server.Port)
+	}
+	for _, origin := range c.Server.CORSOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			problems.add("server.cors_origins: источник %q должен начинаться с http:// или https://", origin)
+		}
+	}
+
+	switch c.Database.Driver {
+	case DriverPostgres:
+		if strings.TrimSpace(c.Database.DSN) == "" {
+			problems.add("database.dsn: обязательный параметр не задан (DB_DSN)")
+		}
+	case DriverMemory:
+	default:
+		problems.add("database.driver: неизвестный драйвер %q, допустимые значения: postgres, memory", c.Database.Driver)
+	}
+	if c.Demo && c.Database.Driver != DriverMemory {
+		problems.add("demo: демонстрационный режим работает только с драйвером memory")
+	}
+	if c.Database.MaxOpenConns < 0 {
+		problems.add("database.max_open_conns: не может быть отрицательным")
+	}
+	if c.Database.MaxIdleConns < 0 {
+		problems.add("database.max_idle_conns: не может быть отрицательным")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		problems.add("database.max_idle_conns: %d превышает database.max_open_conns (%d)",
+			c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		problems.add("database.conn_max_lifetime: не может быть отрицательным")
+	}
+	if c.Database.ConnMaxIdleTime < 0 {
+		problems.add("database.conn_max_idle_time: не может быть отрицательным")
+	}
+	if c.Database.StatementTimeout < 0 {
+		problems.add("database.statement_timeout: не может быть отрицательным")
+	}
+
+	if c.Auth.AccessTokenTTL <= 0 {
+		problems.add("auth.access_token_ttl: должно быть больше нуля")
+	}
+	if c.Auth.RefreshTokenTTL <= c.Auth.AccessTokenTTL {
+		problems.add("auth.refresh_token_ttl: должно быть больше auth.access_token_ttl")
+	}
+	if c.Auth.PasswordResetTTL <= 0 {
+		problems.add("auth.password_reset_ttl: должно быть больше нуля")
+	}
+	if c.Auth.InvitationTTL <= 0 {
+		problems.add("auth.invitation_ttl: должно быть больше нуля")
+	}
+	if c.Auth.LoginMaxFailures < 1 {
+		problems.add("auth.login_max_failures: должно быть не меньше 1")
+	}
+	if c.Auth.LoginIPMaxFailures < c.Auth.LoginMaxFailures {
+		problems.add("auth.login_ip_max_failures: не может быть меньше auth.login_max_failures")
+	}
+	if c.Auth.LoginFailureWindow <= 0 {
+		problems.add("auth.login_failure_window: должно быть больше нуля")
+	}
+	if c.Auth.LoginLockout <= 0 {
+		problems.add("auth.login_lockout: должно быть больше нуля")
+	}
+	if strings.TrimSpace(c.Auth.TOTPIssuer) == "" {
+		problems.add("auth.totp_issuer: не может быть пустым")
+	}
+	// Роли хранятся в базе, поэтому здесь проверяется только формат имени
+	for _, role := range c.Auth.TOTPRequiredRoles {
+		if strings.TrimSpace(role) == "" {
+			problems.add("auth.totp_required_roles: пустое имя роли")
+		}
+	}
+
+	for _, kind := range models.MarkKinds {
+		if w := c.Grading.KindWeights[kind]; w <= 0 || w > 10 {
+			problems.add("grading.weights.%s: вес должен быть больше 0 и не больше 10, получено %v", kind, w)
+		}
+	}
+	if c.Grading.RoundingThreshold <= 0 || c.Grading.RoundingThreshold > 1 {
+		problems.add("grading.rounding_threshold: должно быть больше 0 и не больше 1, получено %v", c.Grading.RoundingThreshold)
+	}
+
+	if len(problems.Problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// readConfigFile читает файл конфигурации и приводит его к плоскому виду "раздел.ключ" -> значение
+func readConfigFile(path string, readFile func(string) ([]byte, error)) (map[string]string, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат, ожидается .yaml, .yml или .toml")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора: %v", err)
+	}
+
+	values := make(map[string]string)
+	flatten("", raw, values)
+	return values, nil
+}
+
+func flatten(prefix string, raw map[string]interface{}, out map[string]string) {
+	for k, v := range raw {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			flatten(key, val, out)
+		case []interface{}:
+			items := make([]string, 0, len(val))
+			for _, item := range val {
+				items = append(items, fmt.Sprint(item))
+			}
+			out[key] = strings.Join(items, ",")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(val)
+		}
+	}
+}
+
+func applyFileValues(cfg *Config, path string, values map[string]string, problems *Error) {
+	known := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		known[s.key] = s
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s, ok := known[k]
+		if !ok {
+			problems.add("файл %s: неизвестный параметр %q", path, k)
+			continue
+		}
+		if err := s.apply(cfg, values[k]); err != nil {
+			problems.add("файл %s (%s): %v", path, k, err)
+		}
+	}
+}
+
+func parseInt(v string, dst *int) error {
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		return fmt.Errorf("ожидается целое число, получено %q", v)
+	}
+	*dst = n
+	return nil
+}
+
+func parseFloat(v string, dst *float64) error {
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return fmt.Errorf("ожидается число, получено %q", v)
+	}
+	*dst = f
+	return nil
+}
+
+func parseWeight(v string, weights map[string]float64, kind string) error {
+	var w float64
+	if err := parseFloat(v, &w); err != nil {
+		return err
+	}
+	weights[kind] = w
+	return nil
+}
+
+func parseBool(v string, dst *bool) error {
+	b, err := strconv.ParseBool(strings.TrimSpace(v))
+	if err != nil {
+		return fmt.Errorf("ожидается true или false, получено %q", v)
+	}
+	*dst = b
+	return nil
+}
+
+func parseDuration(v string, dst *time.Duration) error {
+	d, err := time.ParseDuration(strings.TrimSpace(v))
+	if err != nil {
+		return fmt.Errorf("ожидается длительность (например 30s, 5m), получено %q", v)
+	}
+	*dst = d
+	return nil
+}
+
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
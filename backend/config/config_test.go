@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func env(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok
+	}
+}
+
+func files(content map[string]string) func(string) ([]byte, error) {
+	return func(path string) ([]byte, error) {
+		if data, ok := content[path]; ok {
+			return []byte(data), nil
+		}
+		return nil, os.ErrNotExist
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	yamlFile := `
+server:
+  port: 9000
+  cors_origins:
+    - http://school.local
+database:
+  dsn: host=file
+  max_open_conns: 10
+  statement_timeout: 5s
+`
+	cfg, err := load(
+		[]string{"-config", "app.yaml", "-port", "9100"},
+		env(map[string]string{"DB_DSN": "host=env"}),
+		files(map[string]string{"app.yaml": yamlFile}),
+	)
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+
+	if cfg.Server.Port != 9100 {
+		t.Errorf("Флаг должен переопределять файл: ожидался порт 9100, получен %d", cfg.Server.Port)
+	}
+	if cfg.Database.DSN != "host=env" {
+		t.Errorf("Переменная окружения должна переопределять файл: получено %q", cfg.Database.DSN)
+	}
+	if cfg.Database.MaxOpenConns != 10 {
+		t.Errorf("Ожидалось max_open_conns=10 из файла, получено %d", cfg.Database.MaxOpenConns)
+	}
+	if cfg.Database.StatementTimeout != 5*time.Second {
+		t.Errorf("Ожидался statement_timeout=5s, получено %v", cfg.Database.StatementTimeout)
+	}
+	if len(cfg.Server.CORSOrigins) != 1 || cfg.Server.CORSOrigins[0] != "http://school.local" {
+		t.Errorf("Неверные CORS источники: %v", cfg.Server.CORSOrigins)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	tomlFile := `
+[server]
+port = 8080
+
+[database]
+dsn = "host=toml"
+conn_max_lifetime = "1h"
+`
+	cfg, err := load([]string{"-config", "app.toml"}, env(nil), files(map[string]string{"app.toml": tomlFile}))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if cfg.Server.Port != 8080 || cfg.Database.DSN != "host=toml" || cfg.Database.ConnMaxLifetime != time.Hour {
+		t.Errorf("Неверно прочитан TOML: %+v", cfg)
+	}
+}
+
+func TestLoadReportsEveryBadKey(t *testing.T) {
+	yamlFile := `
+server:
+  port: abc
+database:
+  max_idle_conns: 50
+  max_open_conns: 10
+  unknown_key: 1
+`
+	_, err := load(
+		[]string{"-config", "app.yaml"},
+		env(map[string]string{"DB_STATEMENT_TIMEOUT": "10"}),
+		files(map[string]string{"app.yaml": yamlFile}),
+	)
+
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Ожидалась ошибка конфигурации, получено: %v", err)
+	}
+
+	for _, key := range []string{"server.port", "database.unknown_key", "DB_STATEMENT_TIMEOUT", "database.max_idle_conns", "database.dsn"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("В отчёте нет ошибки для %s:\n%v", key, err)
+		}
+	}
+}
+
+func TestLoadDemoDoesNotRequireDSN(t *testing.T) {
+	cfg, err := load([]string{"--demo"}, env(nil), files(nil))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if !cfg.Demo || cfg.Database.Driver != DriverMemory {
+		t.Errorf("Ожидался демо-режим с драйвером memory, получено demo=%v driver=%s", cfg.Demo, cfg.Database.Driver)
+	}
+
+	if _, err := load([]string{"--demo", "-db-driver", "postgres", "-db-dsn", "host=x"}, env(nil), files(nil)); err == nil {
+		t.Error("Ожидалась ошибка: демо-режим с драйвером postgres")
+	}
+}
+
+func TestLoadGrading(t *testing.T) {
+	yamlFile := `
+database:
+  dsn: host=file
+grading:
+  weights:
+    test: 4
+  rounding_threshold: 0.6
+`
+	cfg, err := load([]string{"-config", "app.yaml"}, env(nil), files(map[string]string{"app.yaml": yamlFile}))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if cfg.Grading.KindWeights["test"] != 4 || cfg.Grading.KindWeights["exam"] != 3 || cfg.Grading.RoundingThreshold != 0.6 {
+		t.Errorf("Неверно прочитаны правила оценивания: %+v", cfg.Grading)
+	}
+
+	_, err = load([]string{"-db-dsn", "host=x", "-grading-rounding-threshold", "1.5"},
+		env(map[string]string{"GRADING_WEIGHT_EXAM": "0"}), files(nil))
+	for _, key := range []string{"grading.weights.exam", "grading.rounding_threshold"} {
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("В отчёте нет ошибки для %s: %v", key, err)
+		}
+	}
+}
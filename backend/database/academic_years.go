@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+const termColumns = `id, academic_year_id, number, name, starts_on, ends_on`
+
+// ListAcademicYears возвращает учебные годы с периодами двумя запросами, без N+1
+func (s *Store) ListAcademicYears(ctx context.Context) ([]models.AcademicYear, error) {
+	var years []models.AcademicYear
+	if err := s.db.SelectContext(ctx, &years, `
+		SELECT id, start_year, starts_on, ends_on, term_kind, archived
+		FROM academic_years ORDER BY start_year DESC`); err != nil {
+		return nil, err
+	}
+
+	var terms []models.Term
+	if err := s.db.SelectContext(ctx, &terms,
+		`SELECT `+termColumns+` FROM terms ORDER BY academic_year_id, number`); err != nil {
+		return nil, err
+	}
+	byYear := make(map[int][]models.Term)
+	for _, term := range terms {
+		byYear[term.AcademicYearID] = append(byYear[term.AcademicYearID], term)
+	}
+	for i := range years {
+		years[i].Name = models.AcademicYearName(years[i].StartYear)
+		years[i].Terms = byYear[years[i].ID]
+		if years[i].Terms == nil {
+			years[i].Terms = []models.Term{}
+		}
+	}
+	return years, nil
+}
+
+func (s *Store) GetAcademicYear(ctx context.Context, startYear int) (*models.AcademicYear, error) {
+	var year models.AcademicYear
+	if err := s.db.GetContext(ctx, &year, `
+		SELECT id, start_year, starts_on, ends_on, term_kind, archived
+		FROM academic_years WHERE start_year = $1`, startYear); err != nil {
+		return nil, mapError(err)
+	}
+	year.Name = models.AcademicYearName(year.StartYear)
+	year.Terms = []models.Term{}
+	if err := s.db.SelectContext(ctx, &year.Terms,
+		`SELECT `+termColumns+` FROM terms WHERE academic_year_id = $1 ORDER BY number`, year.ID); err != nil {
+		return nil, err
+	}
+	return &year, nil
+}
+
+// CreateAcademicYear добавляет год и его периоды в одной транзакции
+func (s *Store) CreateAcademicYear(ctx context.Context, year *models.AcademicYear) error {
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.QueryRowxContext(ctx, `
+			INSERT INTO academic_years (start_year, starts_on, ends_on, term_kind)
+			VALUES ($1, $2, $3, $4) RETURNING id`,
+			year.StartYear, year.StartsOn, year.EndsOn, year.TermKind,
+		).Scan(&year.ID); err != nil {
+			return err
+		}
+		for i := range year.Terms {
+			term := &year.Terms[i]
+			term.AcademicYearID = year.ID
+			if err := tx.QueryRowxContext(ctx, `
+				INSERT INTO terms (academic_year_id, number, name, starts_on, ends_on)
+				VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+				term.AcademicYearID, term.Number, term.Name, term.StartsOn, term.EndsOn,
+			).Scan(&term.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	year.Name = models.AcademicYearName(year.StartYear)
+	return nil
+}
+
+// DeleteAcademicYear удаляет год; периоды удаляются каскадно, а оценки не дают удалить год.
+// Закрытый год удалить нельзя.
+func (s *Store) DeleteAcademicYear(ctx context.Context, startYear int) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		var archived bool
+		if err := tx.GetContext(ctx, &archived,
+			`SELECT archived FROM academic_years WHERE start_year = $1 FOR UPDATE`, startYear); err != nil {
+			return err
+		}
+		if archived {
+			return store.ErrArchived
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM academic_years WHERE start_year = $1`, startYear)
+		return err
+	})
+}
+
+func (s *Store) GetTerm(ctx context.Context, id int) (*models.Term, error) {
+	var term models.Term
+	if err := s.db.GetContext(ctx, &term, `SELECT `+termColumns+` FROM terms WHERE id = $1`, id); err != nil {
+		return nil, mapError(err)
+	}
+	return &term, nil
+}
+
+// UpdateTerm меняет название и даты периода; номер и год не меняются
+func (s *Store) UpdateTerm(ctx context.Context, term *models.Term) error {
+	return expectRows(s.db.ExecContext(ctx,
+		`UPDATE terms SET name = $1, starts_on = $2, ends_on = $3 WHERE id = $4`,
+		term.Name, term.StartsOn, term.EndsOn, term.ID))
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) ListAnnualGrades(ctx context.Context, startYear, studentID int) ([]models.AnnualGrade, error) {
+	var grades []models.AnnualGrade
+	err := s.db.SelectContext(ctx, &grades, `
+		SELECT a.student_id, a.subject_id, sub.name AS subject_name, y.start_year AS academic_year,
+			COALESCE(a.class_id, 0) AS class_id, COALESCE(c.grade_level || c.letter, '') AS class_name,
+			a.average, a.grade, a.unsatisfactory
+		FROM annual_grades a
+		JOIN subjects sub ON sub.id = a.subject_id
+		JOIN academic_years y ON y.id = a.academic_year_id
+		LEFT JOIN classes c ON c.id = a.class_id
+		WHERE y.start_year = $1 AND ($2 = 0 OR a.student_id = $2)
+		ORDER BY a.student_id, sub.name`,
+		startYear, studentID)
+	return grades, err
+}
+
+// CloseAcademicYear закрывает учебный год в одной транзакции: годовые оценки сохраняются
+// с классом ученика на конец года, затем ученики переводятся в классы следующего года
+// (недостающие классы создаются), выпускники отмечаются, а год переводится в архив
+func (s *Store) CloseAcademicYear(ctx context.Context, startYear int, annual []models.AnnualGrade, graduationLevel int) (*models.YearEndResult, error) {
+	result := &models.YearEndResult{AcademicYear: startYear, Promotions: []models.ClassPromotion{}, Unsatisfactory: []int{}}
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		var year struct {
+			ID       int  `db:"id"`
+			Archived bool `db:"archived"`
+		}
+		if err := tx.GetContext(ctx, &year,
+			`SELECT id, archived FROM academic_years WHERE start_year = $1 FOR UPDATE`, startYear); err != nil {
+			return err
+		}
+		if year.Archived {
+			return store.ErrArchived
+		}
+		var nextExists bool
+		if err := tx.GetContext(ctx, &nextExists,
+			`SELECT EXISTS (SELECT 1 FROM academic_years WHERE start_year = $1)`, startYear+1); err != nil {
+			return err
+		}
+		if !nextExists {
+			return store.ErrReference
+		}
+
+		unsatisfactory := make(map[int]bool)
+		for _, g := range annual {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO annual_grades (student_id, subject_id, academic_year_id, class_id, average, grade, unsatisfactory)
+				VALUES ($1, $2, $3, (SELECT class_id FROM students WHERE id = $1), $4, $5, $6)`,
+				g.StudentID, g.SubjectID, year.ID, g.Average, g.Grade, g.Unsatisfactory); err != nil {
+				return err
+			}
+			if g.Unsatisfactory && !unsatisfactory[g.StudentID] {
+				unsatisfactory[g.StudentID] = true
+				result.Unsatisfactory = append(result.Unsatisfactory, g.StudentID)
+			}
+		}
+		sort.Ints(result.Unsatisfactory)
+
+		var classes []models.Class
+		if err := tx.SelectContext(ctx, &classes, `
+			SELECT `+classColumns+` FROM classes WHERE academic_year = $1
+			ORDER BY grade_level, letter`, startYear); err != nil {
+			return err
+		}
+		for _, class := range classes {
+			students, err := lockClassStudents(ctx, tx, class.ID)
+			if err != nil {
+				return err
+			}
+			if class.GradeLevel >= graduationLevel {
+				if _, err := tx.ExecContext(ctx,
+					`UPDATE students SET graduated_year = $1 WHERE class_id = $2 AND graduated_year IS NULL`,
+					startYear, class.ID); err != nil {
+					return err
+				}
+				if err := auditStudents(ctx, tx, students); err != nil {
+					return err
+				}
+				result.Graduates += len(students)
+				continue
+			}
+
+			promotion := models.ClassPromotion{
+				FromClassID: class.ID,
+				From:        class.Name,
+				To:          models.ClassName(class.GradeLevel+1, class.Letter),
+			}
+			if err := tx.GetContext(ctx, &promotion.ToClassID, `
+				INSERT INTO classes (grade_level, letter, academic_year, homeroom_teacher_id)
+				VALUES ($1, $2, $3, NULLIF($4, 0))
+				ON CONFLICT (grade_level, letter, academic_year) DO UPDATE SET letter = EXCLUDED.letter
+				RETURNING id`,
+				class.GradeLevel+1, class.Letter, startYear+1, class.HomeroomTeacherID); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE students SET class_id = $1 WHERE class_id = $2 AND graduated_year IS NULL`,
+				promotion.ToClassID, class.ID); err != nil {
+				return err
+			}
+			if err := auditStudents(ctx, tx, students); err != nil {
+				return err
+			}
+			promotion.Students = len(students)
+			result.Promotions = append(result.Promotions, promotion)
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE academic_years SET archived = TRUE WHERE id = $1`, year.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lockClassStudents возвращает учеников класса, еще не окончивших школу, и блокирует их строки
+// до конца транзакции, чтобы список совпал с изменяемыми записями
+func lockClassStudents(ctx context.Context, tx *sqlx.Tx, classID int) ([]models.Student, error) {
+	var students []models.Student
+	err := tx.SelectContext(ctx, &students, `
+		SELECT `+studentColumns+` FROM students s
+		WHERE s.class_id = $1 AND s.graduated_year IS NULL
+		ORDER BY s.id
+		FOR UPDATE`, classID)
+	return students, err
+}
+
+// auditStudents записывает в журнал перевод или выпуск учеников: прежние записи
+// и записи, сохраненные в транзакции
+func auditStudents(ctx context.Context, tx *sqlx.Tx, students []models.Student) error {
+	for _, old := range students {
+		saved, err := getStudent(ctx, tx, old.ID)
+		if err != nil {
+			return err
+		}
+		if err := audit(ctx, tx, models.AuditUpdate, models.AuditStudent, old.ID, old, saved); err != nil {
+			return err
+		}
+	}
+	return nil
+}
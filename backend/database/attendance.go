@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+)
+
+const attendanceColumns = `a.id, a.student_id, a.subject_id, a.date, a.lesson, a.term_id,
+	a.status, a.reason, COALESCE(a.marked_by, 0) AS marked_by`
+
+// attendanceFrom присоединяет к отметкам a ученика s, его класс c, период t и учебный год y
+// и отбирает отметки по фильтру из параметров $1–$7
+const attendanceFrom = `
+		FROM attendance a
+		JOIN students s ON s.id = a.student_id
+		LEFT JOIN classes c ON c.id = s.class_id
+		JOIN terms t ON t.id = a.term_id
+		JOIN academic_years y ON y.id = t.academic_year_id
+		WHERE ($1 = 0 OR a.student_id = $1)
+		  AND ($2 = 0 OR s.class_id = $2)
+		  AND ($3 = 0 OR a.subject_id = $3)
+		  AND ($4 = 0 OR y.start_year = $4) AND ($5 = 0 OR t.number = $5)
+		  AND ($6::DATE IS NULL OR a.date >= $6) AND ($7::DATE IS NULL OR a.date <= $7)`
+
+// attendanceCounts считает уроки и пропуски по группе отметок
+const attendanceCounts = `COUNT(*) AS lessons,
+	COUNT(*) FILTER (WHERE a.status = 'absent') AS absent,
+	COUNT(*) FILTER (WHERE a.status = 'excused') AS excused,
+	COUNT(*) FILTER (WHERE a.status = 'late') AS late`
+
+func attendanceArgs(f models.AttendanceFilter) []interface{} {
+	return []interface{}{f.StudentID, f.ClassID, f.SubjectID, f.Period.AcademicYear, f.Period.Term, f.From, f.To}
+}
+
+func (s *Store) ListAttendance(ctx context.Context, f models.AttendanceFilter) ([]models.Attendance, error) {
+	var records []models.Attendance
+	err := s.db.SelectContext(ctx, &records, `
+		SELECT `+attendanceColumns+attendanceFrom+`
+		ORDER BY a.date, a.lesson, s.full_name`, attendanceArgs(f)...)
+	return records, err
+}
+
+func (s *Store) GetAttendance(ctx context.Context, id int) (*models.Attendance, error) {
+	var record models.Attendance
+	err := s.db.GetContext(ctx, &record, `SELECT `+attendanceColumns+` FROM attendance a WHERE a.id = $1`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &record, nil
+}
+
+// MarkAttendance сохраняет отметки урока в одной транзакции: повторная отметка
+// ученика на том же уроке заменяет прежнюю
+func (s *Store) MarkAttendance(ctx context.Context, records []models.Attendance) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		for i := range records {
+			r := &records[i]
+			if err := tx.QueryRowxContext(ctx, `
+				INSERT INTO attendance (student_id, subject_id, term_id, date, lesson, status, reason, marked_by)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0))
+				ON CONFLICT (student_id, date, lesson) DO UPDATE
+				SET subject_id = EXCLUDED.subject_id, term_id = EXCLUDED.term_id, status = EXCLUDED.status,
+					reason = EXCLUDED.reason, marked_by = EXCLUDED.marked_by, updated_at = now()
+				RETURNING id`,
+				r.StudentID, r.SubjectID, r.TermID, r.Date, r.Lesson, r.Status, r.Reason, r.MarkedBy,
+			).Scan(&r.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) UpdateAttendance(ctx context.Context, record *models.Attendance) error {
+	return expectRows(s.db.ExecContext(ctx, `
+		UPDATE attendance SET status = $1, reason = $2, marked_by = NULLIF($3, 0), updated_at = now()
+		WHERE id = $4`,
+		record.Status, record.Reason, record.MarkedBy, record.ID))
+}
+
+func (s *Store) DeleteAttendance(ctx context.Context, id int) error {
+	return expectRows(s.db.ExecContext(ctx, `DELETE FROM attendance WHERE id = $1`, id))
+}
+
+func (s *Store) GetAbsenceByStudent(ctx context.Context, f models.AttendanceFilter) ([]models.StudentAbsence, error) {
+	var result []models.StudentAbsence
+	err := s.db.SelectContext(ctx, &result, `
+		SELECT s.id AS student_id, s.full_name, COALESCE(c.grade_level || c.letter, '') AS class_name,
+			`+attendanceCounts+attendanceFrom+`
+		GROUP BY s.id, s.full_name, c.grade_level, c.letter
+		ORDER BY COUNT(*) FILTER (WHERE a.status IN ('absent', 'excused'))::NUMERIC / COUNT(*) DESC, s.full_name`,
+		attendanceArgs(f)...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].AbsenceRate = result[i].Rate()
+	}
+	return result, nil
+}
+
+// GetAbsenceByClass возвращает пропуски по классам; ученики без класса не учитываются
+func (s *Store) GetAbsenceByClass(ctx context.Context, f models.AttendanceFilter) ([]models.ClassAbsence, error) {
+	var result []models.ClassAbsence
+	err := s.db.SelectContext(ctx, &result, `
+		SELECT c.grade_level || c.letter AS class_name, `+attendanceCounts+attendanceFrom+`
+		  AND c.id IS NOT NULL
+		GROUP BY c.id, c.grade_level, c.letter
+		ORDER BY c.grade_level, c.letter`,
+		attendanceArgs(f)...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].AbsenceRate = result[i].Rate()
+	}
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func (s *Store) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	return insertAudit(ctx, s.db, entry)
+}
+
+// audit добавляет в журнал запись об изменении, если в контексте указан его автор.
+// Вызывается в транзакции изменения, чтобы они сохранялись или отменялись вместе.
+func audit(ctx context.Context, q sqlx.QueryerContext, action, entity string, entityID int, oldValue, newValue interface{}) error {
+	entry, err := store.NewAuditEntry(ctx, action, entity, entityID, oldValue, newValue)
+	if entry == nil {
+		return err
+	}
+	return insertAudit(ctx, q, entry)
+}
+
+func insertAudit(ctx context.Context, q sqlx.QueryerContext, entry *models.AuditEntry) error {
+	err := q.QueryRowxContext(ctx, `
+		INSERT INTO audit_log (user_id, action, entity, entity_id, old_value, new_value, ip)
+		VALUES (NULLIF($1, 0), $2, $3, $4, $5::JSONB, $6::JSONB, $7)
+		RETURNING id, created_at`,
+		entry.UserID, entry.Action, entry.Entity, entry.EntityID,
+		jsonValue(entry.OldValue), jsonValue(entry.NewValue), entry.IP,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	return mapError(err)
+}
+
+// auditRow — запись журнала в том виде, в каком ее читает драйвер. JSON читается
+// строкой: срез байтов указывал бы на буфер драйвера, который переиспользуется.
+type auditRow struct {
+	models.AuditEntry
+	OldValue sql.NullString `db:"old_value"`
+	NewValue sql.NullString `db:"new_value"`
+}
+
+func (s *Store) ListAudit(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, error) {
+	var rows []auditRow
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT a.id, COALESCE(a.user_id, 0) AS user_id, COALESCE(u.username, '') AS username,
+		       a.action, a.entity, a.entity_id, a.old_value, a.new_value, a.ip, a.created_at
+		FROM audit_log a
+		LEFT JOIN users u ON u.id = a.user_id
+		WHERE ($1 = '' OR a.entity = $1)
+		  AND ($2 = 0 OR a.entity_id = $2)
+		  AND ($3 = 0 OR a.user_id = $3)
+		  AND ($4::DATE IS NULL OR a.created_at >= $4::DATE)
+		  AND ($5::DATE IS NULL OR a.created_at < $5::DATE + 1)
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT NULLIF($6, 0)`,
+		f.Entity, f.EntityID, f.UserID, f.From, f.To, f.Limit)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]models.AuditEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = row.AuditEntry
+		if row.OldValue.Valid {
+			entries[i].OldValue = json.RawMessage(row.OldValue.String)
+		}
+		if row.NewValue.Valid {
+			entries[i].NewValue = json.RawMessage(row.NewValue.String)
+		}
+	}
+	return entries, nil
+}
+
+// jsonValue передает JSON драйверу строкой: срез байтов lib/pq отправил бы как BYTEA
+func jsonValue(v json.RawMessage) interface{} {
+	if len(v) == 0 {
+		return nil
+	}
+	return string(v)
+}
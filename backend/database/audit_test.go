@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// Изменение и запись журнала сохраняются в одной транзакции: если запись
+// не добавилась, изменение отменяется
+func TestAuditRollsBackChange(t *testing.T) {
+	st := testStore(t)
+	ctx := context.Background()
+
+	subject := &models.Subject{Name: "Тестовый предмет журнала"}
+	if err := st.CreateSubject(ctx, subject); err != nil {
+		t.Fatalf("Ошибка создания предмета: %v", err)
+	}
+	t.Cleanup(func() { st.DeleteSubject(ctx, subject.ID) })
+
+	// Адрес длиннее столбца ip: запись журнала не добавится
+	broken := store.WithAuditActor(ctx, 0, strings.Repeat("1", 46))
+	renamed := *subject
+	renamed.Name = "Переименованный тестовый предмет журнала"
+	if err := st.UpdateSubject(broken, &renamed); err == nil {
+		t.Fatal("Ожидалась ошибка записи в журнал")
+	}
+	if saved, err := st.GetSubject(ctx, subject.ID); err != nil || saved.Name != subject.Name {
+		t.Errorf("Изменение без записи в журнале должно отмениться, получено %+v, %v", saved, err)
+	}
+
+	audited := store.WithAuditActor(ctx, 0, "127.0.0.1")
+	if err := st.UpdateSubject(audited, &renamed); err != nil {
+		t.Fatalf("Ошибка изменения предмета: %v", err)
+	}
+	entries, err := st.ListAudit(ctx, models.AuditFilter{Entity: models.AuditSubject, EntityID: subject.ID})
+	if err != nil || len(entries) != 1 || entries[0].Action != models.AuditUpdate {
+		t.Fatalf("Ожидалась одна запись об изменении предмета, получено %+v, %v", entries, err)
+	}
+}
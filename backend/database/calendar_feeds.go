@@ -0,0 +1,31 @@
+package database
+
+import (
+	"context"
+
+	"school-system/backend/models"
+)
+
+// SetCalendarFeed сохраняет токен подписки, заменяя прежний токен пользователя
+func (s *Store) SetCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error {
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO calendar_feeds (user_id, token_hash) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = now()
+		RETURNING created_at`,
+		feed.UserID, feed.TokenHash).Scan(&feed.CreatedAt)
+	return mapError(err)
+}
+
+func (s *Store) GetCalendarFeed(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := s.db.GetContext(ctx, &feed,
+		`SELECT user_id, token_hash, created_at FROM calendar_feeds WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &feed, nil
+}
+
+func (s *Store) DeleteCalendarFeed(ctx context.Context, userID int) error {
+	return expectRows(s.db.ExecContext(ctx, `DELETE FROM calendar_feeds WHERE user_id = $1`, userID))
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+
+	"school-system/backend/models"
+)
+
+const classColumns = `id, grade_level, letter, academic_year,
+	COALESCE(homeroom_teacher_id, 0) AS homeroom_teacher_id, grade_level || letter AS name`
+
+// ListClasses возвращает классы учебного года (0 — всех годов)
+func (s *Store) ListClasses(ctx context.Context, academicYear int) ([]models.Class, error) {
+	var classes []models.Class
+	err := s.db.SelectContext(ctx, &classes, `
+		SELECT `+classColumns+` FROM classes
+		WHERE $1 = 0 OR academic_year = $1
+		ORDER BY academic_year DESC, grade_level, letter`, academicYear)
+	return classes, err
+}
+
+func (s *Store) GetClass(ctx context.Context, id int) (*models.Class, error) {
+	var class models.Class
+	err := s.db.GetContext(ctx, &class, `SELECT `+classColumns+` FROM classes WHERE id = $1`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &class, nil
+}
+
+func (s *Store) FindClass(ctx context.Context, gradeLevel int, letter string, academicYear int) (*models.Class, error) {
+	var class models.Class
+	err := s.db.GetContext(ctx, &class, `
+		SELECT `+classColumns+` FROM classes
+		WHERE grade_level = $1 AND letter = $2 AND academic_year = $3`,
+		gradeLevel, letter, academicYear)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &class, nil
+}
+
+// CreateClass добавляет класс и заполняет его ID и название
+func (s *Store) CreateClass(ctx context.Context, class *models.Class) error {
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO classes (grade_level, letter, academic_year, homeroom_teacher_id)
+		VALUES ($1, $2, $3, NULLIF($4, 0))
+		RETURNING id`,
+		class.GradeLevel, class.Letter, class.AcademicYear, class.HomeroomTeacherID,
+	).Scan(&class.ID)
+	if err != nil {
+		return mapError(err)
+	}
+	class.Name = models.ClassName(class.GradeLevel, class.Letter)
+	return nil
+}
+
+func (s *Store) UpdateClass(ctx context.Context, class *models.Class) error {
+	err := expectRows(s.db.ExecContext(ctx, `
+		UPDATE classes SET grade_level = $1, letter = $2, academic_year = $3, homeroom_teacher_id = NULLIF($4, 0)
+		WHERE id = $5`,
+		class.GradeLevel, class.Letter, class.AcademicYear, class.HomeroomTeacherID, class.ID))
+	if err != nil {
+		return err
+	}
+	class.Name = models.ClassName(class.GradeLevel, class.Letter)
+	return nil
+}
+
+func (s *Store) DeleteClass(ctx context.Context, id int) error {
+	return expectRows(s.db.ExecContext(ctx, `DELETE FROM classes WHERE id = $1`, id))
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+const enrollmentColumns = `e.id, e.student_id, e.subject_id, COALESCE(e.teacher_id, 0) AS teacher_id,
+	e.academic_year, COALESCE(e.term_id, 0) AS term_id, e.group_name`
+
+func (s *Store) ListEnrollments(ctx context.Context, f models.EnrollmentFilter) ([]models.Enrollment, error) {
+	var enrollments []models.Enrollment
+	err := s.db.SelectContext(ctx, &enrollments, `
+		SELECT `+enrollmentColumns+`
+		FROM enrollments e
+		JOIN students s ON s.id = e.student_id
+		JOIN subjects sub ON sub.id = e.subject_id
+		WHERE ($1 = 0 OR e.student_id = $1)
+		  AND ($2 = 0 OR e.subject_id = $2)
+		  AND ($3 = 0 OR e.teacher_id = $3)
+		  AND ($4 = 0 OR s.class_id = $4)
+		  AND ($5 = 0 OR e.academic_year = $5)
+		  AND ($6 = 0 OR e.term_id = $6 OR (e.term_id IS NULL AND e.academic_year = (
+		      SELECT y.start_year FROM terms t JOIN academic_years y ON y.id = t.academic_year_id WHERE t.id = $6)))
+		ORDER BY e.academic_year DESC, sub.name, e.group_name, s.full_name, e.id`,
+		f.StudentID, f.SubjectID, f.TeacherID, f.ClassID, f.AcademicYear, f.TermID)
+	return enrollments, err
+}
+
+func (s *Store) GetEnrollment(ctx context.Context, id int) (*models.Enrollment, error) {
+	var enrollment models.Enrollment
+	err := s.db.GetContext(ctx, &enrollment, `SELECT `+enrollmentColumns+` FROM enrollments e WHERE e.id = $1`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &enrollment, nil
+}
+
+// resolveEnrollment подставляет учебный год периода и учителя, который ведет предмет
+// в классе classID этого года (назначение на период важнее назначения на весь год);
+// ErrReference, если периода нет
+func resolveEnrollment(ctx context.Context, tx *sqlx.Tx, e *models.Enrollment, classID int) error {
+	if e.TermID != 0 {
+		if err := tx.GetContext(ctx, &e.AcademicYear, `
+			SELECT y.start_year FROM terms t JOIN academic_years y ON y.id = t.academic_year_id
+			WHERE t.id = $1`, e.TermID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return store.ErrReference
+			}
+			return err
+		}
+	}
+	if e.TeacherID == 0 {
+		return tx.GetContext(ctx, &e.TeacherID, `
+			SELECT COALESCE((
+				SELECT a.teacher_id FROM teaching_assignments a JOIN classes c ON c.id = a.class_id
+				WHERE a.class_id = $1 AND a.subject_id = $2 AND c.academic_year = $3
+				  AND (a.term_id IS NULL OR a.term_id = $4)
+				ORDER BY a.term_id IS NULL, a.id
+				LIMIT 1
+			), 0)`, classID, e.SubjectID, e.AcademicYear, e.TermID)
+	}
+	return nil
+}
+
+// studentClass возвращает класс ученика (0 — без класса); ErrReference, если ученика нет
+func studentClass(ctx context.Context, tx *sqlx.Tx, studentID int) (int, error) {
+	var classID int
+	err := tx.GetContext(ctx, &classID, `SELECT COALESCE(class_id, 0) FROM students WHERE id = $1`, studentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, store.ErrReference
+	}
+	return classID, err
+}
+
+func (s *Store) CreateEnrollment(ctx context.Context, enrollment *models.Enrollment) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		classID, err := studentClass(ctx, tx, enrollment.StudentID)
+		if err != nil {
+			return err
+		}
+		if err := resolveEnrollment(ctx, tx, enrollment, classID); err != nil {
+			return err
+		}
+		return tx.QueryRowxContext(ctx, `
+			INSERT INTO enrollments (student_id, subject_id, teacher_id, academic_year, term_id, group_name)
+			VALUES ($1, $2, NULLIF($3, 0), $4, NULLIF($5, 0), $6)
+			RETURNING id`,
+			enrollment.StudentID, enrollment.SubjectID, enrollment.TeacherID,
+			enrollment.AcademicYear, enrollment.TermID, enrollment.GroupName,
+		).Scan(&enrollment.ID)
+	})
+}
+
+// UpdateEnrollment меняет учителя и группу и заполняет остальные поля зачисления
+func (s *Store) UpdateEnrollment(ctx context.Context, enrollment *models.Enrollment) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		var existing models.Enrollment
+		if err := tx.GetContext(ctx, &existing,
+			`SELECT `+enrollmentColumns+` FROM enrollments e WHERE e.id = $1 FOR UPDATE`, enrollment.ID); err != nil {
+			return err
+		}
+		existing.TeacherID, existing.GroupName = enrollment.TeacherID, enrollment.GroupName
+		classID, err := studentClass(ctx, tx, existing.StudentID)
+		if err != nil {
+			return err
+		}
+		if err := resolveEnrollment(ctx, tx, &existing, classID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE enrollments SET teacher_id = NULLIF($1, 0), group_name = $2 WHERE id = $3`,
+			existing.TeacherID, existing.GroupName, existing.ID); err != nil {
+			return err
+		}
+		*enrollment = existing
+		return nil
+	})
+}
+
+func (s *Store) DeleteEnrollment(ctx context.Context, id int) error {
+	return expectRows(s.db.ExecContext(ctx, `DELETE FROM enrollments WHERE id = $1`, id))
+}
+
+// EnrollClass зачисляет учеников класса одним запросом; выпускники не зачисляются
+func (s *Store) EnrollClass(ctx context.Context, classID int, enrollment models.Enrollment) (int, error) {
+	count := 0
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &enrollment.AcademicYear,
+			`SELECT academic_year FROM classes WHERE id = $1`, classID); err != nil {
+			return err
+		}
+		classYear := enrollment.AcademicYear
+		if err := resolveEnrollment(ctx, tx, &enrollment, classID); err != nil {
+			return err
+		}
+		if enrollment.AcademicYear != classYear {
+			return store.ErrReference
+		}
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO enrollments (student_id, subject_id, teacher_id, academic_year, term_id, group_name)
+			SELECT s.id, $2, NULLIF($3, 0), $4, NULLIF($5, 0), $6
+			FROM students s
+			WHERE s.class_id = $1 AND s.graduated_year IS NULL
+			ON CONFLICT DO NOTHING`,
+			classID, enrollment.SubjectID, enrollment.TeacherID, enrollment.AcademicYear,
+			enrollment.TermID, enrollment.GroupName)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		count = int(n)
+		return err
+	})
+	return count, err
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const gradeColumns = `g.id, g.student_id, g.subject_id, g.grade, g.term_id, g.quarter,
+	g.date, g.kind, COALESCE(g.weight, 0) AS weight, g.comment, COALESCE(g.homework_id, 0) AS homework_id`
+
+// periodJoins присоединяет к оценкам g их период t и учебный год y
+const periodJoins = `
+		JOIN terms t ON t.id = g.term_id
+		JOIN academic_years y ON y.id = t.academic_year_id`
+
+// periodFilter отбирает оценки учебного года из параметра $n и периода из $n+1 (0 — весь год)
+func periodFilter(n int) string {
+	return fmt.Sprintf("y.start_year = $%d AND ($%d = 0 OR t.number = $%d)", n, n+1, n+1)
+}
+
+func (s *Store) ListGrades(ctx context.Context) ([]models.Grade, error) {
+	var grades []models.Grade
+	err := s.db.SelectContext(ctx, &grades, `SELECT `+gradeColumns+` FROM grades g ORDER BY g.id`)
+	return grades, err
+}
+
+func (s *Store) GetGrade(ctx context.Context, id int) (*models.Grade, error) {
+	return getGrade(ctx, s.db, id)
+}
+
+func getGrade(ctx context.Context, q sqlx.QueryerContext, id int) (*models.Grade, error) {
+	var grade models.Grade
+	err := sqlx.GetContext(ctx, q, &grade, `SELECT `+gradeColumns+` FROM grades g WHERE g.id = $1`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &grade, nil
+}
+
+// lockGrade возвращает оценку, блокируя ее до конца транзакции
+func lockGrade(ctx context.Context, tx *sqlx.Tx, id int) (*models.Grade, error) {
+	var grade models.Grade
+	err := tx.GetContext(ctx, &grade, `SELECT `+gradeColumns+` FROM grades g WHERE g.id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &grade, nil
+}
+
+// CreateGrade добавляет оценку и заполняет её ID и номер периода; ErrReference, если периода нет.
+// Без вида отметка считается работой на уроке, без даты — получает ближайшую к сегодняшней дату периода.
+func (s *Store) CreateGrade(ctx context.Context, grade *models.Grade) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.QueryRowxContext(ctx, `
+			INSERT INTO grades (student_id, subject_id, grade, term_id, quarter, date, kind, weight, comment, homework_id)
+			SELECT $1, $2, $3, t.id, t.number,
+				COALESCE($5::DATE, LEAST(t.ends_on, GREATEST(t.starts_on, CURRENT_DATE))),
+				COALESCE(NULLIF($6, ''), 'classwork'), NULLIF($7::NUMERIC, 0), $8, NULLIF($9, 0)
+			FROM terms t WHERE t.id = $4
+			RETURNING id, quarter, date, kind`,
+			grade.StudentID, grade.SubjectID, grade.Grade, grade.TermID,
+			grade.Date, grade.Kind, grade.Weight, grade.Comment, grade.HomeworkID,
+		).Scan(&grade.ID, &grade.Quarter, &grade.Date, &grade.Kind)
+		if errors.Is(err, sql.ErrNoRows) {
+			return store.ErrReference
+		}
+		if err != nil {
+			return err
+		}
+		saved, err := getGrade(ctx, tx, grade.ID)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditCreate, models.AuditGrade, grade.ID, nil, saved)
+	})
+}
+
+// UpdateGrade меняет оценку и заполняет номер периода; ErrReference, если периода нет
+func (s *Store) UpdateGrade(ctx context.Context, grade *models.Grade) error {
+	var term models.Term
+	if err := s.db.GetContext(ctx, &term, `SELECT `+termColumns+` FROM terms WHERE id = $1`, grade.TermID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return store.ErrReference
+		}
+		return err
+	}
+	grade.Quarter = term.Number
+	if grade.Kind == "" {
+		grade.Kind = models.MarkKindClasswork
+	}
+	if grade.Date.IsZero() {
+		grade.Date = term.Clamp(models.NewDate(time.Now()))
+	}
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		old, err := lockGrade(ctx, tx, grade.ID)
+		if err != nil {
+			return err
+		}
+		if err := expectRows(tx.ExecContext(ctx, `
+			UPDATE grades SET student_id = $1, subject_id = $2, grade = $3, term_id = $4, quarter = $5,
+				date = $6, kind = $7, weight = NULLIF($8::NUMERIC, 0), comment = $9
+			WHERE id = $10`,
+			grade.StudentID, grade.SubjectID, grade.Grade, grade.TermID, grade.Quarter,
+			grade.Date, grade.Kind, grade.Weight, grade.Comment, grade.ID)); err != nil {
+			return err
+		}
+		saved, err := getGrade(ctx, tx, grade.ID)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditUpdate, models.AuditGrade, grade.ID, old, saved)
+	})
+}
+
+func (s *Store) DeleteGrade(ctx context.Context, id int) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		old, err := lockGrade(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := expectRows(tx.ExecContext(ctx, `DELETE FROM grades WHERE id = $1`, id)); err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditDelete, models.AuditGrade, id, old, nil)
+	})
+}
+
+// GetStudentGrades возвращает оценки ученика (0 — всех учеников) с названиями предметов
+func (s *Store) GetStudentGrades(ctx context.Context, studentID int, period models.Period) ([]models.GradeWithSubject, error) {
+	var grades []models.GradeWithSubject
+	query := `
+		SELECT ` + gradeColumns + `, sub.name AS subject_name
+		FROM grades g
+		JOIN subjects sub ON g.subject_id = sub.id` + periodJoins + `
+		WHERE ($1 = 0 OR g.student_id = $1) AND ` + periodFilter(2) + `
+		ORDER BY sub.name, g.quarter, g.date, g.id
+	`
+	err := s.db.SelectContext(ctx, &grades, query, studentID, period.AcademicYear, period.Term)
+	return grades, err
+}
+
+// GetGradesByTeacher возвращает оценки по предметам, которые учитель ведет у ученика,
+// сгруппированные по ученикам. Зачисление или назначение на период охватывает только
+// оценки этого периода.
+func (s *Store) GetGradesByTeacher(ctx context.Context, teacherID int, period models.Period) (map[int][]models.Grade, error) {
+	query := `
+		SELECT ` + gradeColumns + `
+		FROM grades g
+		JOIN students s ON g.student_id = s.id` + periodJoins + `
+		WHERE ` + periodFilter(2) + ` AND ` + teachesStudent("$1", "g.subject_id", "y.start_year", termOfGrade) + `
+		ORDER BY s.full_name, g.quarter
+	`
+	var grades []models.Grade
+	if err := s.db.SelectContext(ctx, &grades, query, teacherID, period.AcademicYear, period.Term); err != nil {
+		log.Printf("Ошибка при выполнении запроса: %v", err)
+		return nil, err
+	}
+
+	// Мапа для хранения оценок по ученикам
+	studentGrades := make(map[int][]models.Grade)
+	for _, grade := range grades {
+		studentGrades[grade.StudentID] = append(studentGrades[grade.StudentID], grade)
+	}
+
+	log.Printf("Найдено оценок для %d учеников", len(studentGrades))
+	return studentGrades, nil
+}
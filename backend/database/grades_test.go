@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"school-system/backend/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// testStore подключается к тестовой базе из TEST_DB_DSN и применяет миграции;
+// без переменной тест пропускается
+func testStore(t *testing.T) *Store {
+	t.Helper()
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN не задана: тест требует PostgreSQL")
+	}
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("Ошибка подключения к тестовой базе: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("Ошибка загрузки миграций: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Ошибка применения миграций: %v", err)
+	}
+	return NewStore(db)
+}
+
+func TestGradeFractionalWeight(t *testing.T) {
+	st := testStore(t)
+	ctx := context.Background()
+
+	const startYear = 2100
+	year := models.AcademicYear{StartYear: startYear, TermKind: models.TermKindQuarter, Terms: models.DefaultTerms(startYear, models.TermKindQuarter)}
+	year.StartsOn = year.Terms[0].StartsOn
+	year.EndsOn = year.Terms[len(year.Terms)-1].EndsOn
+	if err := st.CreateAcademicYear(ctx, &year); err != nil {
+		t.Fatalf("Ошибка создания учебного года: %v", err)
+	}
+	t.Cleanup(func() { st.DeleteAcademicYear(ctx, startYear) })
+	subject := &models.Subject{Name: "Тестовый предмет с дробным весом"}
+	if err := st.CreateSubject(ctx, subject); err != nil {
+		t.Fatalf("Ошибка создания предмета: %v", err)
+	}
+	t.Cleanup(func() { st.DeleteSubject(ctx, subject.ID) })
+	student := &models.Student{FullName: "Тестовый ученик"}
+	if err := st.CreateStudent(ctx, student); err != nil {
+		t.Fatalf("Ошибка создания ученика: %v", err)
+	}
+	t.Cleanup(func() { st.DeleteStudent(ctx, student.ID) })
+
+	grade := &models.Grade{StudentID: student.ID, SubjectID: subject.ID, Grade: 5, TermID: year.Terms[0].ID, Weight: 1.5}
+	if err := st.CreateGrade(ctx, grade); err != nil {
+		t.Fatalf("Ошибка создания оценки с весом 1.5: %v", err)
+	}
+	t.Cleanup(func() { st.DeleteGrade(ctx, grade.ID) })
+	saved, err := st.GetGrade(ctx, grade.ID)
+	if err != nil || saved.Weight != 1.5 {
+		t.Fatalf("Ожидался вес 1.5, получено %+v, %v", saved, err)
+	}
+
+	grade.Weight = 0.25
+	if err := st.UpdateGrade(ctx, grade); err != nil {
+		t.Fatalf("Ошибка изменения веса на 0.25: %v", err)
+	}
+	if saved, err = st.GetGrade(ctx, grade.ID); err != nil || saved.Weight != 0.25 {
+		t.Errorf("Ожидался вес 0.25, получено %+v, %v", saved, err)
+	}
+
+	// Нулевой вес означает вес по виду отметки и хранится как NULL
+	grade.Weight = 0
+	if err := st.UpdateGrade(ctx, grade); err != nil {
+		t.Fatalf("Ошибка сброса веса: %v", err)
+	}
+	var weight *float64
+	if err := st.db.GetContext(ctx, &weight, `SELECT weight FROM grades WHERE id = $1`, grade.ID); err != nil || weight != nil {
+		t.Errorf("Нулевой вес должен храниться как NULL, получено %v, %v", weight, err)
+	}
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+const gradingScaleColumns = `id, name, min_value, max_value, pass_value, is_default`
+
+// ListGradingScales возвращает шкалы с подписями двумя запросами, без N+1
+func (s *Store) ListGradingScales(ctx context.Context) ([]models.GradingScale, error) {
+	var scales []models.GradingScale
+	if err := s.db.SelectContext(ctx, &scales,
+		`SELECT `+gradingScaleColumns+` FROM grading_scales ORDER BY id`); err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		ScaleID int `db:"scale_id"`
+		models.ScaleLabel
+	}
+	if err := s.db.SelectContext(ctx, &rows,
+		`SELECT scale_id, value, label FROM grading_scale_labels ORDER BY scale_id, value`); err != nil {
+		return nil, err
+	}
+	byScale := make(map[int][]models.ScaleLabel)
+	for _, row := range rows {
+		byScale[row.ScaleID] = append(byScale[row.ScaleID], row.ScaleLabel)
+	}
+	for i := range scales {
+		scales[i].Labels = byScale[scales[i].ID]
+		if scales[i].Labels == nil {
+			scales[i].Labels = []models.ScaleLabel{}
+		}
+	}
+	return scales, nil
+}
+
+func (s *Store) GetGradingScale(ctx context.Context, id int) (*models.GradingScale, error) {
+	return s.getGradingScale(ctx, `id = $1`, id)
+}
+
+func (s *Store) GetDefaultGradingScale(ctx context.Context) (*models.GradingScale, error) {
+	return s.getGradingScale(ctx, `is_default`)
+}
+
+func (s *Store) getGradingScale(ctx context.Context, where string, args ...interface{}) (*models.GradingScale, error) {
+	var scale models.GradingScale
+	if err := s.db.GetContext(ctx, &scale,
+		`SELECT `+gradingScaleColumns+` FROM grading_scales WHERE `+where, args...); err != nil {
+		return nil, mapError(err)
+	}
+	scale.Labels = []models.ScaleLabel{}
+	if err := s.db.SelectContext(ctx, &scale.Labels,
+		`SELECT value, label FROM grading_scale_labels WHERE scale_id = $1 ORDER BY value`, scale.ID); err != nil {
+		return nil, err
+	}
+	return &scale, nil
+}
+
+// CreateGradingScale добавляет шкалу с подписями в одной транзакции
+func (s *Store) CreateGradingScale(ctx context.Context, scale *models.GradingScale) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if scale.IsDefault {
+			if _, err := tx.ExecContext(ctx, `UPDATE grading_scales SET is_default = FALSE WHERE is_default`); err != nil {
+				return err
+			}
+		}
+		if err := tx.QueryRowxContext(ctx, `
+			INSERT INTO grading_scales (name, min_value, max_value, pass_value, is_default)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			scale.Name, scale.MinValue, scale.MaxValue, scale.PassValue, scale.IsDefault,
+		).Scan(&scale.ID); err != nil {
+			return err
+		}
+		return insertScaleLabels(ctx, tx, scale.ID, scale.Labels)
+	})
+}
+
+// UpdateGradingScale меняет шкалу и заменяет ее подписи. Снять отметку шкалы школы
+// нельзя (ErrConflict) — вместо этого отмечается другая шкала.
+func (s *Store) UpdateGradingScale(ctx context.Context, scale *models.GradingScale) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		var current models.GradingScale
+		if err := tx.GetContext(ctx, &current,
+			`SELECT `+gradingScaleColumns+` FROM grading_scales WHERE id = $1 FOR UPDATE`, scale.ID); err != nil {
+			return err
+		}
+		if current.IsDefault && !scale.IsDefault {
+			return store.ErrConflict
+		}
+		if scale.IsDefault && !current.IsDefault {
+			if _, err := tx.ExecContext(ctx, `UPDATE grading_scales SET is_default = FALSE WHERE is_default`); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE grading_scales SET name = $1, pass_value = $2, is_default = $3 WHERE id = $4`,
+			scale.Name, scale.PassValue, scale.IsDefault, scale.ID); err != nil {
+			return err
+		}
+		scale.MinValue, scale.MaxValue = current.MinValue, current.MaxValue
+		if _, err := tx.ExecContext(ctx, `DELETE FROM grading_scale_labels WHERE scale_id = $1`, scale.ID); err != nil {
+			return err
+		}
+		return insertScaleLabels(ctx, tx, scale.ID, scale.Labels)
+	})
+}
+
+// DeleteGradingScale удаляет шкалу; шкалу школы удалить нельзя (ErrConflict),
+// а шкалу, назначенную предметам, не дает удалить внешний ключ (ErrReference)
+func (s *Store) DeleteGradingScale(ctx context.Context, id int) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		var isDefault bool
+		if err := tx.GetContext(ctx, &isDefault, `SELECT is_default FROM grading_scales WHERE id = $1`, id); err != nil {
+			return err
+		}
+		if isDefault {
+			return store.ErrConflict
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM grading_scales WHERE id = $1`, id)
+		return err
+	})
+}
+
+func insertScaleLabels(ctx context.Context, tx *sqlx.Tx, scaleID int, labels []models.ScaleLabel) error {
+	for _, l := range labels {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO grading_scale_labels (scale_id, value, label) VALUES ($1, $2, $3)`,
+			scaleID, l.Value, l.Label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+
+	"school-system/backend/models"
+)
+
+func (s *Store) AddGuardian(ctx context.Context, guardian *models.Guardian) error {
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO guardians (user_id, student_id, relationship) VALUES ($1, $2, $3)
+		RETURNING created_at`,
+		guardian.UserID, guardian.StudentID, guardian.Relationship,
+	).Scan(&guardian.CreatedAt)
+	return mapError(err)
+}
+
+func (s *Store) RemoveGuardian(ctx context.Context, userID, studentID int) error {
+	return expectRows(s.db.ExecContext(ctx,
+		`DELETE FROM guardians WHERE user_id = $1 AND student_id = $2`, userID, studentID))
+}
+
+func (s *Store) ListGuardians(ctx context.Context, studentID int) ([]models.Guardian, error) {
+	var guardians []models.Guardian
+	err := s.db.SelectContext(ctx, &guardians, `
+		SELECT g.user_id, u.username, g.student_id, g.relationship, g.created_at
+		FROM guardians g
+		JOIN users u ON u.id = g.user_id
+		WHERE g.student_id = $1
+		ORDER BY u.username`, studentID)
+	return guardians, err
+}
+
+func (s *Store) ListChildren(ctx context.Context, userID int) ([]models.Child, error) {
+	var children []models.Child
+	err := s.db.SelectContext(ctx, &children, `
+		SELECT `+studentColumns+`, g.relationship
+		FROM guardians g
+		JOIN students s ON s.id = g.student_id
+		WHERE g.user_id = $1
+		ORDER BY s.full_name`, userID)
+	return children, err
+}
+
+func (s *Store) IsGuardian(ctx context.Context, userID, studentID int) (bool, error) {
+	var exists bool
+	err := s.db.GetContext(ctx, &exists,
+		`SELECT EXISTS (SELECT 1 FROM guardians WHERE user_id = $1 AND student_id = $2)`, userID, studentID)
+	return exists, err
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+
+	"school-system/backend/models"
+)
+
+const homeworkColumns = `h.id, h.class_id, h.subject_id, COALESCE(h.teacher_id, 0) AS teacher_id, h.title,
+	h.description, h.due_date, h.created_at, c.grade_level || c.letter AS class_name,
+	sub.name AS subject_name, COALESCE(t.full_name, '') AS teacher_name`
+
+const homeworkJoins = `
+		FROM homework h
+		JOIN classes c ON c.id = h.class_id
+		JOIN subjects sub ON sub.id = h.subject_id
+		LEFT JOIN teachers t ON t.id = h.teacher_id`
+
+// submissionColumns — поля работы без содержимого файла; отметка ищется по заданию и ученику
+const submissionColumns = `hs.id, hs.homework_id, hs.student_id, st.full_name AS student_name, hs.status,
+	hs.text, hs.file_name, hs.file_type, COALESCE(octet_length(hs.file), 0) AS file_size, hs.submitted_at,
+	COALESCE(g.id, 0) AS grade_id, COALESCE(g.grade, 0) AS grade`
+
+const submissionJoins = `
+		FROM homework_submissions hs
+		JOIN students st ON st.id = hs.student_id
+		LEFT JOIN grades g ON g.homework_id = hs.homework_id AND g.student_id = hs.student_id`
+
+func (s *Store) ListHomework(ctx context.Context, f models.HomeworkFilter) ([]models.Homework, error) {
+	var homework []models.Homework
+	err := s.db.SelectContext(ctx, &homework, `
+		SELECT `+homeworkColumns+homeworkJoins+`
+		WHERE ($1 = 0 OR h.class_id = $1)
+		  AND ($2 = 0 OR h.subject_id = $2)
+		  AND ($3 = 0 OR h.teacher_id = $3)
+		  AND ($4::DATE IS NULL OR h.due_date >= $4) AND ($5::DATE IS NULL OR h.due_date <= $5)
+		ORDER BY h.due_date, h.id`,
+		f.ClassID, f.SubjectID, f.TeacherID, f.From, f.To)
+	return homework, err
+}
+
+func (s *Store) GetHomework(ctx context.Context, id int) (*models.Homework, error) {
+	var homework models.Homework
+	err := s.db.GetContext(ctx, &homework, `SELECT `+homeworkColumns+homeworkJoins+` WHERE h.id = $1`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &homework, nil
+}
+
+func (s *Store) CreateHomework(ctx context.Context, homework *models.Homework) error {
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO homework (class_id, subject_id, teacher_id, title, description, due_date)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6)
+		RETURNING id, created_at`,
+		homework.ClassID, homework.SubjectID, homework.TeacherID,
+		homework.Title, homework.Description, homework.DueDate,
+	).Scan(&homework.ID, &homework.CreatedAt)
+	return mapError(err)
+}
+
+func (s *Store) UpdateHomework(ctx context.Context, homework *models.Homework) error {
+	return expectRows(s.db.ExecContext(ctx, `
+		UPDATE homework SET title = $1, description = $2, due_date = $3 WHERE id = $4`,
+		homework.Title, homework.Description, homework.DueDate, homework.ID))
+}
+
+func (s *Store) DeleteHomework(ctx context.Context, id int) error {
+	return expectRows(s.db.ExecContext(ctx, `DELETE FROM homework WHERE id = $1`, id))
+}
+
+func (s *Store) ListSubmissions(ctx context.Context, homeworkID, studentID int) ([]models.Submission, error) {
+	var submissions []models.Submission
+	err := s.db.SelectContext(ctx, &submissions, `
+		SELECT `+submissionColumns+submissionJoins+`
+		WHERE hs.homework_id = $1 AND ($2 = 0 OR hs.student_id = $2)
+		ORDER BY st.full_name, hs.student_id`, homeworkID, studentID)
+	return submissions, err
+}
+
+func (s *Store) GetSubmission(ctx context.Context, homeworkID, studentID int) (*models.Submission, error) {
+	var submission models.Submission
+	err := s.db.GetContext(ctx, &submission, `
+		SELECT `+submissionColumns+`, hs.file`+submissionJoins+`
+		WHERE hs.homework_id = $1 AND hs.student_id = $2`, homeworkID, studentID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &submission, nil
+}
+
+// SaveSubmission сохраняет работу и заполняет её ID и время сдачи
+func (s *Store) SaveSubmission(ctx context.Context, submission *models.Submission) error {
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO homework_submissions (homework_id, student_id, status, text, file_name, file_type, file)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (homework_id, student_id) DO UPDATE SET
+			status = EXCLUDED.status, text = EXCLUDED.text, file_name = EXCLUDED.file_name,
+			file_type = EXCLUDED.file_type, file = EXCLUDED.file, submitted_at = now()
+		RETURNING id, submitted_at`,
+		submission.HomeworkID, submission.StudentID, submission.Status, submission.Text,
+		submission.FileName, submission.FileType, submission.File,
+	).Scan(&submission.ID, &submission.SubmittedAt)
+	submission.FileSize = len(submission.File)
+	return mapError(err)
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+const invitationColumns = `id, code_hash, role,
+	COALESCE(student_id, 0) AS student_id, COALESCE(teacher_id, 0) AS teacher_id,
+	COALESCE(created_by, 0) AS created_by, expires_at, created_at, used_at,
+	COALESCE(used_by, 0) AS used_by`
+
+// CreateInvitation сохраняет приглашение и заполняет его ID
+func (s *Store) CreateInvitation(ctx context.Context, inv *models.Invitation) error {
+	err := s.db.GetContext(ctx, inv, `
+		INSERT INTO invitations (code_hash, role, student_id, teacher_id, created_by, expires_at)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), NULLIF($5, 0), $6)
+		RETURNING `+invitationColumns,
+		inv.CodeHash, inv.Role, inv.StudentID, inv.TeacherID, inv.CreatedBy, inv.ExpiresAt)
+	return mapError(err)
+}
+
+func (s *Store) ListInvitations(ctx context.Context) ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	err := s.db.SelectContext(ctx, &invitations, `SELECT `+invitationColumns+` FROM invitations ORDER BY id`)
+	return invitations, err
+}
+
+// DeleteInvitation удаляет неиспользованное приглашение
+func (s *Store) DeleteInvitation(ctx context.Context, id int) error {
+	return expectRows(s.db.ExecContext(ctx, `DELETE FROM invitations WHERE id = $1 AND used_at IS NULL`, id))
+}
+
+// RegisterWithInvitation создает пользователя по приглашению в одной транзакции
+func (s *Store) RegisterWithInvitation(ctx context.Context, codeHash string, user *models.User, now time.Time) (*models.Invitation, error) {
+	var inv models.Invitation
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &inv, `
+			SELECT `+invitationColumns+` FROM invitations
+			WHERE code_hash = $1 AND used_at IS NULL AND expires_at > $2
+			FOR UPDATE`, codeHash, now); err != nil {
+			return err
+		}
+
+		user.Role = inv.Role
+		if err := tx.QueryRowxContext(ctx,
+			`INSERT INTO users (username, password, role, must_change_password) VALUES ($1, $2, $3, $4) RETURNING id`,
+			user.Username, user.Password, user.Role, user.MustChangePassword,
+		).Scan(&user.ID); err != nil {
+			return err
+		}
+
+		if inv.StudentID != 0 {
+			if err := linkUser(ctx, tx, `UPDATE students SET user_id = $2 WHERE id = $1 AND user_id IS NULL`, inv.StudentID, user.ID); err != nil {
+				return err
+			}
+		}
+		if inv.TeacherID != 0 {
+			if err := linkUser(ctx, tx, `UPDATE teachers SET user_id = $2 WHERE id = $1 AND user_id IS NULL`, inv.TeacherID, user.ID); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx,
+			`UPDATE invitations SET used_at = $2, used_by = $3 WHERE id = $1`, inv.ID, now, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	inv.UsedAt = &now
+	inv.UsedBy = user.ID
+	return &inv, nil
+}
+
+// linkUser привязывает учетную запись к карточке; ErrConflict, если карточка уже привязана
+func linkUser(ctx context.Context, tx *sqlx.Tx, query string, id, userID int) error {
+	res, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return store.ErrConflict
+	}
+	return nil
+}
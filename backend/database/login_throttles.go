@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"school-system/backend/models"
+)
+
+const loginThrottleColumns = `scope, key, failures, last_failure_at, locked_until`
+
+func (s *Store) GetLoginThrottle(ctx context.Context, scope, key string) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := s.db.GetContext(ctx, &throttle,
+		`SELECT `+loginThrottleColumns+` FROM login_throttles WHERE scope = $1 AND key = $2`, scope, key)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &throttle, nil
+}
+
+// RecordLoginFailure атомарно увеличивает счетчик неудачных попыток
+func (s *Store) RecordLoginFailure(ctx context.Context, scope, key string, now time.Time, window time.Duration) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := s.db.GetContext(ctx, &throttle, `
+		INSERT INTO login_throttles (scope, key, failures, last_failure_at) VALUES ($1, $2, 1, $3)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failures = CASE
+				WHEN login_throttles.last_failure_at < $3 - make_interval(secs => $4) THEN 1
+				ELSE login_throttles.failures + 1
+			END,
+			last_failure_at = $3
+		RETURNING `+loginThrottleColumns,
+		scope, key, now, window.Seconds())
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &throttle, nil
+}
+
+func (s *Store) LockLogin(ctx context.Context, scope, key string, until time.Time) error {
+	return expectRows(s.db.ExecContext(ctx,
+		`UPDATE login_throttles SET locked_until = $3 WHERE scope = $1 AND key = $2`, scope, key, until))
+}
+
+func (s *Store) ListLoginThrottles(ctx context.Context) ([]models.LoginThrottle, error) {
+	var throttles []models.LoginThrottle
+	err := s.db.SelectContext(ctx, &throttles,
+		`SELECT `+loginThrottleColumns+` FROM login_throttles ORDER BY last_failure_at DESC`)
+	return throttles, err
+}
+
+func (s *Store) ClearLoginThrottle(ctx context.Context, scope, key string) error {
+	return expectRows(s.db.ExecContext(ctx,
+		`DELETE FROM login_throttles WHERE scope = $1 AND key = $2`, scope, key))
+}
+
+func (s *Store) DeleteStaleLoginThrottles(ctx context.Context, before time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM login_throttles
+		WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < $1)`, before)
+	return err
+}
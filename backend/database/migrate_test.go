@@ -0,0 +1,84 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"school-system/backend/models"
+)
+
+func TestEmbeddedMigrationsAreValid(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatalf("Встроенные миграции не загружаются: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Не найдено ни одной миграции")
+	}
+	for i, mig := range migrations {
+		if i > 0 && mig.Version <= migrations[i-1].Version {
+			t.Errorf("Миграции не упорядочены: %d после %d", mig.Version, migrations[i-1].Version)
+		}
+		if len(mig.Checksum) != 64 {
+			t.Errorf("Некорректная контрольная сумма у %04d_%s", mig.Version, mig.Name)
+		}
+	}
+}
+
+func TestLoadMigrationsOrderAndChecksum(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_second.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"m/0002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+		"m/0001_first.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+		"m/0001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+	}
+
+	migrations, err := loadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "first" || migrations[1].Name != "second" {
+		t.Fatalf("Неверный порядок миграций: %+v", migrations)
+	}
+
+	fsys["m/0001_first.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE a (id BIGINT);")}
+	changed, err := loadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if changed[0].Checksum == migrations[0].Checksum {
+		t.Error("Контрольная сумма не изменилась после правки миграции")
+	}
+
+	verifier := &Migrator{migrations: changed}
+	applied := map[int]appliedMigration{1: {Version: 1, Name: "first", Checksum: migrations[0].Checksum}}
+	if err := verifier.verify(applied); err == nil || !strings.Contains(err.Error(), "0001_first") {
+		t.Errorf("Ожидалась ошибка несовпадения контрольной суммы, получено: %v", err)
+	}
+}
+
+func TestLoadMigrationsRequiresDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0001_first.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+	}
+	if _, err := loadMigrations(fsys, "m"); err == nil {
+		t.Error("Ожидалась ошибка для миграции без .down.sql")
+	}
+}
+
+func TestPermissionsMigrationsSeedAllPermissions(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatalf("Встроенные миграции не загружаются: %v", err)
+	}
+	var all strings.Builder
+	for _, mig := range migrations {
+		all.WriteString(mig.Up)
+	}
+	for _, p := range models.Permissions {
+		if !strings.Contains(all.String(), "'"+p.Name+"'") {
+			t.Errorf("Право %s не добавлено миграциями", p.Name)
+		}
+	}
+}
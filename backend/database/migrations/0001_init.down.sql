@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS grades;
+DROP TABLE IF EXISTS students;
+DROP TABLE IF EXISTS subjects;
+DROP TABLE IF EXISTS teachers;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,45 @@
+-- Базовая схема: пользователи, учителя, предметы, ученики и оценки.
+-- IF NOT EXISTS позволяет подключить мигратор к базе, созданной вручную.
+
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL UNIQUE,
+    password VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS teachers (
+    id SERIAL PRIMARY KEY,
+    full_name VARCHAR(255) NOT NULL,
+    room_number VARCHAR(50) NOT NULL DEFAULT '',
+    user_id INTEGER UNIQUE REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS subjects (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_subjects_name ON subjects(name);
+CREATE INDEX IF NOT EXISTS idx_subjects_teacher_id ON subjects(teacher_id);
+
+CREATE TABLE IF NOT EXISTS students (
+    id SERIAL PRIMARY KEY,
+    full_name VARCHAR(255) NOT NULL,
+    class_name VARCHAR(20) NOT NULL,
+    user_id INTEGER UNIQUE REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_students_class_name ON students(class_name);
+
+CREATE TABLE IF NOT EXISTS grades (
+    id SERIAL PRIMARY KEY,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    grade INTEGER NOT NULL CHECK (grade BETWEEN 1 AND 5),
+    quarter INTEGER NOT NULL CHECK (quarter BETWEEN 1 AND 4)
+);
+
+CREATE INDEX IF NOT EXISTS idx_grades_student_id ON grades(student_id);
+CREATE INDEX IF NOT EXISTS idx_grades_subject_id ON grades(subject_id);
@@ -0,0 +1,21 @@
+-- Приглашения для регистрации. Роль учетной записи задается приглашением,
+-- а не телом запроса /register. Код хранится только в виде SHA-256 хеша.
+
+CREATE TABLE invitations (
+    id SERIAL PRIMARY KEY,
+    code_hash CHAR(64) NOT NULL UNIQUE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('student', 'teacher', 'deputy')),
+    -- карточка ученика или учителя, к которой будет привязана учетная запись
+    student_id INTEGER REFERENCES students(id) ON DELETE CASCADE,
+    teacher_id INTEGER REFERENCES teachers(id) ON DELETE CASCADE,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    used_at TIMESTAMPTZ,
+    used_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    CHECK (student_id IS NULL OR role = 'student'),
+    CHECK (teacher_id IS NULL OR role = 'teacher')
+);
+
+CREATE INDEX idx_invitations_student_id ON invitations(student_id);
+CREATE INDEX idx_invitations_teacher_id ON invitations(teacher_id);
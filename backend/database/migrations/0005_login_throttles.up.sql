@@ -0,0 +1,13 @@
+-- Счетчики неудачных попыток входа для защиты от подбора пароля.
+-- scope = 'user' — по логину, scope = 'ip' — по адресу клиента.
+
+CREATE TABLE login_throttles (
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('user', 'ip')),
+    key VARCHAR(255) NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_login_throttles_last_failure_at ON login_throttles(last_failure_at);
@@ -0,0 +1,20 @@
+-- Двухфакторная аутентификация (TOTP, RFC 6238) и коды восстановления.
+
+CREATE TABLE user_totp (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    -- NULL, пока пользователь не подтвердил настройку первым кодом
+    confirmed_at TIMESTAMPTZ,
+    -- последний принятый интервал; защищает от повторного использования кода
+    last_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Одноразовые коды восстановления, хранятся только в виде SHA-256 хеша
+CREATE TABLE recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    UNIQUE (user_id, code_hash)
+);
@@ -0,0 +1,10 @@
+ALTER TABLE invitations DROP CONSTRAINT IF EXISTS invitations_role_fkey;
+DELETE FROM invitations WHERE role NOT IN ('student', 'teacher', 'deputy');
+ALTER TABLE invitations ADD CONSTRAINT invitations_role_check
+    CHECK (role IN ('student', 'teacher', 'deputy'));
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS permissions;
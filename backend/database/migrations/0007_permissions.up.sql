@@ -0,0 +1,53 @@
+-- Права доступа. Роль — это набор прав; проверка в обработчиках идет по праву,
+-- а не по названию роли, поэтому можно заводить новые роли (например, руководитель МО).
+
+CREATE TABLE permissions (
+    name VARCHAR(64) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE roles (
+    name VARCHAR(20) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permissions (
+    role VARCHAR(20) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(64) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+-- Должно совпадать с models.Permissions и models.DefaultRoles
+INSERT INTO permissions (name, description) VALUES
+    ('students:write', 'Добавление и изменение учеников'),
+    ('students:delete', 'Удаление учеников'),
+    ('teachers:write', 'Добавление и изменение учителей'),
+    ('teachers:delete', 'Удаление учителей'),
+    ('subjects:write', 'Добавление и изменение предметов'),
+    ('subjects:delete', 'Удаление предметов'),
+    ('grades:write', 'Выставление и изменение оценок'),
+    ('grades:delete', 'Удаление оценок'),
+    ('stats:read', 'Просмотр статистики успеваемости по школе'),
+    ('users:manage', 'Приглашения, сброс паролей и 2FA, блокировки входа'),
+    ('permissions:manage', 'Управление ролями и правами');
+
+INSERT INTO roles (name, description) VALUES
+    ('student', 'Ученик'),
+    ('teacher', 'Учитель'),
+    ('deputy', 'Завуч');
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'deputy', name FROM permissions;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('teacher', 'grades:write');
+
+-- Роли пользователей и приглашений должны существовать
+INSERT INTO roles (name) SELECT DISTINCT role FROM users ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE users ADD CONSTRAINT users_role_fkey
+    FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+
+ALTER TABLE invitations DROP CONSTRAINT IF EXISTS invitations_role_check;
+ALTER TABLE invitations ADD CONSTRAINT invitations_role_fkey
+    FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+-- Без права grades:all учитель работает только с оценками по своим предметам
+INSERT INTO permissions (name, description) VALUES
+    ('grades:all', 'Оценки по всем предметам, а не только по своим');
+
+INSERT INTO role_permissions (role, permission) VALUES ('deputy', 'grades:all');
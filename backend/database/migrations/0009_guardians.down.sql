@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS guardians;
+
+DELETE FROM roles WHERE name = 'parent' AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'parent');
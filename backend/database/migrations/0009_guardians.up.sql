@@ -0,0 +1,16 @@
+-- Родители (опекуны). Один родитель может быть привязан к нескольким детям,
+-- у ученика может быть несколько родителей.
+
+INSERT INTO roles (name, description) VALUES ('parent', 'Родитель')
+ON CONFLICT (name) DO NOTHING;
+
+CREATE TABLE guardians (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    relationship VARCHAR(20) NOT NULL DEFAULT 'guardian'
+        CHECK (relationship IN ('mother', 'father', 'guardian', 'other')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, student_id)
+);
+
+CREATE INDEX idx_guardians_student_id ON guardians(student_id);
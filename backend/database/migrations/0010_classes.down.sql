@@ -0,0 +1,13 @@
+DELETE FROM permissions WHERE name IN ('classes:write', 'classes:delete');
+
+ALTER TABLE students ADD COLUMN class_name VARCHAR(20) NOT NULL DEFAULT '';
+
+UPDATE students s SET class_name = c.grade_level || c.letter
+FROM classes c
+WHERE c.id = s.class_id;
+
+ALTER TABLE students ALTER COLUMN class_name DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_students_class_name ON students(class_name);
+
+ALTER TABLE students DROP COLUMN class_id;
+DROP TABLE IF EXISTS classes;
@@ -0,0 +1,63 @@
+-- Классы как отдельная сущность вместо произвольной строки students.class_name.
+-- Существующие названия нормализуются: пробелы и дефисы убираются, латинские буквы,
+-- похожие на кириллические, заменяются кириллицей, все приводится к верхнему регистру.
+-- Так "9А", "9A" (латиница) и "9 а" становятся одним классом.
+
+CREATE TABLE classes (
+    id SERIAL PRIMARY KEY,
+    grade_level SMALLINT NOT NULL CHECK (grade_level BETWEEN 1 AND 11),
+    letter VARCHAR(1) NOT NULL,
+    -- год начала учебного года: 2025 означает 2025/2026
+    academic_year INTEGER NOT NULL,
+    homeroom_teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL,
+    UNIQUE (grade_level, letter, academic_year)
+);
+
+ALTER TABLE students ADD COLUMN class_id INTEGER REFERENCES classes(id) ON DELETE RESTRICT;
+CREATE INDEX idx_students_class_id ON students(class_id);
+
+CREATE TEMP TABLE class_name_map ON COMMIT DROP AS
+SELECT DISTINCT
+    class_name AS raw,
+    translate(
+        upper(regexp_replace(class_name, '[[:space:]-]', '', 'g')),
+        'ABCEHKMOPTXYабвгдеёжзийклмнопрстуфхцчшщъыьэюя',
+        'АВСЕНКМОРТХУАБВГДЕЁЖЗИЙКЛМНОПРСТУФХЦЧШЩЪЫЬЭЮЯ'
+    ) AS normalized
+FROM students
+WHERE class_name IS NOT NULL AND btrim(class_name) <> '';
+
+DO $$
+DECLARE
+    bad TEXT;
+BEGIN
+    SELECT string_agg(raw, ', ' ORDER BY raw) INTO bad
+    FROM class_name_map
+    WHERE normalized !~ '^([1-9]|1[01])[А-ЯЁ]$';
+    IF bad IS NOT NULL THEN
+        RAISE EXCEPTION 'Не удалось разобрать названия классов: %. Исправьте students.class_name и повторите миграцию', bad;
+    END IF;
+END $$;
+
+-- Классы создаются в текущем учебном году (он начинается 1 сентября)
+INSERT INTO classes (grade_level, letter, academic_year)
+SELECT DISTINCT
+    substring(normalized FROM '^[0-9]+')::SMALLINT,
+    substring(normalized FROM '.$'),
+    EXTRACT(YEAR FROM now() - INTERVAL '8 months')::INTEGER
+FROM class_name_map;
+
+UPDATE students s SET class_id = c.id
+FROM class_name_map m, classes c
+WHERE s.class_name = m.raw
+  AND c.grade_level || c.letter = m.normalized;
+
+ALTER TABLE students DROP COLUMN class_name;
+
+INSERT INTO permissions (name, description) VALUES
+    ('classes:write', 'Добавление и изменение классов'),
+    ('classes:delete', 'Удаление классов');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('deputy', 'classes:write'),
+    ('deputy', 'classes:delete');
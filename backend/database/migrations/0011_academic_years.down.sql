@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE name = 'academic_years:manage';
+
+ALTER TABLE grades DROP COLUMN term_id;
+DROP TABLE IF EXISTS terms;
+DROP TABLE IF EXISTS academic_years;
@@ -0,0 +1,53 @@
+-- Учебные годы и периоды (четверти, триместры или полугодия) вместо голого номера
+-- четверти. Каждая оценка ссылается на период; grades.quarter остается копией
+-- номера периода, чтобы старые запросы и клиенты продолжали работать.
+
+CREATE TABLE academic_years (
+    id SERIAL PRIMARY KEY,
+    -- год начала: 2025 означает 2025/2026
+    start_year INTEGER NOT NULL UNIQUE,
+    starts_on DATE NOT NULL,
+    ends_on DATE NOT NULL,
+    term_kind VARCHAR(10) NOT NULL CHECK (term_kind IN ('quarter', 'trimester', 'semester')),
+    CHECK (starts_on < ends_on)
+);
+
+CREATE TABLE terms (
+    id SERIAL PRIMARY KEY,
+    academic_year_id INTEGER NOT NULL REFERENCES academic_years(id) ON DELETE CASCADE,
+    number SMALLINT NOT NULL CHECK (number BETWEEN 1 AND 4),
+    name VARCHAR(50) NOT NULL,
+    starts_on DATE NOT NULL,
+    ends_on DATE NOT NULL,
+    UNIQUE (academic_year_id, number),
+    CHECK (starts_on <= ends_on)
+);
+
+-- Существующие оценки относятся к текущему учебному году (он начинается 1 сентября)
+INSERT INTO academic_years (start_year, starts_on, ends_on, term_kind)
+SELECT y, make_date(y, 9, 1), make_date(y + 1, 5, 31), 'quarter'
+FROM (SELECT EXTRACT(YEAR FROM now() - INTERVAL '8 months')::INTEGER AS y) current_year;
+
+INSERT INTO terms (academic_year_id, number, name, starts_on, ends_on)
+SELECT y.id, q.number, q.number || ' четверть', q.starts_on, q.ends_on
+FROM academic_years y,
+LATERAL (VALUES
+    (1, make_date(y.start_year, 9, 1), make_date(y.start_year, 10, 31)),
+    (2, make_date(y.start_year, 11, 1), make_date(y.start_year, 12, 31)),
+    (3, make_date(y.start_year + 1, 1, 1), make_date(y.start_year + 1, 3, 31)),
+    (4, make_date(y.start_year + 1, 4, 1), make_date(y.start_year + 1, 5, 31))
+) AS q(number, starts_on, ends_on);
+
+ALTER TABLE grades ADD COLUMN term_id INTEGER REFERENCES terms(id) ON DELETE RESTRICT;
+
+UPDATE grades g SET term_id = t.id
+FROM terms t
+WHERE t.number = g.quarter;
+
+ALTER TABLE grades ALTER COLUMN term_id SET NOT NULL;
+CREATE INDEX idx_grades_term_id ON grades(term_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('academic_years:manage', 'Учебные годы и периоды');
+
+INSERT INTO role_permissions (role, permission) VALUES ('deputy', 'academic_years:manage');
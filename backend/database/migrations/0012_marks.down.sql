@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS term_grades;
+
+DROP INDEX IF EXISTS idx_grades_student_subject_term;
+ALTER TABLE grades
+    DROP COLUMN date,
+    DROP COLUMN kind,
+    DROP COLUMN weight,
+    DROP COLUMN comment;
@@ -0,0 +1,29 @@
+-- Отметки с датой, видом, весом и комментарием и итоговые оценки за период,
+-- выставленные учителем вместо вычисленных по отметкам.
+
+ALTER TABLE grades
+    ADD COLUMN date DATE,
+    ADD COLUMN kind VARCHAR(10) NOT NULL DEFAULT 'classwork'
+        CHECK (kind IN ('classwork', 'homework', 'test', 'exam')),
+    -- NULL — вес по умолчанию для вида отметки из настроек сервера
+    ADD COLUMN weight NUMERIC(4, 2) CHECK (weight > 0 AND weight <= 10),
+    ADD COLUMN comment TEXT NOT NULL DEFAULT '';
+
+-- У старых оценок даты нет: берется ближайшая к сегодняшней дата их периода
+UPDATE grades g SET date = LEAST(t.ends_on, GREATEST(t.starts_on, CURRENT_DATE))
+FROM terms t
+WHERE t.id = g.term_id;
+
+ALTER TABLE grades ALTER COLUMN date SET NOT NULL;
+CREATE INDEX idx_grades_student_subject_term ON grades(student_id, subject_id, term_id);
+
+CREATE TABLE term_grades (
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    term_id INTEGER NOT NULL REFERENCES terms(id) ON DELETE RESTRICT,
+    grade SMALLINT NOT NULL CHECK (grade BETWEEN 1 AND 5),
+    comment TEXT NOT NULL DEFAULT '',
+    set_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (student_id, subject_id, term_id)
+);
@@ -0,0 +1,9 @@
+DELETE FROM permissions WHERE name = 'grading_scales:manage';
+
+-- Оценки вне 1–5 не пройдут восстановленную проверку, их нужно удалить заранее
+ALTER TABLE term_grades ADD CONSTRAINT term_grades_grade_check CHECK (grade BETWEEN 1 AND 5);
+ALTER TABLE grades ADD CONSTRAINT grades_grade_check CHECK (grade BETWEEN 1 AND 5);
+
+ALTER TABLE subjects DROP COLUMN grading_scale_id;
+DROP TABLE IF EXISTS grading_scale_labels;
+DROP TABLE IF EXISTS grading_scales;
@@ -0,0 +1,49 @@
+-- Шкалы оценивания: диапазон значений, порог успеваемости и подписи значений.
+-- Шкала назначается предмету; шкала школы (is_default) назначается новым предметам,
+-- и к ней приводятся средние баллы при сравнении классов и предметов.
+
+CREATE TABLE grading_scales (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    min_value INTEGER NOT NULL,
+    max_value INTEGER NOT NULL,
+    pass_value INTEGER NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    CHECK (min_value >= 0 AND min_value < max_value AND max_value <= 100),
+    CHECK (pass_value BETWEEN min_value AND max_value)
+);
+
+-- Шкала школы может быть только одна
+CREATE UNIQUE INDEX idx_grading_scales_default ON grading_scales(is_default) WHERE is_default;
+
+CREATE TABLE grading_scale_labels (
+    scale_id INTEGER NOT NULL REFERENCES grading_scales(id) ON DELETE CASCADE,
+    value INTEGER NOT NULL,
+    label VARCHAR(20) NOT NULL,
+    PRIMARY KEY (scale_id, value)
+);
+
+INSERT INTO grading_scales (name, min_value, max_value, pass_value, is_default) VALUES
+    ('5-балльная', 1, 5, 3, TRUE),
+    ('10-балльная', 1, 10, 4, FALSE),
+    ('100-балльная', 0, 100, 50, FALSE),
+    ('Буквенная', 1, 5, 2, FALSE);
+
+INSERT INTO grading_scale_labels (scale_id, value, label)
+SELECT s.id, l.value, l.label
+FROM grading_scales s,
+(VALUES (1, 'F'), (2, 'D'), (3, 'C'), (4, 'B'), (5, 'A')) AS l(value, label)
+WHERE s.name = 'Буквенная';
+
+ALTER TABLE subjects ADD COLUMN grading_scale_id INTEGER REFERENCES grading_scales(id) ON DELETE RESTRICT;
+UPDATE subjects SET grading_scale_id = (SELECT id FROM grading_scales WHERE is_default);
+ALTER TABLE subjects ALTER COLUMN grading_scale_id SET NOT NULL;
+
+-- Диапазон оценки теперь проверяется по шкале предмета в приложении
+ALTER TABLE grades DROP CONSTRAINT IF EXISTS grades_grade_check;
+ALTER TABLE term_grades DROP CONSTRAINT IF EXISTS term_grades_grade_check;
+
+INSERT INTO permissions (name, description) VALUES
+    ('grading_scales:manage', 'Шкалы оценивания');
+
+INSERT INTO role_permissions (role, permission) VALUES ('deputy', 'grading_scales:manage');
@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS classes_archived ON classes;
+DROP TRIGGER IF EXISTS terms_archived ON terms;
+DROP TRIGGER IF EXISTS term_grades_archived ON term_grades;
+DROP TRIGGER IF EXISTS grades_archived ON grades;
+DROP FUNCTION IF EXISTS forbid_archived_class_changes();
+DROP FUNCTION IF EXISTS forbid_archived_term_changes();
+DROP FUNCTION IF EXISTS forbid_archived_grade_changes();
+DROP FUNCTION IF EXISTS year_archived(INTEGER);
+DROP FUNCTION IF EXISTS term_archived(INTEGER);
+
+DROP TABLE IF EXISTS annual_grades;
+ALTER TABLE students DROP COLUMN graduated_year;
+ALTER TABLE academic_years DROP COLUMN archived;
@@ -0,0 +1,95 @@
+-- Завершение учебного года: годовые оценки, перевод классов, выпускники и архив.
+-- Данные архивного года (оценки, итоговые оценки, периоды и классы) только для чтения;
+-- это проверяют триггеры, каскадные удаления учеников и предметов разрешены.
+
+ALTER TABLE academic_years ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Год окончания школы: у выпускников остается класс выпускного года
+ALTER TABLE students ADD COLUMN graduated_year INTEGER;
+
+CREATE TABLE annual_grades (
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    academic_year_id INTEGER NOT NULL REFERENCES academic_years(id) ON DELETE RESTRICT,
+    -- класс ученика на конец года, до перевода
+    class_id INTEGER REFERENCES classes(id) ON DELETE SET NULL,
+    average NUMERIC(6, 2) NOT NULL,
+    grade SMALLINT NOT NULL,
+    unsatisfactory BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (student_id, subject_id, academic_year_id)
+);
+
+CREATE INDEX idx_annual_grades_year ON annual_grades(academic_year_id);
+
+CREATE FUNCTION term_archived(term INTEGER) RETURNS BOOLEAN AS $$
+    SELECT COALESCE((
+        SELECT y.archived FROM terms t JOIN academic_years y ON y.id = t.academic_year_id WHERE t.id = term
+    ), FALSE)
+$$ LANGUAGE sql STABLE;
+
+CREATE FUNCTION year_archived(start INTEGER) RETURNS BOOLEAN AS $$
+    SELECT COALESCE((SELECT archived FROM academic_years WHERE start_year = start), FALSE)
+$$ LANGUAGE sql STABLE;
+
+-- Оценки и итоговые оценки: проверяется период до и после изменения
+CREATE FUNCTION forbid_archived_grade_changes() RETURNS trigger AS $$
+BEGIN
+    -- Каскадное удаление вместе с учеником или предметом выполняется внутри другого триггера
+    IF pg_trigger_depth() > 1 THEN
+        RETURN COALESCE(NEW, OLD);
+    END IF;
+    IF TG_OP <> 'INSERT' THEN
+        IF term_archived(OLD.term_id) THEN
+            RAISE EXCEPTION 'Учебный год в архиве' USING ERRCODE = 'SCH01';
+        END IF;
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        IF term_archived(NEW.term_id) THEN
+            RAISE EXCEPTION 'Учебный год в архиве' USING ERRCODE = 'SCH01';
+        END IF;
+    END IF;
+    RETURN COALESCE(NEW, OLD);
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER grades_archived BEFORE INSERT OR UPDATE OR DELETE ON grades
+    FOR EACH ROW EXECUTE FUNCTION forbid_archived_grade_changes();
+CREATE TRIGGER term_grades_archived BEFORE INSERT OR UPDATE OR DELETE ON term_grades
+    FOR EACH ROW EXECUTE FUNCTION forbid_archived_grade_changes();
+
+CREATE FUNCTION forbid_archived_term_changes() RETURNS trigger AS $$
+BEGIN
+    IF pg_trigger_depth() > 1 THEN
+        RETURN COALESCE(NEW, OLD);
+    END IF;
+    IF (SELECT archived FROM academic_years WHERE id = OLD.academic_year_id) THEN
+        RAISE EXCEPTION 'Учебный год в архиве' USING ERRCODE = 'SCH01';
+    END IF;
+    RETURN COALESCE(NEW, OLD);
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER terms_archived BEFORE UPDATE OR DELETE ON terms
+    FOR EACH ROW EXECUTE FUNCTION forbid_archived_term_changes();
+
+CREATE FUNCTION forbid_archived_class_changes() RETURNS trigger AS $$
+BEGIN
+    IF pg_trigger_depth() > 1 THEN
+        RETURN COALESCE(NEW, OLD);
+    END IF;
+    IF TG_OP <> 'INSERT' THEN
+        IF year_archived(OLD.academic_year) THEN
+            RAISE EXCEPTION 'Учебный год в архиве' USING ERRCODE = 'SCH01';
+        END IF;
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        IF year_archived(NEW.academic_year) THEN
+            RAISE EXCEPTION 'Учебный год в архиве' USING ERRCODE = 'SCH01';
+        END IF;
+    END IF;
+    RETURN COALESCE(NEW, OLD);
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER classes_archived BEFORE INSERT OR UPDATE OR DELETE ON classes
+    FOR EACH ROW EXECUTE FUNCTION forbid_archived_class_changes();
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'enrollments:manage';
+
+DROP TABLE IF EXISTS enrollments;
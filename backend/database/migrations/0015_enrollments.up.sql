@@ -0,0 +1,38 @@
+-- Зачисление учеников на предметы: кто из учеников изучает предмет, у какого учителя,
+-- в каком учебном году или периоде и в какой группе. Раньше ученики предмета
+-- определялись по выставленным оценкам, и ученик без оценок не был виден учителю.
+
+CREATE TABLE enrollments (
+    id SERIAL PRIMARY KEY,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL,
+    -- год начала учебного года: 2025 означает 2025/2026
+    academic_year INTEGER NOT NULL,
+    -- период зачисления; NULL — весь учебный год
+    term_id INTEGER REFERENCES terms(id) ON DELETE CASCADE,
+    -- группа внутри класса, например при делении на подгруппы по языку
+    group_name VARCHAR(50) NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX idx_enrollments_unique
+    ON enrollments(student_id, subject_id, academic_year, COALESCE(term_id, 0));
+CREATE INDEX idx_enrollments_teacher ON enrollments(teacher_id, academic_year);
+CREATE INDEX idx_enrollments_subject ON enrollments(subject_id, academic_year);
+
+-- Зачисления архивного года только для чтения; проверка та же, что у классов (0014_year_end)
+CREATE TRIGGER enrollments_archived BEFORE INSERT OR UPDATE OR DELETE ON enrollments
+    FOR EACH ROW EXECUTE FUNCTION forbid_archived_class_changes();
+
+-- Ученики, у которых уже есть оценки, зачисляются на весь учебный год к учителю предмета
+INSERT INTO enrollments (student_id, subject_id, teacher_id, academic_year)
+SELECT DISTINCT g.student_id, g.subject_id, sub.teacher_id, y.start_year
+FROM grades g
+JOIN subjects sub ON sub.id = g.subject_id
+JOIN terms t ON t.id = g.term_id
+JOIN academic_years y ON y.id = t.academic_year_id;
+
+INSERT INTO permissions (name, description) VALUES
+    ('enrollments:manage', 'Зачисление учеников на предметы');
+
+INSERT INTO role_permissions (role, permission) VALUES ('deputy', 'enrollments:manage');
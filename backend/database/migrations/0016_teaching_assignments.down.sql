@@ -0,0 +1,13 @@
+DELETE FROM permissions WHERE name = 'teaching_assignments:manage';
+
+-- Предмету возвращается один учитель: из нескольких назначенных берется первый
+ALTER TABLE subjects ADD COLUMN teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_subjects_teacher_id ON subjects(teacher_id);
+
+UPDATE subjects sub SET teacher_id = (
+    SELECT a.teacher_id FROM teaching_assignments a WHERE a.subject_id = sub.id ORDER BY a.id LIMIT 1
+);
+
+DROP TRIGGER IF EXISTS teaching_assignments_archived ON teaching_assignments;
+DROP FUNCTION IF EXISTS forbid_archived_assignment_changes();
+DROP TABLE IF EXISTS teaching_assignments;
@@ -0,0 +1,53 @@
+-- Назначения учителей: учитель ведет предмет в классе весь учебный год или в отдельном периоде.
+-- Заменяют subjects.teacher_id: один предмет в разных классах могут вести разные учителя.
+
+CREATE TABLE teaching_assignments (
+    id SERIAL PRIMARY KEY,
+    teacher_id INTEGER NOT NULL REFERENCES teachers(id) ON DELETE CASCADE,
+    subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    class_id INTEGER NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    -- период назначения; NULL — весь учебный год класса
+    term_id INTEGER REFERENCES terms(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_teaching_assignments_unique
+    ON teaching_assignments(teacher_id, subject_id, class_id, COALESCE(term_id, 0));
+CREATE INDEX idx_teaching_assignments_class ON teaching_assignments(class_id, subject_id);
+
+-- Учитель предмета ведет его во всех классах незакрытых учебных годов, как и раньше
+INSERT INTO teaching_assignments (teacher_id, subject_id, class_id)
+SELECT sub.teacher_id, sub.id, c.id
+FROM subjects sub
+CROSS JOIN classes c
+WHERE sub.teacher_id IS NOT NULL AND NOT year_archived(c.academic_year);
+
+DROP INDEX IF EXISTS idx_subjects_teacher_id;
+ALTER TABLE subjects DROP COLUMN teacher_id;
+
+-- Назначения в классах закрытого года только для чтения
+CREATE FUNCTION forbid_archived_assignment_changes() RETURNS trigger AS $$
+BEGIN
+    IF pg_trigger_depth() > 1 THEN
+        RETURN COALESCE(NEW, OLD);
+    END IF;
+    IF TG_OP <> 'INSERT' THEN
+        IF year_archived((SELECT academic_year FROM classes WHERE id = OLD.class_id)) THEN
+            RAISE EXCEPTION 'Учебный год в архиве' USING ERRCODE = 'SCH01';
+        END IF;
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        IF year_archived((SELECT academic_year FROM classes WHERE id = NEW.class_id)) THEN
+            RAISE EXCEPTION 'Учебный год в архиве' USING ERRCODE = 'SCH01';
+        END IF;
+    END IF;
+    RETURN COALESCE(NEW, OLD);
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER teaching_assignments_archived BEFORE INSERT OR UPDATE OR DELETE ON teaching_assignments
+    FOR EACH ROW EXECUTE FUNCTION forbid_archived_assignment_changes();
+
+INSERT INTO permissions (name, description) VALUES
+    ('teaching_assignments:manage', 'Назначение учителей на предметы в классах');
+
+INSERT INTO role_permissions (role, permission) VALUES ('deputy', 'teaching_assignments:manage');
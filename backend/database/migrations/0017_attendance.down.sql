@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'attendance:write';
+
+DROP TABLE IF EXISTS attendance;
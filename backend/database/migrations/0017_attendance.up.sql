@@ -0,0 +1,33 @@
+-- Посещаемость уроков: отметка ученика на уроке (дата и номер урока) по предмету.
+-- Период отметки определяется по дате урока; данные архивного года только для чтения.
+
+CREATE TABLE attendance (
+    id SERIAL PRIMARY KEY,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    term_id INTEGER NOT NULL REFERENCES terms(id) ON DELETE RESTRICT,
+    date DATE NOT NULL,
+    -- номер урока в расписании дня
+    lesson SMALLINT NOT NULL CHECK (lesson BETWEEN 1 AND 12),
+    status VARCHAR(10) NOT NULL CHECK (status IN ('present', 'absent', 'late', 'excused')),
+    -- причина пропуска по уважительной причине или опоздания
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    marked_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- На одном уроке ученик отмечается один раз
+CREATE UNIQUE INDEX idx_attendance_lesson ON attendance(student_id, date, lesson);
+CREATE INDEX idx_attendance_term ON attendance(term_id);
+CREATE INDEX idx_attendance_subject_date ON attendance(subject_id, date);
+
+-- Проверка архива та же, что у оценок (0014_year_end)
+CREATE TRIGGER attendance_archived BEFORE INSERT OR UPDATE OR DELETE ON attendance
+    FOR EACH ROW EXECUTE FUNCTION forbid_archived_grade_changes();
+
+INSERT INTO permissions (name, description) VALUES
+    ('attendance:write', 'Отметка посещаемости уроков');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('teacher', 'attendance:write'),
+    ('deputy', 'attendance:write');
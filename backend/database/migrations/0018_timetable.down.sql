@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'timetable:manage';
+
+DROP TABLE IF EXISTS lessons;
+DROP TABLE IF EXISTS bell_periods;
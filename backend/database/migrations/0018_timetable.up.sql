@@ -0,0 +1,44 @@
+-- Расписание: звонки (номер урока и его время) и недельная сетка уроков классов.
+-- Класс не может стоять на двух уроках одновременно; занятость учителя и кабинета
+-- в пределах учебного года проверяет приложение.
+
+CREATE TABLE bell_periods (
+    number SMALLINT PRIMARY KEY CHECK (number BETWEEN 1 AND 12),
+    starts_at TIME NOT NULL,
+    ends_at TIME NOT NULL,
+    CHECK (starts_at < ends_at)
+);
+
+INSERT INTO bell_periods (number, starts_at, ends_at) VALUES
+    (1, '08:30', '09:15'),
+    (2, '09:25', '10:10'),
+    (3, '10:30', '11:15'),
+    (4, '11:35', '12:20'),
+    (5, '12:30', '13:15'),
+    (6, '13:25', '14:10'),
+    (7, '14:20', '15:05'),
+    (8, '15:15', '16:00');
+
+CREATE TABLE lessons (
+    id SERIAL PRIMARY KEY,
+    class_id INTEGER NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    teacher_id INTEGER NOT NULL REFERENCES teachers(id) ON DELETE CASCADE,
+    room VARCHAR(50) NOT NULL DEFAULT '',
+    -- день недели: 1 — понедельник, 7 — воскресенье
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 1 AND 7),
+    period SMALLINT NOT NULL REFERENCES bell_periods(number) ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX idx_lessons_class_slot ON lessons(class_id, weekday, period);
+CREATE INDEX idx_lessons_teacher_slot ON lessons(teacher_id, weekday, period);
+CREATE INDEX idx_lessons_room_slot ON lessons(room, weekday, period) WHERE room <> '';
+
+-- Уроки архивного года только для чтения; проверка та же, что у назначений (0016_teaching_assignments)
+CREATE TRIGGER lessons_archived BEFORE INSERT OR UPDATE OR DELETE ON lessons
+    FOR EACH ROW EXECUTE FUNCTION forbid_archived_assignment_changes();
+
+INSERT INTO permissions (name, description) VALUES
+    ('timetable:manage', 'Расписание звонков и уроков');
+
+INSERT INTO role_permissions (role, permission) VALUES ('deputy', 'timetable:manage');
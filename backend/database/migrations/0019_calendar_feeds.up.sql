@@ -0,0 +1,8 @@
+-- Подписка на календарь (iCalendar): у пользователя не больше одного токена,
+-- выпуск нового отзывает прежний. Хранится только SHA-256 хеш токена.
+
+CREATE TABLE calendar_feeds (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE name = 'homework:manage';
+
+ALTER TABLE grades DROP COLUMN IF EXISTS homework_id;
+
+DROP TABLE IF EXISTS homework_submissions;
+DROP TABLE IF EXISTS homework;
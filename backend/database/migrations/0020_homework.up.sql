@@ -0,0 +1,47 @@
+-- Домашние задания и работы учеников.
+-- Оценка за работу — обычная отметка в grades со ссылкой на задание.
+
+CREATE TABLE homework (
+    id SERIAL PRIMARY KEY,
+    class_id INTEGER NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    due_date DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_homework_class_due ON homework(class_id, due_date);
+CREATE INDEX idx_homework_subject ON homework(subject_id);
+
+-- Проверка архива та же, что у назначений учителей (0016_teaching_assignments)
+CREATE TRIGGER homework_archived BEFORE INSERT OR UPDATE OR DELETE ON homework
+    FOR EACH ROW EXECUTE FUNCTION forbid_archived_assignment_changes();
+
+-- Работа ученика: отметка о выполнении (done) или сданный текст и файл (submitted)
+CREATE TABLE homework_submissions (
+    id SERIAL PRIMARY KEY,
+    homework_id INTEGER NOT NULL REFERENCES homework(id) ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('done', 'submitted')),
+    text TEXT NOT NULL DEFAULT '',
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    file_type VARCHAR(100) NOT NULL DEFAULT '',
+    file BYTEA,
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (homework_id, student_id)
+);
+
+ALTER TABLE grades ADD COLUMN homework_id INTEGER REFERENCES homework(id) ON DELETE SET NULL;
+
+-- За работу по заданию у ученика одна отметка
+CREATE UNIQUE INDEX idx_grades_homework_student ON grades(homework_id, student_id)
+    WHERE homework_id IS NOT NULL;
+
+INSERT INTO permissions (name, description) VALUES
+    ('homework:manage', 'Домашние задания');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('teacher', 'homework:manage'),
+    ('deputy', 'homework:manage');
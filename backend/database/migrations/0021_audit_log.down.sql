@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'audit:read';
+
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS forbid_audit_log_changes();
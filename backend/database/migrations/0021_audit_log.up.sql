@@ -0,0 +1,33 @@
+-- Журнал изменений оценок, учеников, учителей и предметов. Ссылки на пользователя
+-- и запись не проверяются: история остается после их удаления.
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    entity VARCHAR(30) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    old_value JSONB,
+    new_value JSONB,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log(entity, entity_id, created_at);
+CREATE INDEX idx_audit_log_user ON audit_log(user_id, created_at);
+CREATE INDEX idx_audit_log_created ON audit_log(created_at);
+
+-- Записи журнала нельзя изменить или удалить
+CREATE FUNCTION forbid_audit_log_changes() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'Журнал изменений доступен только для добавления';
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION forbid_audit_log_changes();
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit:read', 'Просмотр журнала изменений');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('deputy', 'audit:read');
@@ -0,0 +1 @@
+ALTER TABLE term_grades DROP COLUMN IF EXISTS id;
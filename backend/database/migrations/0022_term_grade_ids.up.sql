@@ -0,0 +1,3 @@
+-- Собственный ID итоговой оценки: по нему журнал изменений ведет историю
+-- одной оценки, а не всех итоговых оценок ученика
+ALTER TABLE term_grades ADD COLUMN id SERIAL UNIQUE;
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := s.db.SelectContext(ctx, &permissions, `SELECT name, description FROM permissions ORDER BY name`)
+	return permissions, err
+}
+
+// ListRoles возвращает роли с правами двумя запросами, без N+1
+func (s *Store) ListRoles(ctx context.Context) ([]models.Role, error) {
+	var roles []models.Role
+	if err := s.db.SelectContext(ctx, &roles, `SELECT name, description FROM roles ORDER BY name`); err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Role       string `db:"role"`
+		Permission string `db:"permission"`
+	}
+	if err := s.db.SelectContext(ctx, &rows,
+		`SELECT role, permission FROM role_permissions ORDER BY role, permission`); err != nil {
+		return nil, err
+	}
+	byRole := make(map[string][]string)
+	for _, row := range rows {
+		byRole[row.Role] = append(byRole[row.Role], row.Permission)
+	}
+	for i := range roles {
+		roles[i].Permissions = byRole[roles[i].Name]
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = []string{}
+		}
+	}
+	return roles, nil
+}
+
+func (s *Store) GetRole(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	if err := s.db.GetContext(ctx, &role, `SELECT name, description FROM roles WHERE name = $1`, name); err != nil {
+		return nil, mapError(err)
+	}
+	role.Permissions = []string{}
+	if err := s.db.SelectContext(ctx, &role.Permissions,
+		`SELECT permission FROM role_permissions WHERE role = $1 ORDER BY permission`, name); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// CreateRole создает роль и назначает ей права в одной транзакции
+func (s *Store) CreateRole(ctx context.Context, role *models.Role) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO roles (name, description) VALUES ($1, $2)`, role.Name, role.Description); err != nil {
+			return err
+		}
+		return insertRolePermissions(ctx, tx, role.Name, role.Permissions)
+	})
+}
+
+func (s *Store) DeleteRole(ctx context.Context, name string) error {
+	return expectRows(s.db.ExecContext(ctx, `DELETE FROM roles WHERE name = $1`, name))
+}
+
+// SetRolePermissions заменяет набор прав роли в одной транзакции
+func (s *Store) SetRolePermissions(ctx context.Context, role string, permissions []string) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)`, role); err != nil {
+			return err
+		}
+		if !exists {
+			return store.ErrNotFound
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role = $1`, role); err != nil {
+			return err
+		}
+		return insertRolePermissions(ctx, tx, role, permissions)
+	})
+}
+
+func insertRolePermissions(ctx context.Context, tx *sqlx.Tx, role string, permissions []string) error {
+	for _, permission := range permissions {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO role_permissions (role, permission) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING`, role, permission); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) RoleHasPermission(ctx context.Context, role, permission string) (bool, error) {
+	var ok bool
+	err := s.db.GetContext(ctx, &ok, `
+		SELECT EXISTS (SELECT 1 FROM role_permissions WHERE role = $1 AND permission = $2)`, role, permission)
+	return ok, err
+}
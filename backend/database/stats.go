@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+
+	"school-system/backend/models"
+)
+
+// scaleJoins присоединяет к оценкам g шкалу их предмета (sc) и шкалу школы (ds)
+const scaleJoins = `
+	JOIN subjects gs ON gs.id = g.subject_id
+	JOIN grading_scales sc ON sc.id = gs.grading_scale_id
+	JOIN grading_scales ds ON ds.is_default`
+
+// normalizedGrade — оценка, линейно приведенная к шкале школы, чтобы сравнивать
+// средние баллы по предметам с разными шкалами
+const normalizedGrade = `(ds.min_value + (g.grade - sc.min_value) * (ds.max_value - ds.min_value)::NUMERIC / (sc.max_value - sc.min_value))`
+
+// gradeClassJoin присоединяет к оценкам g класс ученика в учебном году оценки (c):
+// для закрытого года — класс на конец года из годовых оценок, иначе текущий класс,
+// если он относится к этому году. Так оценки 9А не попадают в 10А после перевода.
+const gradeClassJoin = `
+	JOIN classes c ON c.academic_year = y.start_year AND c.id = COALESCE(
+		(SELECT a.class_id FROM annual_grades a
+		 WHERE a.student_id = g.student_id AND a.subject_id = g.subject_id AND a.academic_year_id = y.id),
+		(SELECT s.class_id FROM students s WHERE s.id = g.student_id))`
+
+// GetAverageGrade возвращает средний балл по всем оценкам в шкале школы
+func (s *Store) GetAverageGrade(ctx context.Context, period models.Period) (float64, error) {
+	var avg float64
+	err := s.db.GetContext(ctx, &avg, `
+		SELECT COALESCE(AVG(`+normalizedGrade+`), 0)
+		FROM grades g`+scaleJoins+periodJoins+`
+		WHERE g.grade IS NOT NULL AND `+periodFilter(1),
+		period.AcademicYear, period.Term)
+	return avg, err
+}
+
+// GetAverageGradesByClass возвращает средние оценки по предметам для каждого класса.
+// Оценки одного предмета в одной шкале, поэтому средние остаются в шкале предмета.
+func (s *Store) GetAverageGradesByClass(ctx context.Context, period models.Period) (map[string]map[string]float64, error) {
+	result := make(map[string]map[string]float64)
+
+	query := `
+		WITH subject_quarter_averages AS (
+			SELECT
+				c.grade_level || c.letter AS class_name,
+				sub.name as subject_name,
+				g.quarter,
+				AVG(g.grade) as quarter_average
+			FROM grades g
+			JOIN subjects sub ON g.subject_id = sub.id` + periodJoins + gradeClassJoin + `
+			WHERE ` + periodFilter(1) + `
+			GROUP BY c.grade_level, c.letter, sub.name, g.quarter
+		)
+		SELECT
+			class_name,
+			subject_name,
+			ROUND(AVG(quarter_average), 2) as average_grade
+		FROM subject_quarter_averages
+		GROUP BY class_name, subject_name
+		ORDER BY class_name, subject_name
+	`
+
+	rows, err := s.db.QueryxContext(ctx, query, period.AcademicYear, period.Term)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var className, subjectName string
+		var averageGrade float64
+		if err := rows.Scan(&className, &subjectName, &averageGrade); err != nil {
+			return nil, err
+		}
+
+		if _, ok := result[className]; !ok {
+			result[className] = make(map[string]float64)
+		}
+		result[className][subjectName] = averageGrade
+	}
+
+	return result, rows.Err()
+}
+
+// GetTopAndWorstClasses возвращает классы с самой высокой и низкой успеваемостью,
+// сравнивая оценки в шкале школы
+func (s *Store) GetTopAndWorstClasses(ctx context.Context, period models.Period) (string, string, error) {
+	query := `
+		SELECT c.grade_level || c.letter AS class_name
+		FROM grades g` + scaleJoins + periodJoins + gradeClassJoin + `
+		WHERE ` + periodFilter(1) + `
+		GROUP BY c.grade_level, c.letter
+		ORDER BY AVG(` + normalizedGrade + `) DESC, class_name
+	`
+
+	var classes []string
+	if err := s.db.SelectContext(ctx, &classes, query, period.AcademicYear, period.Term); err != nil {
+		return "", "", err
+	}
+	if len(classes) == 0 {
+		return "", "", nil
+	}
+	return classes[0], classes[len(classes)-1], nil
+}
+
+// GetClassPerformance возвращает средние оценки по классам в шкале школы
+func (s *Store) GetClassPerformance(ctx context.Context, period models.Period) ([]models.ClassPerformance, error) {
+	query := `
+		WITH class_quarter_averages AS (
+			SELECT
+				c.grade_level || c.letter AS class_name,
+				g.quarter,
+				AVG(` + normalizedGrade + `) as quarter_average
+			FROM grades g` + scaleJoins + periodJoins + gradeClassJoin + `
+			WHERE ` + periodFilter(1) + `
+			GROUP BY c.grade_level, c.letter, g.quarter
+		)
+		SELECT
+			class_name,
+			ROUND(AVG(quarter_average), 2) as average
+		FROM class_quarter_averages
+		GROUP BY class_name
+		ORDER BY class_name
+	`
+
+	performances := []models.ClassPerformance{}
+	err := s.db.SelectContext(ctx, &performances, query, period.AcademicYear, period.Term)
+	return performances, err
+}
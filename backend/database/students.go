@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"school-system/backend/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// studentColumns — колонки ученика; название класса вычисляется по class_id
+const studentColumns = `s.id, s.full_name, COALESCE(s.class_id, 0) AS class_id,
+	COALESCE((SELECT c.grade_level || c.letter FROM classes c WHERE c.id = s.class_id), '') AS class_name,
+	COALESCE(s.user_id, 0) AS user_id, COALESCE(s.graduated_year, 0) AS graduated_year`
+
+func (s *Store) ListStudents(ctx context.Context) ([]models.Student, error) {
+	var students []models.Student
+	err := s.db.SelectContext(ctx, &students, `SELECT `+studentColumns+` FROM students s ORDER BY s.id`)
+	return students, err
+}
+
+func (s *Store) GetStudent(ctx context.Context, id int) (*models.Student, error) {
+	return getStudent(ctx, s.db, id)
+}
+
+func getStudent(ctx context.Context, q sqlx.QueryerContext, id int) (*models.Student, error) {
+	var student models.Student
+	err := sqlx.GetContext(ctx, q, &student, `SELECT `+studentColumns+` FROM students s WHERE s.id = $1`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &student, nil
+}
+
+// lockStudent возвращает ученика, блокируя его до конца транзакции
+func lockStudent(ctx context.Context, tx *sqlx.Tx, id int) (*models.Student, error) {
+	var student models.Student
+	err := tx.GetContext(ctx, &student, `SELECT `+studentColumns+` FROM students s WHERE s.id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &student, nil
+}
+
+// GetStudentByUserID возвращает ученика по его user_id
+func (s *Store) GetStudentByUserID(ctx context.Context, userID int) (*models.Student, error) {
+	var student models.Student
+	err := s.db.GetContext(ctx, &student, `SELECT `+studentColumns+` FROM students s WHERE s.user_id = $1`, userID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &student, nil
+}
+
+// CreateStudent добавляет ученика и заполняет его ID и название класса
+func (s *Store) CreateStudent(ctx context.Context, student *models.Student) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.QueryRowxContext(ctx,
+			`INSERT INTO students (full_name, class_id, user_id) VALUES ($1, NULLIF($2, 0), NULLIF($3, 0)) RETURNING id`,
+			student.FullName, student.ClassID, student.UserID,
+		).Scan(&student.ID); err != nil {
+			return err
+		}
+		saved, err := getStudent(ctx, tx, student.ID)
+		if err != nil {
+			return err
+		}
+		student.ClassName = saved.ClassName
+		return audit(ctx, tx, models.AuditCreate, models.AuditStudent, student.ID, nil, saved)
+	})
+}
+
+func (s *Store) UpdateStudent(ctx context.Context, student *models.Student) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		old, err := lockStudent(ctx, tx, student.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE students SET full_name = $1, class_id = NULLIF($2, 0) WHERE id = $3`,
+			student.FullName, student.ClassID, student.ID); err != nil {
+			return err
+		}
+		saved, err := getStudent(ctx, tx, student.ID)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditUpdate, models.AuditStudent, student.ID, old, saved)
+	})
+}
+
+func (s *Store) DeleteStudent(ctx context.Context, id int) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		old, err := lockStudent(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM students WHERE id = $1`, id); err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditDelete, models.AuditStudent, id, old, nil)
+	})
+}
+
+// GetStudentsByClass возвращает учеников класса
+func (s *Store) GetStudentsByClass(ctx context.Context, classID int) ([]models.Student, error) {
+	var students []models.Student
+	err := s.db.SelectContext(ctx, &students,
+		`SELECT `+studentColumns+` FROM students s WHERE s.class_id = $1 ORDER BY s.full_name`, classID)
+	return students, err
+}
+
+func (s *Store) CountStudents(ctx context.Context) (int64, error) {
+	var count int64
+	err := s.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM students`)
+	return count, err
+}
+
+// GetStudentsByTeacher возвращает учеников, у которых учитель ведет хотя бы один предмет
+func (s *Store) GetStudentsByTeacher(ctx context.Context, teacherID int, period models.Period) ([]models.Student, error) {
+	log.Printf("Ищем учеников учителя с id=%d", teacherID)
+	var students []models.Student
+	query := `
+		SELECT ` + studentColumns + ` FROM students s
+		WHERE ` + teachesStudent("$1", "", "$2", termInPeriod(3)) + `
+		ORDER BY s.full_name, s.id
+	`
+	err := s.db.SelectContext(ctx, &students, query, teacherID, period.AcademicYear, period.Term)
+	if err != nil {
+		log.Printf("Ошибка при поиске учеников: %v", err)
+		return nil, err
+	}
+	log.Printf("Найдено учеников: %d", len(students))
+	return students, nil
+}
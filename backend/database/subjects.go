@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+const subjectColumns = `id, name, grading_scale_id`
+
+func (s *Store) ListSubjects(ctx context.Context) ([]models.Subject, error) {
+	var subjects []models.Subject
+	err := s.db.SelectContext(ctx, &subjects, `SELECT `+subjectColumns+` FROM subjects ORDER BY name`)
+	return subjects, err
+}
+
+func (s *Store) GetSubject(ctx context.Context, id int) (*models.Subject, error) {
+	return getSubject(ctx, s.db, id)
+}
+
+func getSubject(ctx context.Context, q sqlx.QueryerContext, id int) (*models.Subject, error) {
+	var subject models.Subject
+	err := sqlx.GetContext(ctx, q, &subject, `SELECT `+subjectColumns+` FROM subjects WHERE id = $1`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &subject, nil
+}
+
+// lockSubject возвращает предмет, блокируя его до конца транзакции
+func lockSubject(ctx context.Context, tx *sqlx.Tx, id int) (*models.Subject, error) {
+	var subject models.Subject
+	err := tx.GetContext(ctx, &subject, `SELECT `+subjectColumns+` FROM subjects WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &subject, nil
+}
+
+// CreateSubject добавляет предмет и заполняет его ID и шкалу
+func (s *Store) CreateSubject(ctx context.Context, subject *models.Subject) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.QueryRowxContext(ctx, `
+			INSERT INTO subjects (name, grading_scale_id)
+			VALUES ($1, COALESCE(NULLIF($2, 0), (SELECT id FROM grading_scales WHERE is_default)))
+			RETURNING id, grading_scale_id`,
+			subject.Name, subject.GradingScaleID,
+		).Scan(&subject.ID, &subject.GradingScaleID); err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditCreate, models.AuditSubject, subject.ID, nil, subject)
+	})
+}
+
+// UpdateSubject меняет название и шкалу предмета. Шкалу нельзя сменить, пока по предмету
+// есть оценки: они потеряли бы смысл в новом диапазоне.
+func (s *Store) UpdateSubject(ctx context.Context, subject *models.Subject) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		old, err := lockSubject(ctx, tx, subject.ID)
+		if err != nil {
+			return err
+		}
+		if subject.GradingScaleID == 0 {
+			subject.GradingScaleID = old.GradingScaleID
+		}
+		if subject.GradingScaleID != old.GradingScaleID {
+			var graded bool
+			if err := tx.GetContext(ctx, &graded, `
+				SELECT EXISTS (SELECT 1 FROM grades WHERE subject_id = $1)
+				    OR EXISTS (SELECT 1 FROM term_grades WHERE subject_id = $1)`, subject.ID); err != nil {
+				return err
+			}
+			if graded {
+				return store.ErrConflict
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE subjects SET name = $1, grading_scale_id = $2 WHERE id = $3`,
+			subject.Name, subject.GradingScaleID, subject.ID); err != nil {
+			return err
+		}
+		saved, err := getSubject(ctx, tx, subject.ID)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditUpdate, models.AuditSubject, subject.ID, old, saved)
+	})
+}
+
+func (s *Store) DeleteSubject(ctx context.Context, id int) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		old, err := lockSubject(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM subjects WHERE id = $1`, id); err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditDelete, models.AuditSubject, id, old, nil)
+	})
+}
+
+// GetSubjectsByTeacher возвращает все предметы, которые учитель ведет хотя бы в одном классе,
+// в том числе предметы, на которые к нему зачислены ученики
+func (s *Store) GetSubjectsByTeacher(ctx context.Context, teacherID int) ([]models.Subject, error) {
+	var subjects []models.Subject
+	err := s.db.SelectContext(ctx, &subjects, `
+		SELECT `+subjectColumns+` FROM subjects sub
+		WHERE EXISTS (SELECT 1 FROM teaching_assignments a WHERE a.subject_id = sub.id AND a.teacher_id = $1)
+		   OR EXISTS (SELECT 1 FROM enrollments e WHERE e.subject_id = sub.id AND e.teacher_id = $1)
+		ORDER BY name`, teacherID)
+	if err != nil {
+		log.Printf("Ошибка при получении предметов: %v", err)
+		return nil, err
+	}
+	log.Printf("Найдено предметов: %d", len(subjects))
+	return subjects, nil
+}
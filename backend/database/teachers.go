@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"school-system/backend/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const teacherColumns = `id, full_name, room_number, COALESCE(user_id, 0) AS user_id`
+
+// ListTeachers возвращает список всех учителей
+func (s *Store) ListTeachers(ctx context.Context) ([]models.Teacher, error) {
+	var teachers []models.Teacher
+	err := s.db.SelectContext(ctx, &teachers, `SELECT `+teacherColumns+` FROM teachers ORDER BY id`)
+	return teachers, err
+}
+
+func (s *Store) GetTeacher(ctx context.Context, id int) (*models.Teacher, error) {
+	return getTeacher(ctx, s.db, id)
+}
+
+func getTeacher(ctx context.Context, q sqlx.QueryerContext, id int) (*models.Teacher, error) {
+	var teacher models.Teacher
+	err := sqlx.GetContext(ctx, q, &teacher, `SELECT `+teacherColumns+` FROM teachers WHERE id = $1`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &teacher, nil
+}
+
+// lockTeacher возвращает учителя, блокируя его до конца транзакции
+func lockTeacher(ctx context.Context, tx *sqlx.Tx, id int) (*models.Teacher, error) {
+	var teacher models.Teacher
+	err := tx.GetContext(ctx, &teacher, `SELECT `+teacherColumns+` FROM teachers WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &teacher, nil
+}
+
+// GetTeacherByUserID возвращает учителя по его user_id
+func (s *Store) GetTeacherByUserID(ctx context.Context, userID int) (*models.Teacher, error) {
+	log.Printf("Ищем учителя с user_id=%d", userID)
+	var teacher models.Teacher
+	err := s.db.GetContext(ctx, &teacher, `SELECT `+teacherColumns+` FROM teachers WHERE user_id = $1`, userID)
+	if err != nil {
+		log.Printf("Ошибка при поиске учителя: %v", err)
+		return nil, mapError(err)
+	}
+	log.Printf("Учитель найден: id=%d, full_name=%s", teacher.ID, teacher.FullName)
+	return &teacher, nil
+}
+
+// CreateTeacher добавляет учителя и заполняет его ID
+func (s *Store) CreateTeacher(ctx context.Context, teacher *models.Teacher) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.QueryRowxContext(ctx,
+			`INSERT INTO teachers (full_name, room_number, user_id) VALUES ($1, $2, NULLIF($3, 0)) RETURNING id`,
+			teacher.FullName, teacher.RoomNumber, teacher.UserID,
+		).Scan(&teacher.ID); err != nil {
+			return err
+		}
+		saved, err := getTeacher(ctx, tx, teacher.ID)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditCreate, models.AuditTeacher, teacher.ID, nil, saved)
+	})
+}
+
+func (s *Store) UpdateTeacher(ctx context.Context, teacher *models.Teacher) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		old, err := lockTeacher(ctx, tx, teacher.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE teachers SET full_name = $1, room_number = $2 WHERE id = $3`,
+			teacher.FullName, teacher.RoomNumber, teacher.ID); err != nil {
+			return err
+		}
+		saved, err := getTeacher(ctx, tx, teacher.ID)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditUpdate, models.AuditTeacher, teacher.ID, old, saved)
+	})
+}
+
+func (s *Store) DeleteTeacher(ctx context.Context, id int) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		old, err := lockTeacher(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM teachers WHERE id = $1`, id); err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditDelete, models.AuditTeacher, id, old, nil)
+	})
+}
+
+func (s *Store) CountTeachers(ctx context.Context) (int64, error) {
+	var count int64
+	err := s.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM teachers`)
+	return count, err
+}
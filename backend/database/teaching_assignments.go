@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+const assignmentColumns = `a.id, a.teacher_id, a.subject_id, a.class_id,
+	COALESCE(a.term_id, 0) AS term_id, c.academic_year`
+
+// termInPeriod возвращает условие на колонку term_id зачисления или назначения: весь год (NULL)
+// подходит к любому периоду, иначе номер периода совпадает с параметром $n (0 — весь год)
+func termInPeriod(n int) func(col string) string {
+	return func(col string) string {
+		return fmt.Sprintf(`($%[1]d = 0 OR %[2]s IS NULL
+			OR EXISTS (SELECT 1 FROM terms pt WHERE pt.id = %[2]s AND pt.number = $%[1]d))`, n, col)
+	}
+}
+
+// termOfGrade — условие на колонку term_id: весь год или период оценки g
+func termOfGrade(col string) string {
+	return fmt.Sprintf("(%[1]s IS NULL OR %[1]s = g.term_id)", col)
+}
+
+// teachesStudent возвращает условие «учитель teacher ведет у ученика s предмет subject
+// (пустая строка — любой предмет) в учебном году year»: ученик зачислен к учителю или
+// учится в классе, где учитель ведет предмет, и не зачислен на этот предмет к другому учителю.
+// Назначение на период (замена) действует и для учеников, зачисленных к другому учителю на весь год.
+func teachesStudent(teacher, subject, year string, term func(col string) string) string {
+	subjectIs := func(col string) string {
+		if subject == "" {
+			return "TRUE"
+		}
+		return col + " = " + subject
+	}
+	return fmt.Sprintf(`(EXISTS (
+			SELECT 1 FROM enrollments e
+			WHERE e.student_id = s.id AND %[1]s AND e.teacher_id = %[2]s
+			  AND e.academic_year = %[3]s AND %[4]s
+		) OR EXISTS (
+			SELECT 1 FROM teaching_assignments a JOIN classes ac ON ac.id = a.class_id
+			WHERE a.class_id = s.class_id AND %[5]s AND a.teacher_id = %[2]s
+			  AND ac.academic_year = %[3]s AND %[6]s
+			  AND NOT EXISTS (
+				SELECT 1 FROM enrollments oe
+				WHERE oe.student_id = s.id AND oe.subject_id = a.subject_id AND oe.academic_year = %[3]s
+				  AND %[7]s AND oe.teacher_id IS DISTINCT FROM %[2]s
+				  AND (a.term_id IS NULL OR oe.term_id IS NOT NULL)
+			  )
+		))`,
+		subjectIs("e.subject_id"), teacher, year, term("e.term_id"),
+		subjectIs("a.subject_id"), term("a.term_id"), term("oe.term_id"))
+}
+
+func (s *Store) ListTeachingAssignments(ctx context.Context, f models.TeachingAssignmentFilter) ([]models.TeachingAssignment, error) {
+	var assignments []models.TeachingAssignment
+	err := s.db.SelectContext(ctx, &assignments, `
+		SELECT `+assignmentColumns+`
+		FROM teaching_assignments a
+		JOIN classes c ON c.id = a.class_id
+		JOIN subjects sub ON sub.id = a.subject_id
+		WHERE ($1 = 0 OR a.teacher_id = $1)
+		  AND ($2 = 0 OR a.subject_id = $2)
+		  AND ($3 = 0 OR a.class_id = $3)
+		  AND ($4 = 0 OR c.academic_year = $4)
+		ORDER BY c.academic_year DESC, c.grade_level, c.letter, sub.name, a.id`,
+		f.TeacherID, f.SubjectID, f.ClassID, f.AcademicYear)
+	return assignments, err
+}
+
+func (s *Store) GetTeachingAssignment(ctx context.Context, id int) (*models.TeachingAssignment, error) {
+	var assignment models.TeachingAssignment
+	err := s.db.GetContext(ctx, &assignment, `
+		SELECT `+assignmentColumns+`
+		FROM teaching_assignments a JOIN classes c ON c.id = a.class_id
+		WHERE a.id = $1`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &assignment, nil
+}
+
+// CreateTeachingAssignment добавляет назначение; период должен относиться к учебному году класса
+func (s *Store) CreateTeachingAssignment(ctx context.Context, assignment *models.TeachingAssignment) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &assignment.AcademicYear,
+			`SELECT academic_year FROM classes WHERE id = $1`, assignment.ClassID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return store.ErrReference
+			}
+			return err
+		}
+		if assignment.TermID != 0 {
+			var inYear bool
+			if err := tx.GetContext(ctx, &inYear, `
+				SELECT EXISTS (
+					SELECT 1 FROM terms t JOIN academic_years y ON y.id = t.academic_year_id
+					WHERE t.id = $1 AND y.start_year = $2
+				)`, assignment.TermID, assignment.AcademicYear); err != nil {
+				return err
+			}
+			if !inYear {
+				return store.ErrReference
+			}
+		}
+		return tx.QueryRowxContext(ctx, `
+			INSERT INTO teaching_assignments (teacher_id, subject_id, class_id, term_id)
+			VALUES ($1, $2, $3, NULLIF($4, 0))
+			RETURNING id`,
+			assignment.TeacherID, assignment.SubjectID, assignment.ClassID, assignment.TermID,
+		).Scan(&assignment.ID)
+	})
+}
+
+// UpdateTeachingAssignment меняет учителя и заполняет остальные поля назначения
+func (s *Store) UpdateTeachingAssignment(ctx context.Context, assignment *models.TeachingAssignment) error {
+	err := expectRows(s.db.ExecContext(ctx,
+		`UPDATE teaching_assignments SET teacher_id = $1 WHERE id = $2`, assignment.TeacherID, assignment.ID))
+	if err != nil {
+		return err
+	}
+	updated, err := s.GetTeachingAssignment(ctx, assignment.ID)
+	if err != nil {
+		return err
+	}
+	*assignment = *updated
+	return nil
+}
+
+func (s *Store) DeleteTeachingAssignment(ctx context.Context, id int) error {
+	return expectRows(s.db.ExecContext(ctx, `DELETE FROM teaching_assignments WHERE id = $1`, id))
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ListTermGrades возвращает итоговые оценки ученика (0 — всех учеников) за год или период
+func (s *Store) ListTermGrades(ctx context.Context, studentID int, period models.Period) ([]models.TermGrade, error) {
+	var grades []models.TermGrade
+	err := s.db.SelectContext(ctx, &grades, `
+		SELECT g.id, g.student_id, g.subject_id, sub.name AS subject_name, g.term_id, t.number AS quarter,
+			g.grade, g.comment, COALESCE(g.set_by, 0) AS set_by, g.updated_at
+		FROM term_grades g
+		JOIN subjects sub ON sub.id = g.subject_id`+periodJoins+`
+		WHERE ($1 = 0 OR g.student_id = $1) AND `+periodFilter(2)+`
+		ORDER BY sub.name, t.number`,
+		studentID, period.AcademicYear, period.Term)
+	return grades, err
+}
+
+// termGradeQuery выбирает итоговую оценку ученика по предмету за период
+const termGradeQuery = `
+	SELECT g.id, g.student_id, g.subject_id, sub.name AS subject_name, g.term_id, t.number AS quarter,
+		g.grade, g.comment, COALESCE(g.set_by, 0) AS set_by, g.updated_at
+	FROM term_grades g
+	JOIN subjects sub ON sub.id = g.subject_id
+	JOIN terms t ON t.id = g.term_id
+	WHERE g.student_id = $1 AND g.subject_id = $2 AND g.term_id = $3`
+
+func (s *Store) GetTermGrade(ctx context.Context, studentID, subjectID, termID int) (*models.TermGrade, error) {
+	return getTermGrade(ctx, s.db, termGradeQuery, studentID, subjectID, termID)
+}
+
+func getTermGrade(ctx context.Context, q sqlx.QueryerContext, query string, studentID, subjectID, termID int) (*models.TermGrade, error) {
+	var grade models.TermGrade
+	if err := sqlx.GetContext(ctx, q, &grade, query, studentID, subjectID, termID); err != nil {
+		return nil, mapError(err)
+	}
+	return &grade, nil
+}
+
+// SetTermGrade выставляет итоговую оценку и заполняет ее ID, номер периода, название предмета и время
+func (s *Store) SetTermGrade(ctx context.Context, grade *models.TermGrade) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		old, err := getTermGrade(ctx, tx, termGradeQuery+` FOR UPDATE OF g`, grade.StudentID, grade.SubjectID, grade.TermID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+		err = tx.QueryRowxContext(ctx, `
+			WITH saved AS (
+				INSERT INTO term_grades (student_id, subject_id, term_id, grade, comment, set_by, updated_at)
+				VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), now())
+				ON CONFLICT (student_id, subject_id, term_id) DO UPDATE
+				SET grade = EXCLUDED.grade, comment = EXCLUDED.comment,
+					set_by = EXCLUDED.set_by, updated_at = EXCLUDED.updated_at
+				RETURNING id, subject_id, term_id, updated_at
+			)
+			SELECT saved.id, t.number, sub.name, saved.updated_at
+			FROM saved
+			JOIN terms t ON t.id = saved.term_id
+			JOIN subjects sub ON sub.id = saved.subject_id`,
+			grade.StudentID, grade.SubjectID, grade.TermID, grade.Grade, grade.Comment, grade.SetBy,
+		).Scan(&grade.ID, &grade.Quarter, &grade.SubjectName, &grade.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return store.ErrReference
+		}
+		if err != nil {
+			return err
+		}
+		saved, err := getTermGrade(ctx, tx, termGradeQuery, grade.StudentID, grade.SubjectID, grade.TermID)
+		if err != nil {
+			return err
+		}
+		if old == nil {
+			return audit(ctx, tx, models.AuditCreate, models.AuditTermGrade, grade.ID, nil, saved)
+		}
+		return audit(ctx, tx, models.AuditUpdate, models.AuditTermGrade, grade.ID, old, saved)
+	})
+}
+
+func (s *Store) DeleteTermGrade(ctx context.Context, studentID, subjectID, termID int) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		old, err := getTermGrade(ctx, tx, termGradeQuery+` FOR UPDATE OF g`, studentID, subjectID, termID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM term_grades WHERE student_id = $1 AND subject_id = $2 AND term_id = $3`,
+			studentID, subjectID, termID); err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditDelete, models.AuditTermGrade, old.ID, old, nil)
+	})
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+const lessonColumns = `l.id, l.class_id, l.subject_id, l.teacher_id, l.room, l.weekday, l.period,
+	c.academic_year, c.grade_level || c.letter AS class_name, sub.name AS subject_name, t.full_name AS teacher_name`
+
+const lessonJoins = `
+		FROM lessons l
+		JOIN classes c ON c.id = l.class_id
+		JOIN subjects sub ON sub.id = l.subject_id
+		JOIN teachers t ON t.id = l.teacher_id`
+
+func (s *Store) ListBellPeriods(ctx context.Context) ([]models.BellPeriod, error) {
+	var periods []models.BellPeriod
+	err := s.db.SelectContext(ctx, &periods, `
+		SELECT number, to_char(starts_at, 'HH24:MI') AS starts_at, to_char(ends_at, 'HH24:MI') AS ends_at
+		FROM bell_periods ORDER BY number`)
+	return periods, err
+}
+
+// SetBellPeriods заменяет расписание звонков в одной транзакции; номера уроков,
+// на которые ссылаются уроки расписания, удалить нельзя (внешний ключ)
+func (s *Store) SetBellPeriods(ctx context.Context, periods []models.BellPeriod) error {
+	numbers := make([]int64, len(periods))
+	for i, p := range periods {
+		numbers[i] = int64(p.Number)
+	}
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM bell_periods WHERE NOT (number = ANY($1))`, pq.Array(numbers)); err != nil {
+			return err
+		}
+		for _, p := range periods {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO bell_periods (number, starts_at, ends_at) VALUES ($1, $2::TIME, $3::TIME)
+				ON CONFLICT (number) DO UPDATE SET starts_at = EXCLUDED.starts_at, ends_at = EXCLUDED.ends_at`,
+				p.Number, p.StartsAt, p.EndsAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) ListLessons(ctx context.Context, f models.LessonFilter) ([]models.Lesson, error) {
+	var lessons []models.Lesson
+	err := s.db.SelectContext(ctx, &lessons, `
+		SELECT `+lessonColumns+lessonJoins+`
+		WHERE ($1 = 0 OR l.class_id = $1)
+		  AND ($2 = 0 OR l.teacher_id = $2)
+		  AND ($3 = 0 OR c.academic_year = $3)
+		  AND ($4 = 0 OR l.weekday = $4)
+		ORDER BY l.weekday, l.period, c.grade_level, c.letter`,
+		f.ClassID, f.TeacherID, f.AcademicYear, f.Weekday)
+	return lessons, err
+}
+
+func (s *Store) GetLesson(ctx context.Context, id int) (*models.Lesson, error) {
+	var lesson models.Lesson
+	err := s.db.GetContext(ctx, &lesson, `SELECT `+lessonColumns+lessonJoins+` WHERE l.id = $1`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &lesson, nil
+}
+
+// checkLessonSlot проверяет, что в это время в учебном году класса у класса, учителя
+// и в кабинете нет других уроков. Таблица блокируется до конца транзакции, чтобы два
+// одновременных запроса не заняли одно время.
+func checkLessonSlot(ctx context.Context, tx *sqlx.Tx, l *models.Lesson) error {
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE lessons IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return err
+	}
+	var busy string
+	err := tx.GetContext(ctx, &busy, `
+		SELECT CASE WHEN l.class_id = $2 THEN 'class' WHEN l.teacher_id = $3 THEN 'teacher' ELSE 'room' END AS busy
+		FROM lessons l
+		JOIN classes c ON c.id = l.class_id
+		WHERE l.id <> $1 AND l.weekday = $5 AND l.period = $6
+		  AND c.academic_year = (SELECT academic_year FROM classes WHERE id = $2)
+		  AND (l.class_id = $2 OR l.teacher_id = $3 OR ($4 <> '' AND l.room = $4))
+		ORDER BY l.class_id = $2 DESC, l.teacher_id = $3 DESC
+		LIMIT 1`,
+		l.ID, l.ClassID, l.TeacherID, l.Room, l.Weekday, l.Period)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return err
+	case busy == "class":
+		return store.ErrClassBusy
+	case busy == "teacher":
+		return store.ErrTeacherBusy
+	default:
+		return store.ErrRoomBusy
+	}
+}
+
+func (s *Store) CreateLesson(ctx context.Context, lesson *models.Lesson) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := checkLessonSlot(ctx, tx, lesson); err != nil {
+			return err
+		}
+		return tx.QueryRowxContext(ctx, `
+			INSERT INTO lessons (class_id, subject_id, teacher_id, room, weekday, period)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, (SELECT academic_year FROM classes WHERE id = $1)`,
+			lesson.ClassID, lesson.SubjectID, lesson.TeacherID, lesson.Room, lesson.Weekday, lesson.Period,
+		).Scan(&lesson.ID, &lesson.AcademicYear)
+	})
+}
+
+func (s *Store) UpdateLesson(ctx context.Context, lesson *models.Lesson) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := checkLessonSlot(ctx, tx, lesson); err != nil {
+			return err
+		}
+		return expectRows(tx.ExecContext(ctx, `
+			UPDATE lessons SET class_id = $1, subject_id = $2, teacher_id = $3, room = $4, weekday = $5, period = $6
+			WHERE id = $7`,
+			lesson.ClassID, lesson.SubjectID, lesson.TeacherID, lesson.Room, lesson.Weekday, lesson.Period, lesson.ID))
+	})
+}
+
+func (s *Store) DeleteLesson(ctx context.Context, id int) error {
+	return expectRows(s.db.ExecContext(ctx, `DELETE FROM lessons WHERE id = $1`, id))
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+const refreshTokenColumns = `id, user_id, token_hash, access_jti, access_expires_at, expires_at, created_at, revoked_at`
+
+func insertRefreshToken(ctx context.Context, q sqlx.QueryerContext, token *models.RefreshToken) error {
+	return sqlx.GetContext(ctx, q, token, `
+		INSERT INTO refresh_tokens (user_id, token_hash, access_jti, access_expires_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+refreshTokenColumns,
+		token.UserID, token.TokenHash, token.AccessJTI, token.AccessExpiresAt, token.ExpiresAt)
+}
+
+func (s *Store) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return mapError(insertRefreshToken(ctx, s.db, token))
+}
+
+func (s *Store) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := s.db.GetContext(ctx, &token, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken отзывает старый токен и сохраняет новый в одной транзакции
+func (s *Store) RotateRefreshToken(ctx context.Context, oldID int, next *models.RefreshToken) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE refresh_tokens SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, oldID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return store.ErrConflict
+		}
+		return insertRefreshToken(ctx, tx, next)
+	})
+}
+
+// RevokeSession отзывает токен доступа и связанный с ним токен обновления
+func (s *Store) RevokeSession(ctx context.Context, userID int, jti string, expiresAt time.Time) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3)
+			ON CONFLICT (jti) DO NOTHING`, jti, userID, expiresAt); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE refresh_tokens SET revoked_at = now()
+			WHERE user_id = $1 AND access_jti = $2 AND revoked_at IS NULL`, userID, jti)
+		return err
+	})
+}
+
+// RevokeUserTokens отзывает все активные токены пользователя
+func (s *Store) RevokeUserTokens(ctx context.Context, userID int) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		return revokeUserTokens(ctx, tx, userID)
+	})
+}
+
+func revokeUserTokens(ctx context.Context, tx *sqlx.Tx, userID int) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		SELECT access_jti, user_id, access_expires_at FROM refresh_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL AND access_expires_at > now()
+		ON CONFLICT (jti) DO NOTHING`, userID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	return err
+}
+
+func (s *Store) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := s.db.GetContext(ctx, &revoked, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`, jti)
+	return revoked, err
+}
+
+// DeleteExpiredTokens удаляет истекшие записи о токенах
+func (s *Store) DeleteExpiredTokens(ctx context.Context, now time.Time) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, `DELETE FROM password_reset_tokens WHERE expires_at < $1`, now)
+	return err
+}
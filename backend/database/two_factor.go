@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+const totpColumns = `user_id, secret, confirmed_at, last_step, created_at`
+
+func (s *Store) GetTOTP(ctx context.Context, userID int) (*models.TOTP, error) {
+	var t models.TOTP
+	err := s.db.GetContext(ctx, &t, `SELECT `+totpColumns+` FROM user_totp WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &t, nil
+}
+
+// SaveTOTPSecret сохраняет неподтвержденный секрет
+func (s *Store) SaveTOTPSecret(ctx context.Context, userID int, secret string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_totp (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret, confirmed_at = NULL, last_step = 0, created_at = now()`,
+		userID, secret)
+	return mapError(err)
+}
+
+// ConfirmTOTP подтверждает настройку и заменяет коды восстановления в одной транзакции
+func (s *Store) ConfirmTOTP(ctx context.Context, userID int, step int64, recoveryCodeHashes []string) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := expectRows(tx.ExecContext(ctx,
+			`UPDATE user_totp SET confirmed_at = now(), last_step = $2 WHERE user_id = $1`, userID, step)); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		for _, hash := range recoveryCodeHashes {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UseTOTPStep атомарно сдвигает последний принятый интервал
+func (s *Store) UseTOTPStep(ctx context.Context, userID int, step int64) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE user_totp SET last_step = $2 WHERE user_id = $1 AND last_step < $2`, userID, step)
+	if err != nil {
+		return mapError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return store.ErrConflict
+	}
+	return nil
+}
+
+func (s *Store) UseRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	return expectRows(s.db.ExecContext(ctx, `
+		UPDATE recovery_codes SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`, userID, codeHash))
+}
+
+func (s *Store) DeleteTOTP(ctx context.Context, userID int) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		return expectRows(tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID))
+	})
+}
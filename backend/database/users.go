@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"school-system/backend/models"
+)
+
+const userColumns = `id, username, password, role, must_change_password`
+
+func (s *Store) GetUser(ctx context.Context, id int) (*models.User, error) {
+	user := &models.User{}
+	err := s.db.GetContext(ctx, user, `SELECT `+userColumns+` FROM users WHERE id = $1`, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return user, nil
+}
+
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	user := &models.User{}
+	err := s.db.GetContext(ctx, user, `SELECT `+userColumns+` FROM users WHERE username = $1`, username)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return user, nil
+}
+
+// CreateUser создает нового пользователя и заполняет его ID
+func (s *Store) CreateUser(ctx context.Context, user *models.User) error {
+	err := s.db.QueryRowxContext(ctx,
+		`INSERT INTO users (username, password, role, must_change_password) VALUES ($1, $2, $3, $4) RETURNING id`,
+		user.Username, user.Password, user.Role, user.MustChangePassword,
+	).Scan(&user.ID)
+	return mapError(err)
+}
+
+// UpdatePassword сохраняет новый хеш пароля пользователя
+func (s *Store) UpdatePassword(ctx context.Context, userID int, passwordHash string, mustChange bool) error {
+	return expectRows(s.db.ExecContext(ctx,
+		`UPDATE users SET password = $2, must_change_password = $3 WHERE id = $1`,
+		userID, passwordHash, mustChange))
+}
+
+const passwordResetColumns = `id, user_id, token_hash, COALESCE(created_by, 0) AS created_by, expires_at, created_at, used_at`
+
+// CreatePasswordReset сохраняет токен сброса; ранее выданные неиспользованные токены пользователя аннулируются
+func (s *Store) CreatePasswordReset(ctx context.Context, reset *models.PasswordReset) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE password_reset_tokens SET used_at = now() WHERE user_id = $1 AND used_at IS NULL`, reset.UserID); err != nil {
+			return err
+		}
+		return tx.GetContext(ctx, reset, `
+			INSERT INTO password_reset_tokens (user_id, token_hash, created_by, expires_at)
+			VALUES ($1, $2, NULLIF($3, 0), $4)
+			RETURNING `+passwordResetColumns,
+			reset.UserID, reset.TokenHash, reset.CreatedBy, reset.ExpiresAt)
+	})
+}
+
+// ResetPassword погашает токен сброса, устанавливает новый пароль и отзывает все сессии пользователя
+func (s *Store) ResetPassword(ctx context.Context, tokenHash, passwordHash string, now time.Time) (int, error) {
+	var userID int
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &userID, `
+			UPDATE password_reset_tokens SET used_at = $2
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+			RETURNING user_id`, tokenHash, now); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE users SET password = $2, must_change_password = FALSE WHERE id = $1`,
+			userID, passwordHash); err != nil {
+			return err
+		}
+		return revokeUserTokens(ctx, tx, userID)
+	})
+	return userID, err
+}
@@ -0,0 +1,172 @@
+// Package demo наполняет хранилище демонстрационными данными для показа
+// системы без внешних сервисов (go run main.go --demo).
+package demo
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// Password — пароль всех демонстрационных учетных записей
+const Password = "demo12345"
+
+// Account — учетная запись, выводимая в журнал при запуске демо-режима
+type Account struct {
+	Username string
+	Role     string
+}
+
+var teacherData = []struct {
+	fullName, room, subject string
+}{
+	{"Ольга Петровна Смирнова", "101", "Математика"},
+	{"Игорь Васильевич Кузнецов", "204", "Физика"},
+	{"Елена Сергеевна Морозова", "305", "Русский язык"},
+	{"Андрей Николаевич Волков", "112", "История"},
+	{"Наталья Викторовна Лебедева", "218", "Английский язык"},
+}
+
+var classData = map[string][]string{
+	"9А":  {"Алексей Иванов", "Мария Соколова", "Дмитрий Попов", "Анна Новикова", "Сергей Фёдоров"},
+	"9Б":  {"Екатерина Морозова", "Павел Волков", "Ольга Алексеева", "Никита Лебедев", "Дарья Семенова"},
+	"10А": {"Иван Егоров", "Полина Павлова", "Артём Козлов", "Виктория Степанова", "Максим Николаев"},
+}
+
+var classOrder = []string{"9А", "9Б", "10А"}
+
+// Seed заполняет пустое хранилище демонстрационными данными и возвращает
+// список созданных учетных записей. Данные детерминированы.
+func Seed(ctx context.Context, st store.Store) ([]Account, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []Account
+	newUser := func(username, role string) (*models.User, error) {
+		user := &models.User{Username: username, Password: string(hash), Role: role}
+		if err := st.CreateUser(ctx, user); err != nil {
+			return nil, fmt.Errorf("пользователь %s: %w", username, err)
+		}
+		accounts = append(accounts, Account{Username: username, Role: role})
+		return user, nil
+	}
+
+	if _, err := newUser("deputy", "deputy"); err != nil {
+		return nil, err
+	}
+
+	var subjects []models.Subject
+	var teachers []models.Teacher
+	for i, td := range teacherData {
+		user, err := newUser(fmt.Sprintf("teacher%d", i+1), "teacher")
+		if err != nil {
+			return nil, err
+		}
+		teacher := models.Teacher{FullName: td.fullName, RoomNumber: td.room, UserID: user.ID}
+		if err := st.CreateTeacher(ctx, &teacher); err != nil {
+			return nil, fmt.Errorf("учитель %s: %w", td.fullName, err)
+		}
+		subject := models.Subject{Name: td.subject}
+		if err := st.CreateSubject(ctx, &subject); err != nil {
+			return nil, fmt.Errorf("предмет %s: %w", td.subject, err)
+		}
+		subjects = append(subjects, subject)
+		teachers = append(teachers, teacher)
+	}
+
+	// Классы различаются средним уровнем, чтобы статистика была наглядной
+	classLevel := map[string]float64{"9А": 4.2, "9Б": 3.4, "10А": 3.9}
+	rnd := rand.New(rand.NewSource(2024))
+
+	year := models.AcademicYearOf(time.Now())
+	academicYear := models.AcademicYear{
+		StartYear: year,
+		TermKind:  models.TermKindQuarter,
+		Terms:     models.DefaultTerms(year, models.TermKindQuarter),
+	}
+	academicYear.StartsOn = academicYear.Terms[0].StartsOn
+	academicYear.EndsOn = academicYear.Terms[len(academicYear.Terms)-1].EndsOn
+	if err := st.CreateAcademicYear(ctx, &academicYear); err != nil {
+		return nil, fmt.Errorf("учебный год: %w", err)
+	}
+
+	for _, className := range classOrder {
+		level, letter, err := models.ParseClassName(className)
+		if err != nil {
+			return nil, err
+		}
+		class := models.Class{GradeLevel: level, Letter: letter, AcademicYear: year}
+		if err := st.CreateClass(ctx, &class); err != nil {
+			return nil, fmt.Errorf("класс %s: %w", className, err)
+		}
+		var students []models.Student
+		for i, fullName := range classData[className] {
+			student := models.Student{FullName: fullName, ClassID: class.ID}
+			if className == "9А" && i == 0 {
+				user, err := newUser("student1", "student")
+				if err != nil {
+					return nil, err
+				}
+				student.UserID = user.ID
+			}
+			if err := st.CreateStudent(ctx, &student); err != nil {
+				return nil, fmt.Errorf("ученик %s: %w", fullName, err)
+			}
+			students = append(students, student)
+		}
+
+		// Каждый учитель ведет свой предмет во всех классах; класс целиком зачисляется к нему
+		for i, subject := range subjects {
+			assignment := models.TeachingAssignment{TeacherID: teachers[i].ID, SubjectID: subject.ID, ClassID: class.ID}
+			if err := st.CreateTeachingAssignment(ctx, &assignment); err != nil {
+				return nil, fmt.Errorf("назначение %s в %s: %w", subject.Name, className, err)
+			}
+			if _, err := st.EnrollClass(ctx, class.ID, models.Enrollment{SubjectID: subject.ID}); err != nil {
+				return nil, fmt.Errorf("зачисление %s на %s: %w", className, subject.Name, err)
+			}
+		}
+
+		for _, student := range students {
+			for _, subject := range subjects {
+				for _, term := range academicYear.Terms[:2] {
+					for n := 0; n < 3; n++ {
+						grade := models.Grade{
+							StudentID: student.ID,
+							SubjectID: subject.ID,
+							Grade:     demoGrade(rnd, classLevel[className]),
+							TermID:    term.ID,
+						}
+						// Третья отметка в четверти — контрольная работа
+						if n == 2 {
+							grade.Kind = models.MarkKindTest
+						}
+						if err := st.CreateGrade(ctx, &grade); err != nil {
+							return nil, fmt.Errorf("оценка для %s: %w", student.FullName, err)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return accounts, nil
+}
+
+// demoGrade возвращает оценку 2..5 около заданного среднего уровня
+func demoGrade(rnd *rand.Rand, level float64) int {
+	grade := int(level + rnd.NormFloat64()*0.9 + 0.5)
+	if grade < 2 {
+		return 2
+	}
+	if grade > 5 {
+		return 5
+	}
+	return grade
+}
@@ -0,0 +1,56 @@
+package demo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"school-system/backend/handlers"
+	"school-system/backend/models"
+	"school-system/backend/store/memory"
+)
+
+func TestSeedFillsEveryStatistic(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+
+	accounts, err := Seed(ctx, st)
+	if err != nil {
+		t.Fatalf("Ошибка заполнения: %v", err)
+	}
+	if len(accounts) == 0 {
+		t.Fatal("Не создано ни одной учетной записи")
+	}
+
+	period := models.Period{AcademicYear: models.AcademicYearOf(time.Now())}
+	if count, _ := st.CountStudents(ctx); count != 15 {
+		t.Errorf("Ожидалось 15 учеников, получено %d", count)
+	}
+	top, worst, err := st.GetTopAndWorstClasses(ctx, period)
+	if err != nil || top == "" || worst == "" || top == worst {
+		t.Errorf("Некорректные лучший/худший классы: %q, %q, %v", top, worst, err)
+	}
+	byClass, _ := st.GetAverageGradesByClass(ctx, period)
+	if len(byClass) != 3 {
+		t.Errorf("Ожидалась статистика по 3 классам, получено %d", len(byClass))
+	}
+	resp := httptest.NewRecorder()
+	handlers.NewServer(st).GetFailingStudents(resp, httptest.NewRequest("GET", "/stats/failing-students", nil))
+	var failing []models.FailingStudent
+	json.Unmarshal(resp.Body.Bytes(), &failing)
+	if resp.Code != http.StatusOK || len(failing) == 0 {
+		t.Errorf("В демо-данных должны быть неуспевающие ученики, получено %d %s", resp.Code, resp.Body.String())
+	}
+
+	teacher, err := st.GetTeacherByUserID(ctx, 2)
+	if err != nil {
+		t.Fatalf("Учитель teacher1 не найден: %v", err)
+	}
+	grades, _ := st.GetGradesByTeacher(ctx, teacher.ID, period)
+	if len(grades) != 15 {
+		t.Errorf("teacher1 должен видеть оценки 15 учеников, получено %d", len(grades))
+	}
+}
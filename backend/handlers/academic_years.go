@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"school-system/backend/models"
+)
+
+// Ошибки определения учебного периода оценки
+var (
+	errTermNotFound    = errors.New("Учебный период не найден")
+	errDateOutsideTerm = errors.New("Дата оценки не входит в учебный период")
+)
+
+// AcademicYearRequest — учебный год. Если периоды не указаны, они создаются
+// с типовыми датами; год начинается с первого периода и заканчивается последним.
+type AcademicYearRequest struct {
+	StartYear int           `json:"start_year"`
+	TermKind  string        `json:"term_kind"` // quarter / trimester / semester
+	Terms     []TermRequest `json:"terms"`
+}
+
+// TermRequest — период учебного года; номер периода определяется порядком в списке
+type TermRequest struct {
+	Name     string      `json:"name"`
+	StartsOn models.Date `json:"starts_on"` // 2025-09-01
+	EndsOn   models.Date `json:"ends_on"`
+}
+
+// periodFromRequest читает параметры academic_year и term. По умолчанию — текущий
+// учебный год целиком. При ошибке ответ уже отправлен.
+func periodFromRequest(w http.ResponseWriter, r *http.Request) (models.Period, bool) {
+	period := models.Period{AcademicYear: models.AcademicYearOf(time.Now())}
+	query := r.URL.Query()
+	if v := query.Get("academic_year"); v != "" {
+		year, err := strconv.Atoi(v)
+		if err != nil || year < 2000 || year > 2100 {
+			http.Error(w, "Некорректный учебный год", http.StatusBadRequest)
+			return period, false
+		}
+		period.AcademicYear = year
+	}
+	if v := query.Get("term"); v != "" {
+		term, err := strconv.Atoi(v)
+		if err != nil || term < 1 || term > 4 {
+			http.Error(w, "Номер периода должен быть от 1 до 4", http.StatusBadRequest)
+			return period, false
+		}
+		period.Term = term
+	}
+	return period, true
+}
+
+// resolveGradeTerm определяет период оценки: по term_id, по номеру четверти в текущем
+// учебном году или по дате оценки (по умолчанию — сегодня). Дата оценки должна попадать
+// в период; если дата не указана, берется ближайшая к сегодняшней дата периода.
+func (s *Server) resolveGradeTerm(ctx context.Context, grade *models.Grade) error {
+	today := models.NewDate(time.Now())
+	var term *models.Term
+	switch {
+	case grade.TermID != 0:
+		found, err := s.AcademicYears.GetTerm(ctx, grade.TermID)
+		if err != nil {
+			if storeErrorStatus(err) == http.StatusNotFound {
+				return errTermNotFound
+			}
+			return err
+		}
+		term = found
+	default:
+		day, byDate := grade.Date, grade.Quarter == 0
+		if day.IsZero() {
+			day = today
+		}
+		year, err := s.AcademicYears.GetAcademicYear(ctx, models.AcademicYearOf(day.Time))
+		if err != nil {
+			if storeErrorStatus(err) == http.StatusNotFound {
+				return errTermNotFound
+			}
+			return err
+		}
+		for i, t := range year.Terms {
+			if (byDate && t.Contains(day)) || (!byDate && t.Number == grade.Quarter) {
+				term = &year.Terms[i]
+				break
+			}
+		}
+		if term == nil {
+			return errTermNotFound
+		}
+	}
+
+	if grade.Date.IsZero() {
+		grade.Date = term.Clamp(today)
+	} else if !term.Contains(grade.Date) {
+		return errDateOutsideTerm
+	}
+	grade.TermID = term.ID
+	grade.Quarter = term.Number
+	return nil
+}
+
+// termOfDate возвращает период учебного года, в который попадает дата; errTermNotFound, если такого нет
+func (s *Server) termOfDate(ctx context.Context, day models.Date) (*models.Term, error) {
+	year, err := s.AcademicYears.GetAcademicYear(ctx, models.AcademicYearOf(day.Time))
+	if err != nil {
+		if storeErrorStatus(err) == http.StatusNotFound {
+			return nil, errTermNotFound
+		}
+		return nil, err
+	}
+	for i, t := range year.Terms {
+		if t.Contains(day) {
+			return &year.Terms[i], nil
+		}
+	}
+	return nil, errTermNotFound
+}
+
+// academicYearFromRequest проверяет данные учебного года и строит его вместе с периодами
+func academicYearFromRequest(req AcademicYearRequest) (*models.AcademicYear, error) {
+	if req.StartYear < 2000 || req.StartYear > 2100 {
+		return nil, errors.New("Некорректный учебный год")
+	}
+	if req.TermKind == "" {
+		req.TermKind = models.TermKindQuarter
+	}
+	if !models.ValidTermKind(req.TermKind) {
+		return nil, errors.New("Вид периодов должен быть quarter, trimester или semester")
+	}
+
+	terms := models.DefaultTerms(req.StartYear, req.TermKind)
+	if len(req.Terms) != 0 {
+		if len(req.Terms) != len(terms) {
+			return nil, fmt.Errorf("Для вида %s нужно периодов: %d", req.TermKind, len(terms))
+		}
+		for i, t := range req.Terms {
+			if t.StartsOn.IsZero() || t.EndsOn.IsZero() {
+				return nil, errors.New("Для каждого периода нужны даты начала и окончания")
+			}
+			if t.Name != "" {
+				terms[i].Name = t.Name
+			}
+			terms[i].StartsOn, terms[i].EndsOn = t.StartsOn, t.EndsOn
+		}
+	}
+	for i, term := range terms {
+		if utf8.RuneCountInString(term.Name) > 50 {
+			return nil, errors.New("Название периода слишком длинное")
+		}
+		if term.EndsOn.Before(term.StartsOn.Time) {
+			return nil, fmt.Errorf("Период %d заканчивается раньше, чем начинается", term.Number)
+		}
+		if i > 0 && !term.StartsOn.After(terms[i-1].EndsOn.Time) {
+			return nil, fmt.Errorf("Период %d пересекается с предыдущим", term.Number)
+		}
+	}
+
+	return &models.AcademicYear{
+		StartYear: req.StartYear,
+		StartsOn:  terms[0].StartsOn,
+		EndsOn:    terms[len(terms)-1].EndsOn,
+		TermKind:  req.TermKind,
+		Terms:     terms,
+	}, nil
+}
+
+// GetAcademicYears возвращает учебные годы с периодами
+func (s *Server) GetAcademicYears(w http.ResponseWriter, r *http.Request) {
+	years, err := s.AcademicYears.ListAcademicYears(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении учебных годов: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if years == nil {
+		years = []models.AcademicYear{}
+	}
+	writeJSON(w, http.StatusOK, years)
+}
+
+// GetAcademicYear возвращает учебный год по году начала
+func (s *Server) GetAcademicYear(w http.ResponseWriter, r *http.Request) {
+	startYear, err := pathInt(r, "year")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	year, err := s.AcademicYears.GetAcademicYear(r.Context(), startYear)
+	if err != nil {
+		log.Printf("Ошибка при получении учебного года %d: %v", startYear, err)
+		http.Error(w, "Учебный год не найден", storeErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, year)
+}
+
+// CreateAcademicYear добавляет учебный год с периодами
+func (s *Server) CreateAcademicYear(w http.ResponseWriter, r *http.Request) {
+	var req AcademicYearRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	year, err := academicYearFromRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.AcademicYears.CreateAcademicYear(r.Context(), year); err != nil {
+		log.Printf("Ошибка при добавлении учебного года %d: %v", year.StartYear, err)
+		http.Error(w, "Ошибка при добавлении учебного года", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Добавлен учебный год %s", year.Name)
+	writeJSON(w, http.StatusCreated, year)
+}
+
+// UpdateTerm меняет название и даты периода
+func (s *Server) UpdateTerm(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req TermRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	term, err := s.AcademicYears.GetTerm(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Учебный период не найден", storeErrorStatus(err))
+		return
+	}
+	if req.Name != "" {
+		if utf8.RuneCountInString(req.Name) > 50 {
+			http.Error(w, "Название периода слишком длинное", http.StatusBadRequest)
+			return
+		}
+		term.Name = req.Name
+	}
+	if !req.StartsOn.IsZero() {
+		term.StartsOn = req.StartsOn
+	}
+	if !req.EndsOn.IsZero() {
+		term.EndsOn = req.EndsOn
+	}
+	if term.EndsOn.Before(term.StartsOn.Time) {
+		http.Error(w, "Период заканчивается раньше, чем начинается", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.AcademicYears.UpdateTerm(r.Context(), term); err != nil {
+		log.Printf("Ошибка при обновлении периода %d: %v", id, err)
+		http.Error(w, "Ошибка при обновлении периода", storeErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, term)
+}
+
+// DeleteAcademicYear удаляет учебный год, если по его периодам нет оценок
+func (s *Server) DeleteAcademicYear(w http.ResponseWriter, r *http.Request) {
+	startYear, err := pathInt(r, "year")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.AcademicYears.DeleteAcademicYear(r.Context(), startYear); err != nil {
+		log.Printf("Ошибка при удалении учебного года %d: %v", startYear, err)
+		status := storeErrorStatus(err)
+		if status == http.StatusBadRequest {
+			http.Error(w, "По периодам этого года есть оценки", status)
+			return
+		}
+		http.Error(w, "Ошибка при удалении учебного года", status)
+		return
+	}
+	log.Printf("Удален учебный год %d", startYear)
+	w.WriteHeader(http.StatusOK)
+}
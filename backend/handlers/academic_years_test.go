@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func TestAcademicYearsAndPeriodFilter(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	deputy := createUser(t, st, "deputy", "deputy")
+	teacher := createUser(t, st, "teacher", "teacher")
+	current := models.AcademicYearOf(time.Now())
+	next := current + 1
+
+	body, _ := json.Marshal(AcademicYearRequest{StartYear: next, TermKind: models.TermKindTrimester})
+	if resp := do(t, router, "POST", "/academic-years", bytes.NewReader(body), teacher); resp.Code != http.StatusForbidden {
+		t.Errorf("Учитель: ожидался статус 403, получен %d", resp.Code)
+	}
+	resp := do(t, router, "POST", "/academic-years", bytes.NewReader(body), deputy)
+	var year models.AcademicYear
+	json.Unmarshal(resp.Body.Bytes(), &year)
+	if resp.Code != http.StatusCreated || len(year.Terms) != 3 || year.Name != models.AcademicYearName(next) {
+		t.Fatalf("Ожидался год с тремя триместрами, получено %d %+v", resp.Code, year)
+	}
+	if resp := do(t, router, "POST", "/academic-years", bytes.NewReader(body), deputy); resp.Code != http.StatusConflict {
+		t.Errorf("Повторный год: ожидался статус 409, получен %d", resp.Code)
+	}
+
+	day := func(year int, month time.Month, d int) models.Date {
+		return models.NewDate(time.Date(year, month, d, 0, 0, 0, 0, time.UTC))
+	}
+	overlapping, _ := json.Marshal(AcademicYearRequest{StartYear: next + 1, TermKind: models.TermKindSemester, Terms: []TermRequest{
+		{StartsOn: day(next+1, time.September, 1), EndsOn: day(next+2, time.January, 15)},
+		{StartsOn: day(next+2, time.January, 10), EndsOn: day(next+2, time.May, 31)},
+	}})
+	if resp := do(t, router, "POST", "/academic-years", bytes.NewReader(overlapping), deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Пересекающиеся периоды: ожидался статус 400, получен %d", resp.Code)
+	}
+
+	student := &models.Student{FullName: "Иван Иванов", ClassName: "9А"}
+	mustCreate(t, st.CreateStudent(ctx, student))
+	subject := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, subject))
+	for _, g := range []models.Grade{
+		{StudentID: student.ID, SubjectID: subject.ID, Grade: 5, TermID: termID(t, st, 1)},
+		{StudentID: student.ID, SubjectID: subject.ID, Grade: 3, TermID: termID(t, st, 2)},
+	} {
+		mustCreate(t, st.CreateGrade(ctx, &g))
+	}
+	body, _ = json.Marshal(models.Grade{StudentID: student.ID, SubjectID: subject.ID, Grade: 2, TermID: year.Terms[0].ID})
+	resp = do(t, router, "POST", "/grades", bytes.NewReader(body), deputy)
+	var created models.Grade
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	if resp.Code != http.StatusCreated || created.Quarter != 1 {
+		t.Fatalf("Оценка за триместр: ожидался статус 201 и период 1, получено %d %+v", resp.Code, created)
+	}
+
+	average := func(query string) float64 {
+		t.Helper()
+		resp := do(t, router, "GET", "/stats/average-grade"+query, nil, deputy)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("Средний балл %q: статус %d", query, resp.Code)
+		}
+		var avg map[string]float64
+		json.Unmarshal(resp.Body.Bytes(), &avg)
+		return avg["average"]
+	}
+	if got := average(""); got != 4 {
+		t.Errorf("Текущий год: ожидался средний балл 4, получено %v", got)
+	}
+	if got := average("?term=2"); got != 3 {
+		t.Errorf("Вторая четверть: ожидался средний балл 3, получено %v", got)
+	}
+	if got := average(fmt.Sprintf("?academic_year=%d", next)); got != 2 {
+		t.Errorf("Следующий год: ожидался средний балл 2, получено %v", got)
+	}
+	if resp := do(t, router, "GET", "/stats/average-grade?term=5", nil, deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Неверный период: ожидался статус 400, получен %d", resp.Code)
+	}
+
+	path := fmt.Sprintf("/academic-years/%d", next)
+	if resp := do(t, router, "DELETE", path, nil, deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Год с оценками: ожидался статус 400, получен %d", resp.Code)
+	}
+	mustCreate(t, st.DeleteGrade(ctx, created.ID))
+	if resp := do(t, router, "DELETE", path, nil, deputy); resp.Code != http.StatusOK {
+		t.Errorf("Удаление года: ожидался статус 200, получен %d", resp.Code)
+	}
+}
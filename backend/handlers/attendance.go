@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// maxLessonNumber — наибольший номер урока в расписании дня
+const maxLessonNumber = 12
+
+// AttendanceMark — отметка одного ученика при отметке класса
+type AttendanceMark struct {
+	StudentID int    `json:"student_id"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason"`
+}
+
+// MarkClassAttendanceRequest — посещаемость урока для всего класса. Ученики класса,
+// которых нет в marks, отмечаются присутствующими.
+type MarkClassAttendanceRequest struct {
+	SubjectID int `json:"subject_id"`
+	// Date — дата урока; по умолчанию сегодня
+	Date   models.Date      `json:"date"`
+	Lesson int              `json:"lesson"`
+	Marks  []AttendanceMark `json:"marks"`
+}
+
+// AttendanceUpdateRequest — исправление отметки, например когда принесли справку
+type AttendanceUpdateRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// validateAttendanceStatus проверяет статус и причину; для пропуска по уважительной
+// причине причина обязательна
+func validateAttendanceStatus(status, reason string) error {
+	if !models.ValidAttendanceStatus(status) {
+		return errors.New("Статус должен быть present, absent, late или excused")
+	}
+	if status == models.AttendanceExcused && reason == "" {
+		return errors.New("Для пропуска по уважительной причине нужно указать причину")
+	}
+	if utf8.RuneCountInString(reason) > 255 {
+		return errors.New("Причина должна быть не длиннее 255 символов")
+	}
+	return nil
+}
+
+// attendanceFilterFromRequest разбирает параметры отбора посещаемости: student_id, class_id,
+// subject_id, учебный год и период (по умолчанию текущий учебный год), from и to.
+// При ошибке ответ уже отправлен.
+func attendanceFilterFromRequest(w http.ResponseWriter, r *http.Request) (models.AttendanceFilter, bool) {
+	var f models.AttendanceFilter
+	query := r.URL.Query()
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{
+		{"student_id", &f.StudentID},
+		{"class_id", &f.ClassID},
+		{"subject_id", &f.SubjectID},
+	} {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "Некорректный "+p.name, http.StatusBadRequest)
+			return f, false
+		}
+		*p.value = n
+	}
+	for _, p := range []struct {
+		name  string
+		value *models.Date
+	}{
+		{"from", &f.From},
+		{"to", &f.To},
+	} {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		day, err := models.ParseDate(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return f, false
+		}
+		*p.value = day
+	}
+	period, ok := periodFromRequest(w, r)
+	f.Period = period
+	return f, ok
+}
+
+// writeAttendanceError отправляет ответ на ошибку сохранения посещаемости
+func writeAttendanceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrArchived) {
+		http.Error(w, "Учебный год в архиве", http.StatusConflict)
+		return
+	}
+	switch storeErrorStatus(err) {
+	case http.StatusNotFound:
+		http.Error(w, "Отметка не найдена", http.StatusNotFound)
+	case http.StatusBadRequest:
+		http.Error(w, "Ученик, предмет или учебный период не найден", http.StatusBadRequest)
+	default:
+		http.Error(w, "Ошибка при сохранении посещаемости", http.StatusInternalServerError)
+	}
+}
+
+// GetAttendance возвращает отметки посещаемости, доступные пользователю так же, как оценки:
+// учителю — по его предметам в его классах, ученику и родителю — свои, с правом grades:all — все
+func (s *Server) GetAttendance(w http.ResponseWriter, r *http.Request) {
+	filter, ok := attendanceFilterFromRequest(w, r)
+	if !ok {
+		return
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return
+	}
+	records, err := s.Attendance.ListAttendance(r.Context(), filter)
+	if err != nil {
+		log.Printf("Ошибка при получении посещаемости: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+
+	result := []models.Attendance{}
+	for _, record := range records {
+		if access.teaches(record.StudentID, record.SubjectID, record.TermID) || access.ownsStudent(record.StudentID) {
+			result = append(result, record)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// MarkClassAttendance отмечает посещаемость урока сразу для всего класса;
+// без права grades:all — только по своему предмету в классе
+func (s *Server) MarkClassAttendance(w http.ResponseWriter, r *http.Request) {
+	classID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	var req MarkClassAttendanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if req.SubjectID < 1 {
+		http.Error(w, "Нужно указать предмет", http.StatusBadRequest)
+		return
+	}
+	if req.Lesson < 1 || req.Lesson > maxLessonNumber {
+		http.Error(w, fmt.Sprintf("Номер урока должен быть от 1 до %d", maxLessonNumber), http.StatusBadRequest)
+		return
+	}
+	if req.Date.IsZero() {
+		req.Date = models.NewDate(time.Now())
+	}
+
+	class, err := s.Classes.GetClass(r.Context(), classID)
+	if err != nil {
+		log.Printf("Ошибка при получении класса %d: %v", classID, err)
+		http.Error(w, "Класс не найден", storeErrorStatus(err))
+		return
+	}
+	if class.AcademicYear != models.AcademicYearOf(req.Date.Time) {
+		http.Error(w, "Дата урока не входит в учебный год класса", http.StatusBadRequest)
+		return
+	}
+	term, err := s.termOfDate(r.Context(), req.Date)
+	if err != nil {
+		if errors.Is(err, errTermNotFound) {
+			http.Error(w, "Дата урока не входит в учебный период", http.StatusBadRequest)
+			return
+		}
+		log.Printf("Ошибка при определении учебного периода: %v", err)
+		http.Error(w, "Ошибка при определении учебного периода", http.StatusInternalServerError)
+		return
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return
+	}
+	if !access.teachesClass(req.SubjectID, classID, term.ID) {
+		http.Error(w, "Вы не ведете этот предмет в классе", http.StatusForbidden)
+		return
+	}
+	students, err := s.Students.GetStudentsByClass(r.Context(), classID)
+	if err != nil {
+		log.Printf("Ошибка при получении учеников класса %d: %v", classID, err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+
+	marks := make(map[int]AttendanceMark, len(req.Marks))
+	inClass := make(map[int]bool, len(students))
+	for _, student := range students {
+		inClass[student.ID] = true
+	}
+	for _, mark := range req.Marks {
+		if !inClass[mark.StudentID] {
+			http.Error(w, fmt.Sprintf("Ученик %d не учится в этом классе", mark.StudentID), http.StatusBadRequest)
+			return
+		}
+		if _, dup := marks[mark.StudentID]; dup {
+			http.Error(w, fmt.Sprintf("Ученик %d отмечен дважды", mark.StudentID), http.StatusBadRequest)
+			return
+		}
+		if err := validateAttendanceStatus(mark.Status, mark.Reason); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		marks[mark.StudentID] = mark
+	}
+
+	records := make([]models.Attendance, 0, len(students))
+	for _, student := range students {
+		record := models.Attendance{
+			StudentID: student.ID,
+			SubjectID: req.SubjectID,
+			Date:      req.Date,
+			Lesson:    req.Lesson,
+			TermID:    term.ID,
+			Status:    models.AttendancePresent,
+			MarkedBy:  userID,
+		}
+		if mark, ok := marks[student.ID]; ok {
+			record.Status, record.Reason = mark.Status, mark.Reason
+		}
+		records = append(records, record)
+	}
+	if err := s.Attendance.MarkAttendance(r.Context(), records); err != nil {
+		log.Printf("Ошибка при отметке посещаемости класса %d: %v", classID, err)
+		writeAttendanceError(w, err)
+		return
+	}
+
+	log.Printf("Отмечена посещаемость класса %d на уроке %d %s, учеников: %d", classID, req.Lesson, req.Date, len(records))
+	writeJSON(w, http.StatusOK, records)
+}
+
+// UpdateAttendance меняет статус и причину отметки; без права grades:all — только по своему предмету у ученика
+func (s *Server) UpdateAttendance(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	var req AttendanceUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if err := validateAttendanceStatus(req.Status, req.Reason); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	record, ok := s.attendanceForWrite(w, r, id)
+	if !ok {
+		return
+	}
+	record.Status, record.Reason, record.MarkedBy = req.Status, req.Reason, userID
+	if err := s.Attendance.UpdateAttendance(r.Context(), record); err != nil {
+		log.Printf("Ошибка при обновлении отметки посещаемости %d: %v", id, err)
+		writeAttendanceError(w, err)
+		return
+	}
+
+	log.Printf("Успешно обновлена отметка посещаемости с ID: %d", id)
+	writeJSON(w, http.StatusOK, record)
+}
+
+func (s *Server) DeleteAttendance(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := s.attendanceForWrite(w, r, id); !ok {
+		return
+	}
+	if err := s.Attendance.DeleteAttendance(r.Context(), id); err != nil {
+		log.Printf("Ошибка при удалении отметки посещаемости %d: %v", id, err)
+		writeAttendanceError(w, err)
+		return
+	}
+
+	log.Printf("Успешно удалена отметка посещаемости с ID: %d", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// attendanceForWrite возвращает отметку, если пользователь ведет её предмет.
+// При ошибке ответ уже отправлен.
+func (s *Server) attendanceForWrite(w http.ResponseWriter, r *http.Request, id int) (*models.Attendance, bool) {
+	record, err := s.Attendance.GetAttendance(r.Context(), id)
+	if err != nil {
+		log.Printf("Ошибка при получении отметки посещаемости %d: %v", id, err)
+		writeAttendanceError(w, err)
+		return nil, false
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return nil, false
+	}
+	if !access.teaches(record.StudentID, record.SubjectID, record.TermID) {
+		http.Error(w, "Вы не ведете этот предмет у ученика", http.StatusForbidden)
+		return nil, false
+	}
+	return record, true
+}
+
+// GetAbsenceByStudent возвращает пропуски учеников по убыванию доли пропущенных уроков
+func (s *Server) GetAbsenceByStudent(w http.ResponseWriter, r *http.Request) {
+	filter, ok := attendanceFilterFromRequest(w, r)
+	if !ok {
+		return
+	}
+	result, err := s.Attendance.GetAbsenceByStudent(r.Context(), filter)
+	if err != nil {
+		log.Printf("Ошибка при получении пропусков учеников: %v", err)
+		http.Error(w, "Ошибка при получении пропусков учеников", http.StatusInternalServerError)
+		return
+	}
+	if result == nil {
+		result = []models.StudentAbsence{}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// GetAbsenceByClass возвращает пропуски по классам
+func (s *Server) GetAbsenceByClass(w http.ResponseWriter, r *http.Request) {
+	filter, ok := attendanceFilterFromRequest(w, r)
+	if !ok {
+		return
+	}
+	result, err := s.Attendance.GetAbsenceByClass(r.Context(), filter)
+	if err != nil {
+		log.Printf("Ошибка при получении пропусков по классам: %v", err)
+		http.Error(w, "Ошибка при получении пропусков по классам", http.StatusInternalServerError)
+		return
+	}
+	if result == nil {
+		result = []models.ClassAbsence{}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
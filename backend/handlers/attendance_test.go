@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func TestMarkClassAttendanceAndAbsenceStats(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	deputy := createUser(t, st, "deputy", "deputy")
+	teacherUser := createUser(t, st, "teacher", "teacher")
+	otherUser := createUser(t, st, "other", "teacher")
+	pupilUser := createUser(t, st, "pupil", "student")
+
+	teacher := &models.Teacher{FullName: "Иванова Анна", UserID: teacherUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, teacher))
+	mustCreate(t, st.CreateTeacher(ctx, &models.Teacher{FullName: "Петров Олег", UserID: otherUser.ID}))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	class := assignTeacher(t, st, teacher.ID, math.ID)
+	ivan := &models.Student{FullName: "Иван Иванов", ClassID: class.ID, UserID: pupilUser.ID}
+	mustCreate(t, st.CreateStudent(ctx, ivan))
+	olga := &models.Student{FullName: "Ольга Смирнова", ClassID: class.ID}
+	mustCreate(t, st.CreateStudent(ctx, olga))
+
+	year, err := st.GetAcademicYear(ctx, models.AcademicYearOf(time.Now()))
+	mustCreate(t, err)
+	day := year.Terms[0].StartsOn
+	path := fmt.Sprintf("/classes/%d/attendance", class.ID)
+	mark := func(user *models.User, lesson int, marks ...AttendanceMark) (int, []models.Attendance) {
+		t.Helper()
+		body, _ := json.Marshal(MarkClassAttendanceRequest{SubjectID: math.ID, Date: day, Lesson: lesson, Marks: marks})
+		resp := do(t, router, "POST", path, bytes.NewReader(body), user)
+		var records []models.Attendance
+		json.Unmarshal(resp.Body.Bytes(), &records)
+		return resp.Code, records
+	}
+
+	if code, _ := mark(otherUser, 1); code != http.StatusForbidden {
+		t.Errorf("Учитель чужого предмета: ожидался статус 403, получен %d", code)
+	}
+	if code, _ := mark(pupilUser, 1); code != http.StatusForbidden {
+		t.Errorf("Ученик без attendance:write: ожидался статус 403, получен %d", code)
+	}
+	if code, _ := mark(teacherUser, 1, AttendanceMark{StudentID: ivan.ID, Status: models.AttendanceExcused}); code != http.StatusBadRequest {
+		t.Errorf("Уважительная причина без причины: ожидался статус 400, получен %d", code)
+	}
+
+	// Неотмеченные ученики считаются присутствующими; повторная отметка урока заменяет прежнюю
+	if code, records := mark(teacherUser, 1, AttendanceMark{StudentID: ivan.ID, Status: models.AttendanceLate}); code != http.StatusOK || len(records) != 2 {
+		t.Fatalf("Отметка класса: ожидался статус 200 и 2 отметки, получено %d %+v", code, records)
+	}
+	code, records := mark(teacherUser, 1, AttendanceMark{StudentID: ivan.ID, Status: models.AttendanceAbsent})
+	if code != http.StatusOK || len(records) != 2 {
+		t.Fatalf("Повторная отметка: ожидался статус 200 и 2 отметки, получено %d %+v", code, records)
+	}
+	if code, _ := mark(teacherUser, 2); code != http.StatusOK {
+		t.Fatalf("Второй урок: ожидался статус 200, получен %d", code)
+	}
+
+	var list []models.Attendance
+	resp := do(t, router, "GET", "/attendance", nil, pupilUser)
+	json.Unmarshal(resp.Body.Bytes(), &list)
+	if resp.Code != http.StatusOK || len(list) != 2 || list[0].StudentID != ivan.ID || list[0].Status != models.AttendanceAbsent {
+		t.Fatalf("Ученик должен видеть свои 2 отметки, получено %d %s", resp.Code, resp.Body.String())
+	}
+
+	// Завуч видит долю пропусков по ученикам и классам
+	resp = do(t, router, "GET", "/stats/absence-by-student", nil, deputy)
+	var byStudent []models.StudentAbsence
+	json.Unmarshal(resp.Body.Bytes(), &byStudent)
+	if resp.Code != http.StatusOK || len(byStudent) != 2 || byStudent[0].StudentID != ivan.ID ||
+		byStudent[0].Lessons != 2 || byStudent[0].Absent != 1 || byStudent[0].AbsenceRate != 50 {
+		t.Fatalf("Пропуски по ученикам: получено %d %s", resp.Code, resp.Body.String())
+	}
+	if resp := do(t, router, "GET", "/stats/absence-by-class", nil, teacherUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Учитель без stats:read: ожидался статус 403, получен %d", resp.Code)
+	}
+
+	// Справка: пропуск становится уважительным и по-прежнему считается пропуском
+	body, _ := json.Marshal(AttendanceUpdateRequest{Status: models.AttendanceExcused, Reason: "Справка"})
+	if resp := do(t, router, "PUT", fmt.Sprintf("/attendance/%d", list[0].ID), bytes.NewReader(body), teacherUser); resp.Code != http.StatusOK {
+		t.Fatalf("Исправление отметки: ожидался статус 200, получен %d %s", resp.Code, resp.Body.String())
+	}
+	resp = do(t, router, "GET", "/stats/absence-by-class", nil, deputy)
+	var byClass []models.ClassAbsence
+	json.Unmarshal(resp.Body.Bytes(), &byClass)
+	if resp.Code != http.StatusOK || len(byClass) != 1 || byClass[0].Name != "9А" || byClass[0].Lessons != 4 ||
+		byClass[0].Excused != 1 || byClass[0].AbsenceRate != 25 {
+		t.Errorf("Пропуски по классам: получено %d %s", resp.Code, resp.Body.String())
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// Ограничения числа записей журнала в одном ответе
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// auditContext возвращает контекст запроса, в котором хранилище записывает изменения
+// в журнал от имени текущего пользователя в той же транзакции, что и само изменение
+func auditContext(r *http.Request) context.Context {
+	userID, _ := userIDFromContext(r)
+	return store.WithAuditActor(r.Context(), userID, clientIP(r))
+}
+
+// auditFilterFromRequest разбирает параметры entity, entity_id, user_id, from, to и limit.
+// При ошибке ответ уже отправлен.
+func auditFilterFromRequest(w http.ResponseWriter, r *http.Request) (models.AuditFilter, bool) {
+	query := r.URL.Query()
+	f := models.AuditFilter{Entity: query.Get("entity"), Limit: defaultAuditLimit}
+	switch f.Entity {
+	case "", models.AuditGrade, models.AuditTermGrade, models.AuditStudent, models.AuditTeacher, models.AuditSubject:
+	default:
+		http.Error(w, "entity должен быть grade, term_grade, student, teacher или subject", http.StatusBadRequest)
+		return f, false
+	}
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{
+		{"entity_id", &f.EntityID},
+		{"user_id", &f.UserID},
+		{"limit", &f.Limit},
+	} {
+		if v := query.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				http.Error(w, "Некорректный "+p.name, http.StatusBadRequest)
+				return f, false
+			}
+			*p.value = n
+		}
+	}
+	if f.EntityID != 0 && f.Entity == "" {
+		http.Error(w, "Вместе с entity_id нужно указать entity", http.StatusBadRequest)
+		return f, false
+	}
+	if f.Limit > maxAuditLimit {
+		f.Limit = maxAuditLimit
+	}
+	for _, p := range []struct {
+		name  string
+		value *models.Date
+	}{
+		{"from", &f.From},
+		{"to", &f.To},
+	} {
+		if v := query.Get(p.name); v != "" {
+			day, err := models.ParseDate(v)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return f, false
+			}
+			*p.value = day
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From.Time) {
+		http.Error(w, "Дата to раньше даты from", http.StatusBadRequest)
+		return f, false
+	}
+	return f, true
+}
+
+// GetAuditLog возвращает записи журнала изменений, начиная с последних. Параметры entity
+// и entity_id выбирают историю одной записи, user_id — изменения пользователя,
+// from и to — даты изменений, limit — число записей (по умолчанию 100, не больше 1000).
+func (s *Server) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	filter, ok := auditFilterFromRequest(w, r)
+	if !ok {
+		return
+	}
+	entries, err := s.Audit.ListAudit(r.Context(), filter)
+	if err != nil {
+		log.Printf("Ошибка при получении журнала изменений: %v", err)
+		http.Error(w, "Ошибка при получении журнала изменений", http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func TestAuditLog(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	annaUser := createUser(t, st, "anna", "teacher")
+	deputy := createUser(t, st, "deputy", "deputy")
+
+	anna := &models.Teacher{FullName: "Иванова Анна", UserID: annaUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, anna))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	class := assignTeacher(t, st, anna.ID, math.ID)
+
+	// Завуч добавляет ученика и переименовывает его
+	var student models.Student
+	body, _ := json.Marshal(models.Student{FullName: "Иван Иванов", ClassID: class.ID})
+	resp := do(t, router, "POST", "/students", bytes.NewReader(body), deputy)
+	json.Unmarshal(resp.Body.Bytes(), &student)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Создание ученика: ожидался статус 201, получен %d %s", resp.Code, resp.Body.String())
+	}
+	body, _ = json.Marshal(models.Student{FullName: "Иван Петров", ClassID: class.ID})
+	if resp := do(t, router, "PUT", fmt.Sprintf("/students/%d", student.ID), bytes.NewReader(body), deputy); resp.Code != http.StatusOK {
+		t.Fatalf("Изменение ученика: ожидался статус 200, получен %d", resp.Code)
+	}
+
+	// Учитель выставляет и исправляет оценку, завуч ее удаляет
+	var grade models.Grade
+	body, _ = json.Marshal(models.Grade{StudentID: student.ID, SubjectID: math.ID, Grade: 5, Quarter: 1})
+	resp = do(t, router, "POST", "/grades", bytes.NewReader(body), annaUser)
+	json.Unmarshal(resp.Body.Bytes(), &grade)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Выставление оценки: ожидался статус 201, получен %d %s", resp.Code, resp.Body.String())
+	}
+	gradePath := fmt.Sprintf("/grades/%d", grade.ID)
+	grade.Grade = 4
+	body, _ = json.Marshal(grade)
+	if resp := do(t, router, "PUT", gradePath, bytes.NewReader(body), annaUser); resp.Code != http.StatusOK {
+		t.Fatalf("Исправление оценки: ожидался статус 200, получен %d %s", resp.Code, resp.Body.String())
+	}
+	if resp := do(t, router, "DELETE", gradePath, nil, deputy); resp.Code != http.StatusOK {
+		t.Fatalf("Удаление оценки: ожидался статус 200, получен %d", resp.Code)
+	}
+
+	history := func(query string) []models.AuditEntry {
+		t.Helper()
+		resp := do(t, router, "GET", "/audit"+query, nil, deputy)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("Журнал %q: ожидался статус 200, получен %d %s", query, resp.Code, resp.Body.String())
+		}
+		var entries []models.AuditEntry
+		json.Unmarshal(resp.Body.Bytes(), &entries)
+		return entries
+	}
+
+	entries := history(fmt.Sprintf("?entity=grade&entity_id=%d", grade.ID))
+	if len(entries) != 3 || entries[0].Action != models.AuditDelete || entries[1].Action != models.AuditUpdate ||
+		entries[2].Action != models.AuditCreate {
+		t.Fatalf("История оценки: ожидались удаление, изменение и создание, получено %+v", entries)
+	}
+	var before, after models.Grade
+	json.Unmarshal(entries[1].OldValue, &before)
+	json.Unmarshal(entries[1].NewValue, &after)
+	if before.Grade != 5 || after.Grade != 4 {
+		t.Errorf("Изменение оценки: ожидалось 5 -> 4, получено %d -> %d", before.Grade, after.Grade)
+	}
+	if entries[0].NewValue != nil || entries[0].OldValue == nil || entries[2].OldValue != nil {
+		t.Errorf("У удаления нет нового значения, у создания — прежнего, получено %+v", entries)
+	}
+	if e := entries[1]; e.UserID != annaUser.ID || e.Username != "anna" || e.IP != "192.0.2.1" {
+		t.Errorf("Автор изменения: ожидался anna с адреса 192.0.2.1, получено %+v", e)
+	}
+
+	if entries := history(fmt.Sprintf("?user_id=%d", deputy.ID)); len(entries) != 3 || entries[0].Entity != models.AuditGrade ||
+		entries[2].Entity != models.AuditStudent {
+		t.Errorf("Изменения завуча: ожидались удаление оценки и две записи об ученике, получено %+v", entries)
+	}
+	if entries := history("?limit=1"); len(entries) != 1 || entries[0].Action != models.AuditDelete {
+		t.Errorf("limit=1: ожидалась последняя запись, получено %+v", entries)
+	}
+	today := time.Now().Format(models.DateLayout)
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(models.DateLayout)
+	if entries := history("?from=" + today + "&to=" + today); len(entries) != 5 {
+		t.Errorf("Изменения за сегодня: ожидалось 5 записей, получено %d", len(entries))
+	}
+	if entries := history("?from=" + tomorrow); len(entries) != 0 {
+		t.Errorf("Изменения с завтрашнего дня: ожидалось 0 записей, получено %d", len(entries))
+	}
+
+	if resp := do(t, router, "GET", "/audit", nil, annaUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Журнал для учителя: ожидался статус 403, получен %d", resp.Code)
+	}
+	for _, query := range []string{"?entity=class", "?entity_id=1", "?from=2025-02-01&to=2025-01-01"} {
+		if resp := do(t, router, "GET", "/audit"+query, nil, deputy); resp.Code != http.StatusBadRequest {
+			t.Errorf("Журнал %q: ожидался статус 400, получен %d", query, resp.Code)
+		}
+	}
+}
+
+// В журнал попадает сохраненная запись, а не тело запроса; отклоненное изменение не записывается
+func TestAuditRecordsStoredChange(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	deputy := createUser(t, st, "deputy", "deputy")
+	annaUser := createUser(t, st, "anna", "teacher")
+
+	class := &models.Class{GradeLevel: 9, Letter: "А", AcademicYear: models.AcademicYearOf(time.Now())}
+	mustCreate(t, st.CreateClass(ctx, class))
+	student := &models.Student{FullName: "Иван Иванов"}
+	mustCreate(t, st.CreateStudent(ctx, student))
+	anna := &models.Teacher{FullName: "Иванова Анна", UserID: annaUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, anna))
+
+	body, _ := json.Marshal(models.Student{FullName: "Иван Петров", ClassID: class.ID})
+	if resp := do(t, router, "PUT", fmt.Sprintf("/students/%d", student.ID), bytes.NewReader(body), deputy); resp.Code != http.StatusOK {
+		t.Fatalf("Изменение ученика: ожидался статус 200, получен %d %s", resp.Code, resp.Body.String())
+	}
+	body, _ = json.Marshal(models.Teacher{FullName: "Петрова Анна", RoomNumber: "101"})
+	if resp := do(t, router, "PUT", fmt.Sprintf("/teachers/%d", anna.ID), bytes.NewReader(body), deputy); resp.Code != http.StatusOK {
+		t.Fatalf("Изменение учителя: ожидался статус 200, получен %d %s", resp.Code, resp.Body.String())
+	}
+	if resp := do(t, router, "DELETE", "/subjects/999", nil, deputy); resp.Code != http.StatusNotFound {
+		t.Errorf("Удаление несуществующего предмета: ожидался статус 404, получен %d", resp.Code)
+	}
+
+	var entries []models.AuditEntry
+	resp := do(t, router, "GET", "/audit", nil, deputy)
+	json.Unmarshal(resp.Body.Bytes(), &entries)
+	if resp.Code != http.StatusOK || len(entries) != 2 || entries[0].Entity != models.AuditTeacher || entries[1].Entity != models.AuditStudent {
+		t.Fatalf("Ожидались записи об учителе и ученике, получено %d %s", resp.Code, resp.Body.String())
+	}
+	var teacher models.Teacher
+	json.Unmarshal(entries[0].NewValue, &teacher)
+	if teacher.FullName != "Петрова Анна" || teacher.UserID != annaUser.ID {
+		t.Errorf("Учитель после изменения: ожидалась сохраненная запись с user_id, получено %+v", teacher)
+	}
+	var saved models.Student
+	json.Unmarshal(entries[1].NewValue, &saved)
+	if saved.FullName != "Иван Петров" || saved.ClassName != "9А" {
+		t.Errorf("Ученик после изменения: ожидалась сохраненная запись с классом 9А, получено %+v", saved)
+	}
+}
+
+func TestTermGradeAudit(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	deputy := createUser(t, st, "deputy", "deputy")
+
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	pupil := &models.Student{FullName: "Иван Иванов"}
+	mustCreate(t, st.CreateStudent(ctx, pupil))
+
+	physics := &models.Subject{Name: "Физика"}
+	mustCreate(t, st.CreateSubject(ctx, physics))
+
+	// История ведется по ID итоговой оценки: оценка по физике в нее не попадает
+	path := fmt.Sprintf("/students/%d/term-grades", pupil.ID)
+	setTermGrade := func(subjectID, grade int) models.TermGrade {
+		t.Helper()
+		var saved models.TermGrade
+		body, _ := json.Marshal(TermGradeRequest{SubjectID: subjectID, TermID: termID(t, st, 1), Grade: grade})
+		resp := do(t, router, "PUT", path, bytes.NewReader(body), deputy)
+		json.Unmarshal(resp.Body.Bytes(), &saved)
+		if resp.Code != http.StatusOK || saved.ID == 0 {
+			t.Fatalf("Итоговая оценка %d: ожидался статус 200 и ID, получено %d %s", grade, resp.Code, resp.Body.String())
+		}
+		return saved
+	}
+	first := setTermGrade(math.ID, 5)
+	if second := setTermGrade(math.ID, 4); second.ID != first.ID {
+		t.Errorf("Изменение итоговой оценки не должно менять ее ID: %d -> %d", first.ID, second.ID)
+	}
+	setTermGrade(physics.ID, 3)
+	if resp := do(t, router, "DELETE", fmt.Sprintf("%s/%d/%d", path, math.ID, termID(t, st, 1)), nil, deputy); resp.Code != http.StatusOK {
+		t.Fatalf("Отмена итоговой оценки: ожидался статус 200, получен %d", resp.Code)
+	}
+
+	var entries []models.AuditEntry
+	resp := do(t, router, "GET", fmt.Sprintf("/audit?entity=term_grade&entity_id=%d", first.ID), nil, deputy)
+	json.Unmarshal(resp.Body.Bytes(), &entries)
+	if resp.Code != http.StatusOK || len(entries) != 3 || entries[0].Action != models.AuditDelete ||
+		entries[1].Action != models.AuditUpdate || entries[2].Action != models.AuditCreate {
+		t.Fatalf("История итоговых оценок: ожидались удаление, изменение и создание, получено %d %s", resp.Code, resp.Body.String())
+	}
+	var before, after models.TermGrade
+	json.Unmarshal(entries[1].OldValue, &before)
+	json.Unmarshal(entries[1].NewValue, &after)
+	if before.Grade != 5 || after.Grade != 4 || after.SubjectID != math.ID {
+		t.Errorf("Изменение итоговой оценки: ожидалось 5 -> 4, получено %+v -> %+v", before, after)
+	}
+}
@@ -2,26 +2,19 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
+	"math"
 	"net/http"
-	"os"
+	"strconv"
 	"time"
 
-	"school-system/backend/database"
-
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"school-system/backend/middleware"
+	"school-system/backend/models"
 )
 
-// Получаем секрет JWT из окружения или используем фиксированный для тестирования
+// getJWTSecret возвращает секрет JWT, заданный в конфигурации при запуске
 func getJWTSecret() []byte {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Printf("ВНИМАНИЕ: Используется тестовый секретный ключ JWT")
-		jwtSecret = "test-secret-key-123" // Фиксированный ключ для тестирования
-	}
-	return []byte(jwtSecret)
+	return middleware.GetJWTSecret()
 }
 
 type Credentials struct {
@@ -29,7 +22,7 @@ type Credentials struct {
 	Password string `json:"password"`
 }
 
-func Login(w http.ResponseWriter, r *http.Request) {
+func (s *Server) Login(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Получен запрос на вход в систему")
 	var creds Credentials
 	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
@@ -38,27 +31,66 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := database.GetUserByUsername(creds.Username)
+	now := time.Now()
+	username := throttleUsername(creds.Username)
+	ip := clientIP(r)
+
+	wait, err := s.loginRetryAfter(r.Context(), now, username, ip)
 	if err != nil {
-		log.Printf("Пользователь не найден: %s", creds.Username)
-		http.Error(w, "Пользователь не найден", http.StatusUnauthorized)
+		log.Printf("Ошибка при проверке блокировки входа: %v", err)
+		http.Error(w, "Ошибка при входе в систему", http.StatusInternalServerError)
+		return
+	}
+	if wait > 0 {
+		log.Printf("Вход для %s с адреса %s временно заблокирован", creds.Username, ip)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, "Слишком много неудачных попыток входа. Повторите позже", http.StatusTooManyRequests)
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(creds.Password)); err != nil {
-		log.Printf("Неверный пароль для пользователя: %s", creds.Username)
-		http.Error(w, "Неверный пароль", http.StatusUnauthorized)
+	user, err := s.Users.GetUserByUsername(r.Context(), creds.Username)
+	if err != nil && storeErrorStatus(err) != http.StatusNotFound {
+		log.Printf("Ошибка при поиске пользователя %s: %v", creds.Username, err)
+		http.Error(w, "Ошибка при входе в систему", http.StatusInternalServerError)
 		return
 	}
 
-	// Генерация токена
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"role":    user.Role,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
-	})
+	if !checkPassword(user, creds.Password) {
+		log.Printf("Неудачная попытка входа: %s с адреса %s", creds.Username, ip)
+		s.recordLoginFailure(r.Context(), now, username, ip)
+		http.Error(w, errInvalidCredentials, http.StatusUnauthorized)
+		return
+	}
+
+	// Успешный вход сбрасывает счетчик логина; счетчик IP сбрасывается только по истечении окна
+	if err := s.LoginThrottles.ClearLoginThrottle(r.Context(), models.ThrottleScopeUser, username); err != nil && storeErrorStatus(err) != http.StatusNotFound {
+		log.Printf("Ошибка при сбросе счетчика неудачных попыток для %s: %v", username, err)
+	}
 
-	tokenString, err := token.SignedString(getJWTSecret())
+	// С включенной двухфакторной аутентификацией токены выдаются только после ввода кода
+	settings, err := s.TwoFactor.GetTOTP(r.Context(), user.ID)
+	if err != nil && storeErrorStatus(err) != http.StatusNotFound {
+		log.Printf("Ошибка при получении настроек 2FA пользователя %s: %v", creds.Username, err)
+		http.Error(w, "Ошибка при входе в систему", http.StatusInternalServerError)
+		return
+	}
+	if settings.Enabled() {
+		challenge, err := s.signChallengeToken(user, now)
+		if err != nil {
+			log.Printf("Ошибка при генерации токена подтверждения для %s: %v", creds.Username, err)
+			http.Error(w, "Ошибка генерации токена", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Пароль пользователя %s принят, ожидается код подтверждения", creds.Username)
+		writeJSON(w, http.StatusOK, challengeResponse{
+			MFARequired:    true,
+			ChallengeToken: challenge,
+			ExpiresIn:      int64(challengeTTL.Seconds()),
+		})
+		return
+	}
+
+	tokens, err := s.issueTokens(r.Context(), user, nil)
 	if err != nil {
 		log.Printf("Ошибка при генерации токена для пользователя %s: %v", creds.Username, err)
 		http.Error(w, "Ошибка генерации токена", http.StatusInternalServerError)
@@ -66,116 +98,182 @@ func Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("Успешный вход пользователя: %s (роль: %s)", creds.Username, user.Role)
-	json.NewEncoder(w).Encode(map[string]string{
-		"token": tokenString,
-	})
+	writeJSON(w, http.StatusOK, tokens)
 }
 
-type RegisterRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-	Role     string `json:"role"` // student / teacher / deputy
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
-func Register(w http.ResponseWriter, r *http.Request) {
-	log.Printf("Получен запрос на регистрацию нового пользователя")
-	var req RegisterRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		log.Printf("Ошибка при чтении данных регистрации: %v", err)
-		http.Error(w, "Некорректные данные", http.StatusBadRequest)
+// Refresh обменивает токен обновления на новую пару токенов.
+// Старый токен обновления отзывается; его повторное использование
+// считается признаком кражи и отзывает все сессии пользователя.
+func (s *Server) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
 		return
 	}
 
-	// Проверяем роль
-	if req.Role != "student" && req.Role != "teacher" && req.Role != "deputy" {
-		log.Printf("Попытка регистрации с недопустимой ролью: %s", req.Role)
-		http.Error(w, "Недопустимая роль. Допустимые значения: student, teacher, deputy", http.StatusBadRequest)
+	record, err := s.Tokens.GetRefreshToken(r.Context(), hashToken(req.RefreshToken))
+	if err != nil {
+		if storeErrorStatus(err) != http.StatusNotFound {
+			log.Printf("Ошибка при поиске токена обновления: %v", err)
+		}
+		http.Error(w, "Недействительный токен обновления", http.StatusUnauthorized)
 		return
 	}
 
-	// Проверим, есть ли уже пользователь с таким именем
-	existingUser, _ := database.GetUserByUsername(req.Username)
-	if existingUser != nil && existingUser.Username != "" {
-		log.Printf("Попытка регистрации существующего пользователя: %s", req.Username)
-		http.Error(w, "Пользователь уже существует", http.StatusConflict)
+	if record.RevokedAt != nil {
+		log.Printf("Повторное использование отозванного токена обновления пользователя %d, отзываем все сессии", record.UserID)
+		if err := s.Tokens.RevokeUserTokens(r.Context(), record.UserID); err != nil {
+			log.Printf("Ошибка при отзыве токенов пользователя %d: %v", record.UserID, err)
+		}
+		http.Error(w, "Недействительный токен обновления", http.StatusUnauthorized)
+		return
+	}
+	if time.Now().After(record.ExpiresAt) {
+		http.Error(w, "Токен обновления истек", http.StatusUnauthorized)
 		return
 	}
 
-	// Хешируем пароль
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	user, err := s.Users.GetUser(r.Context(), record.UserID)
 	if err != nil {
-		log.Printf("Ошибка при хешировании пароля для пользователя %s: %v", req.Username, err)
-		http.Error(w, "Ошибка при хешировании пароля", http.StatusInternalServerError)
+		log.Printf("Ошибка при получении пользователя %d: %v", record.UserID, err)
+		http.Error(w, "Недействительный токен обновления", http.StatusUnauthorized)
 		return
 	}
 
-	// Сохраняем пользователя в базу
-	err = database.CreateUser(req.Username, string(hashedPassword), req.Role)
+	tokens, err := s.issueTokens(r.Context(), user, record)
 	if err != nil {
-		log.Printf("Ошибка при создании пользователя %s: %v", req.Username, err)
-		http.Error(w, "Ошибка при создании пользователя", http.StatusInternalServerError)
+		if storeErrorStatus(err) == http.StatusConflict {
+			http.Error(w, "Недействительный токен обновления", http.StatusUnauthorized)
+			return
+		}
+		log.Printf("Ошибка при обновлении токенов пользователя %d: %v", user.ID, err)
+		http.Error(w, "Ошибка генерации токена", http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("Успешная регистрация нового пользователя: %s (роль: %s)", req.Username, req.Role)
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Пользователь зарегистрирован",
-	})
+	writeJSON(w, http.StatusOK, tokens)
 }
 
-func VerifyToken(w http.ResponseWriter, r *http.Request) {
-	log.Printf("Получен запрос на проверку токена")
+// Logout завершает текущую сессию: отзывает токен доступа и связанный токен обновления
+func (s *Server) Logout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	jti, _ := r.Context().Value(middleware.ContextTokenID).(string)
+	expiresAt, _ := r.Context().Value(middleware.ContextTokenExpiry).(time.Time)
 
-	// Получаем токен из заголовка Authorization
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		log.Printf("Отсутствует заголовок Authorization")
-		http.Error(w, "Требуется авторизация", http.StatusUnauthorized)
+	if err := s.Tokens.RevokeSession(r.Context(), userID, jti, expiresAt); err != nil {
+		log.Printf("Ошибка при выходе пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при выходе из системы", http.StatusInternalServerError)
 		return
 	}
 
-	// Убираем префикс "Bearer " из токена
-	tokenString := authHeader
-	if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
-		tokenString = tokenString[7:]
+	log.Printf("Пользователь %d вышел из системы", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll завершает все сессии пользователя на всех устройствах
+func (s *Server) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
 	}
+	jti, _ := r.Context().Value(middleware.ContextTokenID).(string)
+	expiresAt, _ := r.Context().Value(middleware.ContextTokenExpiry).(time.Time)
 
-	// Парсим и проверяем токен
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("неожиданный метод подписи: %v", token.Header["alg"])
-		}
-		return getJWTSecret(), nil
-	})
+	if err := s.Tokens.RevokeUserTokens(r.Context(), userID); err != nil {
+		log.Printf("Ошибка при отзыве сессий пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при выходе из системы", http.StatusInternalServerError)
+		return
+	}
+	// Текущий токен отзываем явно: он мог быть выпущен не через /login
+	if err := s.Tokens.RevokeSession(r.Context(), userID, jti, expiresAt); err != nil {
+		log.Printf("Ошибка при отзыве текущего токена пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при выходе из системы", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Пользователь %d вышел из системы на всех устройствах", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
 
+type RegisterRequest struct {
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	InviteCode string `json:"invite_code"`
+}
+
+// Register создает учетную запись по приглашению. Роль и привязка к карточке
+// ученика или учителя берутся из приглашения, а не из тела запроса.
+func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на регистрацию нового пользователя")
+	var req RegisterRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		log.Printf("Ошибка при проверке токена: %v", err)
-		http.Error(w, "Недействительный токен", http.StatusUnauthorized)
+		log.Printf("Ошибка при чтении данных регистрации: %v", err)
+		http.Error(w, "Некорректные данные", http.StatusBadRequest)
 		return
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		// Проверяем срок действия токена
-		if exp, ok := claims["exp"].(float64); ok {
-			if time.Now().Unix() > int64(exp) {
-				log.Printf("Токен истек")
-				http.Error(w, "Токен истек", http.StatusUnauthorized)
-				return
-			}
-		}
+	if req.InviteCode == "" {
+		http.Error(w, "Регистрация возможна только по приглашению", http.StatusForbidden)
+		return
+	}
+	if req.Username == "" {
+		http.Error(w, "Не указан логин", http.StatusBadRequest)
+		return
+	}
+	if err := validatePassword(req.Password); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-		// Токен действителен
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"valid":   true,
-			"user_id": claims["user_id"],
-			"role":    claims["role"],
-		})
+	// Хешируем пароль
+	hashedPassword, err := hashPassword(req.Password)
+	if err != nil {
+		log.Printf("Ошибка при хешировании пароля для пользователя %s: %v", req.Username, err)
+		http.Error(w, "Ошибка при хешировании пароля", http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("Недействительный токен")
-	http.Error(w, "Недействительный токен", http.StatusUnauthorized)
+	user := models.User{Username: req.Username, Password: hashedPassword}
+	inv, err := s.Invitations.RegisterWithInvitation(r.Context(), hashToken(req.InviteCode), &user, time.Now())
+	if err != nil {
+		switch storeErrorStatus(err) {
+		case http.StatusNotFound:
+			log.Printf("Попытка регистрации с недействительным приглашением: %s", req.Username)
+			http.Error(w, "Недействительное или истекшее приглашение", http.StatusForbidden)
+		case http.StatusConflict:
+			log.Printf("Конфликт при регистрации пользователя %s: %v", req.Username, err)
+			http.Error(w, "Пользователь уже существует", http.StatusConflict)
+		default:
+			log.Printf("Ошибка при создании пользователя %s: %v", req.Username, err)
+			http.Error(w, "Ошибка при создании пользователя", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("Успешная регистрация нового пользователя: %s (роль: %s, приглашение %d)", req.Username, user.Role, inv.ID)
+	writeJSON(w, http.StatusCreated, map[string]string{
+		"message": "Пользователь зарегистрирован",
+		"role":    user.Role,
+	})
+}
+
+// VerifyToken проверяет токен; подпись, срок действия и отзыв проверяет AuthMiddleware
+func (s *Server) VerifyToken(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на проверку токена")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"valid":   true,
+		"user_id": r.Context().Value(middleware.ContextUserID),
+		"role":    r.Context().Value(middleware.ContextRole),
+		"pending": r.Context().Value(middleware.ContextPending),
+	})
 }
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"school-system/backend/models"
+	"school-system/backend/store/memory"
+)
+
+// createUserWithPassword добавляет пользователя с настоящим хешем пароля
+func createUserWithPassword(t *testing.T, st *memory.Store, username, password, role string) *models.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	mustCreate(t, err)
+	user := &models.User{Username: username, Password: string(hash), Role: role}
+	mustCreate(t, st.CreateUser(context.Background(), user))
+	return user
+}
+
+// postJSON отправляет POST-запрос с JSON-телом и необязательным токеном доступа
+func postJSON(t *testing.T, h http.Handler, path string, body interface{}, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	return sendJSON(t, h, "POST", path, body, token)
+}
+
+func sendJSON(t *testing.T, h http.Handler, method, path string, body interface{}, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	return resp
+}
+
+func getWithToken(h http.Handler, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	return resp
+}
+
+func login(t *testing.T, h http.Handler, username, password string) tokenResponse {
+	t.Helper()
+	resp := postJSON(t, h, "/login", Credentials{Username: username, Password: password}, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Вход %s: ожидался статус 200, получен %d: %s", username, resp.Code, resp.Body.String())
+	}
+	var tokens tokenResponse
+	json.Unmarshal(resp.Body.Bytes(), &tokens)
+	return tokens
+}
+
+func TestRefreshRotatesAndDetectsReuse(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	createUserWithPassword(t, st, "teacher", "secret", "teacher")
+
+	first := login(t, router, "teacher", "secret")
+	if first.Token == "" || first.RefreshToken == "" {
+		t.Fatalf("Ожидались оба токена: %+v", first)
+	}
+
+	resp := postJSON(t, router, "/refresh", RefreshRequest{RefreshToken: first.RefreshToken}, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Обновление: ожидался статус 200, получен %d", resp.Code)
+	}
+	var second tokenResponse
+	json.Unmarshal(resp.Body.Bytes(), &second)
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("Токен обновления должен меняться при каждом обновлении")
+	}
+
+	// Повторное использование старого токена отзывает все сессии
+	if resp := postJSON(t, router, "/refresh", RefreshRequest{RefreshToken: first.RefreshToken}, ""); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Повторное использование: ожидался статус 401, получен %d", resp.Code)
+	}
+	if resp := postJSON(t, router, "/refresh", RefreshRequest{RefreshToken: second.RefreshToken}, ""); resp.Code != http.StatusUnauthorized {
+		t.Errorf("После кражи новый токен тоже должен быть отозван, получен %d", resp.Code)
+	}
+	if resp := getWithToken(router, "/verify-token", second.Token); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Токен доступа после отзыва сессий: ожидался статус 401, получен %d", resp.Code)
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	createUserWithPassword(t, st, "deputy", "secret", "deputy")
+
+	laptop := login(t, router, "deputy", "secret")
+	phone := login(t, router, "deputy", "secret")
+
+	if resp := postJSON(t, router, "/logout", nil, laptop.Token); resp.Code != http.StatusNoContent {
+		t.Fatalf("Выход: ожидался статус 204, получен %d", resp.Code)
+	}
+	if resp := getWithToken(router, "/verify-token", laptop.Token); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Токен после выхода: ожидался статус 401, получен %d", resp.Code)
+	}
+	if resp := getWithToken(router, "/verify-token", phone.Token); resp.Code != http.StatusOK {
+		t.Errorf("Другая сессия должна остаться активной, получен %d", resp.Code)
+	}
+
+	if resp := postJSON(t, router, "/logout-all", nil, phone.Token); resp.Code != http.StatusNoContent {
+		t.Fatalf("Выход везде: ожидался статус 204, получен %d", resp.Code)
+	}
+	if resp := getWithToken(router, "/verify-token", phone.Token); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Токен после выхода везде: ожидался статус 401, получен %d", resp.Code)
+	}
+	if resp := postJSON(t, router, "/refresh", RefreshRequest{RefreshToken: laptop.RefreshToken}, ""); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Токен обновления после выхода: ожидался статус 401, получен %d", resp.Code)
+	}
+}
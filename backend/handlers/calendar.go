@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"school-system/backend/ical"
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// calendarFeedResponse — выпущенный токен подписки; токен показывается только один раз
+type calendarFeedResponse struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateCalendarFeed выпускает токен подписки на календарь текущего пользователя.
+// Прежний токен перестает действовать.
+func (s *Server) CreateCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	token, err := randomToken(32)
+	if err != nil {
+		log.Printf("Ошибка при генерации токена календаря: %v", err)
+		http.Error(w, "Ошибка при создании подписки на календарь", http.StatusInternalServerError)
+		return
+	}
+	feed := &models.CalendarFeed{UserID: userID, TokenHash: hashToken(token)}
+	if err := s.CalendarFeeds.SetCalendarFeed(r.Context(), feed); err != nil {
+		log.Printf("Ошибка при сохранении подписки на календарь пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при создании подписки на календарь", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Пользователь %d выпустил токен подписки на календарь", userID)
+	writeJSON(w, http.StatusCreated, calendarFeedResponse{
+		Token:     token,
+		URL:       "/calendar/" + token + ".ics",
+		CreatedAt: feed.CreatedAt,
+	})
+}
+
+// DeleteCalendarFeed отзывает токен подписки на календарь текущего пользователя
+func (s *Server) DeleteCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	if err := s.CalendarFeeds.DeleteCalendarFeed(r.Context(), userID); err != nil {
+		if storeErrorStatus(err) == http.StatusNotFound {
+			http.Error(w, "Подписка на календарь не найдена", http.StatusNotFound)
+			return
+		}
+		log.Printf("Ошибка при отзыве подписки на календарь пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при отзыве подписки на календарь", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Пользователь %d отозвал подписку на календарь", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetCalendar отдает календарь уроков владельца токена в формате iCalendar.
+// Токен в пути заменяет JWT: календарные приложения не умеют передавать заголовки.
+func (s *Server) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	feed, err := s.CalendarFeeds.GetCalendarFeed(r.Context(), hashToken(mux.Vars(r)["token"]))
+	if err != nil {
+		if storeErrorStatus(err) == http.StatusNotFound {
+			http.Error(w, "Подписка на календарь не найдена", http.StatusNotFound)
+			return
+		}
+		log.Printf("Ошибка при получении подписки на календарь: %v", err)
+		http.Error(w, "Ошибка при получении календаря", http.StatusInternalServerError)
+		return
+	}
+
+	lessons, err := s.calendarLessons(r.Context(), feed.UserID)
+	if err != nil {
+		log.Printf("Ошибка при получении уроков для календаря пользователя %d: %v", feed.UserID, err)
+		http.Error(w, "Ошибка при получении календаря", http.StatusInternalServerError)
+		return
+	}
+	events, err := s.lessonEvents(r.Context(), lessons)
+	if err != nil {
+		log.Printf("Ошибка при формировании календаря пользователя %d: %v", feed.UserID, err)
+		http.Error(w, "Ошибка при получении календаря", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="schedule.ics"`)
+	if err := ical.Write(w, ical.Calendar{Name: "Расписание уроков", Events: events}, time.Now()); err != nil {
+		log.Printf("Ошибка при отправке календаря пользователя %d: %v", feed.UserID, err)
+	}
+}
+
+// calendarLessons возвращает уроки пользователя: уроки учителя, уроки класса ученика
+// и уроки классов детей родителя. Учетная запись может быть привязана к нескольким из них.
+func (s *Server) calendarLessons(ctx context.Context, userID int) ([]models.Lesson, error) {
+	var filters []models.LessonFilter
+	teacher, err := s.Teachers.GetTeacherByUserID(ctx, userID)
+	switch {
+	case err == nil:
+		filters = append(filters, models.LessonFilter{TeacherID: teacher.ID})
+	case !errors.Is(err, store.ErrNotFound):
+		return nil, err
+	}
+	student, err := s.Students.GetStudentByUserID(ctx, userID)
+	switch {
+	case err == nil && student.ClassID != 0:
+		filters = append(filters, models.LessonFilter{ClassID: student.ClassID})
+	case err != nil && !errors.Is(err, store.ErrNotFound):
+		return nil, err
+	}
+	children, err := s.Guardians.ListChildren(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range children {
+		if c.ClassID != 0 {
+			filters = append(filters, models.LessonFilter{ClassID: c.ClassID})
+		}
+	}
+
+	seen := make(map[int]bool)
+	var lessons []models.Lesson
+	for _, f := range filters {
+		found, err := s.Timetable.ListLessons(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range found {
+			if !seen[l.ID] {
+				seen[l.ID] = true
+				lessons = append(lessons, l)
+			}
+		}
+	}
+	return lessons, nil
+}
+
+// lessonEvents превращает уроки в еженедельные события от первого до последнего
+// учебного дня года урока. Недели вне периодов (каникулы) исключаются через EXDATE.
+func (s *Server) lessonEvents(ctx context.Context, lessons []models.Lesson) ([]ical.Event, error) {
+	bells, err := s.Timetable.ListBellPeriods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bellByNumber := make(map[int]models.BellPeriod, len(bells))
+	for _, b := range bells {
+		bellByNumber[b.Number] = b
+	}
+	terms := make(map[int][]models.Term)
+
+	var events []ical.Event
+	for _, l := range lessons {
+		yearTerms, ok := terms[l.AcademicYear]
+		if !ok {
+			year, err := s.AcademicYears.GetAcademicYear(ctx, l.AcademicYear)
+			if err != nil && !errors.Is(err, store.ErrNotFound) {
+				return nil, err
+			}
+			if year != nil {
+				yearTerms = year.Terms
+				sort.Slice(yearTerms, func(i, j int) bool { return yearTerms[i].StartsOn.Before(yearTerms[j].StartsOn.Time) })
+			}
+			terms[l.AcademicYear] = yearTerms
+		}
+		bell, ok := bellByNumber[l.Period]
+		if !ok || len(yearTerms) == 0 {
+			continue
+		}
+		startsAt, err1 := time.Parse(models.ClockLayout, bell.StartsAt)
+		endsAt, err2 := time.Parse(models.ClockLayout, bell.EndsAt)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("некорректное время урока %d: %s-%s", bell.Number, bell.StartsAt, bell.EndsAt)
+		}
+
+		first := yearTerms[0].StartsOn
+		last := yearTerms[len(yearTerms)-1].EndsOn
+		day := first.AddDate(0, 0, (l.Weekday-models.ISOWeekday(first.Time)+7)%7)
+		var occurrences, skipped []time.Time
+		for ; !day.After(last.Time); day = day.AddDate(0, 0, 7) {
+			if inTerms(yearTerms, models.NewDate(day)) {
+				occurrences = append(occurrences, day)
+			} else {
+				skipped = append(skipped, day)
+			}
+		}
+		if len(occurrences) == 0 {
+			continue
+		}
+		firstDay, lastDay := occurrences[0], occurrences[len(occurrences)-1]
+		at := func(day, clock time.Time) time.Time {
+			return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+		}
+		event := ical.Event{
+			UID:         fmt.Sprintf("lesson-%d@school-system", l.ID),
+			Summary:     fmt.Sprintf("%s, %s", l.SubjectName, l.ClassName),
+			Location:    l.Room,
+			Description: fmt.Sprintf("Учитель: %s\nКласс: %s\nУрок: %d", l.TeacherName, l.ClassName, l.Period),
+			Start:       at(firstDay, startsAt),
+			End:         at(firstDay, endsAt),
+		}
+		if len(occurrences) > 1 {
+			event.Until = lastDay
+		}
+		for _, day := range skipped {
+			if day.After(firstDay) && day.Before(lastDay) {
+				event.Except = append(event.Except, at(day, startsAt))
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// inTerms сообщает, попадает ли дата в один из периодов
+func inTerms(terms []models.Term, day models.Date) bool {
+	for _, t := range terms {
+		if t.Contains(day) {
+			return true
+		}
+	}
+	return false
+}
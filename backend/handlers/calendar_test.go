@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	startYear := models.AcademicYearOf(time.Now())
+	annaUser := createUser(t, st, "anna", "teacher")
+	pupilUser := createUser(t, st, "pupil", "student")
+
+	// Осенние каникулы: первая четверть заканчивается на неделю раньше второй
+	year, _ := st.GetAcademicYear(ctx, startYear)
+	first := year.Terms[0]
+	first.EndsOn = models.NewDate(first.EndsOn.AddDate(0, 0, -7))
+	mustCreate(t, st.UpdateTerm(ctx, &first))
+
+	anna := &models.Teacher{FullName: "Иванова Анна", RoomNumber: "12", UserID: annaUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, anna))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	class := assignTeacher(t, st, anna.ID, math.ID)
+	mustCreate(t, st.CreateStudent(ctx, &models.Student{FullName: "Иван Иванов", ClassID: class.ID, UserID: pupilUser.ID}))
+	lesson := &models.Lesson{ClassID: class.ID, SubjectID: math.ID, TeacherID: anna.ID, Room: "12", Weekday: 1, Period: 1}
+	mustCreate(t, st.CreateLesson(ctx, lesson))
+
+	issue := func(user *models.User) string {
+		t.Helper()
+		resp := do(t, router, "POST", "/me/calendar-feed", nil, user)
+		var feed calendarFeedResponse
+		json.Unmarshal(resp.Body.Bytes(), &feed)
+		if resp.Code != http.StatusCreated || feed.URL != "/calendar/"+feed.Token+".ics" {
+			t.Fatalf("Выпуск токена календаря: ожидался статус 201, получено %d %s", resp.Code, resp.Body.String())
+		}
+		return feed.URL
+	}
+	if resp := do(t, router, "POST", "/me/calendar-feed", nil, nil); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Выпуск токена без авторизации: ожидался статус 401, получен %d", resp.Code)
+	}
+
+	monday := first.StartsOn.AddDate(0, 0, (8-models.ISOWeekday(first.StartsOn.Time))%7)
+	holiday := first.EndsOn.AddDate(0, 0, 8-models.ISOWeekday(first.EndsOn.Time))
+	annaURL := issue(annaUser)
+	resp := do(t, router, "GET", annaURL, nil, nil)
+	body := resp.Body.String()
+	if resp.Code != http.StatusOK || !strings.HasPrefix(resp.Header().Get("Content-Type"), "text/calendar") {
+		t.Fatalf("Календарь учителя: ожидался статус 200 и text/calendar, получено %d %s", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{
+		fmt.Sprintf("UID:lesson-%d@school-system", lesson.ID),
+		"DTSTART:" + monday.Format("20060102") + "T083000",
+		"RRULE:FREQ=WEEKLY;UNTIL=",
+		"EXDATE:" + holiday.Format("20060102") + "T083000",
+		`SUMMARY:Математика\, 9А`,
+		"LOCATION:12",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("В календаре учителя нет %q:\n%s", want, body)
+		}
+	}
+
+	pupilURL := issue(pupilUser)
+	if resp := do(t, router, "GET", pupilURL, nil, nil); !strings.Contains(resp.Body.String(), fmt.Sprintf("UID:lesson-%d@", lesson.ID)) {
+		t.Errorf("В календаре ученика должен быть урок класса:\n%s", resp.Body.String())
+	}
+
+	// Новый токен отзывает прежний, удаление отзывает действующий
+	annaNext := issue(annaUser)
+	if resp := do(t, router, "GET", annaURL, nil, nil); resp.Code != http.StatusNotFound {
+		t.Errorf("Прежний токен после перевыпуска: ожидался статус 404, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "DELETE", "/me/calendar-feed", nil, annaUser); resp.Code != http.StatusNoContent {
+		t.Fatalf("Отзыв подписки: ожидался статус 204, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "GET", annaNext, nil, nil); resp.Code != http.StatusNotFound {
+		t.Errorf("Отозванный токен: ожидался статус 404, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "DELETE", "/me/calendar-feed", nil, annaUser); resp.Code != http.StatusNotFound {
+		t.Errorf("Повторный отзыв: ожидался статус 404, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "GET", pupilURL, nil, nil); resp.Code != http.StatusOK {
+		t.Errorf("Токен ученика не должен зависеть от отзыва токена учителя, получен %d", resp.Code)
+	}
+}
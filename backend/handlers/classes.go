@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"school-system/backend/models"
+)
+
+// errClassNotFound — класс, указанный у ученика, не найден
+var errClassNotFound = errors.New("Класс не найден")
+
+// classFromRequest проверяет и нормализует данные класса из тела запроса
+func classFromRequest(class *models.Class) error {
+	if class.GradeLevel < 1 || class.GradeLevel > 11 {
+		return errors.New("Параллель должна быть от 1 до 11")
+	}
+	letter := models.NormalizeClassLetter(class.Letter)
+	if _, _, err := models.ParseClassName(models.ClassName(class.GradeLevel, letter)); err != nil {
+		return errors.New("Литера класса должна быть одной буквой, например А")
+	}
+	class.Letter = letter
+	if class.AcademicYear == 0 {
+		class.AcademicYear = models.AcademicYearOf(time.Now())
+	}
+	if class.AcademicYear < 2000 || class.AcademicYear > 2100 {
+		return errors.New("Некорректный учебный год")
+	}
+	return nil
+}
+
+// resolveStudentClass определяет class_id ученика. Если class_id не указан, класс ищется
+// по class_name в текущем учебном году; "9A" латиницей и "9а" дают класс "9А".
+func (s *Server) resolveStudentClass(ctx context.Context, student *models.Student) error {
+	if student.ClassID != 0 || student.ClassName == "" {
+		return nil
+	}
+	level, letter, err := models.ParseClassName(student.ClassName)
+	if err != nil {
+		return errClassNotFound
+	}
+	class, err := s.Classes.FindClass(ctx, level, letter, models.AcademicYearOf(time.Now()))
+	if err != nil {
+		if storeErrorStatus(err) == http.StatusNotFound {
+			return errClassNotFound
+		}
+		return err
+	}
+	student.ClassID = class.ID
+	return nil
+}
+
+// GetClasses возвращает классы; параметр academic_year отбирает классы одного учебного года
+func (s *Server) GetClasses(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на получение списка классов")
+	year := 0
+	if v := r.URL.Query().Get("academic_year"); v != "" {
+		var err error
+		if year, err = strconv.Atoi(v); err != nil || year < 1 {
+			http.Error(w, "Некорректный учебный год", http.StatusBadRequest)
+			return
+		}
+	}
+
+	classes, err := s.Classes.ListClasses(r.Context(), year)
+	if err != nil {
+		log.Printf("Ошибка при получении классов: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if classes == nil {
+		classes = []models.Class{}
+	}
+	writeJSON(w, http.StatusOK, classes)
+}
+
+func (s *Server) GetClass(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	class, err := s.Classes.GetClass(r.Context(), id)
+	if err != nil {
+		log.Printf("Ошибка при получении класса %d: %v", id, err)
+		http.Error(w, "Класс не найден", storeErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, class)
+}
+
+// GetClassStudents возвращает учеников класса
+func (s *Server) GetClassStudents(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := s.Classes.GetClass(r.Context(), id); err != nil {
+		http.Error(w, "Класс не найден", storeErrorStatus(err))
+		return
+	}
+	students, err := s.Students.GetStudentsByClass(r.Context(), id)
+	if err != nil {
+		log.Printf("Ошибка при получении учеников класса %d: %v", id, err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if students == nil {
+		students = []models.Student{}
+	}
+	writeJSON(w, http.StatusOK, students)
+}
+
+func (s *Server) CreateClass(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на создание класса")
+	var class models.Class
+	if err := json.NewDecoder(r.Body).Decode(&class); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if err := classFromRequest(&class); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Classes.CreateClass(r.Context(), &class); err != nil {
+		log.Printf("Ошибка при создании класса %s: %v", models.ClassName(class.GradeLevel, class.Letter), err)
+		s.writeClassError(w, err)
+		return
+	}
+
+	log.Printf("Создан класс %s (%d/%d)", class.Name, class.AcademicYear, class.AcademicYear+1)
+	writeJSON(w, http.StatusCreated, class)
+}
+
+func (s *Server) UpdateClass(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на обновление класса с ID: %d", id)
+
+	var class models.Class
+	if err := json.NewDecoder(r.Body).Decode(&class); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	class.ID = id
+	if err := classFromRequest(&class); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Classes.UpdateClass(r.Context(), &class); err != nil {
+		log.Printf("Ошибка при обновлении класса %d: %v", id, err)
+		s.writeClassError(w, err)
+		return
+	}
+
+	log.Printf("Успешно обновлен класс с ID: %d", id)
+	writeJSON(w, http.StatusOK, class)
+}
+
+func (s *Server) DeleteClass(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на удаление класса с ID: %d", id)
+
+	if err := s.Classes.DeleteClass(r.Context(), id); err != nil {
+		log.Printf("Ошибка при удалении класса %d: %v", id, err)
+		switch storeErrorStatus(err) {
+		case http.StatusNotFound:
+			http.Error(w, "Класс не найден", http.StatusNotFound)
+		case http.StatusBadRequest:
+			http.Error(w, "В классе есть ученики", http.StatusBadRequest)
+		default:
+			http.Error(w, "Ошибка при удалении класса", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("Успешно удален класс с ID: %d", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeClassError отправляет ответ на ошибку сохранения класса
+func (s *Server) writeClassError(w http.ResponseWriter, err error) {
+	switch storeErrorStatus(err) {
+	case http.StatusNotFound:
+		http.Error(w, "Класс не найден", http.StatusNotFound)
+	case http.StatusConflict:
+		http.Error(w, "Такой класс в этом учебном году уже есть", http.StatusConflict)
+	case http.StatusBadRequest:
+		http.Error(w, "Классный руководитель не найден", http.StatusBadRequest)
+	default:
+		http.Error(w, "Ошибка при сохранении класса", http.StatusInternalServerError)
+	}
+}
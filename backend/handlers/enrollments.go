@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// EnrollClassRequest — зачисление всего класса на предмет
+type EnrollClassRequest struct {
+	SubjectID int `json:"subject_id"`
+	// TeacherID — учитель группы; 0 — учитель предмета
+	TeacherID int `json:"teacher_id"`
+	// TermID — период зачисления; 0 — весь учебный год класса
+	TermID    int    `json:"term_id"`
+	GroupName string `json:"group_name"`
+}
+
+// validateEnrollment проверяет зачисление из тела запроса; без периода и учебного года
+// ученик зачисляется на текущий учебный год
+func validateEnrollment(e *models.Enrollment) error {
+	if e.StudentID < 1 || e.SubjectID < 1 {
+		return errors.New("Нужно указать ученика и предмет")
+	}
+	if e.TeacherID < 0 || e.TermID < 0 {
+		return errors.New("Некорректный учитель или период")
+	}
+	if utf8.RuneCountInString(e.GroupName) > 50 {
+		return errors.New("Название группы должно быть не длиннее 50 символов")
+	}
+	if e.TermID == 0 && e.AcademicYear == 0 {
+		e.AcademicYear = models.AcademicYearOf(time.Now())
+	}
+	if e.TermID == 0 && (e.AcademicYear < 2000 || e.AcademicYear > 2100) {
+		return errors.New("Некорректный учебный год")
+	}
+	return nil
+}
+
+// enrollmentFilterFromRequest разбирает параметры отбора зачислений.
+// При ошибке ответ уже отправлен.
+func enrollmentFilterFromRequest(w http.ResponseWriter, r *http.Request) (models.EnrollmentFilter, bool) {
+	var f models.EnrollmentFilter
+	query := r.URL.Query()
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{
+		{"student_id", &f.StudentID},
+		{"subject_id", &f.SubjectID},
+		{"teacher_id", &f.TeacherID},
+		{"class_id", &f.ClassID},
+		{"academic_year", &f.AcademicYear},
+		{"term_id", &f.TermID},
+	} {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "Некорректный "+p.name, http.StatusBadRequest)
+			return f, false
+		}
+		*p.value = n
+	}
+	return f, true
+}
+
+// writeEnrollmentError отправляет ответ на ошибку сохранения зачисления
+func writeEnrollmentError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrArchived) {
+		http.Error(w, "Учебный год в архиве", http.StatusConflict)
+		return
+	}
+	switch storeErrorStatus(err) {
+	case http.StatusNotFound:
+		http.Error(w, "Зачисление не найдено", http.StatusNotFound)
+	case http.StatusConflict:
+		http.Error(w, "Ученик уже зачислен на этот предмет в этом периоде", http.StatusConflict)
+	case http.StatusBadRequest:
+		http.Error(w, "Ученик, предмет, учитель или период не найден", http.StatusBadRequest)
+	default:
+		http.Error(w, "Ошибка при сохранении зачисления", http.StatusInternalServerError)
+	}
+}
+
+// GetEnrollments возвращает зачисления. Параметры student_id, subject_id, teacher_id,
+// class_id, academic_year и term_id сужают выборку; по term_id возвращаются и
+// зачисления на весь учебный год этого периода.
+func (s *Server) GetEnrollments(w http.ResponseWriter, r *http.Request) {
+	filter, ok := enrollmentFilterFromRequest(w, r)
+	if !ok {
+		return
+	}
+	enrollments, err := s.Enrollments.ListEnrollments(r.Context(), filter)
+	if err != nil {
+		log.Printf("Ошибка при получении зачислений: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if enrollments == nil {
+		enrollments = []models.Enrollment{}
+	}
+	writeJSON(w, http.StatusOK, enrollments)
+}
+
+func (s *Server) CreateEnrollment(w http.ResponseWriter, r *http.Request) {
+	var enrollment models.Enrollment
+	if err := json.NewDecoder(r.Body).Decode(&enrollment); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if err := validateEnrollment(&enrollment); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Enrollments.CreateEnrollment(r.Context(), &enrollment); err != nil {
+		log.Printf("Ошибка при зачислении ученика %d на предмет %d: %v", enrollment.StudentID, enrollment.SubjectID, err)
+		writeEnrollmentError(w, err)
+		return
+	}
+
+	log.Printf("Ученик %d зачислен на предмет %d к учителю %d", enrollment.StudentID, enrollment.SubjectID, enrollment.TeacherID)
+	writeJSON(w, http.StatusCreated, enrollment)
+}
+
+// UpdateEnrollment меняет учителя и группу зачисления
+func (s *Server) UpdateEnrollment(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var enrollment models.Enrollment
+	if err := json.NewDecoder(r.Body).Decode(&enrollment); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	enrollment.ID = id
+	if enrollment.TeacherID < 0 || utf8.RuneCountInString(enrollment.GroupName) > 50 {
+		http.Error(w, "Некорректный учитель или название группы", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Enrollments.UpdateEnrollment(r.Context(), &enrollment); err != nil {
+		log.Printf("Ошибка при обновлении зачисления %d: %v", id, err)
+		writeEnrollmentError(w, err)
+		return
+	}
+
+	log.Printf("Успешно обновлено зачисление с ID: %d", id)
+	writeJSON(w, http.StatusOK, enrollment)
+}
+
+func (s *Server) DeleteEnrollment(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.Enrollments.DeleteEnrollment(r.Context(), id); err != nil {
+		log.Printf("Ошибка при удалении зачисления %d: %v", id, err)
+		writeEnrollmentError(w, err)
+		return
+	}
+
+	log.Printf("Успешно удалено зачисление с ID: %d", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EnrollClass зачисляет всех учеников класса на предмет в учебном году класса.
+// Уже зачисленные ученики пропускаются; в ответе — число новых зачислений.
+func (s *Server) EnrollClass(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req EnrollClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if req.SubjectID < 1 || req.TeacherID < 0 || req.TermID < 0 {
+		http.Error(w, "Нужно указать предмет", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(req.GroupName) > 50 {
+		http.Error(w, "Название группы должно быть не длиннее 50 символов", http.StatusBadRequest)
+		return
+	}
+
+	count, err := s.Enrollments.EnrollClass(r.Context(), id, models.Enrollment{
+		SubjectID: req.SubjectID,
+		TeacherID: req.TeacherID,
+		TermID:    req.TermID,
+		GroupName: req.GroupName,
+	})
+	if err != nil {
+		log.Printf("Ошибка при зачислении класса %d на предмет %d: %v", id, req.SubjectID, err)
+		if storeErrorStatus(err) == http.StatusNotFound {
+			http.Error(w, "Класс не найден", http.StatusNotFound)
+			return
+		}
+		if storeErrorStatus(err) == http.StatusBadRequest {
+			http.Error(w, "Предмет, учитель или период не найден в учебном году класса", http.StatusBadRequest)
+			return
+		}
+		writeEnrollmentError(w, err)
+		return
+	}
+
+	log.Printf("Класс %d зачислен на предмет %d: новых зачислений %d", id, req.SubjectID, count)
+	writeJSON(w, http.StatusOK, map[string]int{"enrolled": count})
+}
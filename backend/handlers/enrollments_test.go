@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func TestEnrollmentDrivesTeacherStudents(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	deputy := createUser(t, st, "deputy", "deputy")
+	firstUser := createUser(t, st, "first", "teacher")
+	secondUser := createUser(t, st, "second", "teacher")
+
+	first := &models.Teacher{FullName: "Иванова Анна", UserID: firstUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, first))
+	second := &models.Teacher{FullName: "Петров Олег", UserID: secondUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, second))
+	english := &models.Subject{Name: "Английский язык"}
+	mustCreate(t, st.CreateSubject(ctx, english))
+	class := &models.Class{GradeLevel: 9, Letter: "А", AcademicYear: models.AcademicYearOf(time.Now())}
+	mustCreate(t, st.CreateClass(ctx, class))
+	mustCreate(t, st.CreateTeachingAssignment(ctx, &models.TeachingAssignment{TeacherID: first.ID, SubjectID: english.ID, ClassID: class.ID}))
+	olga := &models.Student{FullName: "Ольга Смирнова", ClassID: class.ID}
+	for _, student := range []*models.Student{{FullName: "Иван Иванов", ClassID: class.ID}, olga} {
+		mustCreate(t, st.CreateStudent(ctx, student))
+	}
+
+	myStudents := func(user *models.User) []models.Student {
+		t.Helper()
+		var students []models.Student
+		resp := do(t, router, "GET", "/teacher/my-students", nil, user)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("Ученики учителя: ожидался статус 200, получен %d", resp.Code)
+		}
+		json.Unmarshal(resp.Body.Bytes(), &students)
+		return students
+	}
+	if students := myStudents(secondUser); len(students) != 0 {
+		t.Fatalf("Учитель без назначения и зачислений не должен видеть учеников, получено %+v", students)
+	}
+
+	// Зачисление всего класса к назначенному учителю: ученики без оценок видны ему
+	body, _ := json.Marshal(EnrollClassRequest{SubjectID: english.ID})
+	path := fmt.Sprintf("/classes/%d/enroll", class.ID)
+	if resp := do(t, router, "POST", path, bytes.NewReader(body), firstUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Учитель без enrollments:manage: ожидался статус 403, получен %d", resp.Code)
+	}
+	var enrolled map[string]int
+	resp := do(t, router, "POST", path, bytes.NewReader(body), deputy)
+	json.Unmarshal(resp.Body.Bytes(), &enrolled)
+	if resp.Code != http.StatusOK || enrolled["enrolled"] != 2 {
+		t.Fatalf("Ожидалось зачисление 2 учеников, получено %d %s", resp.Code, resp.Body.String())
+	}
+	resp = do(t, router, "POST", path, bytes.NewReader(body), deputy)
+	json.Unmarshal(resp.Body.Bytes(), &enrolled)
+	if enrolled["enrolled"] != 0 {
+		t.Errorf("Повторное зачисление класса не должно создавать записей, получено %d", enrolled["enrolled"])
+	}
+	if students := myStudents(firstUser); len(students) != 2 {
+		t.Fatalf("Учитель должен видеть 2 учеников без оценок, получено %+v", students)
+	}
+
+	body, _ = json.Marshal(models.Enrollment{StudentID: olga.ID, SubjectID: english.ID})
+	if resp := do(t, router, "POST", "/enrollments", bytes.NewReader(body), deputy); resp.Code != http.StatusConflict {
+		t.Errorf("Повторное зачисление: ожидался статус 409, получен %d", resp.Code)
+	}
+
+	// Ольга переходит во вторую группу к другому учителю
+	var enrollments []models.Enrollment
+	resp = do(t, router, "GET", fmt.Sprintf("/enrollments?student_id=%d", olga.ID), nil, deputy)
+	json.Unmarshal(resp.Body.Bytes(), &enrollments)
+	if resp.Code != http.StatusOK || len(enrollments) != 1 || enrollments[0].TeacherID != first.ID {
+		t.Fatalf("Ожидалось одно зачисление Ольги к назначенному учителю, получено %d %s", resp.Code, resp.Body.String())
+	}
+	body, _ = json.Marshal(models.Enrollment{TeacherID: second.ID, GroupName: "2 группа"})
+	resp = do(t, router, "PUT", fmt.Sprintf("/enrollments/%d", enrollments[0].ID), bytes.NewReader(body), deputy)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Смена группы: ожидался статус 200, получен %d %s", resp.Code, resp.Body.String())
+	}
+	if students := myStudents(firstUser); len(students) != 1 || students[0].ID == olga.ID {
+		t.Errorf("Первый учитель должен видеть одного ученика без Ольги, получено %+v", students)
+	}
+	if students := myStudents(secondUser); len(students) != 1 || students[0].ID != olga.ID {
+		t.Errorf("Второй учитель должен видеть только Ольгу, получено %+v", students)
+	}
+
+	// Учитель группы выставляет оценки по предмету и видит их в журнале
+	body, _ = json.Marshal(models.Grade{StudentID: olga.ID, SubjectID: english.ID, Grade: 5, Quarter: 1})
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), secondUser); resp.Code != http.StatusCreated {
+		t.Fatalf("Учитель группы: ожидался статус 201, получен %d", resp.Code)
+	}
+	// Назначенный учитель класса не ставит оценки ученице чужой группы
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), firstUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Ученица другой группы: ожидался статус 403, получен %d", resp.Code)
+	}
+	var journal []struct {
+		StudentID int            `json:"student_id"`
+		Grades    []models.Grade `json:"grades"`
+	}
+	resp = do(t, router, "GET", "/teacher/my-students/grades", nil, secondUser)
+	json.Unmarshal(resp.Body.Bytes(), &journal)
+	if len(journal) != 1 || journal[0].StudentID != olga.ID || len(journal[0].Grades) != 1 {
+		t.Errorf("Второй учитель должен видеть оценку Ольги, получено %s", resp.Body.String())
+	}
+	resp = do(t, router, "GET", "/teacher/my-students/grades", nil, firstUser)
+	json.Unmarshal(resp.Body.Bytes(), &journal)
+	if len(journal) != 1 || journal[0].StudentID == olga.ID || journal[0].Grades == nil || len(journal[0].Grades) != 0 {
+		t.Errorf("Первый учитель должен видеть ученика без оценок, получено %s", resp.Body.String())
+	}
+
+	if resp := do(t, router, "DELETE", fmt.Sprintf("/enrollments/%d", enrollments[0].ID), nil, deputy); resp.Code != http.StatusNoContent {
+		t.Errorf("Удаление зачисления: ожидался статус 204, получен %d", resp.Code)
+	}
+	if students := myStudents(secondUser); len(students) != 0 {
+		t.Errorf("После отчисления второй учитель не должен видеть учеников, получено %+v", students)
+	}
+}
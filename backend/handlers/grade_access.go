@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"school-system/backend/middleware"
+	"school-system/backend/models"
+)
+
+// gradeAccess описывает, к каким оценкам у текущего пользователя есть доступ
+type gradeAccess struct {
+	// all — право grades:all: все предметы без проверки преподавателя
+	all bool
+	// assignments — назначения учителя: предмет в классе на весь учебный год или на период
+	assignments []models.TeachingAssignment
+	// teacherID — карточка учителя текущего пользователя
+	teacherID int
+	// enrollments — зачисления учеников к учителю: группы и индивидуальные занятия
+	enrollments []models.Enrollment
+	// classEnrollments — зачисления учеников классов учителя по его предметам, в том числе к другим учителям
+	classEnrollments []models.Enrollment
+	// classOf — класс каждого ученика из классов, где учитель ведет предметы
+	classOf map[int]int
+	// termYears — учебный год (год начала) каждого периода
+	termYears map[int]int
+	// studentID — собственная карточка ученика
+	studentID int
+	// children — ученики, к которым привязан родитель
+	children map[int]bool
+}
+
+// isTeacher сообщает, ведет ли пользователь хотя бы один предмет
+func (a *gradeAccess) isTeacher() bool {
+	return a.all || len(a.assignments) > 0 || len(a.enrollments) > 0
+}
+
+// coversTerm сообщает, действует ли запись на период termID или на весь учебный год year в период termID.
+// termID 0 — любой период учебного года записи.
+func (a *gradeAccess) coversTerm(recordTermID, recordYear, termID int) bool {
+	return termID == 0 || recordTermID == termID || (recordTermID == 0 && recordYear == a.termYears[termID])
+}
+
+// teachesClass сообщает, ведет ли пользователь предмет в классе в период termID (0 — в любой период)
+func (a *gradeAccess) teachesClass(subjectID, classID, termID int) bool {
+	if a.all {
+		return true
+	}
+	for _, asg := range a.assignments {
+		if asg.SubjectID == subjectID && asg.ClassID == classID && a.coversTerm(asg.TermID, asg.AcademicYear, termID) {
+			return true
+		}
+	}
+	return false
+}
+
+// teaches сообщает, ведет ли пользователь предмет у ученика в период termID: ученик зачислен
+// к пользователю на этот предмет и период или учится в классе, где у пользователя есть
+// назначение на этот предмет и период, и не зачислен на предмет к другому учителю.
+// Назначение на период (замена) действует и для учеников, зачисленных к другому учителю на весь год.
+func (a *gradeAccess) teaches(studentID, subjectID, termID int) bool {
+	if a.all {
+		return true
+	}
+	for _, e := range a.enrollments {
+		if e.StudentID == studentID && e.SubjectID == subjectID && a.coversTerm(e.TermID, e.AcademicYear, termID) {
+			return true
+		}
+	}
+	classID := a.classOf[studentID]
+	if classID == 0 {
+		return false
+	}
+	for _, asg := range a.assignments {
+		if asg.SubjectID == subjectID && asg.ClassID == classID && a.coversTerm(asg.TermID, asg.AcademicYear, termID) &&
+			!a.enrolledElsewhere(studentID, subjectID, termID, asg.TermID != 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// enrolledElsewhere сообщает, зачислен ли ученик на предмет к другому учителю в период termID;
+// при termOnly (назначение на период, например замена) учитываются только зачисления на отдельный период
+func (a *gradeAccess) enrolledElsewhere(studentID, subjectID, termID int, termOnly bool) bool {
+	for _, e := range a.classEnrollments {
+		if e.StudentID == studentID && e.SubjectID == subjectID && e.TeacherID != a.teacherID &&
+			(!termOnly || e.TermID != 0) && a.coversTerm(e.TermID, e.AcademicYear, termID) {
+			return true
+		}
+	}
+	return false
+}
+
+// teachesInYear сообщает, ведет ли пользователь предмет у ученика хотя бы в одном периоде учебного года.
+// Ученик, зачисленный к другому учителю на весь год, в классе пользователя учитывается
+// только по назначению на период (замене).
+func (a *gradeAccess) teachesInYear(studentID, subjectID, year int) bool {
+	if a.all {
+		return true
+	}
+	for _, e := range a.enrollments {
+		if e.StudentID == studentID && e.SubjectID == subjectID && e.AcademicYear == year {
+			return true
+		}
+	}
+	for _, asg := range a.assignments {
+		if asg.SubjectID == subjectID && asg.ClassID == a.classOf[studentID] && asg.AcademicYear == year &&
+			(asg.TermID != 0 || !a.enrolledForYearElsewhere(studentID, subjectID, year)) {
+			return true
+		}
+	}
+	return false
+}
+
+// enrolledForYearElsewhere сообщает, зачислен ли ученик на предмет к другому учителю на весь учебный год
+func (a *gradeAccess) enrolledForYearElsewhere(studentID, subjectID, year int) bool {
+	for _, e := range a.classEnrollments {
+		if e.StudentID == studentID && e.SubjectID == subjectID && e.TeacherID != a.teacherID &&
+			e.TermID == 0 && e.AcademicYear == year {
+			return true
+		}
+	}
+	return false
+}
+
+// canRead сообщает, может ли пользователь видеть оценку
+func (a *gradeAccess) canRead(g models.Grade) bool {
+	return a.teaches(g.StudentID, g.SubjectID, g.TermID) || a.ownsStudent(g.StudentID)
+}
+
+// ownsStudent сообщает, видит ли пользователь все оценки ученика: свои или своего ребенка
+func (a *gradeAccess) ownsStudent(studentID int) bool {
+	return a.all || (a.studentID != 0 && studentID == a.studentID) || a.children[studentID]
+}
+
+// filter оставляет только доступные пользователю оценки
+func (a *gradeAccess) filter(grades []models.Grade) []models.Grade {
+	if a.all {
+		return grades
+	}
+	result := []models.Grade{}
+	for _, g := range grades {
+		if a.canRead(g) {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// gradeAccessFor определяет доступ текущего пользователя к оценкам: по праву grades:all,
+// по назначениям учителя (предмет, класс и период) и зачислениям к нему, по собственной карточке ученика
+// и по детям родителя.
+// При ошибке ответ уже отправлен.
+func (s *Server) gradeAccessFor(w http.ResponseWriter, r *http.Request) (*gradeAccess, bool) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return nil, false
+	}
+	role, _ := r.Context().Value(middleware.ContextRole).(string)
+
+	all, err := s.Permissions.RoleHasPermission(r.Context(), role, models.PermGradesAll)
+	if err != nil {
+		log.Printf("Ошибка при проверке права %s для роли %s: %v", models.PermGradesAll, role, err)
+		http.Error(w, "Ошибка при проверке прав", http.StatusInternalServerError)
+		return nil, false
+	}
+	access := &gradeAccess{all: all, classOf: map[int]int{}, termYears: map[int]int{}, children: map[int]bool{}}
+	if all {
+		return access, true
+	}
+
+	teacher, err := s.Teachers.GetTeacherByUserID(r.Context(), userID)
+	switch {
+	case err == nil:
+		if err := s.loadTeacherAccess(r.Context(), access, teacher.ID); err != nil {
+			log.Printf("Ошибка при получении назначений учителя %d: %v", teacher.ID, err)
+			http.Error(w, "Ошибка при проверке прав", http.StatusInternalServerError)
+			return nil, false
+		}
+	case storeErrorStatus(err) != http.StatusNotFound:
+		log.Printf("Ошибка при получении учителя для user_id=%d: %v", userID, err)
+		http.Error(w, "Ошибка при проверке прав", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	student, err := s.Students.GetStudentByUserID(r.Context(), userID)
+	switch {
+	case err == nil:
+		access.studentID = student.ID
+	case storeErrorStatus(err) != http.StatusNotFound:
+		log.Printf("Ошибка при получении ученика для user_id=%d: %v", userID, err)
+		http.Error(w, "Ошибка при проверке прав", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	children, err := s.Guardians.ListChildren(r.Context(), userID)
+	if err != nil {
+		log.Printf("Ошибка при получении детей пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при проверке прав", http.StatusInternalServerError)
+		return nil, false
+	}
+	for _, child := range children {
+		access.children[child.ID] = true
+	}
+	return access, true
+}
+
+// loadTeacherAccess загружает назначения и зачисления учителя, учеников его классов с их зачислениями
+// по предметам учителя и учебные годы периодов
+func (s *Server) loadTeacherAccess(ctx context.Context, access *gradeAccess, teacherID int) error {
+	assignments, err := s.Assignments.ListTeachingAssignments(ctx, models.TeachingAssignmentFilter{TeacherID: teacherID})
+	if err != nil {
+		return err
+	}
+	access.teacherID = teacherID
+	access.assignments = assignments
+	access.enrollments, err = s.Enrollments.ListEnrollments(ctx, models.EnrollmentFilter{TeacherID: teacherID})
+	if err != nil {
+		return err
+	}
+	loaded := make(map[int]bool)
+	loadedSubjects := make(map[[2]int]bool)
+	for _, asg := range assignments {
+		if key := [2]int{asg.ClassID, asg.SubjectID}; !loadedSubjects[key] {
+			loadedSubjects[key] = true
+			enrollments, err := s.Enrollments.ListEnrollments(ctx, models.EnrollmentFilter{ClassID: asg.ClassID, SubjectID: asg.SubjectID})
+			if err != nil {
+				return err
+			}
+			access.classEnrollments = append(access.classEnrollments, enrollments...)
+		}
+		if loaded[asg.ClassID] {
+			continue
+		}
+		loaded[asg.ClassID] = true
+		students, err := s.Students.GetStudentsByClass(ctx, asg.ClassID)
+		if err != nil {
+			return err
+		}
+		for _, student := range students {
+			access.classOf[student.ID] = asg.ClassID
+		}
+	}
+	years, err := s.AcademicYears.ListAcademicYears(ctx)
+	if err != nil {
+		return err
+	}
+	for _, year := range years {
+		for _, term := range year.Terms {
+			access.termYears[term.ID] = year.StartYear
+		}
+	}
+	return nil
+}
+
+// checkGradeWrite проверяет, что ученик существует, а пользователь ведет предмет у ученика
+// в период оценки g.TermID: ученик зачислен к нему или учится в его классе и не зачислен
+// к другому учителю. При отказе ответ уже отправлен.
+func (s *Server) checkGradeWrite(w http.ResponseWriter, r *http.Request, access *gradeAccess, g *models.Grade) bool {
+	if _, err := s.Students.GetStudent(r.Context(), g.StudentID); err != nil {
+		if storeErrorStatus(err) == http.StatusNotFound {
+			http.Error(w, "Ученик не найден", http.StatusBadRequest)
+		} else {
+			log.Printf("Ошибка при получении ученика %d: %v", g.StudentID, err)
+			http.Error(w, "Ошибка при проверке прав", http.StatusInternalServerError)
+		}
+		return false
+	}
+	if !access.teaches(g.StudentID, g.SubjectID, g.TermID) {
+		log.Printf("Отказано в доступе к оценкам ученика %d по предмету %d", g.StudentID, g.SubjectID)
+		http.Error(w, "Вы не ведете этот предмет у ученика", http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -2,41 +2,101 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
-	"school-system/backend/database"
-	"school-system/backend/models"
+	"unicode/utf8"
 
-	"github.com/gorilla/mux"
+	"school-system/backend/models"
 )
 
-func GetGrades(w http.ResponseWriter, r *http.Request) {
+// maxCommentLength — наибольшая длина комментария к оценке
+const maxCommentLength = 1000
+
+// validateGrade проверяет значения оценки перед записью; пустой вид заменяется на classwork
+func validateGrade(g *models.Grade) error {
+	if g.StudentID < 1 || g.SubjectID < 1 {
+		return fmt.Errorf("не указан ученик или предмет")
+	}
+	if g.Grade < 0 {
+		return fmt.Errorf("оценка не может быть отрицательной")
+	}
+	if g.Quarter < 0 || g.Quarter > 4 {
+		return fmt.Errorf("четверть должна быть от 1 до 4")
+	}
+	if g.Kind == "" {
+		g.Kind = models.MarkKindClasswork
+	}
+	if !models.ValidMarkKind(g.Kind) {
+		return fmt.Errorf("вид отметки должен быть classwork, homework, test или exam")
+	}
+	if g.Weight < 0 || g.Weight > 10 {
+		return fmt.Errorf("вес отметки должен быть от 0 до 10")
+	}
+	if utf8.RuneCountInString(g.Comment) > maxCommentLength {
+		return fmt.Errorf("комментарий длиннее %d символов", maxCommentLength)
+	}
+	return nil
+}
+
+// checkGradeScale проверяет, что оценка входит в шкалу предмета. При ошибке ответ уже отправлен.
+func (s *Server) checkGradeScale(w http.ResponseWriter, r *http.Request, subjectID, value int) bool {
+	scale, err := s.subjectScale(r.Context(), subjectID)
+	if err != nil {
+		if storeErrorStatus(err) == http.StatusNotFound {
+			http.Error(w, "Предмет не найден", http.StatusBadRequest)
+			return false
+		}
+		log.Printf("Ошибка при получении шкалы предмета %d: %v", subjectID, err)
+		http.Error(w, "Ошибка при получении шкалы оценивания", http.StatusInternalServerError)
+		return false
+	}
+	if !scale.Contains(value) {
+		http.Error(w, fmt.Sprintf("Оценка должна быть от %d до %d", scale.MinValue, scale.MaxValue), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// setGradeTerm определяет период оценки. При ошибке ответ уже отправлен.
+func (s *Server) setGradeTerm(w http.ResponseWriter, r *http.Request, g *models.Grade) bool {
+	if err := s.resolveGradeTerm(r.Context(), g); err != nil {
+		if errors.Is(err, errTermNotFound) || errors.Is(err, errDateOutsideTerm) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+		log.Printf("Ошибка при определении учебного периода: %v", err)
+		http.Error(w, "Ошибка при определении учебного периода", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// GetGrades возвращает оценки, доступные текущему пользователю: учителю — по его предметам в его классах,
+// ученику — собственные, с правом grades:all — все
+func (s *Server) GetGrades(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Получен запрос на получение списка оценок")
-	row, err := database.DB.Query(`SELECT id, student_id, subject_id, grade, quarter FROM grades `)
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return
+	}
+	grades, err := s.Grades.ListGrades(r.Context())
 	if err != nil {
 		log.Printf("Ошибка при получении данных оценок: %v", err)
 		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
 		return
 	}
-	defer row.Close()
-
-	var grades []models.Grade
-	for row.Next() {
-		var g models.Grade
-		err := row.Scan(&g.ID, &g.StudentID, &g.SubjectID, &g.Grade, &g.Quarter)
-		if err != nil {
-			log.Printf("Ошибка при сканировании данных оценки: %v", err)
-			http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
-			return
-		}
-		grades = append(grades, g)
+	grades = access.filter(grades)
+	if grades == nil {
+		grades = []models.Grade{}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(grades)
+	writeJSON(w, http.StatusOK, grades)
 }
 
-func CreateGrade(w http.ResponseWriter, r *http.Request) {
+// CreateGrade выставляет оценку; без права grades:all — только по своему предмету у ученика
+func (s *Server) CreateGrade(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Получен запрос на создание новой оценки")
 	var grade models.Grade
 	if err := json.NewDecoder(r.Body).Decode(&grade); err != nil {
@@ -44,33 +104,72 @@ func CreateGrade(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
 		return
 	}
+	// Отметка за домашнее задание выставляется только оценкой работы ученика
+	grade.HomeworkID = 0
+	if err := validateGrade(&grade); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.setGradeTerm(w, r, &grade) {
+		return
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok || !s.checkGradeWrite(w, r, access, &grade) || !s.checkGradeScale(w, r, grade.SubjectID, grade.Grade) {
+		return
+	}
 
-	_, err := database.DB.Exec(`INSERT INTO grades (student_id, subject_id, grade, quarter) VALUES ($1, $2, $3, $4)`,
-		grade.StudentID, grade.SubjectID, grade.Grade, grade.Quarter)
-	if err != nil {
+	if err := s.Grades.CreateGrade(auditContext(r), &grade); err != nil {
 		log.Printf("Ошибка при добавлении оценки в базу данных: %v", err)
-		http.Error(w, "Ошибка при добавлении оценки", http.StatusInternalServerError)
+		http.Error(w, "Ошибка при добавлении оценки", storeErrorStatus(err))
 		return
 	}
 
 	log.Printf("Успешно создана новая оценка для студента %d по предмету %d", grade.StudentID, grade.SubjectID)
-	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, http.StatusCreated, grade)
 }
 
-func UpdateGrade(w http.ResponseWriter, r *http.Request) {
-	log.Printf("Получен запрос на обновление оценки")
+// UpdateGrade изменяет оценку; без права grades:all учитель должен вести
+// предмет у ученика и до, и после изменения оценки
+func (s *Server) UpdateGrade(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на обновление оценки с ID: %d", id)
+
 	var g models.Grade
 	if err := json.NewDecoder(r.Body).Decode(&g); err != nil {
 		log.Printf("Ошибка при чтении данных оценки: %v", err)
 		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
 		return
 	}
-
-	_, err := database.DB.Exec(`UPDATE grades SET student_id=$1, subject_id=$2, grade=$3, quarter=$4 WHERE id=$5`,
-		g.StudentID, g.SubjectID, g.Grade, g.Quarter, g.ID)
+	g.ID = id
+	if err := validateGrade(&g); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.setGradeTerm(w, r, &g) {
+		return
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return
+	}
+	existing, err := s.Grades.GetGrade(r.Context(), id)
 	if err != nil {
+		log.Printf("Ошибка при получении оценки с ID %d: %v", id, err)
+		http.Error(w, "Оценка не найдена", storeErrorStatus(err))
+		return
+	}
+	if !s.checkGradeWrite(w, r, access, existing) || !s.checkGradeWrite(w, r, access, &g) ||
+		!s.checkGradeScale(w, r, g.SubjectID, g.Grade) {
+		return
+	}
+
+	if err := s.Grades.UpdateGrade(auditContext(r), &g); err != nil {
 		log.Printf("Ошибка при обновлении оценки с ID %d: %v", g.ID, err)
-		http.Error(w, "Ошибка при обновлении", http.StatusInternalServerError)
+		http.Error(w, "Ошибка при обновлении", storeErrorStatus(err))
 		return
 	}
 
@@ -78,70 +177,85 @@ func UpdateGrade(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func DeleteGrade(w http.ResponseWriter, r *http.Request) {
-	id := mux.Vars(r)["id"]
-	log.Printf("Получен запрос на удаление оценки с ID: %s", id)
+// DeleteGrade удаляет оценку; без права grades:all — только по своему предмету у ученика
+func (s *Server) DeleteGrade(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на удаление оценки с ID: %d", id)
 
 	// Получаем информацию об оценке перед удалением
-	var grade models.Grade
-	err := database.DB.Get(&grade, `SELECT * FROM grades WHERE id=$1`, id)
+	grade, err := s.Grades.GetGrade(r.Context(), id)
 	if err != nil {
-		log.Printf("Ошибка при получении информации об оценке с ID %s: %v", id, err)
-		http.Error(w, "Ошибка при удалении", http.StatusInternalServerError)
+		log.Printf("Ошибка при получении информации об оценке с ID %d: %v", id, err)
+		http.Error(w, "Ошибка при удалении", storeErrorStatus(err))
+		return
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return
+	}
+	if !access.teaches(grade.StudentID, grade.SubjectID, grade.TermID) {
+		http.Error(w, "Вы не ведете этот предмет у ученика", http.StatusForbidden)
 		return
 	}
 
-	// Удаляем оценку
-	_, err = database.DB.Exec(`DELETE FROM grades WHERE id=$1`, id)
-	if err != nil {
-		log.Printf("Ошибка при удалении оценки с ID %s: %v", id, err)
-		http.Error(w, "Ошибка при удалении", http.StatusInternalServerError)
+	if err := s.Grades.DeleteGrade(auditContext(r), id); err != nil {
+		log.Printf("Ошибка при удалении оценки с ID %d: %v", id, err)
+		http.Error(w, "Ошибка при удалении", storeErrorStatus(err))
 		return
 	}
 
-	log.Printf("Успешно удалена оценка: ID=%s, Студент=%d, Предмет=%d, Оценка=%d, Четверть=%d",
+	log.Printf("Успешно удалена оценка: ID=%d, Студент=%d, Предмет=%d, Оценка=%d, Четверть=%d",
 		id, grade.StudentID, grade.SubjectID, grade.Grade, grade.Quarter)
 	w.WriteHeader(http.StatusOK)
 }
 
-func GetStudentGrades(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	studentID := vars["id"]
-	log.Printf("Получен запрос на получение оценок студента с ID: %s", studentID)
-
-	query := `
-		SELECT g.*, s.name as subject_name
-		FROM grades g
-		JOIN subjects s ON g.subject_id = s.id
-		WHERE g.student_id = $1
-		ORDER BY s.name, g.quarter
-	`
-
-	rows, err := database.DB.Queryx(query, studentID)
+// GetStudentGrades возвращает оценки ученика за учебный год (параметры academic_year и term).
+// Ученик видит только свои оценки, родитель — оценки своих детей, учитель — оценки по своим предметам.
+func (s *Server) GetStudentGrades(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathID(r)
 	if err != nil {
-		log.Printf("Ошибка при получении оценок студента %s: %v", studentID, err)
-		http.Error(w, "Ошибка при получении оценок", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на получение оценок студента с ID: %d", studentID)
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return
+	}
+	own := access.ownsStudent(studentID)
+	if !own && !access.isTeacher() {
+		http.Error(w, "Нет доступа к оценкам этого ученика", http.StatusForbidden)
 		return
 	}
-	defer rows.Close()
 
-	type GradeWithSubject struct {
-		models.Grade
-		SubjectName string `db:"subject_name" json:"subject_name"`
+	period, ok := periodFromRequest(w, r)
+	if !ok {
+		return
 	}
 
-	var grades []GradeWithSubject
-	for rows.Next() {
-		var grade GradeWithSubject
-		if err := rows.StructScan(&grade); err != nil {
-			log.Printf("Ошибка при обработке данных оценки: %v", err)
-			http.Error(w, "Ошибка при обработке данных", http.StatusInternalServerError)
-			return
+	grades, err := s.Grades.GetStudentGrades(r.Context(), studentID, period)
+	if err != nil {
+		log.Printf("Ошибка при получении оценок студента %d: %v", studentID, err)
+		http.Error(w, "Ошибка при получении оценок", http.StatusInternalServerError)
+		return
+	}
+	if !own {
+		visible := []models.GradeWithSubject{}
+		for _, g := range grades {
+			if access.teaches(studentID, g.SubjectID, g.TermID) {
+				visible = append(visible, g)
+			}
 		}
-		grades = append(grades, grade)
+		grades = visible
+	}
+	if grades == nil {
+		grades = []models.GradeWithSubject{}
 	}
 
-	log.Printf("Успешно получено %d оценок для студента с ID: %s", len(grades), studentID)
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(grades)
+	log.Printf("Успешно получено %d оценок для студента с ID: %d", len(grades), studentID)
+	writeJSON(w, http.StatusOK, grades)
 }
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func TestGradesRequireRole(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+
+	student := &models.Student{FullName: "Иван Иванов", ClassName: "9А"}
+	mustCreate(t, st.CreateStudent(ctx, student))
+	subject := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, subject))
+
+	deputy := createUser(t, st, "deputy", "deputy")
+	pupil := createUser(t, st, "pupil", "student")
+
+	body, _ := json.Marshal(models.Grade{StudentID: student.ID, SubjectID: subject.ID, Grade: 5, Quarter: 1})
+
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), nil); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Без токена: ожидался статус 401, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), pupil); resp.Code != http.StatusForbidden {
+		t.Errorf("Ученик: ожидался статус 403, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), deputy); resp.Code != http.StatusCreated {
+		t.Errorf("Завуч: ожидался статус 201, получен %d", resp.Code)
+	}
+
+	resp := do(t, router, "GET", "/stats/average-grade", nil, deputy)
+	var avg map[string]float64
+	json.Unmarshal(resp.Body.Bytes(), &avg)
+	if avg["average"] != 5 {
+		t.Errorf("Ожидался средний балл 5, получено %v", avg["average"])
+	}
+}
+
+func TestCreateGradeValidation(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	deputy := createUser(t, st, "deputy", "deputy")
+
+	body, _ := json.Marshal(models.Grade{StudentID: 1, SubjectID: 1, Grade: 7, Quarter: 1})
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Оценка вне диапазона: ожидался статус 400, получен %d", resp.Code)
+	}
+
+	body, _ = json.Marshal(models.Grade{StudentID: 99, SubjectID: 99, Grade: 4, Quarter: 1})
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Несуществующий ученик: ожидался статус 400, получен %d", resp.Code)
+	}
+}
+
+func TestTeacherGradesOnlyOwnSubjects(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+
+	mathUser := createUser(t, st, "math", "teacher")
+	physicsUser := createUser(t, st, "physics", "teacher")
+	pupilUser := createUser(t, st, "pupil", "student")
+	otherUser := createUser(t, st, "other", "student")
+
+	mathTeacher := &models.Teacher{FullName: "Иванова Анна", UserID: mathUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, mathTeacher))
+	physicsTeacher := &models.Teacher{FullName: "Петров Олег", UserID: physicsUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, physicsTeacher))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	physics := &models.Subject{Name: "Физика"}
+	mustCreate(t, st.CreateSubject(ctx, physics))
+	class := assignTeacher(t, st, mathTeacher.ID, math.ID)
+	assignTeacher(t, st, physicsTeacher.ID, physics.ID)
+	pupil := &models.Student{FullName: "Иван Иванов", ClassID: class.ID, UserID: pupilUser.ID}
+	mustCreate(t, st.CreateStudent(ctx, pupil))
+	mustCreate(t, st.CreateStudent(ctx, &models.Student{FullName: "Сергей Сергеев", ClassID: class.ID, UserID: otherUser.ID}))
+
+	body, _ := json.Marshal(models.Grade{StudentID: pupil.ID, SubjectID: physics.ID, Grade: 3, Quarter: 1})
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), mathUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Чужой предмет: ожидался статус 403, получен %d", resp.Code)
+	}
+	body, _ = json.Marshal(models.Grade{StudentID: pupil.ID, SubjectID: math.ID, Grade: 5, Quarter: 1})
+	resp := do(t, router, "POST", "/grades", bytes.NewReader(body), mathUser)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Свой предмет: ожидался статус 201, получен %d", resp.Code)
+	}
+	var grade models.Grade
+	json.Unmarshal(resp.Body.Bytes(), &grade)
+	physicsGrade := &models.Grade{StudentID: pupil.ID, SubjectID: physics.ID, Grade: 4, TermID: termID(t, st, 1)}
+	mustCreate(t, st.CreateGrade(ctx, physicsGrade))
+
+	// Перенести оценку на чужой предмет нельзя
+	body, _ = json.Marshal(models.Grade{StudentID: pupil.ID, SubjectID: physics.ID, Grade: 5, Quarter: 1})
+	if resp := do(t, router, "PUT", fmt.Sprintf("/grades/%d", grade.ID), bytes.NewReader(body), mathUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Перенос на чужой предмет: ожидался статус 403, получен %d", resp.Code)
+	}
+	body, _ = json.Marshal(models.Grade{StudentID: 999, SubjectID: math.ID, Grade: 5, Quarter: 1})
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), mathUser); resp.Code != http.StatusBadRequest {
+		t.Errorf("Несуществующий ученик: ожидался статус 400, получен %d", resp.Code)
+	}
+
+	// Учитель видит только оценки по своему предмету
+	var grades []models.GradeWithSubject
+	resp = do(t, router, "GET", fmt.Sprintf("/grades/student/%d", pupil.ID), nil, mathUser)
+	json.Unmarshal(resp.Body.Bytes(), &grades)
+	if resp.Code != http.StatusOK || len(grades) != 1 || grades[0].SubjectID != math.ID {
+		t.Errorf("Учитель: ожидалась одна оценка по математике, получено %d %+v", resp.Code, grades)
+	}
+
+	// Ученик видит все свои оценки, но не чужие
+	resp = do(t, router, "GET", fmt.Sprintf("/grades/student/%d", pupil.ID), nil, pupilUser)
+	json.Unmarshal(resp.Body.Bytes(), &grades)
+	if resp.Code != http.StatusOK || len(grades) != 2 {
+		t.Errorf("Ученик: ожидалось 2 оценки, получено %d %+v", resp.Code, grades)
+	}
+	if resp := do(t, router, "GET", fmt.Sprintf("/grades/student/%d", pupil.ID), nil, otherUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Чужие оценки: ожидался статус 403, получен %d", resp.Code)
+	}
+}
+
+func TestGradeAccessScopedByClass(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+
+	firstUser := createUser(t, st, "first", "teacher")
+	secondUser := createUser(t, st, "second", "teacher")
+	first := &models.Teacher{FullName: "Иванова Анна", UserID: firstUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, first))
+	second := &models.Teacher{FullName: "Петров Олег", UserID: secondUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, second))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	assignTeacher(t, st, first.ID, math.ID)
+	other := &models.Class{GradeLevel: 9, Letter: "Б", AcademicYear: models.AcademicYearOf(time.Now())}
+	mustCreate(t, st.CreateClass(ctx, other))
+	mustCreate(t, st.CreateTeachingAssignment(ctx, &models.TeachingAssignment{TeacherID: second.ID, SubjectID: math.ID, ClassID: other.ID}))
+	pupil := &models.Student{FullName: "Иван Иванов", ClassID: other.ID}
+	mustCreate(t, st.CreateStudent(ctx, pupil))
+	grade := &models.Grade{StudentID: pupil.ID, SubjectID: math.ID, Grade: 4, TermID: termID(t, st, 1)}
+	mustCreate(t, st.CreateGrade(ctx, grade))
+
+	// Тот же предмет, но в другом классе: учитель 9А не ведет его у ученика 9Б
+	body, _ := json.Marshal(models.Grade{StudentID: pupil.ID, SubjectID: math.ID, Grade: 2, Quarter: 1})
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), firstUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Другой класс: ожидался статус 403, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "PUT", fmt.Sprintf("/grades/%d", grade.ID), bytes.NewReader(body), firstUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Изменение оценки другого класса: ожидался статус 403, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "DELETE", fmt.Sprintf("/grades/%d", grade.ID), nil, firstUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Удаление оценки другого класса: ожидался статус 403, получен %d", resp.Code)
+	}
+	body, _ = json.Marshal(TermGradeRequest{SubjectID: math.ID, TermID: termID(t, st, 1), Grade: 2})
+	if resp := do(t, router, "PUT", fmt.Sprintf("/students/%d/term-grades", pupil.ID), bytes.NewReader(body), firstUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Итоговая оценка другого класса: ожидался статус 403, получен %d", resp.Code)
+	}
+	var grades []models.GradeWithSubject
+	resp := do(t, router, "GET", fmt.Sprintf("/grades/student/%d", pupil.ID), nil, firstUser)
+	json.Unmarshal(resp.Body.Bytes(), &grades)
+	if resp.Code != http.StatusOK || len(grades) != 0 {
+		t.Errorf("Учитель другого класса не должен видеть оценки, получено %d %+v", resp.Code, grades)
+	}
+
+	// Учитель класса ученика по-прежнему ставит оценки
+	body, _ = json.Marshal(models.Grade{StudentID: pupil.ID, SubjectID: math.ID, Grade: 5, Quarter: 1})
+	if resp := do(t, router, "PUT", fmt.Sprintf("/grades/%d", grade.ID), bytes.NewReader(body), secondUser); resp.Code != http.StatusOK {
+		t.Errorf("Учитель класса: ожидался статус 200, получен %d", resp.Code)
+	}
+}
+
+func TestSubstituteGradesOnlyInTerm(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+
+	mainUser := createUser(t, st, "main", "teacher")
+	substituteUser := createUser(t, st, "substitute", "teacher")
+	mainTeacher := &models.Teacher{FullName: "Иванова Анна", UserID: mainUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, mainTeacher))
+	substitute := &models.Teacher{FullName: "Петров Олег", UserID: substituteUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, substitute))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	class := assignTeacher(t, st, mainTeacher.ID, math.ID)
+	pupil := &models.Student{FullName: "Иван Иванов", ClassID: class.ID}
+	mustCreate(t, st.CreateStudent(ctx, pupil))
+	mustCreate(t, st.CreateEnrollment(ctx, &models.Enrollment{StudentID: pupil.ID, SubjectID: math.ID, TeacherID: mainTeacher.ID}))
+
+	// Учитель предмета без назначения не оценивает чужих учеников
+	body, _ := json.Marshal(models.Grade{StudentID: pupil.ID, SubjectID: math.ID, Grade: 5, Quarter: 1})
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), substituteUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Без назначения: ожидался статус 403, получен %d", resp.Code)
+	}
+
+	// Замена оформляется назначением на период и действует только в нем
+	mustCreate(t, st.CreateTeachingAssignment(ctx, &models.TeachingAssignment{TeacherID: substitute.ID, SubjectID: math.ID, ClassID: class.ID, TermID: termID(t, st, 1)}))
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), substituteUser); resp.Code != http.StatusCreated {
+		t.Errorf("Замена в периоде: ожидался статус 201, получен %d", resp.Code)
+	}
+	body, _ = json.Marshal(models.Grade{StudentID: pupil.ID, SubjectID: math.ID, Grade: 5, Quarter: 2})
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), substituteUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Замена вне периода: ожидался статус 403, получен %d", resp.Code)
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"unicode/utf8"
+
+	"school-system/backend/models"
+)
+
+// validateGradingScale проверяет диапазон, порог успеваемости и подписи шкалы
+func validateGradingScale(scale *models.GradingScale) error {
+	if scale.Name == "" || utf8.RuneCountInString(scale.Name) > 50 {
+		return errors.New("Название шкалы должно быть от 1 до 50 символов")
+	}
+	if scale.MinValue < 0 || scale.MaxValue > 100 || scale.MinValue >= scale.MaxValue {
+		return errors.New("Диапазон шкалы должен быть внутри 0–100, минимум меньше максимума")
+	}
+	if !scale.Contains(scale.PassValue) {
+		return fmt.Errorf("Порог успеваемости должен быть от %d до %d", scale.MinValue, scale.MaxValue)
+	}
+	seen := make(map[int]bool)
+	for _, l := range scale.Labels {
+		if !scale.Contains(l.Value) {
+			return fmt.Errorf("Подпись для значения %d вне шкалы", l.Value)
+		}
+		if seen[l.Value] {
+			return fmt.Errorf("Повторная подпись для значения %d", l.Value)
+		}
+		seen[l.Value] = true
+		if l.Label == "" || utf8.RuneCountInString(l.Label) > 20 {
+			return errors.New("Подпись значения должна быть от 1 до 20 символов")
+		}
+	}
+	if scale.Labels == nil {
+		scale.Labels = []models.ScaleLabel{}
+	}
+	return nil
+}
+
+// subjectScales возвращает шкалы оценивания всех предметов по ID предмета
+func (s *Server) subjectScales(ctx context.Context) (map[int]models.GradingScale, error) {
+	scales, err := s.GradingScales.ListGradingScales(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subjects, err := s.Subjects.ListSubjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.GradingScale, len(scales))
+	for _, scale := range scales {
+		byID[scale.ID] = scale
+	}
+	result := make(map[int]models.GradingScale, len(subjects))
+	for _, subject := range subjects {
+		result[subject.ID] = byID[subject.GradingScaleID]
+	}
+	return result, nil
+}
+
+// subjectScale возвращает шкалу оценивания предмета
+func (s *Server) subjectScale(ctx context.Context, subjectID int) (*models.GradingScale, error) {
+	subject, err := s.Subjects.GetSubject(ctx, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GradingScales.GetGradingScale(ctx, subject.GradingScaleID)
+}
+
+// GetGradingScales возвращает шкалы оценивания с подписями значений
+func (s *Server) GetGradingScales(w http.ResponseWriter, r *http.Request) {
+	scales, err := s.GradingScales.ListGradingScales(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении шкал оценивания: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if scales == nil {
+		scales = []models.GradingScale{}
+	}
+	writeJSON(w, http.StatusOK, scales)
+}
+
+// CreateGradingScale добавляет шкалу оценивания
+func (s *Server) CreateGradingScale(w http.ResponseWriter, r *http.Request) {
+	var scale models.GradingScale
+	if err := json.NewDecoder(r.Body).Decode(&scale); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if err := validateGradingScale(&scale); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.GradingScales.CreateGradingScale(r.Context(), &scale); err != nil {
+		log.Printf("Ошибка при добавлении шкалы оценивания %q: %v", scale.Name, err)
+		http.Error(w, "Ошибка при добавлении шкалы оценивания", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Добавлена шкала оценивания %q (%d–%d)", scale.Name, scale.MinValue, scale.MaxValue)
+	writeJSON(w, http.StatusCreated, scale)
+}
+
+// UpdateGradingScale заменяет название, порог успеваемости, подписи и отметку шкалы школы.
+// Диапазон шкалы не меняется: по ней уже могут быть выставлены оценки.
+func (s *Server) UpdateGradingScale(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var scale models.GradingScale
+	if err := json.NewDecoder(r.Body).Decode(&scale); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	existing, err := s.GradingScales.GetGradingScale(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Шкала оценивания не найдена", storeErrorStatus(err))
+		return
+	}
+	scale.ID = id
+	scale.MinValue, scale.MaxValue = existing.MinValue, existing.MaxValue
+	if err := validateGradingScale(&scale); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.GradingScales.UpdateGradingScale(r.Context(), &scale); err != nil {
+		log.Printf("Ошибка при обновлении шкалы оценивания %d: %v", id, err)
+		status := storeErrorStatus(err)
+		if status == http.StatusConflict && existing.IsDefault && !scale.IsDefault {
+			http.Error(w, "Шкалу школы нельзя снять: отметьте шкалой школы другую шкалу", status)
+			return
+		}
+		http.Error(w, "Ошибка при обновлении шкалы оценивания", status)
+		return
+	}
+	writeJSON(w, http.StatusOK, scale)
+}
+
+// DeleteGradingScale удаляет шкалу, если она не назначена предметам и не является шкалой школы
+func (s *Server) DeleteGradingScale(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.GradingScales.DeleteGradingScale(r.Context(), id); err != nil {
+		log.Printf("Ошибка при удалении шкалы оценивания %d: %v", id, err)
+		switch status := storeErrorStatus(err); status {
+		case http.StatusConflict:
+			http.Error(w, "Шкалу школы удалить нельзя", status)
+		case http.StatusBadRequest:
+			http.Error(w, "Шкала назначена предметам", status)
+		default:
+			http.Error(w, "Ошибка при удалении шкалы оценивания", status)
+		}
+		return
+	}
+	log.Printf("Удалена шкала оценивания %d", id)
+	w.WriteHeader(http.StatusOK)
+}
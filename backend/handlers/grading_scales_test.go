@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func TestGradingScalesValidationAndStats(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	deputy := createUser(t, st, "deputy", "deputy")
+	year := models.AcademicYearOf(time.Now())
+
+	var scales []models.GradingScale
+	json.Unmarshal(do(t, router, "GET", "/grading-scales", nil, nil).Body.Bytes(), &scales)
+	byName := make(map[string]models.GradingScale)
+	for _, scale := range scales {
+		byName[scale.Name] = scale
+	}
+	ten, letter := byName["10-балльная"], byName["Буквенная"]
+	if len(scales) != 4 || !byName["5-балльная"].IsDefault || ten.PassValue != 4 || letter.Label(5) != "A" {
+		t.Fatalf("Ожидались четыре встроенные шкалы, получено %+v", scales)
+	}
+
+	math := &models.Subject{Name: "Математика"}
+	physics := &models.Subject{Name: "Физика", GradingScaleID: ten.ID}
+	english := &models.Subject{Name: "Английский", GradingScaleID: letter.ID}
+	for _, subject := range []*models.Subject{math, physics, english} {
+		mustCreate(t, st.CreateSubject(ctx, subject))
+	}
+	if math.GradingScaleID != byName["5-балльная"].ID {
+		t.Errorf("Предмету без шкалы ожидалась шкала школы, получено %d", math.GradingScaleID)
+	}
+
+	pupils := make(map[string]*models.Student)
+	for _, letterName := range []string{"А", "Б", "В"} {
+		class := &models.Class{GradeLevel: 9, Letter: letterName, AcademicYear: year}
+		mustCreate(t, st.CreateClass(ctx, class))
+		pupil := &models.Student{FullName: "Ученик 9" + letterName, ClassID: class.ID}
+		mustCreate(t, st.CreateStudent(ctx, pupil))
+		pupils[letterName] = pupil
+	}
+	// 9А: 4 из 5; 9Б: 3 из 10 (ниже порога 4); 9В: 5 из 10 — больше 4, но хуже в шкале школы
+	for _, g := range []models.Grade{
+		{StudentID: pupils["А"].ID, SubjectID: math.ID, Grade: 4},
+		{StudentID: pupils["Б"].ID, SubjectID: physics.ID, Grade: 3},
+		{StudentID: pupils["В"].ID, SubjectID: physics.ID, Grade: 5},
+	} {
+		g.TermID = termID(t, st, 1)
+		mustCreate(t, st.CreateGrade(ctx, &g))
+	}
+
+	period := models.Period{AcademicYear: year}
+	top, worst, err := st.GetTopAndWorstClasses(ctx, period)
+	if err != nil || top != "9А" || worst != "9Б" {
+		t.Errorf("Ожидались лучший класс 9А и худший 9Б, получено %q %q %v", top, worst, err)
+	}
+	var failing []models.FailingStudent
+	resp := do(t, router, "GET", "/stats/failing-students", nil, deputy)
+	json.Unmarshal(resp.Body.Bytes(), &failing)
+	if resp.Code != http.StatusOK || len(failing) != 1 || failing[0].ID != pupils["Б"].ID {
+		t.Errorf("Неуспевающим ожидался только ученик 9Б, получено %d %s", resp.Code, resp.Body.String())
+	}
+
+	post := func(g models.Grade) int {
+		t.Helper()
+		g.StudentID, g.TermID = pupils["А"].ID, termID(t, st, 1)
+		body, _ := json.Marshal(g)
+		return do(t, router, "POST", "/grades", bytes.NewReader(body), deputy).Code
+	}
+	if code := post(models.Grade{SubjectID: physics.ID, Grade: 11}); code != http.StatusBadRequest {
+		t.Errorf("Оценка 11 по 10-балльной шкале: ожидался статус 400, получен %d", code)
+	}
+	if code := post(models.Grade{SubjectID: physics.ID, Grade: 9}); code != http.StatusCreated {
+		t.Errorf("Оценка 9 по 10-балльной шкале: ожидался статус 201, получен %d", code)
+	}
+	if code := post(models.Grade{SubjectID: english.ID, Grade: 4}); code != http.StatusCreated {
+		t.Errorf("Буквенная оценка: ожидался статус 201, получен %d", code)
+	}
+
+	resp = do(t, router, "GET", fmt.Sprintf("/students/%d/term-grades", pupils["А"].ID), nil, deputy)
+	var subjects []models.SubjectGrades
+	json.Unmarshal(resp.Body.Bytes(), &subjects)
+	if len(subjects) != 3 || subjects[0].SubjectName != "Английский" || subjects[0].Quarters[0].Label != "B" ||
+		subjects[2].Scale.MaxValue != 10 {
+		t.Errorf("Ожидались оценки с шкалами и подписью B, получено %d %s", resp.Code, resp.Body.String())
+	}
+
+	changeScale, _ := json.Marshal(models.Subject{Name: "Физика", GradingScaleID: math.GradingScaleID})
+	if resp := do(t, router, "PUT", fmt.Sprintf("/subjects/%d", physics.ID), bytes.NewReader(changeScale), deputy); resp.Code != http.StatusConflict {
+		t.Errorf("Смена шкалы предмета с оценками: ожидался статус 409, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "DELETE", fmt.Sprintf("/grading-scales/%d", ten.ID), nil, deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Удаление используемой шкалы: ожидался статус 400, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "DELETE", fmt.Sprintf("/grading-scales/%d", math.GradingScaleID), nil, deputy); resp.Code != http.StatusConflict {
+		t.Errorf("Удаление шкалы школы: ожидался статус 409, получен %d", resp.Code)
+	}
+
+	invalid, _ := json.Marshal(models.GradingScale{Name: "Зачет", MinValue: 0, MaxValue: 1, PassValue: 2})
+	if resp := do(t, router, "POST", "/grading-scales", bytes.NewReader(invalid), deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Порог вне шкалы: ожидался статус 400, получен %d", resp.Code)
+	}
+	pass, _ := json.Marshal(models.GradingScale{Name: "Зачет", MinValue: 0, MaxValue: 1, PassValue: 1, IsDefault: true,
+		Labels: []models.ScaleLabel{{Value: 0, Label: "незачет"}, {Value: 1, Label: "зачет"}}})
+	resp = do(t, router, "POST", "/grading-scales", bytes.NewReader(pass), deputy)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Новая шкала: ожидался статус 201, получен %d %s", resp.Code, resp.Body.String())
+	}
+	school, err := st.GetDefaultGradingScale(ctx)
+	if err != nil || school.Name != "Зачет" {
+		t.Errorf("Ожидалась новая шкала школы, получено %+v %v", school, err)
+	}
+}
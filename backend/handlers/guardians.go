@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"school-system/backend/models"
+)
+
+// parentRole — роль учетных записей родителей
+const parentRole = "parent"
+
+type GuardianRequest struct {
+	UserID       int    `json:"user_id"`
+	Relationship string `json:"relationship"` // mother / father / guardian / other
+}
+
+// AddGuardian привязывает учетную запись родителя к ученику
+func (s *Server) AddGuardian(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на привязку родителя к ученику %d", studentID)
+
+	var req GuardianRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Некорректные данные", http.StatusBadRequest)
+		return
+	}
+	if req.Relationship == "" {
+		req.Relationship = models.RelationshipGuardian
+	}
+	if !models.ValidRelationship(req.Relationship) {
+		http.Error(w, "Недопустимая степень родства. Допустимые значения: mother, father, guardian, other", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.Users.GetUser(r.Context(), req.UserID)
+	if err != nil {
+		log.Printf("Ошибка при получении пользователя %d: %v", req.UserID, err)
+		if storeErrorStatus(err) == http.StatusNotFound {
+			http.Error(w, "Пользователь не найден", http.StatusBadRequest)
+		} else {
+			http.Error(w, "Ошибка при привязке родителя", http.StatusInternalServerError)
+		}
+		return
+	}
+	if user.Role != parentRole {
+		http.Error(w, "Привязать к ученику можно только пользователя с ролью parent", http.StatusBadRequest)
+		return
+	}
+
+	guardian := models.Guardian{UserID: user.ID, Username: user.Username, StudentID: studentID, Relationship: req.Relationship}
+	if err := s.Guardians.AddGuardian(r.Context(), &guardian); err != nil {
+		log.Printf("Ошибка при привязке родителя %d к ученику %d: %v", user.ID, studentID, err)
+		switch storeErrorStatus(err) {
+		case http.StatusConflict:
+			http.Error(w, "Родитель уже привязан к ученику", http.StatusConflict)
+		case http.StatusBadRequest:
+			http.Error(w, "Ученик не найден", http.StatusNotFound)
+		default:
+			http.Error(w, "Ошибка при привязке родителя", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("Родитель %d привязан к ученику %d (%s)", user.ID, studentID, guardian.Relationship)
+	writeJSON(w, http.StatusCreated, guardian)
+}
+
+// GetGuardians возвращает родителей ученика
+func (s *Server) GetGuardians(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на получение родителей ученика %d", studentID)
+
+	guardians, err := s.Guardians.ListGuardians(r.Context(), studentID)
+	if err != nil {
+		log.Printf("Ошибка при получении родителей ученика %d: %v", studentID, err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if guardians == nil {
+		guardians = []models.Guardian{}
+	}
+	writeJSON(w, http.StatusOK, guardians)
+}
+
+// RemoveGuardian отвязывает родителя от ученика
+func (s *Server) RemoveGuardian(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	userID, err := pathInt(r, "user_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на отвязку родителя %d от ученика %d", userID, studentID)
+
+	if err := s.Guardians.RemoveGuardian(r.Context(), userID, studentID); err != nil {
+		log.Printf("Ошибка при отвязке родителя %d от ученика %d: %v", userID, studentID, err)
+		http.Error(w, "Связь не найдена", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Родитель %d отвязан от ученика %d", userID, studentID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMyChildren возвращает учеников, к которым привязан текущий родитель
+func (s *Server) GetMyChildren(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Получен запрос на получение детей пользователя %d", userID)
+
+	children, err := s.Guardians.ListChildren(r.Context(), userID)
+	if err != nil {
+		log.Printf("Ошибка при получении детей пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if children == nil {
+		children = []models.Child{}
+	}
+	writeJSON(w, http.StatusOK, children)
+}
+
+// childID возвращает {id} ученика из пути, если текущий пользователь — его родитель.
+// Чужие ученики не отличаются от несуществующих. При ошибке ответ уже отправлен.
+func (s *Server) childID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return 0, false
+	}
+	studentID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, false
+	}
+
+	linked, err := s.Guardians.IsGuardian(r.Context(), userID, studentID)
+	if err != nil {
+		log.Printf("Ошибка при проверке связи родителя %d с учеником %d: %v", userID, studentID, err)
+		http.Error(w, "Ошибка при проверке прав", http.StatusInternalServerError)
+		return 0, false
+	}
+	if !linked {
+		log.Printf("Пользователь %d запросил данные чужого ученика %d", userID, studentID)
+		http.Error(w, "Нет доступа к данным этого ученика", http.StatusForbidden)
+		return 0, false
+	}
+	return studentID, true
+}
+
+// GetChildGrades возвращает оценки ребенка по предметам и четвертям
+func (s *Server) GetChildGrades(w http.ResponseWriter, r *http.Request) {
+	studentID, ok := s.childID(w, r)
+	if !ok {
+		return
+	}
+	log.Printf("Получен запрос родителя на получение оценок ученика %d", studentID)
+	subjects, ok := s.groupedStudentGrades(w, r, studentID)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, subjects)
+}
+
+// GetChildAverages возвращает средние баллы ребенка
+func (s *Server) GetChildAverages(w http.ResponseWriter, r *http.Request) {
+	studentID, ok := s.childID(w, r)
+	if !ok {
+		return
+	}
+	log.Printf("Получен запрос родителя на получение средних баллов ученика %d", studentID)
+	subjects, ok := s.groupedStudentGrades(w, r, studentID)
+	if !ok {
+		return
+	}
+	s.writeAverages(w, r, subjects)
+}
+
+// GetChildrenWarnings возвращает предметы, по которым у детей текущего родителя
+// средний балл за четверть ниже порога успеваемости шкалы предмета
+func (s *Server) GetChildrenWarnings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Получен запрос на получение предупреждений об успеваемости детей пользователя %d", userID)
+
+	children, err := s.Guardians.ListChildren(r.Context(), userID)
+	if err != nil {
+		log.Printf("Ошибка при получении детей пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+
+	result := []models.FailingStudent{}
+	for _, child := range children {
+		subjects, ok := s.groupedStudentGrades(w, r, child.ID)
+		if !ok {
+			return
+		}
+		warning := models.FailingStudent{Student: child.Student, SubjectAverages: []models.SubjectAverage{}}
+		for _, subject := range subjects {
+			for _, q := range subject.Quarters {
+				if len(q.Grades) == 0 || subject.Scale.Passes(q.Average) {
+					continue
+				}
+				warning.SubjectAverages = append(warning.SubjectAverages, models.SubjectAverage{
+					SubjectName: subject.SubjectName,
+					Quarter:     q.Quarter,
+					Average:     q.Average,
+				})
+			}
+		}
+		if len(warning.SubjectAverages) > 0 {
+			result = append(result, warning)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
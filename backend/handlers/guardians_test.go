@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"school-system/backend/models"
+)
+
+func TestParentSeesOnlyOwnChildren(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+
+	deputy := createUser(t, st, "deputy", "deputy")
+	parent := createUser(t, st, "mother", "parent")
+	pupilUser := createUser(t, st, "pupil", "student")
+
+	child := &models.Student{FullName: "Иван Иванов", ClassName: "9А"}
+	mustCreate(t, st.CreateStudent(ctx, child))
+	stranger := &models.Student{FullName: "Петр Петров", ClassName: "9А"}
+	mustCreate(t, st.CreateStudent(ctx, stranger))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	for _, g := range []models.Grade{
+		{StudentID: child.ID, SubjectID: math.ID, Grade: 2, TermID: termID(t, st, 1)},
+		{StudentID: child.ID, SubjectID: math.ID, Grade: 3, TermID: termID(t, st, 1)},
+		{StudentID: stranger.ID, SubjectID: math.ID, Grade: 5, TermID: termID(t, st, 1)},
+	} {
+		mustCreate(t, st.CreateGrade(ctx, &g))
+	}
+
+	link := func(user *models.User) int {
+		body, _ := json.Marshal(GuardianRequest{UserID: user.ID, Relationship: models.RelationshipMother})
+		return do(t, router, "POST", fmt.Sprintf("/students/%d/guardians", child.ID), bytes.NewReader(body), deputy).Code
+	}
+	if code := link(pupilUser); code != http.StatusBadRequest {
+		t.Errorf("Привязка ученика как родителя: ожидался статус 400, получен %d", code)
+	}
+	if code := link(parent); code != http.StatusCreated {
+		t.Fatalf("Привязка родителя: ожидался статус 201, получен %d", code)
+	}
+	if code := link(parent); code != http.StatusConflict {
+		t.Errorf("Повторная привязка: ожидался статус 409, получен %d", code)
+	}
+
+	resp := do(t, router, "GET", "/parent/children", nil, parent)
+	var children []models.Child
+	json.Unmarshal(resp.Body.Bytes(), &children)
+	if len(children) != 1 || children[0].ID != child.ID || children[0].Relationship != models.RelationshipMother {
+		t.Fatalf("Неверный список детей: %+v", children)
+	}
+
+	if resp := do(t, router, "GET", fmt.Sprintf("/parent/children/%d/grades", child.ID), nil, parent); resp.Code != http.StatusOK {
+		t.Errorf("Оценки своего ребенка: ожидался статус 200, получен %d", resp.Code)
+	}
+	for _, path := range []string{
+		fmt.Sprintf("/parent/children/%d/grades", stranger.ID),
+		fmt.Sprintf("/parent/children/%d/averages", stranger.ID),
+		fmt.Sprintf("/grades/student/%d", stranger.ID),
+	} {
+		if resp := do(t, router, "GET", path, nil, parent); resp.Code != http.StatusForbidden {
+			t.Errorf("%s: ожидался статус 403, получен %d", path, resp.Code)
+		}
+	}
+	resp = do(t, router, "GET", "/grades", nil, parent)
+	var grades []models.Grade
+	json.Unmarshal(resp.Body.Bytes(), &grades)
+	for _, g := range grades {
+		if g.StudentID != child.ID {
+			t.Errorf("Родителю видна чужая оценка: %+v", g)
+		}
+	}
+
+	resp = do(t, router, "GET", "/parent/warnings", nil, parent)
+	var warnings []models.FailingStudent
+	json.Unmarshal(resp.Body.Bytes(), &warnings)
+	if len(warnings) != 1 || len(warnings[0].SubjectAverages) != 1 || warnings[0].SubjectAverages[0].Average != 2.5 {
+		t.Errorf("Ожидалось предупреждение по математике со средним 2.5, получено %+v", warnings)
+	}
+
+	// После отвязки доступ пропадает
+	if resp := do(t, router, "DELETE", fmt.Sprintf("/students/%d/guardians/%d", child.ID, parent.ID), nil, deputy); resp.Code != http.StatusNoContent {
+		t.Fatalf("Отвязка родителя: ожидался статус 204, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "GET", fmt.Sprintf("/parent/children/%d/grades", child.ID), nil, parent); resp.Code != http.StatusForbidden {
+		t.Errorf("После отвязки: ожидался статус 403, получен %d", resp.Code)
+	}
+}
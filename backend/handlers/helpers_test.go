@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"school-system/backend/middleware"
+	"school-system/backend/models"
+	"school-system/backend/store/memory"
+)
+
+var testSecret = []byte("test-secret")
+
+// newTestServer создает сервер поверх хранилища в памяти
+func newTestServer(t *testing.T) (*Server, *memory.Store) {
+	t.Helper()
+	middleware.SetJWTSecret(testSecret)
+	st := memory.New()
+	middleware.SetRevocationChecker(st.IsAccessTokenRevoked)
+	middleware.SetPermissionChecker(st.RoleHasPermission)
+
+	// Текущий учебный год с четвертями, как после миграции
+	year, _ := academicYearFromRequest(AcademicYearRequest{StartYear: models.AcademicYearOf(time.Now())})
+	mustCreate(t, st.CreateAcademicYear(context.Background(), year))
+	return NewServer(st), st
+}
+
+// termID возвращает ID четверти текущего учебного года
+func termID(t *testing.T, st *memory.Store, number int) int {
+	t.Helper()
+	year, err := st.GetAcademicYear(context.Background(), models.AcademicYearOf(time.Now()))
+	if err != nil || number > len(year.Terms) {
+		t.Fatalf("Четверть %d не найдена: %v", number, err)
+	}
+	return year.Terms[number-1].ID
+}
+
+// mustCreate падает, если не удалось подготовить тестовые данные
+func mustCreate(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Ошибка подготовки данных: %v", err)
+	}
+}
+
+// assignTeacher назначает учителя на предмет в классе 9А текущего учебного года
+// и возвращает этот класс
+func assignTeacher(t *testing.T, st *memory.Store, teacherID, subjectID int) *models.Class {
+	t.Helper()
+	ctx := context.Background()
+	year := models.AcademicYearOf(time.Now())
+	class, err := st.FindClass(ctx, 9, "А", year)
+	if err != nil {
+		class = &models.Class{GradeLevel: 9, Letter: "А", AcademicYear: year}
+		mustCreate(t, st.CreateClass(ctx, class))
+	}
+	mustCreate(t, st.CreateTeachingAssignment(ctx, &models.TeachingAssignment{TeacherID: teacherID, SubjectID: subjectID, ClassID: class.ID}))
+	return class
+}
+
+// createUser добавляет пользователя с указанной ролью
+func createUser(t *testing.T, st *memory.Store, username, role string) *models.User {
+	t.Helper()
+	user := &models.User{Username: username, Password: "-", Role: role}
+	mustCreate(t, st.CreateUser(context.Background(), user))
+	return user
+}
+
+// tokenFor выпускает токен доступа для пользователя
+func tokenFor(t *testing.T, user *models.User) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID,
+		"role":    user.Role,
+		"typ":     "access",
+		"jti":     fmt.Sprintf("test-%d-%d", user.ID, time.Now().UnixNano()),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString(testSecret)
+	if err != nil {
+		t.Fatalf("Ошибка подписи токена: %v", err)
+	}
+	return signed
+}
+
+// do выполняет запрос к роутеру от имени пользователя (user может быть nil)
+func do(t *testing.T, h http.Handler, method, path string, body io.Reader, user *models.User) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	if user != nil {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokenFor(t, user)))
+	}
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	return resp
+}
@@ -0,0 +1,619 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+const (
+	// maxHomeworkDescription — наибольшая длина описания задания и текста работы в символах
+	maxHomeworkDescription = 10000
+	// maxSubmissionFileSize — наибольший размер файла работы
+	maxSubmissionFileSize = 5 << 20
+)
+
+// HomeworkRequest — задание в теле запроса; при изменении класс и предмет не меняются
+type HomeworkRequest struct {
+	ClassID   int `json:"class_id"`
+	SubjectID int `json:"subject_id"`
+	// TeacherID — учитель задания, если его выдает не учитель (например, завуч)
+	TeacherID   int         `json:"teacher_id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	DueDate     models.Date `json:"due_date"`
+}
+
+// SubmissionRequest — работа ученика в формате JSON; файл сдается запросом multipart/form-data
+type SubmissionRequest struct {
+	// Status — done, чтобы только отметить задание выполненным
+	Status string `json:"status"`
+	Text   string `json:"text"`
+}
+
+// GradeSubmissionRequest — оценка работы ученика
+type GradeSubmissionRequest struct {
+	Grade   int     `json:"grade"`
+	Weight  float64 `json:"weight"`
+	Comment string  `json:"comment"`
+}
+
+// validateHomework проверяет название, описание и срок сдачи задания
+func validateHomework(req *HomeworkRequest) error {
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		return errors.New("Нужно указать название задания")
+	}
+	if utf8.RuneCountInString(req.Title) > 255 {
+		return errors.New("Название задания должно быть не длиннее 255 символов")
+	}
+	if utf8.RuneCountInString(req.Description) > maxHomeworkDescription {
+		return fmt.Errorf("Описание задания должно быть не длиннее %d символов", maxHomeworkDescription)
+	}
+	if req.DueDate.IsZero() {
+		return errors.New("Нужно указать срок сдачи")
+	}
+	return nil
+}
+
+// writeHomeworkError отправляет ответ на ошибку сохранения задания или работы
+func writeHomeworkError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrArchived) {
+		http.Error(w, "Учебный год в архиве", http.StatusConflict)
+		return
+	}
+	switch storeErrorStatus(err) {
+	case http.StatusNotFound:
+		http.Error(w, "Задание не найдено", http.StatusNotFound)
+	case http.StatusBadRequest:
+		http.Error(w, "Класс, предмет, учитель или ученик не найден", http.StatusBadRequest)
+	default:
+		http.Error(w, "Ошибка при сохранении задания", http.StatusInternalServerError)
+	}
+}
+
+// homeworkFromPath возвращает задание из параметра {id}. При ошибке ответ уже отправлен.
+func (s *Server) homeworkFromPath(w http.ResponseWriter, r *http.Request) (*models.Homework, bool) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	homework, err := s.Homework.GetHomework(r.Context(), id)
+	if err != nil {
+		log.Printf("Ошибка при получении задания %d: %v", id, err)
+		if storeErrorStatus(err) == http.StatusNotFound {
+			http.Error(w, "Задание не найдено", http.StatusNotFound)
+		} else {
+			http.Error(w, "Ошибка при получении задания", http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	return homework, true
+}
+
+// checkDueDate проверяет, что срок сдачи входит в учебный год класса и в один из его периодов:
+// по нему определяется период отметки за работу. При ошибке ответ уже отправлен.
+func (s *Server) checkDueDate(w http.ResponseWriter, r *http.Request, classID int, due models.Date) bool {
+	class, err := s.Classes.GetClass(r.Context(), classID)
+	if err != nil {
+		log.Printf("Ошибка при получении класса %d: %v", classID, err)
+		if storeErrorStatus(err) == http.StatusNotFound {
+			http.Error(w, "Класс не найден", http.StatusBadRequest)
+		} else {
+			http.Error(w, "Ошибка при получении класса", http.StatusInternalServerError)
+		}
+		return false
+	}
+	if class.AcademicYear != models.AcademicYearOf(due.Time) {
+		http.Error(w, "Срок сдачи не входит в учебный год класса", http.StatusBadRequest)
+		return false
+	}
+	if _, err := s.termOfDate(r.Context(), due); err != nil {
+		if errors.Is(err, errTermNotFound) {
+			http.Error(w, "Срок сдачи не входит в учебный период", http.StatusBadRequest)
+			return false
+		}
+		log.Printf("Ошибка при определении учебного периода: %v", err)
+		http.Error(w, "Ошибка при определении учебного периода", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// teachesHomework сообщает, ведет ли пользователь предмет задания в классе задания
+// в период срока сдачи (срок вне периодов — в любой период учебного года)
+func (s *Server) teachesHomework(ctx context.Context, access *gradeAccess, homework *models.Homework) (bool, error) {
+	if access.all {
+		return true, nil
+	}
+	termID := 0
+	term, err := s.termOfDate(ctx, homework.DueDate)
+	switch {
+	case err == nil:
+		termID = term.ID
+	case !errors.Is(err, errTermNotFound):
+		return false, err
+	}
+	return access.teachesClass(homework.SubjectID, homework.ClassID, termID), nil
+}
+
+// checkHomeworkWrite проверяет, что пользователь ведет предмет задания в его классе.
+// При отказе ответ уже отправлен.
+func (s *Server) checkHomeworkWrite(w http.ResponseWriter, r *http.Request, homework *models.Homework) bool {
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return false
+	}
+	teaches, err := s.teachesHomework(r.Context(), access, homework)
+	if err != nil {
+		log.Printf("Ошибка при проверке доступа к заданию: %v", err)
+		http.Error(w, "Ошибка при проверке прав", http.StatusInternalServerError)
+		return false
+	}
+	if !teaches {
+		http.Error(w, "Вы не ведете этот предмет в классе", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// homeworkFilterFromRequest разбирает параметры отбора заданий: class_id, subject_id,
+// teacher_id, from и to (срок сдачи). При ошибке ответ уже отправлен.
+func homeworkFilterFromRequest(w http.ResponseWriter, r *http.Request) (models.HomeworkFilter, bool) {
+	var f models.HomeworkFilter
+	query := r.URL.Query()
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{
+		{"class_id", &f.ClassID},
+		{"subject_id", &f.SubjectID},
+		{"teacher_id", &f.TeacherID},
+	} {
+		if v := query.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				http.Error(w, "Некорректный "+p.name, http.StatusBadRequest)
+				return f, false
+			}
+			*p.value = n
+		}
+	}
+	for _, p := range []struct {
+		name  string
+		value *models.Date
+	}{
+		{"from", &f.From},
+		{"to", &f.To},
+	} {
+		if v := query.Get(p.name); v != "" {
+			day, err := models.ParseDate(v)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return f, false
+			}
+			*p.value = day
+		}
+	}
+	return f, true
+}
+
+// GetHomework возвращает задания. Параметры class_id, subject_id, teacher_id, from и to
+// (срок сдачи) сужают выборку.
+func (s *Server) GetHomework(w http.ResponseWriter, r *http.Request) {
+	filter, ok := homeworkFilterFromRequest(w, r)
+	if !ok {
+		return
+	}
+	homework, err := s.Homework.ListHomework(r.Context(), filter)
+	if err != nil {
+		log.Printf("Ошибка при получении заданий: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if homework == nil {
+		homework = []models.Homework{}
+	}
+	writeJSON(w, http.StatusOK, homework)
+}
+
+// CreateHomework выдает задание классу; без права grades:all — только по предмету, который пользователь ведет в классе.
+// Учителем задания становится текущий пользователь, если у него есть карточка учителя.
+func (s *Server) CreateHomework(w http.ResponseWriter, r *http.Request) {
+	var req HomeworkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if req.ClassID < 1 || req.SubjectID < 1 {
+		http.Error(w, "Нужно указать класс и предмет", http.StatusBadRequest)
+		return
+	}
+	if err := validateHomework(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.checkDueDate(w, r, req.ClassID, req.DueDate) {
+		return
+	}
+	if !s.checkHomeworkWrite(w, r, &models.Homework{ClassID: req.ClassID, SubjectID: req.SubjectID, DueDate: req.DueDate}) {
+		return
+	}
+	userID, _ := userIDFromContext(r)
+	teacher, err := s.Teachers.GetTeacherByUserID(r.Context(), userID)
+	switch {
+	case err == nil:
+		req.TeacherID = teacher.ID
+	case storeErrorStatus(err) != http.StatusNotFound:
+		log.Printf("Ошибка при получении учителя для user_id=%d: %v", userID, err)
+		http.Error(w, "Ошибка при сохранении задания", http.StatusInternalServerError)
+		return
+	}
+
+	homework := &models.Homework{
+		ClassID:     req.ClassID,
+		SubjectID:   req.SubjectID,
+		TeacherID:   req.TeacherID,
+		Title:       req.Title,
+		Description: req.Description,
+		DueDate:     req.DueDate,
+	}
+	if err := s.Homework.CreateHomework(r.Context(), homework); err != nil {
+		log.Printf("Ошибка при добавлении задания: %v", err)
+		writeHomeworkError(w, err)
+		return
+	}
+	if created, err := s.Homework.GetHomework(r.Context(), homework.ID); err == nil {
+		homework = created
+	}
+
+	log.Printf("Задание %d выдано классу %d по предмету %d", homework.ID, homework.ClassID, homework.SubjectID)
+	writeJSON(w, http.StatusCreated, homework)
+}
+
+// UpdateHomework меняет название, описание и срок сдачи задания
+func (s *Server) UpdateHomework(w http.ResponseWriter, r *http.Request) {
+	homework, ok := s.homeworkFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req HomeworkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if err := validateHomework(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.checkDueDate(w, r, homework.ClassID, req.DueDate) {
+		return
+	}
+	// Учитель должен вести предмет в классе и в период прежнего, и в период нового срока сдачи
+	moved := *homework
+	moved.DueDate = req.DueDate
+	if !s.checkHomeworkWrite(w, r, homework) || !s.checkHomeworkWrite(w, r, &moved) {
+		return
+	}
+
+	homework.Title, homework.Description, homework.DueDate = req.Title, req.Description, req.DueDate
+	if err := s.Homework.UpdateHomework(r.Context(), homework); err != nil {
+		log.Printf("Ошибка при обновлении задания %d: %v", homework.ID, err)
+		writeHomeworkError(w, err)
+		return
+	}
+
+	log.Printf("Задание %d обновлено", homework.ID)
+	writeJSON(w, http.StatusOK, homework)
+}
+
+// DeleteHomework удаляет задание вместе с работами; отметки за работы остаются
+func (s *Server) DeleteHomework(w http.ResponseWriter, r *http.Request) {
+	homework, ok := s.homeworkFromPath(w, r)
+	if !ok {
+		return
+	}
+	if !s.checkHomeworkWrite(w, r, homework) {
+		return
+	}
+	if err := s.Homework.DeleteHomework(r.Context(), homework.ID); err != nil {
+		log.Printf("Ошибка при удалении задания %d: %v", homework.ID, err)
+		writeHomeworkError(w, err)
+		return
+	}
+
+	log.Printf("Задание %d удалено", homework.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMyHomework возвращает задания класса текущего ученика вместе с его работами.
+// Параметры те же, что у GetHomework, класс всегда класс ученика.
+func (s *Server) GetMyHomework(w http.ResponseWriter, r *http.Request) {
+	student, ok := s.currentStudent(w, r)
+	if !ok {
+		return
+	}
+	if student.ClassID == 0 {
+		http.Error(w, "Ученик не зачислен в класс", http.StatusNotFound)
+		return
+	}
+	filter, ok := homeworkFilterFromRequest(w, r)
+	if !ok {
+		return
+	}
+	filter.ClassID, filter.TeacherID = student.ClassID, 0
+	homework, err := s.Homework.ListHomework(r.Context(), filter)
+	if err != nil {
+		log.Printf("Ошибка при получении заданий класса %d: %v", student.ClassID, err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	result := make([]models.StudentHomework, 0, len(homework))
+	for _, h := range homework {
+		item := models.StudentHomework{Homework: h}
+		submissions, err := s.Homework.ListSubmissions(r.Context(), h.ID, student.ID)
+		if err != nil {
+			log.Printf("Ошибка при получении работы по заданию %d: %v", h.ID, err)
+			http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+			return
+		}
+		if len(submissions) > 0 {
+			item.Submission = &submissions[0]
+		}
+		result = append(result, item)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// submissionFromRequest читает работу из JSON или из формы multipart/form-data
+// с полями text и file. При ошибке ответ уже отправлен.
+func submissionFromRequest(w http.ResponseWriter, r *http.Request) (*models.Submission, bool) {
+	submission := &models.Submission{}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		r.Body = http.MaxBytesReader(w, r.Body, maxSubmissionFileSize+1<<20)
+		if err := r.ParseMultipartForm(maxSubmissionFileSize); err != nil {
+			http.Error(w, fmt.Sprintf("Файл должен быть не больше %d МБ", maxSubmissionFileSize>>20), http.StatusBadRequest)
+			return nil, false
+		}
+		submission.Text = r.FormValue("text")
+		file, header, err := r.FormFile("file")
+		switch {
+		case err == nil:
+			defer file.Close()
+			if header.Size > maxSubmissionFileSize {
+				http.Error(w, fmt.Sprintf("Файл должен быть не больше %d МБ", maxSubmissionFileSize>>20), http.StatusBadRequest)
+				return nil, false
+			}
+			data, err := io.ReadAll(file)
+			if err != nil {
+				http.Error(w, "Невозможно прочитать файл", http.StatusBadRequest)
+				return nil, false
+			}
+			submission.File = data
+			submission.FileName = header.Filename
+			submission.FileType = header.Header.Get("Content-Type")
+		case !errors.Is(err, http.ErrMissingFile):
+			http.Error(w, "Невозможно прочитать файл", http.StatusBadRequest)
+			return nil, false
+		}
+	} else {
+		var req SubmissionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+			return nil, false
+		}
+		if req.Status != "" && req.Status != models.SubmissionDone {
+			http.Error(w, "Статус работы может быть только done", http.StatusBadRequest)
+			return nil, false
+		}
+		submission.Text = req.Text
+	}
+
+	if utf8.RuneCountInString(submission.Text) > maxHomeworkDescription {
+		http.Error(w, fmt.Sprintf("Текст работы должен быть не длиннее %d символов", maxHomeworkDescription), http.StatusBadRequest)
+		return nil, false
+	}
+	if utf8.RuneCountInString(submission.FileName) > 255 {
+		http.Error(w, "Имя файла должно быть не длиннее 255 символов", http.StatusBadRequest)
+		return nil, false
+	}
+	if len(submission.FileType) > 100 {
+		submission.FileType = ""
+	}
+	submission.Status = models.SubmissionDone
+	if strings.TrimSpace(submission.Text) != "" || submission.File != nil {
+		submission.Status = models.SubmissionSubmitted
+	}
+	return submission, true
+}
+
+// SubmitHomework сохраняет работу текущего ученика по заданию его класса, заменяя прежнюю.
+// Оцененную работу изменить нельзя.
+func (s *Server) SubmitHomework(w http.ResponseWriter, r *http.Request) {
+	homework, ok := s.homeworkFromPath(w, r)
+	if !ok {
+		return
+	}
+	student, ok := s.currentStudent(w, r)
+	if !ok {
+		return
+	}
+	if student.ClassID != homework.ClassID {
+		http.Error(w, "Задание выдано другому классу", http.StatusForbidden)
+		return
+	}
+	submission, ok := submissionFromRequest(w, r)
+	if !ok {
+		return
+	}
+	previous, err := s.Homework.GetSubmission(r.Context(), homework.ID, student.ID)
+	switch {
+	case err == nil && previous.GradeID != 0:
+		http.Error(w, "Работа уже оценена", http.StatusConflict)
+		return
+	case err != nil && storeErrorStatus(err) != http.StatusNotFound:
+		log.Printf("Ошибка при получении работы ученика %d по заданию %d: %v", student.ID, homework.ID, err)
+		http.Error(w, "Ошибка при сохранении работы", http.StatusInternalServerError)
+		return
+	}
+
+	submission.HomeworkID, submission.StudentID = homework.ID, student.ID
+	if err := s.Homework.SaveSubmission(r.Context(), submission); err != nil {
+		log.Printf("Ошибка при сохранении работы ученика %d по заданию %d: %v", student.ID, homework.ID, err)
+		writeHomeworkError(w, err)
+		return
+	}
+	submission.StudentName = student.FullName
+
+	log.Printf("Ученик %d сдал задание %d (%s)", student.ID, homework.ID, submission.Status)
+	writeJSON(w, http.StatusOK, submission)
+}
+
+// GetHomeworkSubmissions возвращает работы учеников по заданию без содержимого файлов
+func (s *Server) GetHomeworkSubmissions(w http.ResponseWriter, r *http.Request) {
+	homework, ok := s.homeworkFromPath(w, r)
+	if !ok {
+		return
+	}
+	if !s.checkHomeworkWrite(w, r, homework) {
+		return
+	}
+	submissions, err := s.Homework.ListSubmissions(r.Context(), homework.ID, 0)
+	if err != nil {
+		log.Printf("Ошибка при получении работ по заданию %d: %v", homework.ID, err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if submissions == nil {
+		submissions = []models.Submission{}
+	}
+	writeJSON(w, http.StatusOK, submissions)
+}
+
+// submissionFromPath возвращает задание и работу ученика из параметров {id} и {student_id}.
+// При ошибке ответ уже отправлен.
+func (s *Server) submissionFromPath(w http.ResponseWriter, r *http.Request) (*models.Homework, *models.Submission, bool) {
+	homework, ok := s.homeworkFromPath(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	studentID, err := pathInt(r, "student_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	submission, err := s.Homework.GetSubmission(r.Context(), homework.ID, studentID)
+	if err != nil {
+		if storeErrorStatus(err) == http.StatusNotFound {
+			http.Error(w, "Ученик не сдал работу", http.StatusNotFound)
+		} else {
+			log.Printf("Ошибка при получении работы ученика %d по заданию %d: %v", studentID, homework.ID, err)
+			http.Error(w, "Ошибка при получении работы", http.StatusInternalServerError)
+		}
+		return nil, nil, false
+	}
+	return homework, submission, true
+}
+
+// GetSubmissionFile отдает файл работы учителю предмета, самому ученику и его родителям
+func (s *Server) GetSubmissionFile(w http.ResponseWriter, r *http.Request) {
+	homework, submission, ok := s.submissionFromPath(w, r)
+	if !ok {
+		return
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return
+	}
+	teaches, err := s.teachesHomework(r.Context(), access, homework)
+	if err != nil {
+		log.Printf("Ошибка при проверке доступа к заданию %d: %v", homework.ID, err)
+		http.Error(w, "Ошибка при проверке прав", http.StatusInternalServerError)
+		return
+	}
+	if !teaches && !access.ownsStudent(submission.StudentID) {
+		http.Error(w, "Нет доступа к работе", http.StatusForbidden)
+		return
+	}
+	if submission.FileName == "" {
+		http.Error(w, "К работе не приложен файл", http.StatusNotFound)
+		return
+	}
+
+	fileType := submission.FileType
+	if fileType == "" {
+		fileType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", fileType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": submission.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write(submission.File)
+}
+
+// GradeSubmission оценивает работу ученика: выставляет отметку вида homework на дату срока сдачи
+// со ссылкой на задание или меняет уже выставленную
+func (s *Server) GradeSubmission(w http.ResponseWriter, r *http.Request) {
+	homework, submission, ok := s.submissionFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req GradeSubmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	grade := models.Grade{
+		ID:         submission.GradeID,
+		StudentID:  submission.StudentID,
+		SubjectID:  homework.SubjectID,
+		Grade:      req.Grade,
+		Date:       homework.DueDate,
+		Kind:       models.MarkKindHomework,
+		Weight:     req.Weight,
+		Comment:    req.Comment,
+		HomeworkID: homework.ID,
+	}
+	if err := validateGrade(&grade); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.setGradeTerm(w, r, &grade) {
+		return
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok || !s.checkGradeWrite(w, r, access, &grade) || !s.checkGradeScale(w, r, grade.SubjectID, grade.Grade) {
+		return
+	}
+
+	status := http.StatusOK
+	if grade.ID != 0 {
+		if err := s.Grades.UpdateGrade(auditContext(r), &grade); err != nil {
+			log.Printf("Ошибка при изменении отметки за задание %d: %v", homework.ID, err)
+			http.Error(w, "Ошибка при сохранении отметки", storeErrorStatus(err))
+			return
+		}
+	} else {
+		if err := s.Grades.CreateGrade(auditContext(r), &grade); err != nil {
+			log.Printf("Ошибка при выставлении отметки за задание %d: %v", homework.ID, err)
+			http.Error(w, "Ошибка при сохранении отметки", storeErrorStatus(err))
+			return
+		}
+		status = http.StatusCreated
+	}
+
+	log.Printf("Работа ученика %d по заданию %d оценена: %d", grade.StudentID, homework.ID, grade.Grade)
+	writeJSON(w, status, grade)
+}
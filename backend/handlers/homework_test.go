@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"school-system/backend/models"
+)
+
+func TestHomeworkSubmissionsAndGrading(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	annaUser := createUser(t, st, "anna", "teacher")
+	olegUser := createUser(t, st, "oleg", "teacher")
+	pupilUser := createUser(t, st, "pupil", "student")
+	otherUser := createUser(t, st, "other", "student")
+
+	anna := &models.Teacher{FullName: "Иванова Анна", UserID: annaUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, anna))
+	mustCreate(t, st.CreateTeacher(ctx, &models.Teacher{FullName: "Петров Олег", UserID: olegUser.ID}))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	class := assignTeacher(t, st, anna.ID, math.ID)
+	classB := &models.Class{GradeLevel: 9, Letter: "Б", AcademicYear: class.AcademicYear}
+	mustCreate(t, st.CreateClass(ctx, classB))
+	pupil := &models.Student{FullName: "Иван Иванов", ClassID: class.ID, UserID: pupilUser.ID}
+	mustCreate(t, st.CreateStudent(ctx, pupil))
+	mustCreate(t, st.CreateStudent(ctx, &models.Student{FullName: "Петр Петров", ClassID: classB.ID, UserID: otherUser.ID}))
+
+	year, _ := st.GetAcademicYear(ctx, class.AcademicYear)
+	due := models.NewDate(year.Terms[0].StartsOn.AddDate(0, 0, 7))
+	create := func(user *models.User, req HomeworkRequest) (int, models.Homework) {
+		t.Helper()
+		body, _ := json.Marshal(req)
+		resp := do(t, router, "POST", "/homework", bytes.NewReader(body), user)
+		var homework models.Homework
+		json.Unmarshal(resp.Body.Bytes(), &homework)
+		return resp.Code, homework
+	}
+	req := HomeworkRequest{ClassID: class.ID, SubjectID: math.ID, Title: "Упражнения 1-5", Description: "Стр. 12", DueDate: due}
+	if code, _ := create(olegUser, req); code != http.StatusForbidden {
+		t.Errorf("Учитель другого предмета: ожидался статус 403, получен %d", code)
+	}
+	outside := req
+	outside.DueDate = models.NewDate(due.AddDate(2, 0, 0))
+	if code, _ := create(annaUser, outside); code != http.StatusBadRequest {
+		t.Errorf("Срок сдачи вне учебного года: ожидался статус 400, получен %d", code)
+	}
+	code, homework := create(annaUser, req)
+	if code != http.StatusCreated || homework.TeacherID != anna.ID || homework.SubjectName != "Математика" {
+		t.Fatalf("Задание: ожидался статус 201 и учитель %d, получено %d %+v", anna.ID, code, homework)
+	}
+	base := fmt.Sprintf("/homework/%d", homework.ID)
+
+	var mine []models.StudentHomework
+	resp := do(t, router, "GET", "/me/homework", nil, pupilUser)
+	json.Unmarshal(resp.Body.Bytes(), &mine)
+	if resp.Code != http.StatusOK || len(mine) != 1 || mine[0].Submission != nil {
+		t.Fatalf("Задания ученика: ожидалось одно задание без работы, получено %d %s", resp.Code, resp.Body.String())
+	}
+
+	if resp := do(t, router, "PUT", base+"/submission", bytes.NewBufferString(`{"status": "done"}`), otherUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Ученик другого класса: ожидался статус 403, получен %d", resp.Code)
+	}
+	var submission models.Submission
+	resp = do(t, router, "PUT", base+"/submission", bytes.NewBufferString(`{"status": "done"}`), pupilUser)
+	json.Unmarshal(resp.Body.Bytes(), &submission)
+	if resp.Code != http.StatusOK || submission.Status != models.SubmissionDone {
+		t.Fatalf("Отметка о выполнении: ожидался статус done, получено %d %s", resp.Code, resp.Body.String())
+	}
+
+	// Работа с файлом заменяет отметку о выполнении
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	mw.WriteField("text", "Ответы в файле")
+	part, _ := mw.CreateFormFile("file", "решение.txt")
+	part.Write([]byte("1) 42"))
+	mw.Close()
+	upload := httptest.NewRequest("PUT", base+"/submission", &form)
+	upload.Header.Set("Content-Type", mw.FormDataContentType())
+	upload.Header.Set("Authorization", "Bearer "+tokenFor(t, pupilUser))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, upload)
+	json.Unmarshal(rec.Body.Bytes(), &submission)
+	if rec.Code != http.StatusOK || submission.Status != models.SubmissionSubmitted || submission.FileSize != 5 {
+		t.Fatalf("Сдача файла: ожидался статус submitted и файл 5 байт, получено %d %s", rec.Code, rec.Body.String())
+	}
+
+	var submissions []models.Submission
+	resp = do(t, router, "GET", base+"/submissions", nil, annaUser)
+	json.Unmarshal(resp.Body.Bytes(), &submissions)
+	if resp.Code != http.StatusOK || len(submissions) != 1 || submissions[0].FileName != "решение.txt" {
+		t.Fatalf("Работы по заданию: ожидалась одна работа с файлом, получено %d %s", resp.Code, resp.Body.String())
+	}
+	filePath := fmt.Sprintf("%s/submissions/%d/file", base, pupil.ID)
+	if resp := do(t, router, "GET", filePath, nil, annaUser); resp.Code != http.StatusOK || resp.Body.String() != "1) 42" {
+		t.Errorf("Файл работы: ожидалось содержимое файла, получено %d %q", resp.Code, resp.Body.String())
+	}
+	if resp := do(t, router, "GET", filePath, nil, otherUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Файл чужой работы: ожидался статус 403, получен %d", resp.Code)
+	}
+
+	// Оценка работы создает отметку со ссылкой на задание, повторная оценка ее меняет
+	gradePath := fmt.Sprintf("%s/submissions/%d/grade", base, pupil.ID)
+	var grade models.Grade
+	resp = do(t, router, "PUT", gradePath, bytes.NewBufferString(`{"grade": 5}`), olegUser)
+	if resp.Code != http.StatusForbidden {
+		t.Errorf("Оценка учителем другого предмета: ожидался статус 403, получен %d", resp.Code)
+	}
+	resp = do(t, router, "PUT", gradePath, bytes.NewBufferString(`{"grade": 5}`), annaUser)
+	json.Unmarshal(resp.Body.Bytes(), &grade)
+	if resp.Code != http.StatusCreated || grade.HomeworkID != homework.ID || grade.Kind != models.MarkKindHomework || !grade.Date.Equal(due.Time) {
+		t.Fatalf("Оценка работы: ожидалась отметка за задание, получено %d %s", resp.Code, resp.Body.String())
+	}
+	if resp := do(t, router, "PUT", gradePath, bytes.NewBufferString(`{"grade": 4}`), annaUser); resp.Code != http.StatusOK {
+		t.Fatalf("Повторная оценка: ожидался статус 200, получен %d %s", resp.Code, resp.Body.String())
+	}
+	grades, _ := st.ListGrades(ctx)
+	if len(grades) != 1 || grades[0].Grade != 4 || grades[0].HomeworkID != homework.ID {
+		t.Errorf("Ожидалась одна отметка 4 за задание, получено %+v", grades)
+	}
+	if resp := do(t, router, "PUT", base+"/submission", bytes.NewBufferString(`{"text": "Исправил"}`), pupilUser); resp.Code != http.StatusConflict {
+		t.Errorf("Изменение оцененной работы: ожидался статус 409, получен %d", resp.Code)
+	}
+	resp = do(t, router, "GET", "/me/homework", nil, pupilUser)
+	json.Unmarshal(resp.Body.Bytes(), &mine)
+	if len(mine) != 1 || mine[0].Submission == nil || mine[0].Submission.Grade != 4 {
+		t.Errorf("Ученик должен видеть оценку за работу, получено %s", resp.Body.String())
+	}
+
+	// Удаление задания удаляет работы, но не отметки
+	if resp := do(t, router, "DELETE", base, nil, annaUser); resp.Code != http.StatusNoContent {
+		t.Fatalf("Удаление задания: ожидался статус 204, получен %d", resp.Code)
+	}
+	grades, _ = st.ListGrades(ctx)
+	if len(grades) != 1 || grades[0].HomeworkID != 0 {
+		t.Errorf("Отметка должна остаться без ссылки на задание, получено %+v", grades)
+	}
+	if resp := do(t, router, "GET", filePath, nil, annaUser); resp.Code != http.StatusNotFound {
+		t.Errorf("Работа удаленного задания: ожидался статус 404, получен %d", resp.Code)
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"school-system/backend/middleware"
+	"school-system/backend/models"
+)
+
+type InvitationRequest struct {
+	Role      string     `json:"role"` // student / teacher / deputy или своя роль
+	StudentID int        `json:"student_id"`
+	TeacherID int        `json:"teacher_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// invitationResponse — созданное приглашение; код показывается только один раз
+type invitationResponse struct {
+	models.Invitation
+	Code string `json:"code"`
+}
+
+// checkInvitedRole проверяет, что у приглашаемой роли нет прав, которых нет у роли
+// приглашающего: иначе право users:manage позволило бы выдать себе роль deputy.
+// При отказе ответ уже отправлен.
+func (s *Server) checkInvitedRole(w http.ResponseWriter, r *http.Request, role *models.Role) bool {
+	inviterRole, _ := r.Context().Value(middleware.ContextRole).(string)
+	for _, permission := range role.Permissions {
+		allowed, err := s.Permissions.RoleHasPermission(r.Context(), inviterRole, permission)
+		if err != nil {
+			log.Printf("Ошибка при проверке права %s для роли %s: %v", permission, inviterRole, err)
+			http.Error(w, "Ошибка при создании приглашения", http.StatusInternalServerError)
+			return false
+		}
+		if !allowed {
+			log.Printf("Роль %s не может пригласить пользователя с ролью %s: нет права %s", inviterRole, role.Name, permission)
+			http.Error(w, "Нельзя пригласить пользователя с правами, которых нет у вас", http.StatusForbidden)
+			return false
+		}
+	}
+	return true
+}
+
+// CreateInvitation создает приглашение на регистрацию
+func (s *Server) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на создание приглашения")
+	var req InvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Ошибка при чтении данных приглашения: %v", err)
+		http.Error(w, "Некорректные данные", http.StatusBadRequest)
+		return
+	}
+
+	role, err := s.Permissions.GetRole(r.Context(), req.Role)
+	if err != nil {
+		if storeErrorStatus(err) != http.StatusNotFound {
+			log.Printf("Ошибка при получении роли %q: %v", req.Role, err)
+			http.Error(w, "Ошибка при создании приглашения", http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, "Роль не найдена", http.StatusBadRequest)
+		return
+	}
+	if !s.checkInvitedRole(w, r, role) {
+		return
+	}
+	if req.StudentID != 0 && req.Role != "student" {
+		http.Error(w, "Карточку ученика можно привязать только к приглашению с ролью student", http.StatusBadRequest)
+		return
+	}
+	if req.TeacherID != 0 && req.Role != "teacher" {
+		http.Error(w, "Карточку учителя можно привязать только к приглашению с ролью teacher", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.Auth.InvitationTTL)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			http.Error(w, "Срок действия приглашения должен быть в будущем", http.StatusBadRequest)
+			return
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	// Карточка не должна быть уже привязана к учетной записи
+	if req.StudentID != 0 {
+		student, err := s.Students.GetStudent(r.Context(), req.StudentID)
+		if err != nil {
+			http.Error(w, "Ученик не найден", http.StatusBadRequest)
+			return
+		}
+		if student.UserID != 0 {
+			http.Error(w, "У ученика уже есть учетная запись", http.StatusConflict)
+			return
+		}
+	}
+	if req.TeacherID != 0 {
+		teacher, err := s.Teachers.GetTeacher(r.Context(), req.TeacherID)
+		if err != nil {
+			http.Error(w, "Учитель не найден", http.StatusBadRequest)
+			return
+		}
+		if teacher.UserID != 0 {
+			http.Error(w, "У учителя уже есть учетная запись", http.StatusConflict)
+			return
+		}
+	}
+
+	code, err := randomToken(24)
+	if err != nil {
+		log.Printf("Ошибка при генерации кода приглашения: %v", err)
+		http.Error(w, "Ошибка при создании приглашения", http.StatusInternalServerError)
+		return
+	}
+	createdBy, _ := userIDFromContext(r)
+	inv := models.Invitation{
+		CodeHash:  hashToken(code),
+		Role:      req.Role,
+		StudentID: req.StudentID,
+		TeacherID: req.TeacherID,
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.Invitations.CreateInvitation(r.Context(), &inv); err != nil {
+		log.Printf("Ошибка при сохранении приглашения: %v", err)
+		http.Error(w, "Ошибка при создании приглашения", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Создано приглашение %d с ролью %s", inv.ID, inv.Role)
+	writeJSON(w, http.StatusCreated, invitationResponse{Invitation: inv, Code: code})
+}
+
+// GetInvitations возвращает все приглашения (без кодов)
+func (s *Server) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на получение списка приглашений")
+	invitations, err := s.Invitations.ListInvitations(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении приглашений: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if invitations == nil {
+		invitations = []models.Invitation{}
+	}
+	writeJSON(w, http.StatusOK, invitations)
+}
+
+// DeleteInvitation отзывает неиспользованное приглашение
+func (s *Server) DeleteInvitation(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на отзыв приглашения с ID: %d", id)
+
+	if err := s.Invitations.DeleteInvitation(r.Context(), id); err != nil {
+		log.Printf("Ошибка при отзыве приглашения %d: %v", id, err)
+		http.Error(w, "Активное приглашение не найдено", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Приглашение %d отозвано", id)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"school-system/backend/models"
+)
+
+func TestRegisterRequiresInvitation(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	deputy := createUser(t, st, "deputy", "deputy")
+	teacher := createUser(t, st, "teacher", "teacher")
+	student := &models.Student{FullName: "Петров Петр", ClassName: "9А"}
+	mustCreate(t, st.CreateStudent(context.Background(), student))
+
+	selfAssigned := map[string]string{"username": "hacker", "password": "password-1", "role": "deputy"}
+	if resp := postJSON(t, router, "/register", selfAssigned, ""); resp.Code != http.StatusForbidden {
+		t.Errorf("Регистрация без приглашения: ожидался статус 403, получен %d", resp.Code)
+	}
+
+	invite := InvitationRequest{Role: "student", StudentID: student.ID}
+	if resp := postJSON(t, router, "/invitations", invite, tokenFor(t, teacher)); resp.Code != http.StatusForbidden {
+		t.Errorf("Приглашение от учителя: ожидался статус 403, получен %d", resp.Code)
+	}
+	if resp := postJSON(t, router, "/invitations", InvitationRequest{Role: "teacher", StudentID: student.ID}, tokenFor(t, deputy)); resp.Code != http.StatusBadRequest {
+		t.Errorf("Карточка ученика в приглашении учителя: ожидался статус 400, получен %d", resp.Code)
+	}
+
+	resp := postJSON(t, router, "/invitations", invite, tokenFor(t, deputy))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Создание приглашения: ожидался статус 201, получен %d: %s", resp.Code, resp.Body.String())
+	}
+	var created invitationResponse
+	json.Unmarshal(resp.Body.Bytes(), &created)
+
+	// Роль из тела запроса игнорируется — она берется из приглашения
+	body := map[string]string{"username": "petrov", "password": "password-1", "role": "deputy", "invite_code": created.Code}
+	if resp := postJSON(t, router, "/register", body, ""); resp.Code != http.StatusCreated {
+		t.Fatalf("Регистрация по приглашению: ожидался статус 201, получен %d: %s", resp.Code, resp.Body.String())
+	}
+	user, err := st.GetUserByUsername(context.Background(), "petrov")
+	if err != nil || user.Role != "student" {
+		t.Fatalf("Ожидался пользователь с ролью student, получено %+v (%v)", user, err)
+	}
+	linked, _ := st.GetStudent(context.Background(), student.ID)
+	if linked.UserID != user.ID {
+		t.Errorf("Карточка ученика не привязана к учетной записи: user_id=%d", linked.UserID)
+	}
+
+	body["username"] = "petrov2"
+	if resp := postJSON(t, router, "/register", body, ""); resp.Code != http.StatusForbidden {
+		t.Errorf("Повторное использование приглашения: ожидался статус 403, получен %d", resp.Code)
+	}
+	if resp := postJSON(t, router, "/invitations", invite, tokenFor(t, deputy)); resp.Code != http.StatusConflict {
+		t.Errorf("Приглашение для уже привязанного ученика: ожидался статус 409, получен %d", resp.Code)
+	}
+}
+
+func TestInvitationRoleWithinInviterPermissions(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	mustCreate(t, st.CreateRole(context.Background(), &models.Role{Name: "secretary", Permissions: []string{models.PermUsersManage}}))
+	secretary := createUser(t, st, "secretary", "secretary")
+
+	for _, role := range []string{"deputy", "teacher"} {
+		if resp := postJSON(t, router, "/invitations", InvitationRequest{Role: role}, tokenFor(t, secretary)); resp.Code != http.StatusForbidden {
+			t.Errorf("Приглашение на роль %s без ее прав: ожидался статус 403, получен %d", role, resp.Code)
+		}
+	}
+	if resp := postJSON(t, router, "/invitations", InvitationRequest{Role: "parent"}, tokenFor(t, secretary)); resp.Code != http.StatusCreated {
+		t.Errorf("Приглашение на роль без прав: ожидался статус 201, получен %d: %s", resp.Code, resp.Body.String())
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
+
+	"school-system/backend/models"
+)
+
+// errInvalidCredentials — единое сообщение для неизвестного логина и неверного пароля,
+// чтобы по ответу нельзя было определить, существует ли пользователь
+const errInvalidCredentials = "Неверный логин или пароль"
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// checkPassword сравнивает пароль с хешем пользователя. Для несуществующего
+// пользователя сравнение выполняется с фиктивным хешем, чтобы время ответа не выдавало,
+// есть ли такой логин.
+func checkPassword(user *models.User, password string) bool {
+	if user == nil {
+		dummyHashOnce.Do(func() {
+			dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+		})
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil
+}
+
+// throttleUsername приводит логин к ключу счетчика
+func throttleUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// clientIP возвращает адрес клиента. Заголовок X-Forwarded-For не учитывается:
+// его может подделать сам клиент.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// loginDelay возвращает, на сколько блокируется вход после failures неудачных попыток подряд.
+// Первые две ошибки не блокируют, дальше задержка удваивается (1с, 2с, 4с, ...),
+// а после maxFailures попыток вход блокируется на lockout.
+func loginDelay(failures, maxFailures int, lockout time.Duration) time.Duration {
+	if failures >= maxFailures {
+		return lockout
+	}
+	if failures < 3 {
+		return 0
+	}
+	delay := time.Second << uint(failures-3)
+	if delay > lockout {
+		return lockout
+	}
+	return delay
+}
+
+// loginRetryAfter возвращает, сколько еще действует блокировка входа для логина или IP
+func (s *Server) loginRetryAfter(ctx context.Context, now time.Time, username, ip string) (time.Duration, error) {
+	var wait time.Duration
+	for _, k := range [][2]string{{models.ThrottleScopeUser, username}, {models.ThrottleScopeIP, ip}} {
+		throttle, err := s.LoginThrottles.GetLoginThrottle(ctx, k[0], k[1])
+		if err != nil {
+			if storeErrorStatus(err) == http.StatusNotFound {
+				continue
+			}
+			return 0, err
+		}
+		if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+			if left := throttle.LockedUntil.Sub(now); left > wait {
+				wait = left
+			}
+		}
+	}
+	return wait, nil
+}
+
+// recordLoginFailure учитывает неудачную попытку и при необходимости блокирует вход.
+// Для логина задержка растет с каждой ошибкой; для IP, за которым может быть
+// целый компьютерный класс, вход блокируется только после LoginIPMaxFailures ошибок.
+func (s *Server) recordLoginFailure(ctx context.Context, now time.Time, username, ip string) {
+	for _, k := range [][2]string{{models.ThrottleScopeUser, username}, {models.ThrottleScopeIP, ip}} {
+		throttle, err := s.LoginThrottles.RecordLoginFailure(ctx, k[0], k[1], now, s.Auth.LoginFailureWindow)
+		if err != nil {
+			log.Printf("Ошибка при учете неудачной попытки входа (%s %s): %v", k[0], k[1], err)
+			continue
+		}
+
+		var delay time.Duration
+		if k[0] == models.ThrottleScopeUser {
+			delay = loginDelay(throttle.Failures, s.Auth.LoginMaxFailures, s.Auth.LoginLockout)
+		} else if throttle.Failures >= s.Auth.LoginIPMaxFailures {
+			delay = s.Auth.LoginLockout
+		}
+		if delay == 0 {
+			continue
+		}
+		if err := s.LoginThrottles.LockLogin(ctx, k[0], k[1], now.Add(delay)); err != nil {
+			log.Printf("Ошибка при блокировке входа (%s %s): %v", k[0], k[1], err)
+			continue
+		}
+		if delay == s.Auth.LoginLockout {
+			log.Printf("Вход заблокирован на %v (%s %s) после %d неудачных попыток", delay, k[0], k[1], throttle.Failures)
+		}
+	}
+}
+
+// GetLoginLockouts возвращает счетчики неудачных попыток входа и активные блокировки (только завуч)
+func (s *Server) GetLoginLockouts(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на получение блокировок входа")
+	throttles, err := s.LoginThrottles.ListLoginThrottles(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении блокировок входа: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if throttles == nil {
+		throttles = []models.LoginThrottle{}
+	}
+	writeJSON(w, http.StatusOK, throttles)
+}
+
+// ClearLoginLockout снимает блокировку и обнуляет счетчик для логина или IP (только завуч)
+func (s *Server) ClearLoginLockout(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	scope, key := vars["scope"], vars["key"]
+	if scope != models.ThrottleScopeUser && scope != models.ThrottleScopeIP {
+		http.Error(w, "Недопустимая область. Допустимые значения: user, ip", http.StatusBadRequest)
+		return
+	}
+	if scope == models.ThrottleScopeUser {
+		key = throttleUsername(key)
+	}
+	log.Printf("Получен запрос на снятие блокировки входа: %s %s", scope, key)
+
+	if err := s.LoginThrottles.ClearLoginThrottle(r.Context(), scope, key); err != nil {
+		log.Printf("Ошибка при снятии блокировки входа (%s %s): %v", scope, key, err)
+		http.Error(w, "Блокировка не найдена", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Блокировка входа снята: %s %s", scope, key)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestLoginDelay(t *testing.T) {
+	lockout := 15 * time.Minute
+	cases := map[int]time.Duration{1: 0, 2: 0, 3: time.Second, 4: 2 * time.Second, 5: lockout, 9: lockout}
+	for failures, want := range cases {
+		if got := loginDelay(failures, 5, lockout); got != want {
+			t.Errorf("loginDelay(%d) = %v, ожидалось %v", failures, got, want)
+		}
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	deputy := createUser(t, st, "deputy", "deputy")
+	createUserWithPassword(t, st, "teacher", "correct-password", "teacher")
+
+	unknown := postJSON(t, router, "/login", Credentials{Username: "nobody", Password: "x"}, "")
+	wrong := postJSON(t, router, "/login", Credentials{Username: "teacher", Password: "x"}, "")
+	if unknown.Code != http.StatusUnauthorized || wrong.Code != http.StatusUnauthorized || unknown.Body.String() != wrong.Body.String() {
+		t.Errorf("Ответы для неизвестного логина и неверного пароля должны совпадать: %d %q / %d %q",
+			unknown.Code, unknown.Body.String(), wrong.Code, wrong.Body.String())
+	}
+
+	// Вторая и третья ошибка; после третьей включается задержка
+	postJSON(t, router, "/login", Credentials{Username: "teacher", Password: "x"}, "")
+	postJSON(t, router, "/login", Credentials{Username: "Teacher", Password: "x"}, "")
+
+	resp := postJSON(t, router, "/login", Credentials{Username: "teacher", Password: "correct-password"}, "")
+	if resp.Code != http.StatusTooManyRequests {
+		t.Fatalf("Во время блокировки: ожидался статус 429, получен %d", resp.Code)
+	}
+	if resp.Header().Get("Retry-After") == "" {
+		t.Error("Ответ 429 должен содержать заголовок Retry-After")
+	}
+
+	if resp := do(t, router, "GET", "/login-lockouts", nil, deputy); resp.Code != http.StatusOK {
+		t.Errorf("Список блокировок: ожидался статус 200, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "DELETE", "/login-lockouts/user/"+url.PathEscape("TEACHER"), nil, deputy); resp.Code != http.StatusNoContent {
+		t.Fatalf("Снятие блокировки: ожидался статус 204, получен %d", resp.Code)
+	}
+	login(t, router, "teacher", "correct-password")
+}
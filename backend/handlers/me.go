@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+
+	"school-system/backend/models"
+)
+
+// currentStudent находит карточку ученика, связанную с текущим пользователем
+func (s *Server) currentStudent(w http.ResponseWriter, r *http.Request) (*models.Student, bool) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	student, err := s.Students.GetStudentByUserID(r.Context(), userID)
+	if err != nil {
+		log.Printf("Ошибка при получении ученика для user_id=%d: %v", userID, err)
+		if storeErrorStatus(err) == http.StatusNotFound {
+			http.Error(w, "Ученик не найден", http.StatusNotFound)
+		} else {
+			http.Error(w, "Ошибка при получении ученика", http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	return student, true
+}
+
+// myGrades возвращает оценки текущего ученика, сгруппированные по предметам и четвертям.
+// При ошибке ответ уже отправлен.
+func (s *Server) myGrades(w http.ResponseWriter, r *http.Request) ([]models.SubjectGrades, bool) {
+	student, ok := s.currentStudent(w, r)
+	if !ok {
+		return nil, false
+	}
+	return s.groupedStudentGrades(w, r, student.ID)
+}
+
+// groupedStudentGrades возвращает оценки ученика за учебный год из параметров запроса,
+// сгруппированные по предметам и четвертям. При ошибке ответ уже отправлен.
+func (s *Server) groupedStudentGrades(w http.ResponseWriter, r *http.Request, studentID int) ([]models.SubjectGrades, bool) {
+	period, ok := periodFromRequest(w, r)
+	if !ok {
+		return nil, false
+	}
+	grades, err := s.Grades.GetStudentGrades(r.Context(), studentID, period)
+	if err != nil {
+		log.Printf("Ошибка при получении оценок ученика %d: %v", studentID, err)
+		http.Error(w, "Ошибка при получении оценок", http.StatusInternalServerError)
+		return nil, false
+	}
+	overrides, err := s.TermGrades.ListTermGrades(r.Context(), studentID, period)
+	if err != nil {
+		log.Printf("Ошибка при получении итоговых оценок ученика %d: %v", studentID, err)
+		http.Error(w, "Ошибка при получении оценок", http.StatusInternalServerError)
+		return nil, false
+	}
+	subjects := groupGrades(grades, overrides, s.Grading)
+	if err := s.attachScales(r.Context(), subjects); err != nil {
+		log.Printf("Ошибка при получении шкал оценивания: %v", err)
+		http.Error(w, "Ошибка при получении оценок", http.StatusInternalServerError)
+		return nil, false
+	}
+	return subjects, true
+}
+
+// attachScales добавляет к оценкам по предметам шкалы оценивания и подписи итоговых оценок
+func (s *Server) attachScales(ctx context.Context, subjects []models.SubjectGrades) error {
+	scales, err := s.subjectScales(ctx)
+	if err != nil {
+		return err
+	}
+	applyScales(subjects, scales)
+	return nil
+}
+
+// applyScales добавляет к оценкам по предметам уже загруженные шкалы предметов и подписи итоговых оценок
+func applyScales(subjects []models.SubjectGrades, scales map[int]models.GradingScale) {
+	for i := range subjects {
+		scale := scales[subjects[i].SubjectID]
+		subjects[i].Scale = scale
+		for q := range subjects[i].Quarters {
+			quarter := &subjects[i].Quarters[q]
+			quarter.Label = scale.Label(quarter.Final)
+		}
+	}
+}
+
+// groupGrades группирует оценки по предметам и четвертям, считает средневзвешенный балл
+// и итоговую оценку за каждую четверть; итоговые оценки учителя заменяют вычисленные
+func groupGrades(grades []models.GradeWithSubject, overrides []models.TermGrade, rules models.GradingRules) []models.SubjectGrades {
+	result := []models.SubjectGrades{}
+	index := make(map[int]int)
+	quarterOf := func(subjectID int, subjectName string, quarter int) *models.QuarterGrades {
+		i, ok := index[subjectID]
+		if !ok {
+			i = len(result)
+			index[subjectID] = i
+			result = append(result, models.SubjectGrades{SubjectID: subjectID, SubjectName: subjectName})
+		}
+		subject := &result[i]
+		q := 0
+		for q < len(subject.Quarters) && subject.Quarters[q].Quarter != quarter {
+			q++
+		}
+		if q == len(subject.Quarters) {
+			subject.Quarters = append(subject.Quarters, models.QuarterGrades{Quarter: quarter, Grades: []models.Grade{}})
+		}
+		return &subject.Quarters[q]
+	}
+	for _, g := range grades {
+		quarter := quarterOf(g.SubjectID, g.SubjectName, g.Quarter)
+		quarter.Grades = append(quarter.Grades, g.Grade)
+	}
+	for i := range result {
+		for q := range result[i].Quarters {
+			quarter := &result[i].Quarters[q]
+			average := rules.Average(quarter.Grades)
+			quarter.Average = roundAverage(average)
+			quarter.Computed = rules.Round(average)
+			quarter.Final = quarter.Computed
+		}
+	}
+	for _, o := range overrides {
+		quarter := quarterOf(o.SubjectID, o.SubjectName, o.Quarter)
+		quarter.Final = o.Grade
+		quarter.Overridden = true
+	}
+
+	for i := range result {
+		quarters := result[i].Quarters
+		sort.SliceStable(quarters, func(a, b int) bool { return quarters[a].Quarter < quarters[b].Quarter })
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].SubjectName < result[j].SubjectName })
+	return result
+}
+
+// roundAverage округляет средний балл до сотых
+func roundAverage(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// GetMyGrades возвращает оценки текущего ученика по предметам и четвертям
+func (s *Server) GetMyGrades(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на получение оценок текущего ученика")
+	subjects, ok := s.myGrades(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, subjects)
+}
+
+// averagesResponse — средние баллы ученика по предметам и по четвертям в целом
+type averagesResponse struct {
+	BySubject []models.SubjectAverage `json:"by_subject"`
+	ByQuarter []models.QuarterAverage `json:"by_quarter"`
+}
+
+// GetMyAverages возвращает средние баллы текущего ученика
+func (s *Server) GetMyAverages(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на получение средних баллов текущего ученика")
+	subjects, ok := s.myGrades(w, r)
+	if !ok {
+		return
+	}
+	s.writeAverages(w, r, subjects)
+}
+
+// writeAverages отправляет средние баллы по сгруппированным оценкам
+func (s *Server) writeAverages(w http.ResponseWriter, r *http.Request, subjects []models.SubjectGrades) {
+	school, err := s.GradingScales.GetDefaultGradingScale(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении шкалы школы: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, averagesOf(subjects, *school))
+}
+
+// averagesOf считает средние баллы по сгруппированным оценкам. Средние по предметам остаются
+// в шкале предмета, а средний балл за четверть считается по средним баллам предметов,
+// приведенным к шкале школы school.
+func averagesOf(subjects []models.SubjectGrades, school models.GradingScale) averagesResponse {
+	resp := averagesResponse{BySubject: []models.SubjectAverage{}, ByQuarter: []models.QuarterAverage{}}
+	sums := make(map[int]float64)
+	counts := make(map[int]int)
+	for _, subject := range subjects {
+		for _, q := range subject.Quarters {
+			// Четверть только с итоговой оценкой учителя не дает среднего балла
+			if len(q.Grades) == 0 {
+				continue
+			}
+			resp.BySubject = append(resp.BySubject, models.SubjectAverage{
+				SubjectName: subject.SubjectName,
+				Quarter:     q.Quarter,
+				Average:     q.Average,
+			})
+			sums[q.Quarter] += subject.Scale.Normalize(q.Average, school)
+			counts[q.Quarter]++
+		}
+	}
+	for quarter, sum := range sums {
+		resp.ByQuarter = append(resp.ByQuarter, models.QuarterAverage{
+			Quarter: quarter,
+			Average: roundAverage(sum / float64(counts[quarter])),
+		})
+	}
+	sort.Slice(resp.ByQuarter, func(i, j int) bool { return resp.ByQuarter[i].Quarter < resp.ByQuarter[j].Quarter })
+	return resp
+}
+
+// studentTeachers возвращает учителей ученика по предметам: из последнего зачисления с учителем,
+// иначе из назначения в класс ученика. Предметов без учителя в результате нет.
+func (s *Server) studentTeachers(ctx context.Context, student *models.Student) (map[int]int, error) {
+	enrollments, err := s.Enrollments.ListEnrollments(ctx, models.EnrollmentFilter{StudentID: student.ID})
+	if err != nil {
+		return nil, err
+	}
+	teachers := make(map[int]int)
+	for _, e := range enrollments {
+		if _, ok := teachers[e.SubjectID]; !ok && e.TeacherID != 0 {
+			teachers[e.SubjectID] = e.TeacherID
+		}
+	}
+	if student.ClassID == 0 {
+		return teachers, nil
+	}
+	assignments, err := s.Assignments.ListTeachingAssignments(ctx, models.TeachingAssignmentFilter{ClassID: student.ClassID})
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range assignments {
+		if _, ok := teachers[a.SubjectID]; !ok {
+			teachers[a.SubjectID] = a.TeacherID
+		}
+	}
+	return teachers, nil
+}
+
+// mySubject — предмет ученика вместе с именем учителя
+type mySubject struct {
+	models.Subject
+	TeacherName string `json:"teacher_name"`
+}
+
+// GetMySubjects возвращает предметы, по которым у текущего ученика есть оценки
+func (s *Server) GetMySubjects(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на получение предметов текущего ученика")
+	student, ok := s.currentStudent(w, r)
+	if !ok {
+		return
+	}
+	grades, ok := s.groupedStudentGrades(w, r, student.ID)
+	if !ok {
+		return
+	}
+
+	subjects, err := s.Subjects.ListSubjects(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении предметов: %v", err)
+		http.Error(w, "Ошибка при получении предметов", http.StatusInternalServerError)
+		return
+	}
+	teachers, err := s.Teachers.ListTeachers(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении учителей: %v", err)
+		http.Error(w, "Ошибка при получении предметов", http.StatusInternalServerError)
+		return
+	}
+	subjectTeachers, err := s.studentTeachers(r.Context(), student)
+	if err != nil {
+		log.Printf("Ошибка при получении учителей ученика %d: %v", student.ID, err)
+		http.Error(w, "Ошибка при получении предметов", http.StatusInternalServerError)
+		return
+	}
+	subjectsByID := make(map[int]models.Subject, len(subjects))
+	for _, subject := range subjects {
+		subjectsByID[subject.ID] = subject
+	}
+	teacherNames := make(map[int]string, len(teachers))
+	for _, teacher := range teachers {
+		teacherNames[teacher.ID] = teacher.FullName
+	}
+
+	result := []mySubject{}
+	for _, g := range grades {
+		subject, ok := subjectsByID[g.SubjectID]
+		if !ok {
+			log.Printf("Предмет %d из оценок ученика %d не найден", g.SubjectID, student.ID)
+			http.Error(w, "Ошибка при получении предметов", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, mySubject{Subject: subject, TeacherName: teacherNames[subjectTeachers[subject.ID]]})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
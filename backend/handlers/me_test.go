@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// brokenEnrollments — зачисления, которые не удается прочитать
+type brokenEnrollments struct {
+	store.EnrollmentStore
+}
+
+func (brokenEnrollments) ListEnrollments(ctx context.Context, f models.EnrollmentFilter) ([]models.Enrollment, error) {
+	return nil, errors.New("хранилище недоступно")
+}
+
+func TestStudentSelfService(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+
+	pupilUser := createUser(t, st, "pupil", "student")
+	teacherUser := createUser(t, st, "teacher", "teacher")
+	teacher := &models.Teacher{FullName: "Иванова Анна", UserID: teacherUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, teacher))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	class := assignTeacher(t, st, teacher.ID, math.ID)
+	pupil := &models.Student{FullName: "Иван Иванов", ClassID: class.ID, UserID: pupilUser.ID}
+	mustCreate(t, st.CreateStudent(ctx, pupil))
+	other := &models.Student{FullName: "Петр Петров", ClassID: class.ID}
+	mustCreate(t, st.CreateStudent(ctx, other))
+	physics := &models.Subject{Name: "Физика"}
+	mustCreate(t, st.CreateSubject(ctx, physics))
+
+	for _, g := range []models.Grade{
+		{StudentID: pupil.ID, SubjectID: math.ID, Grade: 5, TermID: termID(t, st, 1)},
+		{StudentID: pupil.ID, SubjectID: math.ID, Grade: 4, TermID: termID(t, st, 1)},
+		{StudentID: pupil.ID, SubjectID: math.ID, Grade: 3, TermID: termID(t, st, 2)},
+		{StudentID: pupil.ID, SubjectID: physics.ID, Grade: 3, TermID: termID(t, st, 1)},
+		{StudentID: other.ID, SubjectID: physics.ID, Grade: 2, TermID: termID(t, st, 1)},
+	} {
+		mustCreate(t, st.CreateGrade(ctx, &g))
+	}
+
+	resp := do(t, router, "GET", "/me/grades", nil, pupilUser)
+	var grades []models.SubjectGrades
+	json.Unmarshal(resp.Body.Bytes(), &grades)
+	if resp.Code != http.StatusOK || len(grades) != 2 {
+		t.Fatalf("Ожидались оценки по двум предметам, получено %d %+v", resp.Code, grades)
+	}
+	if grades[0].SubjectName != "Математика" || len(grades[0].Quarters) != 2 || grades[0].Quarters[0].Average != 4.5 {
+		t.Errorf("Неверная группировка оценок по математике: %+v", grades[0])
+	}
+
+	resp = do(t, router, "GET", "/me/averages", nil, pupilUser)
+	var averages averagesResponse
+	json.Unmarshal(resp.Body.Bytes(), &averages)
+	if len(averages.ByQuarter) != 2 || averages.ByQuarter[0].Average != 3.75 || averages.ByQuarter[1].Average != 3 {
+		t.Errorf("Неверные средние баллы по четвертям: %+v", averages.ByQuarter)
+	}
+
+	resp = do(t, router, "GET", "/me/subjects", nil, pupilUser)
+	var subjects []mySubject
+	json.Unmarshal(resp.Body.Bytes(), &subjects)
+	if len(subjects) != 2 || subjects[0].TeacherName != "Иванова Анна" {
+		t.Errorf("Неверный список предметов: %+v", subjects)
+	}
+
+	if resp := do(t, router, "GET", "/me/grades", nil, teacherUser); resp.Code != http.StatusNotFound {
+		t.Errorf("Пользователь без карточки ученика: ожидался статус 404, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "GET", "/me/grades", nil, nil); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Без токена: ожидался статус 401, получен %d", resp.Code)
+	}
+}
+
+func TestMySubjectsStoreError(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+
+	pupilUser := createUser(t, st, "pupil", "student")
+	pupil := &models.Student{FullName: "Иван Иванов", UserID: pupilUser.ID}
+	mustCreate(t, st.CreateStudent(ctx, pupil))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	mustCreate(t, st.CreateGrade(ctx, &models.Grade{StudentID: pupil.ID, SubjectID: math.ID, Grade: 5, TermID: termID(t, st, 1)}))
+
+	s.Enrollments = brokenEnrollments{st}
+	if resp := do(t, router, "GET", "/me/subjects", nil, pupilUser); resp.Code != http.StatusInternalServerError {
+		t.Errorf("Ошибка хранилища: ожидался статус 500, получен %d %s", resp.Code, resp.Body.String())
+	}
+}
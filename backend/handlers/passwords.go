@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"school-system/backend/models"
+)
+
+// minPasswordLength — минимальная длина пароля в символах
+const minPasswordLength = 8
+
+// validatePassword проверяет требования к новому паролю
+func validatePassword(password string) error {
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return errors.New("Пароль должен содержать не менее 8 символов")
+	}
+	return nil
+}
+
+// hashPassword возвращает bcrypt-хеш пароля
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ChangePassword меняет пароль текущего пользователя. Все прочие сессии
+// завершаются, в ответе возвращается новая пара токенов.
+func (s *Server) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Получен запрос на смену пароля пользователя %d", userID)
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.Users.GetUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Ошибка при получении пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при смене пароля", storeErrorStatus(err))
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		log.Printf("Неверный текущий пароль пользователя %d", userID)
+		http.Error(w, "Неверный текущий пароль", http.StatusForbidden)
+		return
+	}
+	if err := validatePassword(req.NewPassword); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.NewPassword == req.CurrentPassword {
+		http.Error(w, "Новый пароль должен отличаться от текущего", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := hashPassword(req.NewPassword)
+	if err != nil {
+		log.Printf("Ошибка при хешировании пароля: %v", err)
+		http.Error(w, "Ошибка при смене пароля", http.StatusInternalServerError)
+		return
+	}
+	if err := s.Users.UpdatePassword(r.Context(), userID, hash, false); err != nil {
+		log.Printf("Ошибка при сохранении пароля пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при смене пароля", storeErrorStatus(err))
+		return
+	}
+	if err := s.Tokens.RevokeUserTokens(r.Context(), userID); err != nil {
+		log.Printf("Ошибка при отзыве сессий пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при смене пароля", http.StatusInternalServerError)
+		return
+	}
+
+	user.Password = hash
+	user.MustChangePassword = false
+	tokens, err := s.issueTokens(r.Context(), user, nil)
+	if err != nil {
+		log.Printf("Ошибка при генерации токена для пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка генерации токена", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Пользователь %d сменил пароль", userID)
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// passwordResetResponse — одноразовый токен сброса, который завуч передает пользователю
+type passwordResetResponse struct {
+	UserID     int       `json:"user_id"`
+	ResetToken string    `json:"reset_token"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// CreatePasswordReset выдает одноразовый токен сброса пароля пользователю (только завуч)
+func (s *Server) CreatePasswordReset(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на сброс пароля пользователя %d", id)
+
+	if _, err := s.Users.GetUser(r.Context(), id); err != nil {
+		log.Printf("Ошибка при получении пользователя %d: %v", id, err)
+		http.Error(w, "Пользователь не найден", storeErrorStatus(err))
+		return
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		log.Printf("Ошибка при генерации токена сброса: %v", err)
+		http.Error(w, "Ошибка при сбросе пароля", http.StatusInternalServerError)
+		return
+	}
+	createdBy, _ := userIDFromContext(r)
+	reset := &models.PasswordReset{
+		UserID:    id,
+		TokenHash: hashToken(token),
+		CreatedBy: createdBy,
+		ExpiresAt: time.Now().Add(s.Auth.PasswordResetTTL),
+	}
+	if err := s.Users.CreatePasswordReset(r.Context(), reset); err != nil {
+		log.Printf("Ошибка при сохранении токена сброса для пользователя %d: %v", id, err)
+		http.Error(w, "Ошибка при сбросе пароля", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Пользователь %d выдал токен сброса пароля пользователю %d", createdBy, id)
+	writeJSON(w, http.StatusCreated, passwordResetResponse{UserID: id, ResetToken: token, ExpiresAt: reset.ExpiresAt})
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ResetPassword устанавливает новый пароль по одноразовому токену сброса
+func (s *Server) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на установку пароля по токену сброса")
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+		return
+	}
+	if err := validatePassword(req.NewPassword); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hash, err := hashPassword(req.NewPassword)
+	if err != nil {
+		log.Printf("Ошибка при хешировании пароля: %v", err)
+		http.Error(w, "Ошибка при сбросе пароля", http.StatusInternalServerError)
+		return
+	}
+
+	userID, err := s.Users.ResetPassword(r.Context(), hashToken(req.Token), hash, time.Now())
+	if err != nil {
+		if storeErrorStatus(err) == http.StatusNotFound {
+			http.Error(w, "Недействительный или истекший токен сброса", http.StatusBadRequest)
+			return
+		}
+		log.Printf("Ошибка при сбросе пароля: %v", err)
+		http.Error(w, "Ошибка при сбросе пароля", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Пароль пользователя %d сброшен", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
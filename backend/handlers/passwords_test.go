@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestNewTeacherMustChangePassword(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	deputy := createUser(t, st, "deputy", "deputy")
+
+	resp := postJSON(t, router, "/teachers", map[string]string{"full_name": "Иванова Анна Петровна"}, tokenFor(t, deputy))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Создание учителя: ожидался статус 201, получен %d: %s", resp.Code, resp.Body.String())
+	}
+	var created createdTeacher
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	if created.TemporaryPassword == "" || created.TemporaryPassword == "password123" {
+		t.Fatalf("Ожидался случайный временный пароль, получен %q", created.TemporaryPassword)
+	}
+
+	first := login(t, router, created.Username, created.TemporaryPassword)
+	if !first.MustChangePassword {
+		t.Error("После входа с временным паролем должен требоваться его смена")
+	}
+	if resp := getWithToken(router, "/teacher/my-students", first.Token); resp.Code != http.StatusForbidden {
+		t.Errorf("До смены пароля: ожидался статус 403, получен %d", resp.Code)
+	}
+
+	change := ChangePasswordRequest{CurrentPassword: "wrong-password", NewPassword: "new-password-1"}
+	if resp := sendJSON(t, router, "PUT", "/me/password", change, first.Token); resp.Code != http.StatusForbidden {
+		t.Errorf("Неверный текущий пароль: ожидался статус 403, получен %d", resp.Code)
+	}
+	change = ChangePasswordRequest{CurrentPassword: created.TemporaryPassword, NewPassword: "short"}
+	if resp := sendJSON(t, router, "PUT", "/me/password", change, first.Token); resp.Code != http.StatusBadRequest {
+		t.Errorf("Короткий пароль: ожидался статус 400, получен %d", resp.Code)
+	}
+
+	change = ChangePasswordRequest{CurrentPassword: created.TemporaryPassword, NewPassword: "new-password-1"}
+	resp = sendJSON(t, router, "PUT", "/me/password", change, first.Token)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Смена пароля: ожидался статус 200, получен %d: %s", resp.Code, resp.Body.String())
+	}
+	var second tokenResponse
+	json.Unmarshal(resp.Body.Bytes(), &second)
+	if second.MustChangePassword {
+		t.Error("После смены пароля флаг must_change_password должен сниматься")
+	}
+	if resp := getWithToken(router, "/teacher/my-students", second.Token); resp.Code != http.StatusOK {
+		t.Errorf("После смены пароля: ожидался статус 200, получен %d", resp.Code)
+	}
+	if resp := getWithToken(router, "/verify-token", first.Token); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Старая сессия должна быть отозвана, получен %d", resp.Code)
+	}
+	login(t, router, created.Username, "new-password-1")
+}
+
+func TestPasswordResetIsSingleUse(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	deputy := createUser(t, st, "deputy", "deputy")
+	user := createUserWithPassword(t, st, "teacher", "forgotten-1", "teacher")
+	session := login(t, router, "teacher", "forgotten-1")
+
+	if resp := postJSON(t, router, "/users/999/password-reset", nil, tokenFor(t, deputy)); resp.Code != http.StatusNotFound {
+		t.Errorf("Сброс для несуществующего пользователя: ожидался статус 404, получен %d", resp.Code)
+	}
+	if resp := postJSON(t, router, "/users/1/password-reset", nil, tokenFor(t, user)); resp.Code != http.StatusForbidden {
+		t.Errorf("Сброс пароля учителем: ожидался статус 403, получен %d", resp.Code)
+	}
+
+	resp := postJSON(t, router, "/users/2/password-reset", nil, tokenFor(t, deputy))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("Выдача токена сброса: ожидался статус 201, получен %d", resp.Code)
+	}
+	var reset passwordResetResponse
+	json.Unmarshal(resp.Body.Bytes(), &reset)
+
+	body := ResetPasswordRequest{Token: reset.ResetToken, NewPassword: "brand-new-1"}
+	if resp := postJSON(t, router, "/password-reset", body, ""); resp.Code != http.StatusNoContent {
+		t.Fatalf("Сброс пароля: ожидался статус 204, получен %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := postJSON(t, router, "/password-reset", body, ""); resp.Code != http.StatusBadRequest {
+		t.Errorf("Повторный сброс тем же токеном: ожидался статус 400, получен %d", resp.Code)
+	}
+	if resp := getWithToken(router, "/verify-token", session.Token); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Сессии пользователя после сброса должны быть отозваны, получен %d", resp.Code)
+	}
+	login(t, router, "teacher", "brand-new-1")
+}
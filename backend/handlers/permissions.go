@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/gorilla/mux"
+
+	"school-system/backend/models"
+)
+
+// roleNamePattern — имя роли: до 20 символов (как в таблице roles), латиница, цифры, "_" и "-"
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,19}$`)
+
+// managerRole — роль, которая не может лишиться права управлять ролями,
+// иначе в школе не останется никого, кто может вернуть права
+const managerRole = "deputy"
+
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// GetPermissions возвращает список всех прав
+func (s *Server) GetPermissions(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на получение списка прав")
+	permissions, err := s.Permissions.ListPermissions(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении прав: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if permissions == nil {
+		permissions = []models.Permission{}
+	}
+	writeJSON(w, http.StatusOK, permissions)
+}
+
+// GetRoles возвращает роли вместе с их правами
+func (s *Server) GetRoles(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на получение списка ролей")
+	roles, err := s.Permissions.ListRoles(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении ролей: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if roles == nil {
+		roles = []models.Role{}
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// CreateRole создает новую роль с указанными правами
+func (s *Server) CreateRole(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на создание роли")
+	var req RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Некорректные данные", http.StatusBadRequest)
+		return
+	}
+	if !roleNamePattern.MatchString(req.Name) {
+		http.Error(w, "Имя роли: 2-20 символов, латиница в нижнем регистре, цифры, _ и -", http.StatusBadRequest)
+		return
+	}
+	if unknown := unknownPermission(req.Permissions); unknown != "" {
+		http.Error(w, "Неизвестное право: "+unknown, http.StatusBadRequest)
+		return
+	}
+
+	role := models.Role{Name: req.Name, Description: req.Description, Permissions: dedupe(req.Permissions)}
+	if err := s.Permissions.CreateRole(r.Context(), &role); err != nil {
+		log.Printf("Ошибка при создании роли %q: %v", req.Name, err)
+		if storeErrorStatus(err) == http.StatusConflict {
+			http.Error(w, "Роль с таким именем уже существует", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Ошибка при создании роли", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Создана роль %s с правами %v", role.Name, role.Permissions)
+	writeJSON(w, http.StatusCreated, role)
+}
+
+// SetRolePermissions заменяет набор прав роли
+func (s *Server) SetRolePermissions(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	log.Printf("Получен запрос на изменение прав роли %s", name)
+	var req RolePermissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Некорректные данные", http.StatusBadRequest)
+		return
+	}
+	if unknown := unknownPermission(req.Permissions); unknown != "" {
+		http.Error(w, "Неизвестное право: "+unknown, http.StatusBadRequest)
+		return
+	}
+	permissions := dedupe(req.Permissions)
+	if name == managerRole && !contains(permissions, models.PermPermissionsManage) {
+		http.Error(w, "Нельзя отнять у завуча право permissions:manage", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Permissions.SetRolePermissions(r.Context(), name, permissions); err != nil {
+		log.Printf("Ошибка при изменении прав роли %s: %v", name, err)
+		http.Error(w, "Роль не найдена", storeErrorStatus(err))
+		return
+	}
+	role, err := s.Permissions.GetRole(r.Context(), name)
+	if err != nil {
+		log.Printf("Ошибка при получении роли %s: %v", name, err)
+		http.Error(w, "Ошибка при получении данных", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Права роли %s изменены: %v", name, role.Permissions)
+	writeJSON(w, http.StatusOK, role)
+}
+
+// DeleteRole удаляет роль, если это не встроенная роль и у нее нет пользователей
+func (s *Server) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	log.Printf("Получен запрос на удаление роли %s", name)
+	if models.IsDefaultRole(name) {
+		http.Error(w, "Встроенную роль удалить нельзя", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Permissions.DeleteRole(r.Context(), name); err != nil {
+		log.Printf("Ошибка при удалении роли %s: %v", name, err)
+		switch storeErrorStatus(err) {
+		case http.StatusNotFound:
+			http.Error(w, "Роль не найдена", http.StatusNotFound)
+		case http.StatusBadRequest:
+			http.Error(w, "У роли есть пользователи", http.StatusBadRequest)
+		default:
+			http.Error(w, "Ошибка при удалении роли", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("Роль %s удалена", name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unknownPermission возвращает первое право из списка, которого нет в приложении
+func unknownPermission(names []string) string {
+	for _, name := range names {
+		found := false
+		for _, p := range models.Permissions {
+			if p.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return name
+		}
+	}
+	return ""
+}
+
+// dedupe убирает повторы, сохраняя порядок
+func dedupe(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if !contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
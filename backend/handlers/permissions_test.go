@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"school-system/backend/models"
+)
+
+func TestCustomRolePermissions(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+
+	deputy := createUser(t, st, "deputy", "deputy")
+	body, _ := json.Marshal(RoleRequest{Name: "head_teacher", Description: "Классный руководитель", Permissions: []string{models.PermGradesWrite}})
+	if resp := do(t, router, "POST", "/roles", bytes.NewReader(body), deputy); resp.Code != http.StatusCreated {
+		t.Fatalf("Создание роли: ожидался статус 201, получен %d: %s", resp.Code, resp.Body.String())
+	}
+	head := createUser(t, st, "head", "head_teacher")
+
+	teacher := &models.Teacher{FullName: "Петрова Анна", UserID: head.ID}
+	mustCreate(t, st.CreateTeacher(ctx, teacher))
+	subject := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, subject))
+	class := assignTeacher(t, st, teacher.ID, subject.ID)
+	student := &models.Student{FullName: "Иван Иванов", ClassID: class.ID}
+	mustCreate(t, st.CreateStudent(ctx, student))
+
+	grade, _ := json.Marshal(models.Grade{StudentID: student.ID, SubjectID: subject.ID, Grade: 4, Quarter: 1})
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(grade), head); resp.Code != http.StatusCreated {
+		t.Errorf("Своя роль с grades:write: ожидался статус 201, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "DELETE", "/students/1", nil, head); resp.Code != http.StatusForbidden {
+		t.Errorf("Своя роль без students:delete: ожидался статус 403, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "GET", "/roles", nil, head); resp.Code != http.StatusForbidden {
+		t.Errorf("Своя роль без permissions:manage: ожидался статус 403, получен %d", resp.Code)
+	}
+
+	// После выдачи права удаление разрешено
+	body, _ = json.Marshal(RolePermissionsRequest{Permissions: []string{models.PermGradesWrite, models.PermStudentsDelete}})
+	if resp := do(t, router, "PUT", "/roles/head_teacher/permissions", bytes.NewReader(body), deputy); resp.Code != http.StatusOK {
+		t.Fatalf("Изменение прав: ожидался статус 200, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "DELETE", "/students/1", nil, head); resp.Code != http.StatusNoContent {
+		t.Errorf("Своя роль с students:delete: ожидался статус 204, получен %d", resp.Code)
+	}
+
+	if resp := do(t, router, "DELETE", "/roles/head_teacher", nil, deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Удаление роли с пользователями: ожидался статус 400, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "DELETE", "/roles/teacher", nil, deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Удаление встроенной роли: ожидался статус 400, получен %d", resp.Code)
+	}
+}
+
+func TestDeputyKeepsPermissionsManage(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	deputy := createUser(t, st, "deputy", "deputy")
+
+	body, _ := json.Marshal(RolePermissionsRequest{Permissions: []string{models.PermStatsRead}})
+	if resp := do(t, router, "PUT", "/roles/deputy/permissions", bytes.NewReader(body), deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус 400, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "GET", "/roles", nil, deputy); resp.Code != http.StatusOK {
+		t.Errorf("Завуч должен сохранить доступ к ролям, получен статус %d", resp.Code)
+	}
+
+	body, _ = json.Marshal(RolePermissionsRequest{Permissions: []string{"grades:everything"}})
+	if resp := do(t, router, "PUT", "/roles/teacher/permissions", bytes.NewReader(body), deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Неизвестное право: ожидался статус 400, получен %d", resp.Code)
+	}
+}
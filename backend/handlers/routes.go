@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"school-system/backend/middleware"
+	"school-system/backend/models"
+)
+
+// authenticated требует действительный JWT
+func authenticated(h http.HandlerFunc) http.Handler {
+	return middleware.AuthMiddleware(h)
+}
+
+// authenticatedPending требует действительный JWT, но пропускает пользователей,
+// которым еще нужно выполнить обязательное действие (например, сменить пароль)
+func authenticatedPending(h http.HandlerFunc) http.Handler {
+	return middleware.AllowPending(h)
+}
+
+// withPermission требует действительный JWT и право у роли пользователя
+func withPermission(h http.HandlerFunc, permission string) http.Handler {
+	return middleware.AuthMiddleware(middleware.RequirePermission(permission)(h))
+}
+
+// Router регистрирует все маршруты API
+func (s *Server) Router() *mux.Router {
+	r := mux.NewRouter()
+
+	// ====== GET Requests ======
+	log.Printf("Регистрация GET маршрутов...")
+	r.HandleFunc("/students", s.GetStudents).Methods("GET")
+	r.HandleFunc("/teachers", s.GetTeachers).Methods("GET")
+	r.HandleFunc("/subjects", s.GetSubjects).Methods("GET")
+	r.HandleFunc("/classes", s.GetClasses).Methods("GET")
+	r.HandleFunc("/classes/{id}", s.GetClass).Methods("GET")
+	r.HandleFunc("/classes/{id}/students", s.GetClassStudents).Methods("GET")
+
+	// Получение оценок — только для авторизованных пользователей
+	r.Handle("/grades", authenticated(s.GetGrades)).Methods("GET")
+
+	// Получение оценок конкретного студента
+	r.Handle("/grades/student/{id}", authenticated(s.GetStudentGrades)).Methods("GET")
+
+	// Итоговые оценки за период: вычисленные по отметкам или выставленные учителем
+	r.Handle("/students/{id}/term-grades", authenticated(s.GetStudentTermGrades)).Methods("GET")
+
+	// Годовые оценки: у закрытого года — зафиксированные при закрытии
+	r.Handle("/students/{id}/annual-grades", authenticated(s.GetStudentAnnualGrades)).Methods("GET")
+
+	// Маршруты для завуча
+	r.Handle("/students/failing", withPermission(s.GetFailingStudents, models.PermStatsRead)).Methods("GET")
+	r.Handle("/grades/average-by-class", withPermission(s.GetAverageGradesByClass, models.PermStatsRead)).Methods("GET")
+
+	// Маршруты для ученика: данные берутся по карточке, привязанной к учетной записи
+	r.Handle("/me/grades", authenticated(s.GetMyGrades)).Methods("GET")
+	r.Handle("/me/averages", authenticated(s.GetMyAverages)).Methods("GET")
+	r.Handle("/me/subjects", authenticated(s.GetMySubjects)).Methods("GET")
+
+	// Маршруты для родителя: доступны только дети, привязанные к учетной записи
+	r.Handle("/parent/children", authenticated(s.GetMyChildren)).Methods("GET")
+	r.Handle("/parent/children/{id}/grades", authenticated(s.GetChildGrades)).Methods("GET")
+	r.Handle("/parent/children/{id}/averages", authenticated(s.GetChildAverages)).Methods("GET")
+	r.Handle("/parent/warnings", authenticated(s.GetChildrenWarnings)).Methods("GET")
+
+	// Маршруты для учителя
+	r.Handle("/teacher/my-students", withPermission(s.GetMyStudents, models.PermGradesWrite)).Methods("GET")
+	r.Handle("/teacher/my-students/grades", withPermission(s.GetMyStudentsGrades, models.PermGradesWrite)).Methods("GET")
+
+	// Статистика
+	r.Handle("/stats/students-count", authenticated(s.GetStudentsCount)).Methods("GET")
+	r.Handle("/stats/teachers-count", authenticated(s.GetTeachersCount)).Methods("GET")
+	r.Handle("/stats/average-grade", authenticated(s.GetAverageGrade)).Methods("GET")
+	r.Handle("/stats/class-performance", authenticated(s.GetClassPerformance)).Methods("GET")
+	r.Handle("/stats/average-grades", withPermission(s.GetAverageGradesByClass, models.PermStatsRead)).Methods("GET")
+	r.Handle("/stats/failing-students", withPermission(s.GetFailingStudents, models.PermStatsRead)).Methods("GET")
+	r.Handle("/stats/top-worst-classes", withPermission(s.GetTopAndWorstClasses, models.PermStatsRead)).Methods("GET")
+	r.Handle("/stats/absence-by-student", withPermission(s.GetAbsenceByStudent, models.PermStatsRead)).Methods("GET")
+	r.Handle("/stats/absence-by-class", withPermission(s.GetAbsenceByClass, models.PermStatsRead)).Methods("GET")
+
+	// ====== POST Requests ======
+	log.Printf("Регистрация POST маршрутов...")
+	r.Handle("/students", withPermission(s.CreateStudent, models.PermStudentsWrite)).Methods("POST")
+	r.Handle("/subjects", withPermission(s.CreateSubject, models.PermSubjectsWrite)).Methods("POST")
+	r.Handle("/teachers", withPermission(s.CreateTeacher, models.PermTeachersWrite)).Methods("POST")
+
+	// Создание оценки — для ролей с правом grades:write
+	r.Handle("/grades", withPermission(s.CreateGrade, models.PermGradesWrite)).Methods("POST")
+
+	// ====== PUT Requests ======
+	log.Printf("Регистрация PUT маршрутов...")
+	r.Handle("/students/{id}", withPermission(s.UpdateStudent, models.PermStudentsWrite)).Methods("PUT")
+	r.Handle("/teachers/{id}", withPermission(s.UpdateTeacher, models.PermTeachersWrite)).Methods("PUT")
+	r.Handle("/subjects/{id}", withPermission(s.UpdateSubject, models.PermSubjectsWrite)).Methods("PUT")
+	r.Handle("/grades/{id}", withPermission(s.UpdateGrade, models.PermGradesWrite)).Methods("PUT")
+
+	// ====== DELETE Requests ======
+	log.Printf("Регистрация DELETE маршрутов...")
+	r.Handle("/students/{id}", withPermission(s.DeleteStudent, models.PermStudentsDelete)).Methods("DELETE")
+	r.Handle("/teachers/{id}", withPermission(s.DeleteTeacher, models.PermTeachersDelete)).Methods("DELETE")
+	r.Handle("/subjects/{id}", withPermission(s.DeleteSubject, models.PermSubjectsDelete)).Methods("DELETE")
+
+	// Удаление оценки — для ролей с правом grades:delete
+	r.Handle("/grades/{id}", withPermission(s.DeleteGrade, models.PermGradesDelete)).Methods("DELETE")
+
+	// ====== Аутентификация ======
+	log.Printf("Регистрация маршрутов аутентификации...")
+	r.HandleFunc("/login", s.Login).Methods("POST")
+	r.HandleFunc("/register", s.Register).Methods("POST")
+	r.HandleFunc("/login/2fa", s.LoginTwoFactor).Methods("POST")
+	r.HandleFunc("/refresh", s.Refresh).Methods("POST")
+	r.Handle("/logout", authenticatedPending(s.Logout)).Methods("POST")
+	r.Handle("/logout-all", authenticatedPending(s.LogoutAll)).Methods("POST")
+	r.Handle("/verify-token", authenticatedPending(s.VerifyToken)).Methods("GET")
+
+	// ====== Двухфакторная аутентификация ======
+	r.Handle("/me/2fa/setup", authenticatedPending(s.SetupTwoFactor)).Methods("POST")
+	r.Handle("/me/2fa/confirm", authenticatedPending(s.ConfirmTwoFactor)).Methods("POST")
+	r.Handle("/me/2fa", authenticated(s.DisableTwoFactor)).Methods("DELETE")
+	r.Handle("/users/{id}/2fa", withPermission(s.ResetTwoFactor, models.PermUsersManage)).Methods("DELETE")
+
+	// ====== Приглашения ======
+	r.Handle("/invitations", withPermission(s.GetInvitations, models.PermUsersManage)).Methods("GET")
+	r.Handle("/invitations", withPermission(s.CreateInvitation, models.PermUsersManage)).Methods("POST")
+	r.Handle("/invitations/{id}", withPermission(s.DeleteInvitation, models.PermUsersManage)).Methods("DELETE")
+
+	// ====== Блокировки входа ======
+	r.Handle("/login-lockouts", withPermission(s.GetLoginLockouts, models.PermUsersManage)).Methods("GET")
+	r.Handle("/login-lockouts/{scope}/{key:.+}", withPermission(s.ClearLoginLockout, models.PermUsersManage)).Methods("DELETE")
+
+	// ====== Пароли ======
+	r.Handle("/me/password", authenticatedPending(s.ChangePassword)).Methods("PUT")
+	r.Handle("/users/{id}/password-reset", withPermission(s.CreatePasswordReset, models.PermUsersManage)).Methods("POST")
+	r.HandleFunc("/password-reset", s.ResetPassword).Methods("POST")
+
+	// ====== Классы ======
+	r.Handle("/classes", withPermission(s.CreateClass, models.PermClassesWrite)).Methods("POST")
+	r.Handle("/classes/{id}", withPermission(s.UpdateClass, models.PermClassesWrite)).Methods("PUT")
+	r.Handle("/classes/{id}", withPermission(s.DeleteClass, models.PermClassesDelete)).Methods("DELETE")
+
+	// ====== Учебные годы и периоды ======
+	r.HandleFunc("/academic-years", s.GetAcademicYears).Methods("GET")
+	r.HandleFunc("/academic-years/{year}", s.GetAcademicYear).Methods("GET")
+	r.Handle("/academic-years", withPermission(s.CreateAcademicYear, models.PermAcademicYears)).Methods("POST")
+	r.Handle("/academic-years/{year}", withPermission(s.DeleteAcademicYear, models.PermAcademicYears)).Methods("DELETE")
+	r.Handle("/terms/{id}", withPermission(s.UpdateTerm, models.PermAcademicYears)).Methods("PUT")
+
+	// ====== Завершение учебного года ======
+	r.Handle("/academic-years/{year}/annual-grades", withPermission(s.GetYearAnnualGrades, models.PermStatsRead)).Methods("GET")
+	r.Handle("/academic-years/{year}/close", withPermission(s.CloseAcademicYear, models.PermAcademicYears)).Methods("POST")
+
+	// ====== Шкалы оценивания ======
+	r.HandleFunc("/grading-scales", s.GetGradingScales).Methods("GET")
+	r.Handle("/grading-scales", withPermission(s.CreateGradingScale, models.PermGradingScales)).Methods("POST")
+	r.Handle("/grading-scales/{id}", withPermission(s.UpdateGradingScale, models.PermGradingScales)).Methods("PUT")
+	r.Handle("/grading-scales/{id}", withPermission(s.DeleteGradingScale, models.PermGradingScales)).Methods("DELETE")
+
+	// ====== Расписание ======
+	r.Handle("/bell-schedule", authenticated(s.GetBellSchedule)).Methods("GET")
+	r.Handle("/bell-schedule", withPermission(s.SetBellSchedule, models.PermTimetable)).Methods("PUT")
+	r.Handle("/lessons", authenticated(s.GetLessons)).Methods("GET")
+	r.Handle("/lessons", withPermission(s.CreateLesson, models.PermTimetable)).Methods("POST")
+	r.Handle("/lessons/{id}", withPermission(s.UpdateLesson, models.PermTimetable)).Methods("PUT")
+	r.Handle("/lessons/{id}", withPermission(s.DeleteLesson, models.PermTimetable)).Methods("DELETE")
+	// Расписание учителя определяется карточкой учителя текущего пользователя
+	r.Handle("/teacher/schedule", authenticated(s.GetTeacherSchedule)).Methods("GET")
+	r.Handle("/me/schedule", authenticated(s.GetMySchedule)).Methods("GET")
+	r.Handle("/classes/{id}/schedule", authenticated(s.GetClassSchedule)).Methods("GET")
+
+	// ====== Подписка на календарь ======
+	r.Handle("/me/calendar-feed", authenticated(s.CreateCalendarFeed)).Methods("POST")
+	r.Handle("/me/calendar-feed", authenticated(s.DeleteCalendarFeed)).Methods("DELETE")
+	r.HandleFunc("/calendar/{token:[A-Za-z0-9_-]+}.ics", s.GetCalendar).Methods("GET")
+
+	// ====== Домашние задания ======
+	r.Handle("/homework", authenticated(s.GetHomework)).Methods("GET")
+	r.Handle("/homework", withPermission(s.CreateHomework, models.PermHomework)).Methods("POST")
+	r.Handle("/homework/{id}", withPermission(s.UpdateHomework, models.PermHomework)).Methods("PUT")
+	r.Handle("/homework/{id}", withPermission(s.DeleteHomework, models.PermHomework)).Methods("DELETE")
+	r.Handle("/me/homework", authenticated(s.GetMyHomework)).Methods("GET")
+	r.Handle("/homework/{id}/submission", authenticated(s.SubmitHomework)).Methods("PUT")
+	r.Handle("/homework/{id}/submissions", withPermission(s.GetHomeworkSubmissions, models.PermHomework)).Methods("GET")
+	r.Handle("/homework/{id}/submissions/{student_id}/file", authenticated(s.GetSubmissionFile)).Methods("GET")
+	r.Handle("/homework/{id}/submissions/{student_id}/grade", withPermission(s.GradeSubmission, models.PermGradesWrite)).Methods("PUT")
+
+	// ====== Посещаемость ======
+	r.Handle("/attendance", authenticated(s.GetAttendance)).Methods("GET")
+	r.Handle("/classes/{id}/attendance", withPermission(s.MarkClassAttendance, models.PermAttendanceWrite)).Methods("POST")
+	r.Handle("/attendance/{id}", withPermission(s.UpdateAttendance, models.PermAttendanceWrite)).Methods("PUT")
+	r.Handle("/attendance/{id}", withPermission(s.DeleteAttendance, models.PermAttendanceWrite)).Methods("DELETE")
+
+	// ====== Назначения учителей ======
+	r.Handle("/teaching-assignments", authenticated(s.GetTeachingAssignments)).Methods("GET")
+	r.Handle("/teaching-assignments", withPermission(s.CreateTeachingAssignment, models.PermAssignments)).Methods("POST")
+	r.Handle("/teaching-assignments/{id}", withPermission(s.UpdateTeachingAssignment, models.PermAssignments)).Methods("PUT")
+	r.Handle("/teaching-assignments/{id}", withPermission(s.DeleteTeachingAssignment, models.PermAssignments)).Methods("DELETE")
+
+	// ====== Зачисление на предметы ======
+	r.Handle("/enrollments", withPermission(s.GetEnrollments, models.PermEnrollments)).Methods("GET")
+	r.Handle("/enrollments", withPermission(s.CreateEnrollment, models.PermEnrollments)).Methods("POST")
+	r.Handle("/enrollments/{id}", withPermission(s.UpdateEnrollment, models.PermEnrollments)).Methods("PUT")
+	r.Handle("/enrollments/{id}", withPermission(s.DeleteEnrollment, models.PermEnrollments)).Methods("DELETE")
+	r.Handle("/classes/{id}/enroll", withPermission(s.EnrollClass, models.PermEnrollments)).Methods("POST")
+
+	// ====== Итоговые оценки ======
+	r.Handle("/students/{id}/term-grades", withPermission(s.SetTermGrade, models.PermGradesWrite)).Methods("PUT")
+	r.Handle("/students/{id}/term-grades/{subject_id}/{term_id}", withPermission(s.DeleteTermGrade, models.PermGradesWrite)).Methods("DELETE")
+
+	// ====== Родители учеников ======
+	r.Handle("/students/{id}/guardians", withPermission(s.GetGuardians, models.PermUsersManage)).Methods("GET")
+	r.Handle("/students/{id}/guardians", withPermission(s.AddGuardian, models.PermUsersManage)).Methods("POST")
+	r.Handle("/students/{id}/guardians/{user_id}", withPermission(s.RemoveGuardian, models.PermUsersManage)).Methods("DELETE")
+
+	// ====== Журнал изменений ======
+	r.Handle("/audit", withPermission(s.GetAuditLog, models.PermAuditRead)).Methods("GET")
+
+	// ====== Роли и права ======
+	r.Handle("/permissions", withPermission(s.GetPermissions, models.PermPermissionsManage)).Methods("GET")
+	r.Handle("/roles", withPermission(s.GetRoles, models.PermPermissionsManage)).Methods("GET")
+	r.Handle("/roles", withPermission(s.CreateRole, models.PermPermissionsManage)).Methods("POST")
+	r.Handle("/roles/{name}/permissions", withPermission(s.SetRolePermissions, models.PermPermissionsManage)).Methods("PUT")
+	r.Handle("/roles/{name}", withPermission(s.DeleteRole, models.PermPermissionsManage)).Methods("DELETE")
+
+	return r
+}
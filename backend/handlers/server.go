@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"school-system/backend/config"
+	"school-system/backend/middleware"
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// Server содержит зависимости HTTP-обработчиков
+type Server struct {
+	Users         store.UserStore
+	Students      store.StudentStore
+	Classes       store.ClassStore
+	AcademicYears store.AcademicYearStore
+	Teachers      store.TeacherStore
+	Subjects      store.SubjectStore
+	Grades        store.GradeStore
+	TermGrades    store.TermGradeStore
+	Attendance    store.AttendanceStore
+	Timetable     store.TimetableStore
+	Homework      store.HomeworkStore
+	GradingScales store.GradingScaleStore
+	AnnualGrades  store.AnnualGradeStore
+	Assignments   store.TeachingAssignmentStore
+	Enrollments   store.EnrollmentStore
+	Tokens        store.TokenStore
+	Invitations   store.InvitationStore
+
+	LoginThrottles store.LoginThrottleStore
+	TwoFactor      store.TwoFactorStore
+	CalendarFeeds  store.CalendarFeedStore
+	Audit          store.AuditStore
+	Permissions    store.PermissionStore
+	Guardians      store.GuardianStore
+
+	Auth    config.AuthConfig
+	Grading models.GradingRules
+}
+
+// NewServer создает сервер, использующий одно хранилище для всех сущностей
+func NewServer(st store.Store) *Server {
+	return &Server{
+		Users:         st,
+		Students:      st,
+		Classes:       st,
+		AcademicYears: st,
+		Teachers:      st,
+		Subjects:      st,
+		Grades:        st,
+		TermGrades:    st,
+		Attendance:    st,
+		Timetable:     st,
+		Homework:      st,
+		GradingScales: st,
+		AnnualGrades:  st,
+		Assignments:   st,
+		Enrollments:   st,
+		Tokens:        st,
+		Invitations:   st,
+
+		LoginThrottles: st,
+		TwoFactor:      st,
+		CalendarFeeds:  st,
+		Audit:          st,
+		Permissions:    st,
+		Guardians:      st,
+
+		Auth:    config.Default().Auth,
+		Grading: config.Default().Grading,
+	}
+}
+
+// writeJSON отправляет ответ в формате JSON
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// storeErrorStatus подбирает HTTP-статус для ошибки хранилища
+func storeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, store.ErrReference):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// pathID возвращает числовой параметр {id} из пути запроса
+func pathID(r *http.Request) (int, error) {
+	return pathInt(r, "id")
+}
+
+func pathInt(r *http.Request, name string) (int, error) {
+	value := mux.Vars(r)[name]
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("некорректный %s: %q", name, value)
+	}
+	return id, nil
+}
+
+// userIDFromContext возвращает ID пользователя, сохраненный AuthMiddleware
+func userIDFromContext(r *http.Request) (int, bool) {
+	switch v := r.Context().Value(middleware.ContextUserID).(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case string:
+		id, err := strconv.Atoi(v)
+		return id, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -1,95 +1,62 @@
 package handlers
 
 import (
-	"database/sql"
-	"encoding/json"
 	"log"
 	"net/http"
-
-	"school-system/backend/database"
 )
 
 // GetStudentsCount возвращает общее количество учеников
-func GetStudentsCount(w http.ResponseWriter, r *http.Request) {
-	var count int64
-	err := database.DB.Get(&count, "SELECT COUNT(*) FROM students")
+func (s *Server) GetStudentsCount(w http.ResponseWriter, r *http.Request) {
+	count, err := s.Students.CountStudents(r.Context())
 	if err != nil {
 		log.Printf("Ошибка при получении количества учеников: %v", err)
 		http.Error(w, "Ошибка при получении количества учеников: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]int64{"count": count})
+	writeJSON(w, http.StatusOK, map[string]int64{"count": count})
 }
 
 // GetTeachersCount возвращает общее количество учителей
-func GetTeachersCount(w http.ResponseWriter, r *http.Request) {
-	var count int64
-	err := database.DB.Get(&count, "SELECT COUNT(*) FROM teachers")
+func (s *Server) GetTeachersCount(w http.ResponseWriter, r *http.Request) {
+	count, err := s.Teachers.CountTeachers(r.Context())
 	if err != nil {
 		log.Printf("Ошибка при получении количества учителей: %v", err)
 		http.Error(w, "Ошибка при получении количества учителей: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]int64{"count": count})
+	writeJSON(w, http.StatusOK, map[string]int64{"count": count})
 }
 
-// GetAverageGrade возвращает средний балл по всем оценкам
-func GetAverageGrade(w http.ResponseWriter, r *http.Request) {
-	var avg sql.NullFloat64
-	query := `
-		SELECT COALESCE(AVG(grade), 0) 
-		FROM grades 
-		WHERE grade IS NOT NULL
-	`
-	err := database.DB.Get(&avg, query)
+// GetAverageGrade возвращает средний балл по всем оценкам учебного года или периода
+func (s *Server) GetAverageGrade(w http.ResponseWriter, r *http.Request) {
+	period, ok := periodFromRequest(w, r)
+	if !ok {
+		return
+	}
+	avg, err := s.Grades.GetAverageGrade(r.Context(), period)
 	if err != nil {
 		log.Printf("Ошибка при получении среднего балла: %v", err)
 		http.Error(w, "Ошибка при получении среднего балла: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]float64{"average": avg.Float64})
+	writeJSON(w, http.StatusOK, map[string]float64{"average": avg})
 }
 
-// GetClassPerformance возвращает средние оценки по классам
-func GetClassPerformance(w http.ResponseWriter, r *http.Request) {
-	type ClassPerformance struct {
-		Name  string  `json:"name" db:"class_name"`
-		Value float64 `json:"value" db:"average"`
+// GetClassPerformance возвращает средние оценки по классам за учебный год или период
+func (s *Server) GetClassPerformance(w http.ResponseWriter, r *http.Request) {
+	period, ok := periodFromRequest(w, r)
+	if !ok {
+		return
 	}
-
-	query := `
-		WITH class_quarter_averages AS (
-			SELECT 
-				s.class_name,
-				g.quarter,
-				AVG(g.grade) as quarter_average
-			FROM students s
-			JOIN grades g ON g.student_id = s.id
-			GROUP BY s.class_name, g.quarter
-		)
-		SELECT 
-			class_name,
-			ROUND(AVG(quarter_average), 2) as average
-		FROM class_quarter_averages
-		GROUP BY class_name
-		ORDER BY class_name
-	`
-
-	var performances []ClassPerformance
-	err := database.DB.Select(&performances, query)
+	performances, err := s.Grades.GetClassPerformance(r.Context(), period)
 	if err != nil {
 		log.Printf("Ошибка при получении успеваемости по классам: %v", err)
 		http.Error(w, "Ошибка при получении успеваемости по классам: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Если нет данных, возвращаем пустой массив
-	if performances == nil {
-		performances = []ClassPerformance{}
-	}
-
-	json.NewEncoder(w).Encode(performances)
+	writeJSON(w, http.StatusOK, performances)
 }
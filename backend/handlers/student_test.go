@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -13,20 +15,29 @@ import (
 )
 
 // newRouter — локальный роутер для тестов, с нужными маршрутами.
-func newRouter() *mux.Router {
+// Сервер работает поверх хранилища в памяти, в котором уже есть классы 9А и 10Б и один ученик.
+func newRouter(t *testing.T) *mux.Router {
+	s, st := newTestServer(t)
+	ctx := context.Background()
+	year := models.AcademicYearOf(time.Now())
+	class := &models.Class{GradeLevel: 9, Letter: "А", AcademicYear: year}
+	mustCreate(t, st.CreateClass(ctx, class))
+	mustCreate(t, st.CreateClass(ctx, &models.Class{GradeLevel: 10, Letter: "Б", AcademicYear: year}))
+	mustCreate(t, st.CreateStudent(ctx, &models.Student{FullName: "Анна Смирнова", ClassID: class.ID}))
+
 	r := mux.NewRouter()
 
 	// Маршруты для студентов (которые нужны в тестах)
-	r.HandleFunc("/students", GetStudents).Methods("GET")
-	r.HandleFunc("/students", CreateStudent).Methods("POST")
-	r.HandleFunc("/students/{id}", UpdateStudent).Methods("PUT")
-	r.HandleFunc("/students/{id}", DeleteStudent).Methods("DELETE")
+	r.HandleFunc("/students", s.GetStudents).Methods("GET")
+	r.HandleFunc("/students", s.CreateStudent).Methods("POST")
+	r.HandleFunc("/students/{id}", s.UpdateStudent).Methods("PUT")
+	r.HandleFunc("/students/{id}", s.DeleteStudent).Methods("DELETE")
 
 	return r
 }
 
 func TestCreateStudent(t *testing.T) {
-	router := newRouter()
+	router := newRouter(t)
 	newStudent := models.Student{
 		FullName:  "Иван Иванов",
 		ClassName: "9А",
@@ -45,7 +56,7 @@ func TestCreateStudent(t *testing.T) {
 }
 
 func TestGetStudents(t *testing.T) {
-	router := newRouter()
+	router := newRouter(t)
 
 	req, _ := http.NewRequest("GET", "/students", nil)
 	resp := httptest.NewRecorder()
@@ -60,10 +71,13 @@ func TestGetStudents(t *testing.T) {
 	if err != nil {
 		t.Errorf("Ошибка при разборе ответа: %v", err)
 	}
+	if len(students) != 1 {
+		t.Errorf("Ожидался 1 студент, получено %d", len(students))
+	}
 }
 
 func TestUpdateStudent(t *testing.T) {
-	router := newRouter()
+	router := newRouter(t)
 
 	updatedStudent := models.Student{
 		FullName:  "Пётр Петров",
@@ -83,13 +97,48 @@ func TestUpdateStudent(t *testing.T) {
 }
 
 func TestDeleteStudent(t *testing.T) {
-	router := newRouter()
+	router := newRouter(t)
 
 	req, _ := http.NewRequest("DELETE", "/students/1", nil)
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
 
-	if resp.Code != http.StatusOK {
-		t.Errorf("Ожидался статус 200, получен %d", resp.Code)
+	// DeleteStudent, как и DeleteTeacher, отвечает 204 No Content
+	if resp.Code != http.StatusNoContent {
+		t.Errorf("Ожидался статус 204, получен %d", resp.Code)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/students/1", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusNotFound {
+		t.Errorf("Повторное удаление: ожидался статус 404, получен %d", resp.Code)
+	}
+}
+
+func TestStudentClassNameIsNormalized(t *testing.T) {
+	router := newRouter(t)
+
+	// "9A" с латинской A и "9 а" попадают в тот же класс 9А
+	for _, name := range []string{"9A", "9 а"} {
+		body, _ := json.Marshal(models.Student{FullName: "Иван Иванов", ClassName: name})
+		req, _ := http.NewRequest("POST", "/students", bytes.NewBuffer(body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var created models.Student
+		json.Unmarshal(resp.Body.Bytes(), &created)
+		if resp.Code != http.StatusCreated || created.ClassID != 1 || created.ClassName != "9А" {
+			t.Errorf("%q: ожидался класс 9А (id 1), получено %d %+v", name, resp.Code, created)
+		}
+	}
+
+	body, _ := json.Marshal(models.Student{FullName: "Иван Иванов", ClassName: "7В"})
+	req, _ := http.NewRequest("POST", "/students", bytes.NewBuffer(body))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("Несуществующий класс: ожидался статус 400, получен %d", resp.Code)
 	}
 }
@@ -1,49 +1,49 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"school-system/backend/database"
-	"school-system/backend/models"
+	"sort"
 
-	"github.com/gorilla/mux"
+	"school-system/backend/models"
 )
 
-func GetStudents(w http.ResponseWriter, r *http.Request) {
+func (s *Server) GetStudents(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Получен запрос на получение списка студентов")
-	rows, err := database.DB.Query("SELECT id, full_name, class_name FROM students")
+	students, err := s.Students.ListStudents(r.Context())
 	if err != nil {
 		log.Printf("Ошибка при получении данных студентов: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	var students []models.Student
-	for rows.Next() {
-		var student models.Student
-		if err := rows.Scan(&student.ID, &student.FullName, &student.ClassName); err != nil {
-			log.Printf("Ошибка при сканировании данных студента: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		students = append(students, student)
-	}
-
-	if err := rows.Err(); err != nil {
-		log.Printf("Ошибка при обработке результатов запроса: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if students == nil {
+		students = []models.Student{}
 	}
 
 	log.Printf("Успешно получено %d студентов", len(students))
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(students)
+	writeJSON(w, http.StatusOK, students)
+}
+
+// checkStudentClass определяет класс ученика по class_id или class_name.
+// При ошибке ответ уже отправлен.
+func (s *Server) checkStudentClass(w http.ResponseWriter, r *http.Request, student *models.Student) bool {
+	if err := s.resolveStudentClass(r.Context(), student); err != nil {
+		if errors.Is(err, errClassNotFound) {
+			http.Error(w, "Класс не найден. Создайте его через /classes", http.StatusBadRequest)
+			return false
+		}
+		log.Printf("Ошибка при поиске класса %q: %v", student.ClassName, err)
+		http.Error(w, "Ошибка при поиске класса", http.StatusInternalServerError)
+		return false
+	}
+	return true
 }
 
-func CreateStudent(w http.ResponseWriter, r *http.Request) {
+func (s *Server) CreateStudent(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Получен запрос на создание нового студента")
 	var student models.Student
 	if err := json.NewDecoder(r.Body).Decode(&student); err != nil {
@@ -51,37 +51,45 @@ func CreateStudent(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
 		return
 	}
+	if !s.checkStudentClass(w, r, &student) {
+		return
+	}
 
-	_, err := database.DB.Exec(`INSERT INTO students (full_name, class_name) VALUES ($1, $2)`,
-		student.FullName, student.ClassName)
-	if err != nil {
+	if err := s.Students.CreateStudent(auditContext(r), &student); err != nil {
 		log.Printf("Ошибка при добавлении студента в базу данных: %v", err)
-		http.Error(w, "Ошибка при добавлении студента", http.StatusInternalServerError)
+		http.Error(w, "Ошибка при добавлении студента", storeErrorStatus(err))
 		return
 	}
 
 	log.Printf("Успешно создан новый студент: %s", student.FullName)
-	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, http.StatusCreated, student)
 }
 
-func DeleteStudent(w http.ResponseWriter, r *http.Request) {
-	id := mux.Vars(r)["id"]
-	log.Printf("Получен запрос на удаление студента с ID: %s", id)
-
-	_, err := database.DB.Exec(`DELETE FROM students WHERE id = $1`, id)
+func (s *Server) DeleteStudent(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
 	if err != nil {
-		log.Printf("Ошибка при удалении студента с ID %s: %v", id, err)
-		http.Error(w, "Ошибка при удалении студента", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на удаление студента с ID: %d", id)
+
+	if err := s.Students.DeleteStudent(auditContext(r), id); err != nil {
+		log.Printf("Ошибка при удалении студента с ID %d: %v", id, err)
+		http.Error(w, "Ошибка при удалении студента", storeErrorStatus(err))
 		return
 	}
 
-	log.Printf("Успешно удален студент с ID: %s", id)
+	log.Printf("Успешно удален студент с ID: %d", id)
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func UpdateStudent(w http.ResponseWriter, r *http.Request) {
-	id := mux.Vars(r)["id"]
-	log.Printf("Получен запрос на обновление студента с ID: %s", id)
+func (s *Server) UpdateStudent(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на обновление студента с ID: %d", id)
 
 	var student models.Student
 	if err := json.NewDecoder(r.Body).Decode(&student); err != nil {
@@ -89,24 +97,32 @@ func UpdateStudent(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
 		return
 	}
+	student.ID = id
+	if !s.checkStudentClass(w, r, &student) {
+		return
+	}
 
-	_, err := database.DB.Exec(`UPDATE students SET full_name = $1, class_name = $2 WHERE id = $3`,
-		student.FullName, student.ClassName, id)
-	if err != nil {
-		log.Printf("Ошибка при обновлении студента с ID %s: %v", id, err)
-		http.Error(w, "Ошибка при обновлении студента", http.StatusInternalServerError)
+	if err := s.Students.UpdateStudent(auditContext(r), &student); err != nil {
+		log.Printf("Ошибка при обновлении студента с ID %d: %v", id, err)
+		http.Error(w, "Ошибка при обновлении студента", storeErrorStatus(err))
 		return
 	}
 
-	log.Printf("Успешно обновлен студент с ID: %s", id)
+	log.Printf("Успешно обновлен студент с ID: %d", id)
 	w.WriteHeader(http.StatusOK)
 }
 
-// GetFailingStudents возвращает список неуспевающих учеников с их средними оценками по предметам
-func GetFailingStudents(w http.ResponseWriter, r *http.Request) {
+// GetFailingStudents возвращает список неуспевающих учеников: итоговая оценка за четверть
+// (выставленная учителем или вычисленная по средневзвешенному баллу) ниже порога успеваемости
+// шкалы предмета
+func (s *Server) GetFailingStudents(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Получен запрос на получение списка отстающих учеников")
+	period, ok := periodFromRequest(w, r)
+	if !ok {
+		return
+	}
 
-	students, err := database.GetFailingStudents()
+	students, err := s.failingStudents(r.Context(), period)
 	if err != nil {
 		log.Printf("Ошибка при получении списка неуспевающих учеников: %v", err)
 		http.Error(w, fmt.Sprintf("Ошибка при получении списка неуспевающих учеников: %v", err), http.StatusInternalServerError)
@@ -114,36 +130,88 @@ func GetFailingStudents(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("Найдено отстающих учеников: %d", len(students))
+	writeJSON(w, http.StatusOK, students)
+}
+
+// failingStudents вычисляет итоговые оценки за четверти по правилам оценивания, как в журнале
+// ученика, и отбирает учеников с оценками ниже порога успеваемости
+func (s *Server) failingStudents(ctx context.Context, period models.Period) ([]models.FailingStudent, error) {
+	grades, err := s.Grades.GetStudentGrades(ctx, 0, period)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.TermGrades.ListTermGrades(ctx, 0, period)
+	if err != nil {
+		return nil, err
+	}
+	students, err := s.Students.ListStudents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scales, err := s.subjectScales(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byStudent := make(map[int][]models.GradeWithSubject)
+	for _, g := range grades {
+		byStudent[g.StudentID] = append(byStudent[g.StudentID], g)
+	}
+	overridesByStudent := make(map[int][]models.TermGrade)
+	for _, o := range overrides {
+		overridesByStudent[o.StudentID] = append(overridesByStudent[o.StudentID], o)
+	}
+
+	result := []models.FailingStudent{}
 	for _, student := range students {
-		log.Printf("Отстающий ученик: %s (Класс: %s), предметов: %d",
-			student.FullName,
-			student.ClassName,
-			len(student.SubjectAverages))
-		for _, subject := range student.SubjectAverages {
-			log.Printf("  - %s: %.2f", subject.SubjectName, subject.Average)
+		if len(byStudent[student.ID]) == 0 && len(overridesByStudent[student.ID]) == 0 {
+			continue
+		}
+		averages := []models.SubjectAverage{}
+		for _, subject := range groupGrades(byStudent[student.ID], overridesByStudent[student.ID], s.Grading) {
+			for _, q := range subject.Quarters {
+				if q.Final < scales[subject.SubjectID].PassValue {
+					averages = append(averages, models.SubjectAverage{
+						SubjectName: subject.SubjectName,
+						Quarter:     q.Quarter,
+						Average:     q.Average,
+						Grade:       q.Final,
+					})
+				}
+			}
+		}
+		if len(averages) > 0 {
+			result = append(result, models.FailingStudent{Student: student, SubjectAverages: averages})
 		}
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(students)
+	sort.SliceStable(result, func(i, j int) bool { return result[i].FullName < result[j].FullName })
+	return result, nil
 }
 
 // GetAverageGradesByClass возвращает средние оценки по предметам для каждого класса
-func GetAverageGradesByClass(w http.ResponseWriter, r *http.Request) {
-	averages, err := database.GetAverageGradesByClass()
+func (s *Server) GetAverageGradesByClass(w http.ResponseWriter, r *http.Request) {
+	period, ok := periodFromRequest(w, r)
+	if !ok {
+		return
+	}
+	averages, err := s.Grades.GetAverageGradesByClass(r.Context(), period)
 	if err != nil {
+		log.Printf("Ошибка при получении средних оценок: %v", err)
 		http.Error(w, "Ошибка при получении средних оценок", http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(averages)
+	writeJSON(w, http.StatusOK, averages)
 }
 
 // GetTopAndWorstClasses возвращает классы с самой высокой и низкой успеваемостью
-func GetTopAndWorstClasses(w http.ResponseWriter, r *http.Request) {
-	topClass, worstClass, err := database.GetTopAndWorstClasses()
+func (s *Server) GetTopAndWorstClasses(w http.ResponseWriter, r *http.Request) {
+	period, ok := periodFromRequest(w, r)
+	if !ok {
+		return
+	}
+	topClass, worstClass, err := s.Grades.GetTopAndWorstClasses(r.Context(), period)
 	if err != nil {
+		log.Printf("Ошибка при получении информации о классах: %v", err)
 		http.Error(w, "Ошибка при получении информации о классах", http.StatusInternalServerError)
 		return
 	}
@@ -156,6 +224,5 @@ func GetTopAndWorstClasses(w http.ResponseWriter, r *http.Request) {
 		WorstClass: worstClass,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeJSON(w, http.StatusOK, response)
 }
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// writeAssignmentError отправляет ответ на ошибку сохранения назначения
+func writeAssignmentError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrArchived) {
+		http.Error(w, "Учебный год в архиве", http.StatusConflict)
+		return
+	}
+	switch storeErrorStatus(err) {
+	case http.StatusNotFound:
+		http.Error(w, "Назначение не найдено", http.StatusNotFound)
+	case http.StatusConflict:
+		http.Error(w, "Учитель уже ведет этот предмет в классе", http.StatusConflict)
+	case http.StatusBadRequest:
+		http.Error(w, "Учитель, предмет, класс или период учебного года класса не найден", http.StatusBadRequest)
+	default:
+		http.Error(w, "Ошибка при сохранении назначения", http.StatusInternalServerError)
+	}
+}
+
+// GetTeachingAssignments возвращает назначения учителей. Параметры teacher_id, subject_id,
+// class_id и academic_year сужают выборку.
+func (s *Server) GetTeachingAssignments(w http.ResponseWriter, r *http.Request) {
+	var filter models.TeachingAssignmentFilter
+	query := r.URL.Query()
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{
+		{"teacher_id", &filter.TeacherID},
+		{"subject_id", &filter.SubjectID},
+		{"class_id", &filter.ClassID},
+		{"academic_year", &filter.AcademicYear},
+	} {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "Некорректный "+p.name, http.StatusBadRequest)
+			return
+		}
+		*p.value = n
+	}
+
+	assignments, err := s.Assignments.ListTeachingAssignments(r.Context(), filter)
+	if err != nil {
+		log.Printf("Ошибка при получении назначений учителей: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if assignments == nil {
+		assignments = []models.TeachingAssignment{}
+	}
+	writeJSON(w, http.StatusOK, assignments)
+}
+
+func (s *Server) CreateTeachingAssignment(w http.ResponseWriter, r *http.Request) {
+	var assignment models.TeachingAssignment
+	if err := json.NewDecoder(r.Body).Decode(&assignment); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if assignment.TeacherID < 1 || assignment.SubjectID < 1 || assignment.ClassID < 1 || assignment.TermID < 0 {
+		http.Error(w, "Нужно указать учителя, предмет и класс", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Assignments.CreateTeachingAssignment(r.Context(), &assignment); err != nil {
+		log.Printf("Ошибка при назначении учителя %d на предмет %d в классе %d: %v",
+			assignment.TeacherID, assignment.SubjectID, assignment.ClassID, err)
+		writeAssignmentError(w, err)
+		return
+	}
+
+	log.Printf("Учитель %d назначен на предмет %d в классе %d", assignment.TeacherID, assignment.SubjectID, assignment.ClassID)
+	writeJSON(w, http.StatusCreated, assignment)
+}
+
+// UpdateTeachingAssignment передает назначение другому учителю, например на время замены
+func (s *Server) UpdateTeachingAssignment(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var assignment models.TeachingAssignment
+	if err := json.NewDecoder(r.Body).Decode(&assignment); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	assignment.ID = id
+	if assignment.TeacherID < 1 {
+		http.Error(w, "Нужно указать учителя", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Assignments.UpdateTeachingAssignment(r.Context(), &assignment); err != nil {
+		log.Printf("Ошибка при обновлении назначения %d: %v", id, err)
+		writeAssignmentError(w, err)
+		return
+	}
+
+	log.Printf("Успешно обновлено назначение с ID: %d", id)
+	writeJSON(w, http.StatusOK, assignment)
+}
+
+func (s *Server) DeleteTeachingAssignment(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.Assignments.DeleteTeachingAssignment(r.Context(), id); err != nil {
+		log.Printf("Ошибка при удалении назначения %d: %v", id, err)
+		writeAssignmentError(w, err)
+		return
+	}
+
+	log.Printf("Успешно удалено назначение с ID: %d", id)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func TestTeachingAssignmentsScopeTeacherClasses(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	year := models.AcademicYearOf(time.Now())
+	deputy := createUser(t, st, "deputy", "deputy")
+	firstUser := createUser(t, st, "first", "teacher")
+	secondUser := createUser(t, st, "second", "teacher")
+
+	first := &models.Teacher{FullName: "Иванова Анна", UserID: firstUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, first))
+	second := &models.Teacher{FullName: "Петров Олег", UserID: secondUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, second))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	classA := &models.Class{GradeLevel: 9, Letter: "А", AcademicYear: year}
+	mustCreate(t, st.CreateClass(ctx, classA))
+	classB := &models.Class{GradeLevel: 9, Letter: "Б", AcademicYear: year}
+	mustCreate(t, st.CreateClass(ctx, classB))
+	ivan := &models.Student{FullName: "Иван Иванов", ClassID: classA.ID}
+	mustCreate(t, st.CreateStudent(ctx, ivan))
+	petr := &models.Student{FullName: "Петр Петров", ClassID: classB.ID}
+	mustCreate(t, st.CreateStudent(ctx, petr))
+
+	assign := func(teacherID, classID int, user *models.User) (int, models.TeachingAssignment) {
+		t.Helper()
+		body, _ := json.Marshal(models.TeachingAssignment{TeacherID: teacherID, SubjectID: math.ID, ClassID: classID})
+		resp := do(t, router, "POST", "/teaching-assignments", bytes.NewReader(body), user)
+		var assignment models.TeachingAssignment
+		json.Unmarshal(resp.Body.Bytes(), &assignment)
+		return resp.Code, assignment
+	}
+	if code, _ := assign(first.ID, classA.ID, firstUser); code != http.StatusForbidden {
+		t.Errorf("Учитель без teaching_assignments:manage: ожидался статус 403, получен %d", code)
+	}
+	if code, assignment := assign(first.ID, classA.ID, deputy); code != http.StatusCreated || assignment.AcademicYear != year {
+		t.Fatalf("Назначение: ожидался статус 201 и год %d, получено %d %+v", year, code, assignment)
+	}
+	if code, _ := assign(first.ID, classA.ID, deputy); code != http.StatusConflict {
+		t.Errorf("Повторное назначение: ожидался статус 409, получен %d", code)
+	}
+	if code, _ := assign(second.ID, classB.ID, deputy); code != http.StatusCreated {
+		t.Fatalf("Назначение второго учителя: ожидался статус 201, получен %d", code)
+	}
+
+	myStudents := func(user *models.User) []models.Student {
+		t.Helper()
+		var students []models.Student
+		resp := do(t, router, "GET", "/teacher/my-students", nil, user)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("Ученики учителя: ожидался статус 200, получен %d", resp.Code)
+		}
+		json.Unmarshal(resp.Body.Bytes(), &students)
+		return students
+	}
+	if students := myStudents(firstUser); len(students) != 1 || students[0].ID != ivan.ID {
+		t.Errorf("Первый учитель должен видеть только 9А, получено %+v", students)
+	}
+	if students := myStudents(secondUser); len(students) != 1 || students[0].ID != petr.ID {
+		t.Errorf("Второй учитель должен видеть только 9Б, получено %+v", students)
+	}
+
+	// Оценки по общему предмету видны только учителю класса ученика
+	mustCreate(t, st.CreateGrade(ctx, &models.Grade{StudentID: ivan.ID, SubjectID: math.ID, Grade: 5, TermID: termID(t, st, 1)}))
+	mustCreate(t, st.CreateGrade(ctx, &models.Grade{StudentID: petr.ID, SubjectID: math.ID, Grade: 3, TermID: termID(t, st, 1)}))
+	var journal []struct {
+		StudentID int            `json:"student_id"`
+		Grades    []models.Grade `json:"grades"`
+	}
+	resp := do(t, router, "GET", "/teacher/my-students/grades", nil, secondUser)
+	json.Unmarshal(resp.Body.Bytes(), &journal)
+	if len(journal) != 1 || journal[0].StudentID != petr.ID || len(journal[0].Grades) != 1 || journal[0].Grades[0].Grade != 3 {
+		t.Errorf("Второй учитель должен видеть только оценку Петра, получено %s", resp.Body.String())
+	}
+
+	var assignments []models.TeachingAssignment
+	resp = do(t, router, "GET", fmt.Sprintf("/teaching-assignments?subject_id=%d&class_id=%d", math.ID, classB.ID), nil, firstUser)
+	json.Unmarshal(resp.Body.Bytes(), &assignments)
+	if resp.Code != http.StatusOK || len(assignments) != 1 || assignments[0].TeacherID != second.ID {
+		t.Fatalf("Ожидалось одно назначение в 9Б, получено %d %s", resp.Code, resp.Body.String())
+	}
+
+	// Замена: 9Б передается первому учителю, второй теряет доступ к классу
+	body, _ := json.Marshal(models.TeachingAssignment{TeacherID: first.ID})
+	path := fmt.Sprintf("/teaching-assignments/%d", assignments[0].ID)
+	if resp := do(t, router, "PUT", path, bytes.NewReader(body), deputy); resp.Code != http.StatusOK {
+		t.Fatalf("Передача назначения: ожидался статус 200, получен %d %s", resp.Code, resp.Body.String())
+	}
+	if students := myStudents(firstUser); len(students) != 2 {
+		t.Errorf("Первый учитель должен видеть оба класса, получено %+v", students)
+	}
+	if students := myStudents(secondUser); len(students) != 0 {
+		t.Errorf("Второй учитель не должен видеть учеников, получено %+v", students)
+	}
+	body, _ = json.Marshal(models.Grade{StudentID: petr.ID, SubjectID: math.ID, Grade: 4, Quarter: 1})
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), secondUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Учитель без назначений: ожидался статус 403, получен %d", resp.Code)
+	}
+
+	if resp := do(t, router, "DELETE", path, nil, deputy); resp.Code != http.StatusNoContent {
+		t.Errorf("Удаление назначения: ожидался статус 204, получен %d", resp.Code)
+	}
+	if students := myStudents(firstUser); len(students) != 1 || students[0].ID != ivan.ID {
+		t.Errorf("После удаления назначения первый учитель должен видеть только 9А, получено %+v", students)
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"unicode/utf8"
+
+	"school-system/backend/models"
+)
+
+// TermGradeRequest — итоговая оценка за период, выставляемая учителем
+type TermGradeRequest struct {
+	SubjectID int    `json:"subject_id"`
+	TermID    int    `json:"term_id"`
+	Grade     int    `json:"grade"`
+	Comment   string `json:"comment"`
+}
+
+// GetStudentTermGrades возвращает отметки ученика по предметам и четвертям со средневзвешенным
+// баллом, вычисленной и итоговой оценкой. Учитель видит только свои предметы.
+func (s *Server) GetStudentTermGrades(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return
+	}
+	own := access.ownsStudent(studentID)
+	if !own && !access.isTeacher() {
+		http.Error(w, "Нет доступа к оценкам этого ученика", http.StatusForbidden)
+		return
+	}
+	period, ok := periodFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	subjects, ok := s.groupedStudentGrades(w, r, studentID)
+	if !ok {
+		return
+	}
+	if !own {
+		visible := []models.SubjectGrades{}
+		for _, subject := range subjects {
+			if access.teachesInYear(studentID, subject.SubjectID, period.AcademicYear) {
+				visible = append(visible, subject)
+			}
+		}
+		subjects = visible
+	}
+	writeJSON(w, http.StatusOK, subjects)
+}
+
+// SetTermGrade выставляет итоговую оценку за период вместо вычисленной по отметкам
+func (s *Server) SetTermGrade(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	var req TermGradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if req.SubjectID < 1 || req.TermID < 1 {
+		http.Error(w, "Не указан предмет или период", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(req.Comment) > maxCommentLength {
+		http.Error(w, "Слишком длинный комментарий", http.StatusBadRequest)
+		return
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok || !s.checkGradeWrite(w, r, access, &models.Grade{StudentID: studentID, SubjectID: req.SubjectID, TermID: req.TermID}) ||
+		!s.checkGradeScale(w, r, req.SubjectID, req.Grade) {
+		return
+	}
+
+	grade := models.TermGrade{
+		StudentID: studentID,
+		SubjectID: req.SubjectID,
+		TermID:    req.TermID,
+		Grade:     req.Grade,
+		Comment:   req.Comment,
+		SetBy:     userID,
+	}
+	if err := s.TermGrades.SetTermGrade(auditContext(r), &grade); err != nil {
+		log.Printf("Ошибка при выставлении итоговой оценки ученику %d: %v", studentID, err)
+		http.Error(w, "Ошибка при выставлении итоговой оценки", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Итоговая оценка %d за период %d по предмету %d выставлена ученику %d пользователем %d",
+		grade.Grade, grade.TermID, grade.SubjectID, studentID, userID)
+	writeJSON(w, http.StatusOK, grade)
+}
+
+// DeleteTermGrade отменяет итоговую оценку учителя: снова действует вычисленная
+func (s *Server) DeleteTermGrade(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	subjectID, err := pathInt(r, "subject_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	termID, err := pathInt(r, "term_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return
+	}
+	if !access.teaches(studentID, subjectID, termID) {
+		http.Error(w, "Вы не ведете этот предмет у ученика", http.StatusForbidden)
+		return
+	}
+
+	if err := s.TermGrades.DeleteTermGrade(auditContext(r), studentID, subjectID, termID); err != nil {
+		log.Printf("Ошибка при удалении итоговой оценки ученика %d: %v", studentID, err)
+		http.Error(w, "Итоговая оценка не найдена", storeErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func TestWeightedTermGradeAndOverride(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+
+	teacherUser := createUser(t, st, "teacher", "teacher")
+	otherUser := createUser(t, st, "other", "teacher")
+	teacher := &models.Teacher{FullName: "Ольга Смирнова", UserID: teacherUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, teacher))
+	mustCreate(t, st.CreateTeacher(ctx, &models.Teacher{FullName: "Игорь Кузнецов", UserID: otherUser.ID}))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	class := assignTeacher(t, st, teacher.ID, math.ID)
+	pupil := &models.Student{FullName: "Иван Иванов", ClassID: class.ID}
+	mustCreate(t, st.CreateStudent(ctx, pupil))
+
+	year, err := st.GetAcademicYear(ctx, models.AcademicYearOf(time.Now()))
+	mustCreate(t, err)
+	first := year.Terms[0]
+
+	post := func(g models.Grade) int {
+		t.Helper()
+		body, _ := json.Marshal(g)
+		return do(t, router, "POST", "/grades", bytes.NewReader(body), teacherUser).Code
+	}
+	// 5 (вес 1) + 4 (вес 1) + 3 (контрольная, вес 2) = 15 / 4 = 3.75
+	for _, g := range []models.Grade{
+		{Grade: 5, Kind: models.MarkKindClasswork, Date: first.StartsOn},
+		{Grade: 4, Kind: models.MarkKindHomework, Date: first.StartsOn},
+		{Grade: 3, Kind: models.MarkKindTest, Date: first.EndsOn, Comment: "Контрольная по дробям"},
+	} {
+		g.StudentID, g.SubjectID = pupil.ID, math.ID
+		if code := post(g); code != http.StatusCreated {
+			t.Fatalf("Отметка %+v: ожидался статус 201, получен %d", g, code)
+		}
+	}
+	outside := models.Grade{StudentID: pupil.ID, SubjectID: math.ID, Grade: 5, TermID: first.ID,
+		Date: models.NewDate(first.EndsOn.AddDate(0, 0, 1))}
+	if code := post(outside); code != http.StatusBadRequest {
+		t.Errorf("Дата вне периода: ожидался статус 400, получен %d", code)
+	}
+	if code := post(models.Grade{StudentID: pupil.ID, SubjectID: math.ID, Grade: 5, Kind: "quiz"}); code != http.StatusBadRequest {
+		t.Errorf("Неизвестный вид отметки: ожидался статус 400, получен %d", code)
+	}
+
+	path := fmt.Sprintf("/students/%d/term-grades", pupil.ID)
+	quarter := func() models.QuarterGrades {
+		t.Helper()
+		resp := do(t, router, "GET", path+"?term=1", nil, teacherUser)
+		var subjects []models.SubjectGrades
+		json.Unmarshal(resp.Body.Bytes(), &subjects)
+		if resp.Code != http.StatusOK || len(subjects) != 1 || len(subjects[0].Quarters) != 1 {
+			t.Fatalf("Ожидались оценки по одному предмету за четверть, получено %d %s", resp.Code, resp.Body.String())
+		}
+		return subjects[0].Quarters[0]
+	}
+	if q := quarter(); q.Average != 3.75 || q.Computed != 4 || q.Final != 4 || q.Overridden {
+		t.Errorf("Ожидались средний балл 3.75 и оценка 4, получено %+v", q)
+	}
+
+	s.Grading.RoundingThreshold = 0.8
+	if q := quarter(); q.Computed != 3 {
+		t.Errorf("С порогом 0.8 ожидалась оценка 3, получено %d", q.Computed)
+	}
+
+	override, _ := json.Marshal(TermGradeRequest{SubjectID: math.ID, TermID: first.ID, Grade: 5})
+	if resp := do(t, router, "PUT", path, bytes.NewReader(override), otherUser); resp.Code != http.StatusForbidden {
+		t.Errorf("Чужой предмет: ожидался статус 403, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "PUT", path, bytes.NewReader(override), teacherUser); resp.Code != http.StatusOK {
+		t.Fatalf("Итоговая оценка: ожидался статус 200, получен %d", resp.Code)
+	}
+	if q := quarter(); q.Final != 5 || !q.Overridden || q.Computed != 3 {
+		t.Errorf("Ожидалась итоговая оценка учителя 5, получено %+v", q)
+	}
+
+	deletePath := fmt.Sprintf("%s/%d/%d", path, math.ID, first.ID)
+	if resp := do(t, router, "DELETE", deletePath, nil, teacherUser); resp.Code != http.StatusOK {
+		t.Fatalf("Отмена итоговой оценки: ожидался статус 200, получен %d", resp.Code)
+	}
+	if q := quarter(); q.Final != 3 || q.Overridden {
+		t.Errorf("После отмены ожидалась вычисленная оценка 3, получено %+v", q)
+	}
+}
+
+func TestFailingStudentsUseTermGrades(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	deputy := createUser(t, st, "deputy", "deputy")
+
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	pupils := make(map[string]*models.Student)
+	for _, name := range []string{"Взвешенный", "Округленный", "Итоговая учителя"} {
+		pupil := &models.Student{FullName: name}
+		mustCreate(t, st.CreateStudent(ctx, pupil))
+		pupils[name] = pupil
+	}
+	first := termID(t, st, 1)
+	for _, g := range []models.Grade{
+		// Среднее 2.67, но с весом экзамена (2 + 2 + 4·3) / 5 = 3.2 — оценка 3
+		{StudentID: pupils["Взвешенный"].ID, Grade: 2, Kind: models.MarkKindClasswork},
+		{StudentID: pupils["Взвешенный"].ID, Grade: 2, Kind: models.MarkKindClasswork},
+		{StudentID: pupils["Взвешенный"].ID, Grade: 4, Kind: models.MarkKindExam},
+		// Среднее 2.5 округляется до 3
+		{StudentID: pupils["Округленный"].ID, Grade: 2, Kind: models.MarkKindClasswork},
+		{StudentID: pupils["Округленный"].ID, Grade: 3, Kind: models.MarkKindClasswork},
+		// Среднее 3, но учитель выставил за четверть 2
+		{StudentID: pupils["Итоговая учителя"].ID, Grade: 3, Kind: models.MarkKindClasswork},
+	} {
+		g.SubjectID, g.TermID = math.ID, first
+		mustCreate(t, st.CreateGrade(ctx, &g))
+	}
+	mustCreate(t, st.SetTermGrade(ctx, &models.TermGrade{StudentID: pupils["Итоговая учителя"].ID, SubjectID: math.ID, TermID: first, Grade: 2}))
+
+	var failing []models.FailingStudent
+	resp := do(t, router, "GET", "/stats/failing-students", nil, deputy)
+	json.Unmarshal(resp.Body.Bytes(), &failing)
+	if resp.Code != http.StatusOK || len(failing) != 1 || failing[0].ID != pupils["Итоговая учителя"].ID ||
+		failing[0].SubjectAverages[0].Grade != 2 {
+		t.Errorf("Неуспевающим ожидался только ученик с итоговой оценкой 2, получено %d %s", resp.Code, resp.Body.String())
+	}
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// validateBellPeriods проверяет расписание звонков: номера от 1 до 12 без повторов,
+// время в формате ЧЧ:ММ, уроки идут по порядку и не пересекаются
+func validateBellPeriods(periods []models.BellPeriod) error {
+	if len(periods) == 0 {
+		return errors.New("Нужен хотя бы один урок")
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Number < periods[j].Number })
+	var prevEnd time.Time
+	for i, p := range periods {
+		if p.Number < 1 || p.Number > maxLessonNumber {
+			return fmt.Errorf("Номер урока должен быть от 1 до %d", maxLessonNumber)
+		}
+		if i > 0 && p.Number == periods[i-1].Number {
+			return fmt.Errorf("Урок %d указан дважды", p.Number)
+		}
+		start, err1 := time.Parse(models.ClockLayout, p.StartsAt)
+		end, err2 := time.Parse(models.ClockLayout, p.EndsAt)
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("Время урока %d должно быть в формате ЧЧ:ММ", p.Number)
+		}
+		if !start.Before(end) {
+			return fmt.Errorf("Урок %d должен заканчиваться позже, чем начинается", p.Number)
+		}
+		if i > 0 && start.Before(prevEnd) {
+			return fmt.Errorf("Урок %d начинается раньше, чем заканчивается предыдущий", p.Number)
+		}
+		prevEnd = end
+	}
+	return nil
+}
+
+// validateLesson проверяет урок расписания из тела запроса
+func validateLesson(l *models.Lesson) error {
+	if l.ClassID < 1 || l.SubjectID < 1 || l.TeacherID < 1 {
+		return errors.New("Нужно указать класс, предмет и учителя")
+	}
+	if l.Weekday < 1 || l.Weekday > 7 {
+		return errors.New("День недели должен быть от 1 (понедельник) до 7 (воскресенье)")
+	}
+	if l.Period < 1 || l.Period > maxLessonNumber {
+		return fmt.Errorf("Номер урока должен быть от 1 до %d", maxLessonNumber)
+	}
+	if utf8.RuneCountInString(l.Room) > 50 {
+		return errors.New("Номер кабинета должен быть не длиннее 50 символов")
+	}
+	return nil
+}
+
+// writeLessonError отправляет ответ на ошибку сохранения урока расписания
+func writeLessonError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrArchived):
+		http.Error(w, "Учебный год в архиве", http.StatusConflict)
+	case errors.Is(err, store.ErrTeacherBusy):
+		http.Error(w, "У учителя уже есть урок в это время", http.StatusConflict)
+	case errors.Is(err, store.ErrRoomBusy):
+		http.Error(w, "Кабинет уже занят в это время", http.StatusConflict)
+	case errors.Is(err, store.ErrConflict):
+		http.Error(w, "У класса уже есть урок в это время", http.StatusConflict)
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, "Урок не найден", http.StatusNotFound)
+	case errors.Is(err, store.ErrReference):
+		http.Error(w, "Класс, предмет, учитель или номер урока в расписании звонков не найден", http.StatusBadRequest)
+	default:
+		http.Error(w, "Ошибка при сохранении урока", http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) GetBellSchedule(w http.ResponseWriter, r *http.Request) {
+	periods, err := s.Timetable.ListBellPeriods(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении расписания звонков: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if periods == nil {
+		periods = []models.BellPeriod{}
+	}
+	writeJSON(w, http.StatusOK, periods)
+}
+
+// SetBellSchedule заменяет расписание звонков целиком
+func (s *Server) SetBellSchedule(w http.ResponseWriter, r *http.Request) {
+	var periods []models.BellPeriod
+	if err := json.NewDecoder(r.Body).Decode(&periods); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if err := validateBellPeriods(periods); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.Timetable.SetBellPeriods(r.Context(), periods); err != nil {
+		log.Printf("Ошибка при сохранении расписания звонков: %v", err)
+		if storeErrorStatus(err) == http.StatusBadRequest {
+			http.Error(w, "Нельзя убрать номер урока, который есть в расписании уроков", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Ошибка при сохранении расписания звонков", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Расписание звонков обновлено, уроков: %d", len(periods))
+	writeJSON(w, http.StatusOK, periods)
+}
+
+// GetLessons возвращает уроки расписания. Параметры class_id, teacher_id, academic_year
+// и weekday сужают выборку.
+func (s *Server) GetLessons(w http.ResponseWriter, r *http.Request) {
+	var filter models.LessonFilter
+	query := r.URL.Query()
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{
+		{"class_id", &filter.ClassID},
+		{"teacher_id", &filter.TeacherID},
+		{"academic_year", &filter.AcademicYear},
+		{"weekday", &filter.Weekday},
+	} {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "Некорректный "+p.name, http.StatusBadRequest)
+			return
+		}
+		*p.value = n
+	}
+
+	lessons, err := s.Timetable.ListLessons(r.Context(), filter)
+	if err != nil {
+		log.Printf("Ошибка при получении расписания уроков: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	if lessons == nil {
+		lessons = []models.Lesson{}
+	}
+	writeJSON(w, http.StatusOK, lessons)
+}
+
+// lessonFromRequest читает и проверяет урок; без кабинета урок проходит в кабинете учителя.
+// При ошибке ответ уже отправлен.
+func (s *Server) lessonFromRequest(w http.ResponseWriter, r *http.Request) (*models.Lesson, bool) {
+	var lesson models.Lesson
+	if err := json.NewDecoder(r.Body).Decode(&lesson); err != nil {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return nil, false
+	}
+	if err := validateLesson(&lesson); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if lesson.Room == "" {
+		teacher, err := s.Teachers.GetTeacher(r.Context(), lesson.TeacherID)
+		if err != nil {
+			log.Printf("Ошибка при получении учителя %d: %v", lesson.TeacherID, err)
+			if storeErrorStatus(err) == http.StatusNotFound {
+				http.Error(w, "Учитель не найден", http.StatusBadRequest)
+			} else {
+				http.Error(w, "Ошибка при получении учителя", http.StatusInternalServerError)
+			}
+			return nil, false
+		}
+		lesson.Room = teacher.RoomNumber
+	}
+	return &lesson, true
+}
+
+func (s *Server) CreateLesson(w http.ResponseWriter, r *http.Request) {
+	lesson, ok := s.lessonFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := s.Timetable.CreateLesson(r.Context(), lesson); err != nil {
+		log.Printf("Ошибка при добавлении урока в расписание класса %d: %v", lesson.ClassID, err)
+		writeLessonError(w, err)
+		return
+	}
+
+	log.Printf("Урок %d добавлен в расписание класса %d: день %d, урок %d", lesson.ID, lesson.ClassID, lesson.Weekday, lesson.Period)
+	writeJSON(w, http.StatusCreated, lesson)
+}
+
+func (s *Server) UpdateLesson(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lesson, ok := s.lessonFromRequest(w, r)
+	if !ok {
+		return
+	}
+	lesson.ID = id
+	if err := s.Timetable.UpdateLesson(r.Context(), lesson); err != nil {
+		log.Printf("Ошибка при обновлении урока %d: %v", id, err)
+		writeLessonError(w, err)
+		return
+	}
+
+	log.Printf("Успешно обновлен урок с ID: %d", id)
+	writeJSON(w, http.StatusOK, lesson)
+}
+
+func (s *Server) DeleteLesson(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.Timetable.DeleteLesson(r.Context(), id); err != nil {
+		log.Printf("Ошибка при удалении урока %d: %v", id, err)
+		writeLessonError(w, err)
+		return
+	}
+
+	log.Printf("Успешно удален урок с ID: %d", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeSchedule отправляет расписание уроков из filter на день или неделю.
+// Параметры запроса: date — день (по умолчанию сегодня), range=week — вся неделя с этим днем.
+// В дни вне учебных периодов (каникулы) уроков нет.
+func (s *Server) writeSchedule(w http.ResponseWriter, r *http.Request, filter models.LessonFilter) {
+	day := models.NewDate(time.Now())
+	if v := r.URL.Query().Get("date"); v != "" {
+		parsed, err := models.ParseDate(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		day = parsed
+	}
+	days := []models.Date{day}
+	switch r.URL.Query().Get("range") {
+	case "", "day":
+	case "week":
+		monday := day.AddDate(0, 0, 1-models.ISOWeekday(day.Time))
+		days = days[:0]
+		for i := 0; i < 7; i++ {
+			days = append(days, models.NewDate(monday.AddDate(0, 0, i)))
+		}
+	default:
+		http.Error(w, "Параметр range должен быть day или week", http.StatusBadRequest)
+		return
+	}
+
+	bells, err := s.Timetable.ListBellPeriods(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении расписания звонков: %v", err)
+		http.Error(w, "Ошибка при получении расписания", http.StatusInternalServerError)
+		return
+	}
+	bellByNumber := make(map[int]models.BellPeriod, len(bells))
+	for _, b := range bells {
+		bellByNumber[b.Number] = b
+	}
+	// Неделя может захватывать два учебных года, поэтому уроки выбираются по году каждого дня
+	lessonsByYear := make(map[int][]models.Lesson)
+
+	schedule := make([]models.ScheduleDay, 0, len(days))
+	for _, d := range days {
+		entry := models.ScheduleDay{Date: d, Weekday: models.ISOWeekday(d.Time), Lessons: []models.ScheduleLesson{}}
+		_, err := s.termOfDate(r.Context(), d)
+		if err != nil && !errors.Is(err, errTermNotFound) {
+			log.Printf("Ошибка при определении учебного периода: %v", err)
+			http.Error(w, "Ошибка при получении расписания", http.StatusInternalServerError)
+			return
+		}
+		if err == nil {
+			year := models.AcademicYearOf(d.Time)
+			lessons, ok := lessonsByYear[year]
+			if !ok {
+				f := filter
+				f.AcademicYear = year
+				if lessons, err = s.Timetable.ListLessons(r.Context(), f); err != nil {
+					log.Printf("Ошибка при получении расписания уроков: %v", err)
+					http.Error(w, "Ошибка при получении расписания", http.StatusInternalServerError)
+					return
+				}
+				lessonsByYear[year] = lessons
+			}
+			for _, l := range lessons {
+				if l.Weekday == entry.Weekday {
+					bell := bellByNumber[l.Period]
+					entry.Lessons = append(entry.Lessons, models.ScheduleLesson{Lesson: l, StartsAt: bell.StartsAt, EndsAt: bell.EndsAt})
+				}
+			}
+		}
+		schedule = append(schedule, entry)
+	}
+	writeJSON(w, http.StatusOK, schedule)
+}
+
+// GetTeacherSchedule возвращает уроки текущего учителя на день или неделю
+func (s *Server) GetTeacherSchedule(w http.ResponseWriter, r *http.Request) {
+	teacher, ok := s.currentTeacher(w, r)
+	if !ok {
+		return
+	}
+	s.writeSchedule(w, r, models.LessonFilter{TeacherID: teacher.ID})
+}
+
+// GetMySchedule возвращает уроки класса текущего ученика на день или неделю
+func (s *Server) GetMySchedule(w http.ResponseWriter, r *http.Request) {
+	student, ok := s.currentStudent(w, r)
+	if !ok {
+		return
+	}
+	if student.ClassID == 0 {
+		http.Error(w, "Ученик не зачислен в класс", http.StatusNotFound)
+		return
+	}
+	s.writeSchedule(w, r, models.LessonFilter{ClassID: student.ClassID})
+}
+
+// GetClassSchedule возвращает уроки класса на день или неделю
+func (s *Server) GetClassSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := s.Classes.GetClass(r.Context(), id); err != nil {
+		log.Printf("Ошибка при получении класса %d: %v", id, err)
+		http.Error(w, "Класс не найден", storeErrorStatus(err))
+		return
+	}
+	s.writeSchedule(w, r, models.LessonFilter{ClassID: id})
+}
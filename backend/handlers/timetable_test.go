@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func TestTimetableConflictsAndSchedules(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	year := models.AcademicYearOf(time.Now())
+	deputy := createUser(t, st, "deputy", "deputy")
+	annaUser := createUser(t, st, "anna", "teacher")
+	pupilUser := createUser(t, st, "pupil", "student")
+
+	anna := &models.Teacher{FullName: "Иванова Анна", RoomNumber: "12", UserID: annaUser.ID}
+	mustCreate(t, st.CreateTeacher(ctx, anna))
+	oleg := &models.Teacher{FullName: "Петров Олег", RoomNumber: "14"}
+	mustCreate(t, st.CreateTeacher(ctx, oleg))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	physics := &models.Subject{Name: "Физика"}
+	mustCreate(t, st.CreateSubject(ctx, physics))
+	classA := &models.Class{GradeLevel: 9, Letter: "А", AcademicYear: year}
+	mustCreate(t, st.CreateClass(ctx, classA))
+	classB := &models.Class{GradeLevel: 9, Letter: "Б", AcademicYear: year}
+	mustCreate(t, st.CreateClass(ctx, classB))
+	mustCreate(t, st.CreateStudent(ctx, &models.Student{FullName: "Иван Иванов", ClassID: classB.ID, UserID: pupilUser.ID}))
+
+	create := func(user *models.User, l models.Lesson) (int, string) {
+		t.Helper()
+		body, _ := json.Marshal(l)
+		resp := do(t, router, "POST", "/lessons", bytes.NewReader(body), user)
+		return resp.Code, resp.Body.String()
+	}
+	monday := func(l models.Lesson) models.Lesson {
+		l.Weekday, l.Period = 1, 1
+		return l
+	}
+
+	if code, _ := create(annaUser, monday(models.Lesson{ClassID: classA.ID, SubjectID: math.ID, TeacherID: anna.ID})); code != http.StatusForbidden {
+		t.Errorf("Учитель без timetable:manage: ожидался статус 403, получен %d", code)
+	}
+	code, respBody := create(deputy, monday(models.Lesson{ClassID: classA.ID, SubjectID: math.ID, TeacherID: anna.ID}))
+	var lesson models.Lesson
+	json.Unmarshal([]byte(respBody), &lesson)
+	if code != http.StatusCreated || lesson.Room != "12" || lesson.AcademicYear != year {
+		t.Fatalf("Урок без кабинета должен пройти в кабинете учителя, получено %d %s", code, respBody)
+	}
+
+	for _, c := range []struct {
+		name   string
+		lesson models.Lesson
+		want   string
+	}{
+		{"учитель", models.Lesson{ClassID: classB.ID, SubjectID: physics.ID, TeacherID: anna.ID, Room: "20"}, "учителя"},
+		{"класс", models.Lesson{ClassID: classA.ID, SubjectID: physics.ID, TeacherID: oleg.ID}, "класса"},
+		{"кабинет", models.Lesson{ClassID: classB.ID, SubjectID: physics.ID, TeacherID: oleg.ID, Room: "12"}, "Кабинет"},
+	} {
+		if code, body := create(deputy, monday(c.lesson)); code != http.StatusConflict || !strings.Contains(body, c.want) {
+			t.Errorf("Занят %s: ожидался статус 409, получено %d %s", c.name, code, body)
+		}
+	}
+	if code, body := create(deputy, monday(models.Lesson{ClassID: classB.ID, SubjectID: physics.ID, TeacherID: oleg.ID})); code != http.StatusCreated {
+		t.Fatalf("Урок в свободном кабинете: ожидался статус 201, получено %d %s", code, body)
+	}
+
+	// Звонки: номер урока из расписания убрать нельзя, время поменять можно
+	bells := []models.BellPeriod{{Number: 2, StartsAt: "09:00", EndsAt: "09:45"}}
+	body, _ := json.Marshal(bells)
+	if resp := do(t, router, "PUT", "/bell-schedule", bytes.NewReader(body), deputy); resp.Code != http.StatusConflict {
+		t.Errorf("Удаление занятого урока из звонков: ожидался статус 409, получен %d", resp.Code)
+	}
+	bells = []models.BellPeriod{{Number: 1, StartsAt: "08:00", EndsAt: "08:45"}, {Number: 2, StartsAt: "08:40", EndsAt: "09:25"}}
+	body, _ = json.Marshal(bells)
+	if resp := do(t, router, "PUT", "/bell-schedule", bytes.NewReader(body), deputy); resp.Code != http.StatusBadRequest {
+		t.Errorf("Пересекающиеся уроки: ожидался статус 400, получен %d", resp.Code)
+	}
+	bells[1].StartsAt = "08:55"
+	body, _ = json.Marshal(bells)
+	if resp := do(t, router, "PUT", "/bell-schedule", bytes.NewReader(body), deputy); resp.Code != http.StatusOK {
+		t.Fatalf("Новые звонки: ожидался статус 200, получен %d %s", resp.Code, resp.Body.String())
+	}
+
+	academicYear, err := st.GetAcademicYear(ctx, year)
+	mustCreate(t, err)
+	day := academicYear.Terms[0].StartsOn
+	day = models.NewDate(day.AddDate(0, 0, (8-models.ISOWeekday(day.Time))%7))
+	schedule := func(user *models.User, path string) []models.ScheduleDay {
+		t.Helper()
+		var days []models.ScheduleDay
+		resp := do(t, router, "GET", path, nil, user)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("%s: ожидался статус 200, получен %d %s", path, resp.Code, resp.Body.String())
+		}
+		json.Unmarshal(resp.Body.Bytes(), &days)
+		return days
+	}
+
+	week := schedule(annaUser, "/teacher/schedule?range=week&date="+day.AddDate(0, 0, 2).Format(models.DateLayout))
+	if len(week) != 7 || !week[0].Date.Equal(day.Time) || len(week[0].Lessons) != 1 ||
+		week[0].Lessons[0].ClassName != "9А" || week[0].Lessons[0].StartsAt != "08:00" || len(week[1].Lessons) != 0 {
+		t.Errorf("Неделя учителя: получено %+v", week)
+	}
+	today := schedule(pupilUser, "/me/schedule?date="+day.String())
+	if len(today) != 1 || len(today[0].Lessons) != 1 || today[0].Lessons[0].SubjectName != "Физика" ||
+		today[0].Lessons[0].TeacherName != "Петров Олег" || today[0].Lessons[0].Room != "14" {
+		t.Errorf("День ученика: получено %+v", today)
+	}
+
+	// Летом уроков нет
+	summer := academicYear.Terms[len(academicYear.Terms)-1].EndsOn.AddDate(0, 0, 1)
+	summer = summer.AddDate(0, 0, (8-models.ISOWeekday(summer))%7)
+	path := fmt.Sprintf("/classes/%d/schedule?date=%s", classA.ID, summer.Format(models.DateLayout))
+	if days := schedule(deputy, path); len(days) != 1 || len(days[0].Lessons) != 0 {
+		t.Errorf("Каникулы: ожидался день без уроков, получено %+v", days)
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"school-system/backend/middleware"
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// randomToken возвращает криптографически случайную строку из n байт в base64url
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken возвращает SHA-256 хеш токена; в базе хранятся только хеши
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenResponse — ответ на успешный вход или обновление токена
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	// MustChangePassword — токен действует только для смены пароля и выхода
+	MustChangePassword bool `json:"must_change_password,omitempty"`
+	// Pending — обязательное действие, без которого токен принимается не всеми маршрутами
+	Pending string `json:"pending,omitempty"`
+}
+
+// pendingAction возвращает обязательное действие, которое пользователь должен выполнить
+// до получения полного доступа, или пустую строку
+func (s *Server) pendingAction(ctx context.Context, user *models.User) (string, error) {
+	if user.MustChangePassword {
+		return middleware.PendingPasswordChange, nil
+	}
+	if s.Auth.TOTPRequired(user.Role) {
+		settings, err := s.TwoFactor.GetTOTP(ctx, user.ID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return "", err
+		}
+		if !settings.Enabled() {
+			return middleware.PendingTOTPEnrollment, nil
+		}
+	}
+	return "", nil
+}
+
+// signAccessToken выпускает короткоживущий токен доступа с уникальным jti
+func (s *Server) signAccessToken(user *models.User, pending string, now time.Time) (string, string, time.Time, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	expiresAt := now.Add(s.Auth.AccessTokenTTL)
+
+	claims := jwt.MapClaims{
+		"user_id": user.ID,
+		"role":    user.Role,
+		"typ":     "access",
+		"jti":     jti,
+		"iat":     now.Unix(),
+		"exp":     expiresAt.Unix(),
+	}
+	if pending != "" {
+		claims["pending"] = pending
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(getJWTSecret())
+	return signed, jti, expiresAt, err
+}
+
+// issueTokens выпускает пару токенов: доступа и обновления.
+// Если previous не nil, старый токен обновления отзывается (ротация).
+func (s *Server) issueTokens(ctx context.Context, user *models.User, previous *models.RefreshToken) (*tokenResponse, error) {
+	pending, err := s.pendingAction(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	accessToken, jti, accessExpiresAt, err := s.signAccessToken(user, pending, now)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	record := &models.RefreshToken{
+		UserID:          user.ID,
+		TokenHash:       hashToken(refreshToken),
+		AccessJTI:       jti,
+		AccessExpiresAt: accessExpiresAt,
+		ExpiresAt:       now.Add(s.Auth.RefreshTokenTTL),
+	}
+
+	if previous != nil {
+		err = s.Tokens.RotateRefreshToken(ctx, previous.ID, record)
+	} else {
+		err = s.Tokens.CreateRefreshToken(ctx, record)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.Auth.AccessTokenTTL.Seconds()),
+
+		MustChangePassword: user.MustChangePassword,
+		Pending:            pending,
+	}, nil
+}
@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"school-system/backend/models"
+	"school-system/backend/totp"
+)
+
+const (
+	// challengeTTL — время на ввод кода после успешной проверки пароля
+	challengeTTL = 5 * time.Minute
+	// totpSkew — допуск на расхождение часов в интервалах по 30 секунд
+	totpSkew = 1
+	// recoveryCodeCount — количество кодов восстановления
+	recoveryCodeCount = 10
+)
+
+// challengeResponse — ответ /login для пользователя с включенной двухфакторной аутентификацией
+type challengeResponse struct {
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int64  `json:"expires_in"`
+}
+
+// signChallengeToken выпускает промежуточный токен (typ "mfa"), который
+// подтверждает проверку пароля и обменивается на токены через /login/2fa
+func (s *Server) signChallengeToken(user *models.User, now time.Time) (string, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID,
+		"typ":     "mfa",
+		"jti":     jti,
+		"iat":     now.Unix(),
+		"exp":     now.Add(challengeTTL).Unix(),
+	})
+	return token.SignedString(getJWTSecret())
+}
+
+// parseChallengeToken проверяет промежуточный токен и возвращает user_id, jti и срок действия
+func parseChallengeToken(tokenStr string) (int, string, time.Time, error) {
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("неожиданный метод подписи: %v", token.Header["alg"])
+		}
+		return getJWTSecret(), nil
+	}, jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, "", time.Time{}, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != "mfa" {
+		return 0, "", time.Time{}, errors.New("токен не является токеном подтверждения входа")
+	}
+	userID, _ := claims["user_id"].(float64)
+	jti, _ := claims["jti"].(string)
+	exp, err := claims.GetExpirationTime()
+	if err != nil || userID == 0 || jti == "" {
+		return 0, "", time.Time{}, errors.New("в токене подтверждения нет обязательных полей")
+	}
+	return int(userID), jti, exp.Time, nil
+}
+
+// generateRecoveryCodes возвращает коды восстановления вида xxxxx-xxxxx и их хеши
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 6)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(enc.EncodeToString(b))
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode убирает дефисы и пробелы, которые пользователь мог ввести
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// LoginTwoFactor завершает вход: проверяет код из приложения или код восстановления
+func (s *Server) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Получен запрос на подтверждение входа вторым фактором")
+	var req TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChallengeToken == "" {
+		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+		return
+	}
+
+	userID, jti, expiresAt, err := parseChallengeToken(req.ChallengeToken)
+	if err != nil {
+		log.Printf("Ошибка при проверке токена подтверждения: %v", err)
+		http.Error(w, "Недействительный или истекший токен подтверждения", http.StatusUnauthorized)
+		return
+	}
+	revoked, err := s.Tokens.IsAccessTokenRevoked(r.Context(), jti)
+	if err != nil {
+		log.Printf("Ошибка при проверке отзыва токена подтверждения: %v", err)
+		http.Error(w, "Ошибка при входе в систему", http.StatusInternalServerError)
+		return
+	}
+	if revoked {
+		http.Error(w, "Недействительный или истекший токен подтверждения", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := s.Users.GetUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Ошибка при получении пользователя %d: %v", userID, err)
+		http.Error(w, "Недействительный или истекший токен подтверждения", http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now()
+	username := throttleUsername(user.Username)
+	ip := clientIP(r)
+	wait, err := s.loginRetryAfter(r.Context(), now, username, ip)
+	if err != nil {
+		log.Printf("Ошибка при проверке блокировки входа: %v", err)
+		http.Error(w, "Ошибка при входе в систему", http.StatusInternalServerError)
+		return
+	}
+	if wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, "Слишком много неудачных попыток входа. Повторите позже", http.StatusTooManyRequests)
+		return
+	}
+
+	settings, err := s.TwoFactor.GetTOTP(r.Context(), userID)
+	if err != nil || !settings.Enabled() {
+		log.Printf("Двухфакторная аутентификация пользователя %d не настроена: %v", userID, err)
+		http.Error(w, "Недействительный или истекший токен подтверждения", http.StatusUnauthorized)
+		return
+	}
+
+	verified := false
+	switch {
+	case req.Code != "":
+		if step, ok := totp.Validate(settings.Secret, req.Code, now, totpSkew); ok {
+			// Код каждого интервала принимается только один раз
+			verified = s.TwoFactor.UseTOTPStep(r.Context(), userID, step) == nil
+		}
+	case req.RecoveryCode != "":
+		verified = s.TwoFactor.UseRecoveryCode(r.Context(), userID, hashToken(normalizeRecoveryCode(req.RecoveryCode))) == nil
+		if verified {
+			log.Printf("Пользователь %d вошел по коду восстановления", userID)
+		}
+	}
+	if !verified {
+		log.Printf("Неверный код подтверждения для пользователя %d с адреса %s", userID, ip)
+		s.recordLoginFailure(r.Context(), now, username, ip)
+		http.Error(w, "Неверный код подтверждения", http.StatusUnauthorized)
+		return
+	}
+
+	// Токен подтверждения одноразовый
+	if err := s.Tokens.RevokeSession(r.Context(), userID, jti, expiresAt); err != nil {
+		log.Printf("Ошибка при погашении токена подтверждения: %v", err)
+		http.Error(w, "Ошибка при входе в систему", http.StatusInternalServerError)
+		return
+	}
+
+	tokens, err := s.issueTokens(r.Context(), user, nil)
+	if err != nil {
+		log.Printf("Ошибка при генерации токена для пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка генерации токена", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Успешный вход пользователя с двухфакторной аутентификацией: %s", user.Username)
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// twoFactorSetupResponse — секрет для ручного ввода и ссылка для QR-кода
+type twoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// SetupTwoFactor начинает настройку двухфакторной аутентификации
+func (s *Server) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Получен запрос на настройку двухфакторной аутентификации пользователя %d", userID)
+
+	user, err := s.Users.GetUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Ошибка при получении пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при настройке двухфакторной аутентификации", storeErrorStatus(err))
+		return
+	}
+	settings, err := s.TwoFactor.GetTOTP(r.Context(), userID)
+	if err == nil && settings.Enabled() {
+		http.Error(w, "Двухфакторная аутентификация уже включена", http.StatusConflict)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		log.Printf("Ошибка при генерации секрета TOTP: %v", err)
+		http.Error(w, "Ошибка при настройке двухфакторной аутентификации", http.StatusInternalServerError)
+		return
+	}
+	if err := s.TwoFactor.SaveTOTPSecret(r.Context(), userID, secret); err != nil {
+		log.Printf("Ошибка при сохранении секрета TOTP пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при настройке двухфакторной аутентификации", storeErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, twoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.Auth.TOTPIssuer, user.Username, secret),
+	})
+}
+
+type TwoFactorCodeRequest struct {
+	Code     string `json:"code"`
+	Password string `json:"password"`
+}
+
+// twoFactorConfirmResponse — коды восстановления (показываются один раз) и новая пара токенов
+type twoFactorConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	tokenResponse
+}
+
+// ConfirmTwoFactor включает двухфакторную аутентификацию после ввода пароля и первого кода.
+// Остальные сессии пользователя завершаются.
+func (s *Server) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := s.TwoFactor.GetTOTP(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Сначала начните настройку двухфакторной аутентификации", http.StatusBadRequest)
+		return
+	}
+	if settings.Enabled() {
+		http.Error(w, "Двухфакторная аутентификация уже включена", http.StatusConflict)
+		return
+	}
+	user, err := s.Users.GetUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Ошибка при получении пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при настройке двухфакторной аутентификации", storeErrorStatus(err))
+		return
+	}
+	// Без пароля украденный токен позволил бы включить 2FA со своим приложением и закрыть владельцу вход
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+		http.Error(w, "Неверный пароль", http.StatusForbidden)
+		return
+	}
+	step, ok := totp.Validate(settings.Secret, req.Code, time.Now(), totpSkew)
+	if !ok {
+		http.Error(w, "Неверный код подтверждения", http.StatusBadRequest)
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		log.Printf("Ошибка при генерации кодов восстановления: %v", err)
+		http.Error(w, "Ошибка при настройке двухфакторной аутентификации", http.StatusInternalServerError)
+		return
+	}
+	if err := s.TwoFactor.ConfirmTOTP(r.Context(), userID, step, hashes); err != nil {
+		log.Printf("Ошибка при включении двухфакторной аутентификации пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при настройке двухфакторной аутентификации", storeErrorStatus(err))
+		return
+	}
+	if err := s.Tokens.RevokeUserTokens(r.Context(), userID); err != nil {
+		log.Printf("Ошибка при отзыве сессий пользователя %d: %v", userID, err)
+	}
+
+	tokens, err := s.issueTokens(r.Context(), user, nil)
+	if err != nil {
+		log.Printf("Ошибка при генерации токена для пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка генерации токена", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Пользователь %d включил двухфакторную аутентификацию", userID)
+	writeJSON(w, http.StatusOK, twoFactorConfirmResponse{RecoveryCodes: codes, tokenResponse: *tokens})
+}
+
+// DisableTwoFactor отключает двухфакторную аутентификацию по паролю и коду из приложения
+func (s *Server) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Не удалось определить user_id", http.StatusUnauthorized)
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.Users.GetUser(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Ошибка при отключении двухфакторной аутентификации", storeErrorStatus(err))
+		return
+	}
+	if s.Auth.TOTPRequired(user.Role) {
+		http.Error(w, "Для вашей роли двухфакторная аутентификация обязательна", http.StatusForbidden)
+		return
+	}
+	settings, err := s.TwoFactor.GetTOTP(r.Context(), userID)
+	if err != nil || !settings.Enabled() {
+		http.Error(w, "Двухфакторная аутентификация не включена", http.StatusNotFound)
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+		http.Error(w, "Неверный пароль", http.StatusForbidden)
+		return
+	}
+	step, ok := totp.Validate(settings.Secret, req.Code, time.Now(), totpSkew)
+	if !ok || s.TwoFactor.UseTOTPStep(r.Context(), userID, step) != nil {
+		http.Error(w, "Неверный код подтверждения", http.StatusForbidden)
+		return
+	}
+
+	if err := s.TwoFactor.DeleteTOTP(r.Context(), userID); err != nil {
+		log.Printf("Ошибка при отключении двухфакторной аутентификации пользователя %d: %v", userID, err)
+		http.Error(w, "Ошибка при отключении двухфакторной аутентификации", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Пользователь %d отключил двухфакторную аутентификацию", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetTwoFactor сбрасывает двухфакторную аутентификацию пользователя,
+// потерявшего телефон и коды восстановления (только завуч)
+func (s *Server) ResetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Получен запрос на сброс двухфакторной аутентификации пользователя %d", id)
+
+	if err := s.TwoFactor.DeleteTOTP(r.Context(), id); err != nil {
+		log.Printf("Ошибка при сбросе двухфакторной аутентификации пользователя %d: %v", id, err)
+		http.Error(w, "Двухфакторная аутентификация не включена", storeErrorStatus(err))
+		return
+	}
+	if err := s.Tokens.RevokeUserTokens(r.Context(), id); err != nil {
+		log.Printf("Ошибка при отзыве сессий пользователя %d: %v", id, err)
+	}
+
+	log.Printf("Двухфакторная аутентификация пользователя %d сброшена", id)
+	w.WriteHeader(http.StatusNoContent)
+}
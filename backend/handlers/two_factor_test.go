@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"school-system/backend/middleware"
+	"school-system/backend/totp"
+)
+
+// enrollTwoFactor включает 2FA от имени пользователя с паролем password и возвращает секрет и ответ подтверждения
+func enrollTwoFactor(t *testing.T, h http.Handler, accessToken, password string) (string, twoFactorConfirmResponse) {
+	t.Helper()
+	resp := postJSON(t, h, "/me/2fa/setup", nil, accessToken)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Настройка 2FA: ожидался статус 200, получен %d: %s", resp.Code, resp.Body.String())
+	}
+	var setup twoFactorSetupResponse
+	json.Unmarshal(resp.Body.Bytes(), &setup)
+
+	code, _ := totp.Code(setup.Secret, totp.Step(time.Now()))
+	resp = postJSON(t, h, "/me/2fa/confirm", TwoFactorCodeRequest{Code: code, Password: password}, accessToken)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Подтверждение 2FA: ожидался статус 200, получен %d: %s", resp.Code, resp.Body.String())
+	}
+	var confirmed twoFactorConfirmResponse
+	json.Unmarshal(resp.Body.Bytes(), &confirmed)
+	return setup.Secret, confirmed
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	createUserWithPassword(t, st, "teacher", "teacher-password", "teacher")
+
+	secret, confirmed := enrollTwoFactor(t, router, login(t, router, "teacher", "teacher-password").Token, "teacher-password")
+	if len(confirmed.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("Ожидалось %d кодов восстановления, получено %d", recoveryCodeCount, len(confirmed.RecoveryCodes))
+	}
+
+	challengeFor := func() string {
+		resp := postJSON(t, router, "/login", Credentials{Username: "teacher", Password: "teacher-password"}, "")
+		var challenge challengeResponse
+		json.Unmarshal(resp.Body.Bytes(), &challenge)
+		if !challenge.MFARequired || challenge.ChallengeToken == "" {
+			t.Fatalf("После пароля ожидался запрос кода: %s", resp.Body.String())
+		}
+		return challenge.ChallengeToken
+	}
+
+	// Промежуточный токен не дает доступа к API
+	challenge := challengeFor()
+	if resp := getWithToken(router, "/verify-token", challenge); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Токен подтверждения как токен доступа: ожидался статус 401, получен %d", resp.Code)
+	}
+	if resp := postJSON(t, router, "/login/2fa", TwoFactorLoginRequest{ChallengeToken: challenge, Code: "000000"}, ""); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Неверный код: ожидался статус 401, получен %d", resp.Code)
+	}
+
+	// Код текущего интервала уже использован при подтверждении настройки — берем следующий
+	next, _ := totp.Code(secret, totp.Step(time.Now())+1)
+	resp := postJSON(t, router, "/login/2fa", TwoFactorLoginRequest{ChallengeToken: challenge, Code: next}, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Вход с кодом: ожидался статус 200, получен %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := postJSON(t, router, "/login/2fa", TwoFactorLoginRequest{ChallengeToken: challengeFor(), Code: next}, ""); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Повторное использование кода: ожидался статус 401, получен %d", resp.Code)
+	}
+
+	recovery := TwoFactorLoginRequest{ChallengeToken: challengeFor(), RecoveryCode: confirmed.RecoveryCodes[0]}
+	if resp := postJSON(t, router, "/login/2fa", recovery, ""); resp.Code != http.StatusOK {
+		t.Fatalf("Вход по коду восстановления: ожидался статус 200, получен %d", resp.Code)
+	}
+	if resp := postJSON(t, router, "/login/2fa", recovery, ""); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Токен подтверждения и код восстановления одноразовые, получен %d", resp.Code)
+	}
+}
+
+func TestTwoFactorRequiredForRole(t *testing.T) {
+	s, st := newTestServer(t)
+	s.Auth.TOTPRequiredRoles = []string{"deputy"}
+	router := s.Router()
+	createUserWithPassword(t, st, "deputy", "deputy-password", "deputy")
+
+	first := login(t, router, "deputy", "deputy-password")
+	if first.Pending != middleware.PendingTOTPEnrollment {
+		t.Fatalf("Ожидалось требование настроить 2FA, получено %q", first.Pending)
+	}
+	if resp := getWithToken(router, "/login-lockouts", first.Token); resp.Code != http.StatusForbidden {
+		t.Errorf("До настройки 2FA: ожидался статус 403, получен %d", resp.Code)
+	}
+
+	_, confirmed := enrollTwoFactor(t, router, first.Token, "deputy-password")
+	if confirmed.Pending != "" {
+		t.Errorf("После настройки 2FA не должно оставаться обязательных действий: %q", confirmed.Pending)
+	}
+	if resp := getWithToken(router, "/login-lockouts", confirmed.Token); resp.Code != http.StatusOK {
+		t.Errorf("После настройки 2FA: ожидался статус 200, получен %d", resp.Code)
+	}
+	if resp := sendJSON(t, router, "DELETE", "/me/2fa", TwoFactorCodeRequest{Password: "deputy-password"}, confirmed.Token); resp.Code != http.StatusForbidden {
+		t.Errorf("Отключение обязательной 2FA: ожидался статус 403, получен %d", resp.Code)
+	}
+}
+
+func TestTwoFactorConfirmRequiresPassword(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	createUserWithPassword(t, st, "teacher", "teacher-password", "teacher")
+	token := login(t, router, "teacher", "teacher-password").Token
+
+	resp := postJSON(t, router, "/me/2fa/setup", nil, token)
+	var setup twoFactorSetupResponse
+	json.Unmarshal(resp.Body.Bytes(), &setup)
+	code, _ := totp.Code(setup.Secret, totp.Step(time.Now()))
+	for _, password := range []string{"", "wrong-password"} {
+		resp := postJSON(t, router, "/me/2fa/confirm", TwoFactorCodeRequest{Code: code, Password: password}, token)
+		if resp.Code != http.StatusForbidden {
+			t.Errorf("Подтверждение 2FA с паролем %q: ожидался статус 403, получен %d", password, resp.Code)
+		}
+	}
+
+	// 2FA не включена, а сессия владельца не завершена
+	resp = postJSON(t, router, "/login", Credentials{Username: "teacher", Password: "teacher-password"}, "")
+	var challenge challengeResponse
+	json.Unmarshal(resp.Body.Bytes(), &challenge)
+	if resp.Code != http.StatusOK || challenge.MFARequired {
+		t.Errorf("После отказа вход не должен требовать код: %s", resp.Body.String())
+	}
+	if resp := getWithToken(router, "/verify-token", token); resp.Code != http.StatusOK {
+		t.Errorf("Сессия владельца должна действовать, получен %d", resp.Code)
+	}
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"school-system/backend/models"
+)
+
+// graduationLevel — параллель выпускных классов по умолчанию
+const graduationLevel = 11
+
+// YearEndRequest — параметры закрытия учебного года
+type YearEndRequest struct {
+	// GraduationLevel — параллель, ученики которой становятся выпускниками (по умолчанию 11)
+	GraduationLevel int `json:"graduation_level"`
+}
+
+// annualGradesOf вычисляет годовые оценки ученика: среднее итоговых оценок за периоды,
+// округленное по правилам оценивания. Учитываются периоды с отметками или оценкой учителя.
+func annualGradesOf(subjects []models.SubjectGrades, rules models.GradingRules) []models.AnnualGrade {
+	grades := []models.AnnualGrade{}
+	for _, subject := range subjects {
+		var sum float64
+		var count int
+		for _, q := range subject.Quarters {
+			if len(q.Grades) == 0 && !q.Overridden {
+				continue
+			}
+			sum += float64(q.Final)
+			count++
+		}
+		if count == 0 {
+			continue
+		}
+		average := sum / float64(count)
+		grade := rules.Round(average)
+		grades = append(grades, models.AnnualGrade{
+			SubjectID:      subject.SubjectID,
+			SubjectName:    subject.SubjectName,
+			Average:        roundAverage(average),
+			Grade:          grade,
+			Unsatisfactory: grade < subject.Scale.PassValue,
+		})
+	}
+	return grades
+}
+
+// annualGrades возвращает годовые оценки ученика (0 — всех учеников): у закрытого года —
+// сохраненные при закрытии, у текущего — вычисленные по оценкам на сегодня
+func (s *Server) annualGrades(ctx context.Context, year *models.AcademicYear, studentID int) ([]models.AnnualGrade, error) {
+	if year.Archived {
+		return s.AnnualGrades.ListAnnualGrades(ctx, year.StartYear, studentID)
+	}
+
+	period := models.Period{AcademicYear: year.StartYear}
+	grades, err := s.Grades.GetStudentGrades(ctx, studentID, period)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.TermGrades.ListTermGrades(ctx, studentID, period)
+	if err != nil {
+		return nil, err
+	}
+	students, err := s.Students.ListStudents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scales, err := s.subjectScales(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byStudent := make(map[int][]models.GradeWithSubject)
+	for _, g := range grades {
+		byStudent[g.StudentID] = append(byStudent[g.StudentID], g)
+	}
+	overridesByStudent := make(map[int][]models.TermGrade)
+	for _, o := range overrides {
+		overridesByStudent[o.StudentID] = append(overridesByStudent[o.StudentID], o)
+	}
+
+	result := []models.AnnualGrade{}
+	for _, student := range students {
+		if len(byStudent[student.ID]) == 0 && len(overridesByStudent[student.ID]) == 0 {
+			continue
+		}
+		subjects := groupGrades(byStudent[student.ID], overridesByStudent[student.ID], s.Grading)
+		applyScales(subjects, scales)
+		for _, g := range annualGradesOf(subjects, s.Grading) {
+			g.StudentID = student.ID
+			g.AcademicYear = year.StartYear
+			g.ClassID, g.ClassName = student.ClassID, student.ClassName
+			result = append(result, g)
+		}
+	}
+	return result, nil
+}
+
+// yearFromPath читает учебный год из пути. При ошибке ответ уже отправлен.
+func (s *Server) yearFromPath(w http.ResponseWriter, r *http.Request) (*models.AcademicYear, bool) {
+	startYear, err := pathInt(r, "year")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	year, err := s.AcademicYears.GetAcademicYear(r.Context(), startYear)
+	if err != nil {
+		log.Printf("Ошибка при получении учебного года %d: %v", startYear, err)
+		http.Error(w, "Учебный год не найден", storeErrorStatus(err))
+		return nil, false
+	}
+	return year, true
+}
+
+// GetStudentAnnualGrades возвращает годовые оценки ученика за учебный год (параметр academic_year).
+// Доступ — как к итоговым оценкам за периоды: учитель видит только свои предметы.
+func (s *Server) GetStudentAnnualGrades(w http.ResponseWriter, r *http.Request) {
+	studentID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	access, ok := s.gradeAccessFor(w, r)
+	if !ok {
+		return
+	}
+	own := access.ownsStudent(studentID)
+	if !own && !access.isTeacher() {
+		http.Error(w, "Нет доступа к оценкам этого ученика", http.StatusForbidden)
+		return
+	}
+	period, ok := periodFromRequest(w, r)
+	if !ok {
+		return
+	}
+	year, err := s.AcademicYears.GetAcademicYear(r.Context(), period.AcademicYear)
+	if err != nil {
+		http.Error(w, "Учебный год не найден", storeErrorStatus(err))
+		return
+	}
+
+	grades, err := s.annualGrades(r.Context(), year, studentID)
+	if err != nil {
+		log.Printf("Ошибка при получении годовых оценок ученика %d: %v", studentID, err)
+		http.Error(w, "Ошибка при получении оценок", http.StatusInternalServerError)
+		return
+	}
+	visible := []models.AnnualGrade{}
+	for _, g := range grades {
+		if own || access.teachesInYear(studentID, g.SubjectID, year.StartYear) {
+			visible = append(visible, g)
+		}
+	}
+	writeJSON(w, http.StatusOK, visible)
+}
+
+// GetYearAnnualGrades возвращает годовые оценки учеников за учебный год, сгруппированные
+// по ученикам. Параметры: class_id — класс на конец года, unsatisfactory=true — только
+// ученики с неудовлетворительными годовыми оценками.
+func (s *Server) GetYearAnnualGrades(w http.ResponseWriter, r *http.Request) {
+	year, ok := s.yearFromPath(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	classID := 0
+	if v := query.Get("class_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 1 {
+			http.Error(w, "Некорректный class_id", http.StatusBadRequest)
+			return
+		}
+		classID = id
+	}
+	onlyUnsatisfactory := query.Get("unsatisfactory") == "true"
+
+	grades, err := s.annualGrades(r.Context(), year, 0)
+	if err != nil {
+		log.Printf("Ошибка при получении годовых оценок за %d год: %v", year.StartYear, err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	students, err := s.Students.ListStudents(r.Context())
+	if err != nil {
+		log.Printf("Ошибка при получении учеников: %v", err)
+		http.Error(w, "Ошибка при получении данных", http.StatusInternalServerError)
+		return
+	}
+	byID := make(map[int]models.Student, len(students))
+	for _, student := range students {
+		byID[student.ID] = student
+	}
+
+	result := []models.StudentAnnualGrades{}
+	index := make(map[int]int)
+	for _, g := range grades {
+		if classID != 0 && g.ClassID != classID {
+			continue
+		}
+		i, ok := index[g.StudentID]
+		if !ok {
+			i = len(result)
+			index[g.StudentID] = i
+			result = append(result, models.StudentAnnualGrades{Student: byID[g.StudentID], Grades: []models.AnnualGrade{}})
+		}
+		result[i].Grades = append(result[i].Grades, g)
+		result[i].Unsatisfactory = result[i].Unsatisfactory || g.Unsatisfactory
+	}
+	if onlyUnsatisfactory {
+		filtered := []models.StudentAnnualGrades{}
+		for _, student := range result {
+			if student.Unsatisfactory {
+				filtered = append(filtered, student)
+			}
+		}
+		result = filtered
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].FullName < result[j].FullName })
+	writeJSON(w, http.StatusOK, result)
+}
+
+// CloseAcademicYear завершает учебный год: фиксирует годовые оценки, переводит классы
+// в следующий год (9А → 10А; следующий год создается с типовыми периодами, если его нет),
+// отмечает выпускников и переводит год в архив только для чтения. Ученики с
+// неудовлетворительными годовыми оценками переводятся условно и перечисляются в ответе.
+// Перевод и выпуск каждого ученика записываются в журнал изменений.
+func (s *Server) CloseAcademicYear(w http.ResponseWriter, r *http.Request) {
+	year, ok := s.yearFromPath(w, r)
+	if !ok {
+		return
+	}
+	req := YearEndRequest{GraduationLevel: graduationLevel}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Невозможно прочитать данные", http.StatusBadRequest)
+		return
+	}
+	if req.GraduationLevel < 1 || req.GraduationLevel > 11 {
+		http.Error(w, "Параллель выпускников должна быть от 1 до 11", http.StatusBadRequest)
+		return
+	}
+	if year.Archived {
+		http.Error(w, "Учебный год уже закрыт", http.StatusConflict)
+		return
+	}
+
+	annual, err := s.annualGrades(r.Context(), year, 0)
+	if err != nil {
+		log.Printf("Ошибка при вычислении годовых оценок за %d год: %v", year.StartYear, err)
+		http.Error(w, "Ошибка при закрытии учебного года", http.StatusInternalServerError)
+		return
+	}
+	if _, err := s.AcademicYears.GetAcademicYear(r.Context(), year.StartYear+1); err != nil {
+		if storeErrorStatus(err) != http.StatusNotFound {
+			log.Printf("Ошибка при получении учебного года %d: %v", year.StartYear+1, err)
+			http.Error(w, "Ошибка при закрытии учебного года", http.StatusInternalServerError)
+			return
+		}
+		next, err := academicYearFromRequest(AcademicYearRequest{StartYear: year.StartYear + 1, TermKind: year.TermKind})
+		if err == nil {
+			err = s.AcademicYears.CreateAcademicYear(r.Context(), next)
+		}
+		if err != nil && storeErrorStatus(err) != http.StatusConflict {
+			log.Printf("Ошибка при создании учебного года %d: %v", year.StartYear+1, err)
+			http.Error(w, "Ошибка при создании следующего учебного года", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	result, err := s.AnnualGrades.CloseAcademicYear(auditContext(r), year.StartYear, annual, req.GraduationLevel)
+	if err != nil {
+		log.Printf("Ошибка при закрытии учебного года %d: %v", year.StartYear, err)
+		http.Error(w, "Ошибка при закрытии учебного года", storeErrorStatus(err))
+		return
+	}
+
+	log.Printf("Учебный год %s закрыт: переведено классов %d, выпускников %d, условно переведено %d",
+		year.Name, len(result.Promotions), result.Graduates, len(result.Unsatisfactory))
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func TestCloseAcademicYear(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	deputy := createUser(t, st, "deputy", "deputy")
+	teacher := createUser(t, st, "teacher", "teacher")
+	year := models.AcademicYearOf(time.Now())
+
+	ninth := &models.Class{GradeLevel: 9, Letter: "А", AcademicYear: year}
+	eleventh := &models.Class{GradeLevel: 11, Letter: "А", AcademicYear: year}
+	mustCreate(t, st.CreateClass(ctx, ninth))
+	mustCreate(t, st.CreateClass(ctx, eleventh))
+	ivan := &models.Student{FullName: "Иван Иванов", ClassID: ninth.ID}
+	petr := &models.Student{FullName: "Петр Петров", ClassID: ninth.ID}
+	olga := &models.Student{FullName: "Ольга Смирнова", ClassID: eleventh.ID}
+	for _, student := range []*models.Student{ivan, petr, olga} {
+		mustCreate(t, st.CreateStudent(ctx, student))
+	}
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+
+	// Иван: 5 и 4 за четверти → 4.5 → 5; Петр: 2, 2 и итоговая 3 → 2.33 → 2
+	var first models.Grade
+	for i, g := range []models.Grade{
+		{StudentID: ivan.ID, Grade: 5, TermID: termID(t, st, 1)},
+		{StudentID: ivan.ID, Grade: 4, TermID: termID(t, st, 2)},
+		{StudentID: petr.ID, Grade: 2, TermID: termID(t, st, 1)},
+		{StudentID: petr.ID, Grade: 2, TermID: termID(t, st, 2)},
+		{StudentID: olga.ID, Grade: 4, TermID: termID(t, st, 1)},
+	} {
+		g.SubjectID = math.ID
+		mustCreate(t, st.CreateGrade(ctx, &g))
+		if i == 0 {
+			first = g
+		}
+	}
+	mustCreate(t, st.SetTermGrade(ctx, &models.TermGrade{StudentID: petr.ID, SubjectID: math.ID, TermID: termID(t, st, 3), Grade: 3}))
+
+	var annual []models.AnnualGrade
+	resp := do(t, router, "GET", fmt.Sprintf("/students/%d/annual-grades", ivan.ID), nil, deputy)
+	json.Unmarshal(resp.Body.Bytes(), &annual)
+	if resp.Code != http.StatusOK || len(annual) != 1 || annual[0].Grade != 5 || annual[0].Average != 4.5 {
+		t.Fatalf("Ивану ожидалась годовая 5, получено %d %s", resp.Code, resp.Body.String())
+	}
+
+	path := fmt.Sprintf("/academic-years/%d", year)
+	if resp := do(t, router, "GET", path+"/annual-grades", nil, teacher); resp.Code != http.StatusForbidden {
+		t.Errorf("Учитель: ожидался статус 403, получен %d", resp.Code)
+	}
+	var report []models.StudentAnnualGrades
+	resp = do(t, router, "GET", path+"/annual-grades?unsatisfactory=true", nil, deputy)
+	json.Unmarshal(resp.Body.Bytes(), &report)
+	if resp.Code != http.StatusOK || len(report) != 1 || report[0].ID != petr.ID || report[0].Grades[0].Grade != 2 {
+		t.Fatalf("Неуспевающим за год ожидался Петр, получено %d %s", resp.Code, resp.Body.String())
+	}
+
+	if resp := do(t, router, "POST", path+"/close", nil, teacher); resp.Code != http.StatusForbidden {
+		t.Errorf("Закрытие года учителем: ожидался статус 403, получен %d", resp.Code)
+	}
+	resp = do(t, router, "POST", path+"/close", nil, deputy)
+	var result models.YearEndResult
+	json.Unmarshal(resp.Body.Bytes(), &result)
+	if resp.Code != http.StatusOK || len(result.Promotions) != 1 || result.Promotions[0].To != "10А" ||
+		result.Promotions[0].Students != 2 || result.Graduates != 1 ||
+		len(result.Unsatisfactory) != 1 || result.Unsatisfactory[0] != petr.ID {
+		t.Fatalf("Ожидался перевод 9А → 10А и один выпускник, получено %d %s", resp.Code, resp.Body.String())
+	}
+
+	promoted, _ := st.GetStudent(ctx, ivan.ID)
+	graduate, _ := st.GetStudent(ctx, olga.ID)
+	if promoted.ClassName != "10А" || graduate.GraduatedYear != year || graduate.ClassID != eleventh.ID {
+		t.Errorf("Ожидались Иван в 10А и выпускница Ольга, получено %+v %+v", promoted, graduate)
+	}
+	// Перевод и выпуск записываются в журнал изменений учеников
+	studentChange := func(id int) (before, after models.Student) {
+		t.Helper()
+		var entries []models.AuditEntry
+		resp := do(t, router, "GET", fmt.Sprintf("/audit?entity=student&entity_id=%d", id), nil, deputy)
+		json.Unmarshal(resp.Body.Bytes(), &entries)
+		if len(entries) != 1 || entries[0].Action != models.AuditUpdate || entries[0].UserID != deputy.ID {
+			t.Fatalf("Ожидалась одна запись журнала об ученике %d, получено %s", id, resp.Body.String())
+		}
+		json.Unmarshal(entries[0].OldValue, &before)
+		json.Unmarshal(entries[0].NewValue, &after)
+		return before, after
+	}
+	if before, after := studentChange(ivan.ID); before.ClassID != ninth.ID || after.ClassName != "10А" {
+		t.Errorf("Перевод Ивана: ожидалось 9А -> 10А, получено %+v -> %+v", before, after)
+	}
+	if before, after := studentChange(olga.ID); before.GraduatedYear != 0 || after.GraduatedYear != year {
+		t.Errorf("Выпуск Ольги: ожидался год выпуска %d, получено %+v -> %+v", year, before, after)
+	}
+	if next, err := st.GetAcademicYear(ctx, year+1); err != nil || len(next.Terms) != 4 {
+		t.Errorf("Ожидался созданный следующий учебный год, получено %+v %v", next, err)
+	}
+
+	body, _ := json.Marshal(models.Grade{StudentID: ivan.ID, SubjectID: math.ID, Grade: 5, TermID: termID(t, st, 1)})
+	if resp := do(t, router, "POST", "/grades", bytes.NewReader(body), deputy); resp.Code != http.StatusConflict {
+		t.Errorf("Оценка в закрытом году: ожидался статус 409, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "DELETE", fmt.Sprintf("/grades/%d", first.ID), nil, deputy); resp.Code != http.StatusConflict {
+		t.Errorf("Удаление оценки закрытого года: ожидался статус 409, получен %d", resp.Code)
+	}
+	if resp := do(t, router, "POST", path+"/close", nil, deputy); resp.Code != http.StatusConflict {
+		t.Errorf("Повторное закрытие: ожидался статус 409, получен %d", resp.Code)
+	}
+
+	report = nil
+	resp = do(t, router, "GET", fmt.Sprintf("%s/annual-grades?class_id=%d", path, ninth.ID), nil, deputy)
+	json.Unmarshal(resp.Body.Bytes(), &report)
+	if resp.Code != http.StatusOK || len(report) != 2 || report[0].Grades[0].ClassName != "9А" {
+		t.Errorf("В архиве ожидались годовые оценки 9А, получено %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+// Статистика закрытого года относит оценки к классу того года, а не к классу после перевода
+func TestClassStatsAfterYearEnd(t *testing.T) {
+	s, st := newTestServer(t)
+	router := s.Router()
+	ctx := context.Background()
+	deputy := createUser(t, st, "deputy", "deputy")
+	year := models.AcademicYearOf(time.Now())
+
+	ninth := &models.Class{GradeLevel: 9, Letter: "А", AcademicYear: year}
+	mustCreate(t, st.CreateClass(ctx, ninth))
+	ivan := &models.Student{FullName: "Иван Иванов", ClassID: ninth.ID}
+	mustCreate(t, st.CreateStudent(ctx, ivan))
+	math := &models.Subject{Name: "Математика"}
+	mustCreate(t, st.CreateSubject(ctx, math))
+	mustCreate(t, st.CreateGrade(ctx, &models.Grade{StudentID: ivan.ID, SubjectID: math.ID, Grade: 5, TermID: termID(t, st, 1)}))
+
+	path := fmt.Sprintf("/academic-years/%d/close", year)
+	if resp := do(t, router, "POST", path, nil, deputy); resp.Code != http.StatusOK {
+		t.Fatalf("Закрытие года: ожидался статус 200, получен %d %s", resp.Code, resp.Body.String())
+	}
+
+	query := fmt.Sprintf("?academic_year=%d", year)
+	var averages map[string]map[string]float64
+	resp := do(t, router, "GET", "/grades/average-by-class"+query, nil, deputy)
+	json.Unmarshal(resp.Body.Bytes(), &averages)
+	if resp.Code != http.StatusOK || len(averages) != 1 || averages["9А"]["Математика"] != 5 {
+		t.Errorf("Средние по классам: ожидался 9А, получено %d %s", resp.Code, resp.Body.String())
+	}
+	var classes struct {
+		TopClass   string `json:"top_class"`
+		WorstClass string `json:"worst_class"`
+	}
+	resp = do(t, router, "GET", "/stats/top-worst-classes"+query, nil, deputy)
+	json.Unmarshal(resp.Body.Bytes(), &classes)
+	if resp.Code != http.StatusOK || classes.TopClass != "9А" || classes.WorstClass != "9А" {
+		t.Errorf("Лучший и худший класс: ожидался 9А, получено %d %s", resp.Code, resp.Body.String())
+	}
+	var performances []models.ClassPerformance
+	resp = do(t, router, "GET", "/stats/class-performance"+query, nil, deputy)
+	json.Unmarshal(resp.Body.Bytes(), &performances)
+	if resp.Code != http.StatusOK || len(performances) != 1 || performances[0].Name != "9А" {
+		t.Errorf("Успеваемость по классам: ожидался 9А, получено %d %s", resp.Code, resp.Body.String())
+	}
+}
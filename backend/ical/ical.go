@@ -0,0 +1,110 @@
+// Package ical формирует календари в формате iCalendar (RFC 5545) для подписки
+// из календарей телефонов и почтовых клиентов.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// localLayout — дата и местное время без часового пояса («плавающее» время)
+	localLayout = "20060102T150405"
+	// utcLayout — момент времени в UTC
+	utcLayout = "20060102T150405Z"
+	// maxLineOctets — предельная длина строки без перевода строки; длинные строки переносятся
+	maxLineOctets = 75
+)
+
+// Event — событие календаря (VEVENT). Время начала и конца записывается как местное,
+// без часового пояса: календарь показывает его во времени устройства.
+type Event struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Start       time.Time
+	End         time.Time
+	// Until — последний день еженедельного повторения; нулевое значение — событие без повторений
+	Until time.Time
+	// Except — начала пропускаемых повторений (EXDATE), например на каникулах
+	Except []time.Time
+}
+
+// Calendar — календарь с набором событий
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Write записывает календарь в w; stamp — время формирования (DTSTAMP)
+func Write(w io.Writer, cal Calendar, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//School System//Timetable//RU")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", escapeText(cal.Name))
+	}
+	for _, e := range cal.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp.UTC().Format(utcLayout))
+		line("DTSTART", e.Start.Format(localLayout))
+		line("DTEND", e.End.Format(localLayout))
+		if !e.Until.IsZero() {
+			until := time.Date(e.Until.Year(), e.Until.Month(), e.Until.Day(), 23, 59, 59, 0, e.Until.Location())
+			line("RRULE", "FREQ=WEEKLY;UNTIL="+until.Format(localLayout))
+		}
+		if len(e.Except) > 0 {
+			dates := make([]string, len(e.Except))
+			for i, t := range e.Except {
+				dates[i] = t.Format(localLayout)
+			}
+			line("EXDATE", strings.Join(dates, ","))
+		}
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Location != "" {
+			line("LOCATION", escapeText(e.Location))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// escapeText экранирует значение типа TEXT (RFC 5545, раздел 3.3.11)
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeFolded записывает строку содержимого, перенося ее по 75 байт без разрыва
+// символов UTF-8: продолжение начинается с пробела (RFC 5545, раздел 3.1)
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(s[cut]) {
+			cut--
+		}
+		fmt.Fprintf(w, "%s\r\n ", s[:cut])
+		s = s[cut:]
+		limit = maxLineOctets - 1
+	}
+	fmt.Fprintf(w, "%s\r\n", s)
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
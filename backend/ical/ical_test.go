@@ -0,0 +1,70 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteWeeklyEvent(t *testing.T) {
+	start := time.Date(2025, 9, 1, 8, 30, 0, 0, time.UTC)
+	cal := Calendar{Name: "Расписание", Events: []Event{{
+		UID:         "lesson-1@school-system",
+		Summary:     "Математика, 9А; алгебра",
+		Location:    "12",
+		Description: "Учитель: Иванова Анна\nКласс: 9А",
+		Start:       start,
+		End:         start.Add(45 * time.Minute),
+		Until:       time.Date(2025, 12, 22, 0, 0, 0, 0, time.UTC),
+		Except:      []time.Time{start.AddDate(0, 0, 56), start.AddDate(0, 0, 63)},
+	}}}
+	var b strings.Builder
+	if err := Write(&b, cal, time.Date(2025, 8, 20, 10, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"DTSTAMP:20250820T100000Z\r\n",
+		"DTSTART:20250901T083000\r\nDTEND:20250901T091500\r\n",
+		"RRULE:FREQ=WEEKLY;UNTIL=20251222T235959\r\n",
+		"EXDATE:20251027T083000,20251103T083000\r\n",
+		`SUMMARY:Математика\, 9А\; алгебра` + "\r\n",
+		"END:VEVENT\r\nEND:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("В календаре нет %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\n\n") || strings.Contains(out, "Анна\nКласс") {
+		t.Errorf("Перевод строки в тексте должен экранироваться:\n%s", out)
+	}
+}
+
+func TestWriteFoldsLongLines(t *testing.T) {
+	start := time.Date(2025, 9, 1, 8, 30, 0, 0, time.UTC)
+	summary := strings.Repeat("Ж", 60)
+	var b strings.Builder
+	Write(&b, Calendar{Events: []Event{{UID: "1", Summary: summary, Start: start, End: start}}}, start)
+
+	var unfolded []string
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("Строка длиннее %d байт: %q", maxLineOctets, line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+	found := false
+	for _, line := range unfolded {
+		if line == "SUMMARY:"+summary {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("После склейки перенесенных строк SUMMARY должен совпасть:\n%s", b.String())
+	}
+}
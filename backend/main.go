@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
-	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	"github.com/rs/cors"
 
+	"school-system/backend/config"
 	"school-system/backend/database"
+	"school-system/backend/demo"
 	"school-system/backend/handlers"
 	"school-system/backend/middleware"
+	"school-system/backend/store"
+	"school-system/backend/store/memory"
 )
 
 func main() {
@@ -23,8 +31,13 @@ func main() {
 		log.Printf("Предупреждение: .env файл не найден, используются переменные окружения")
 	}
 
-	// Получаем секрет JWT из окружения или используем фиксированный для тестирования
-	jwtSecret := os.Getenv("JWT_SECRET")
+	// Загружаем конфигурацию: файл, переменные окружения и флаги
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Ошибка конфигурации: %v", err)
+	}
+
+	jwtSecret := cfg.Auth.JWTSecret
 	if jwtSecret == "" {
 		log.Printf("Предупреждение: используется тестовый секретный ключ JWT")
 		jwtSecret = "test-secret-key-123" // Фиксированный ключ для тестирования
@@ -34,160 +47,137 @@ func main() {
 	middleware.SetJWTSecret([]byte(jwtSecret))
 	log.Printf("Секретный ключ JWT установлен")
 
-	// Инициализируем базу данных
-	log.Printf("Инициализация подключения к базе данных...")
-	database.InitDB()
+	var st store.Store
+	if cfg.Database.Driver == config.DriverMemory {
+		if len(cfg.Args) > 0 && cfg.Args[0] == "migrate" {
+			log.Fatalf("Подкоманда migrate доступна только для драйвера postgres")
+		}
+		log.Printf("Используется хранилище в памяти: данные не сохраняются после остановки сервера")
+		mem := memory.New()
+		if cfg.Demo {
+			accounts, err := demo.Seed(context.Background(), mem)
+			if err != nil {
+				log.Fatalf("Ошибка заполнения демонстрационных данных: %v", err)
+			}
+			log.Printf("Демонстрационный режим. Учетные записи (пароль %s):", demo.Password)
+			for _, account := range accounts {
+				log.Printf("  %s (%s)", account.Username, account.Role)
+			}
+		}
+		st = mem
+	} else {
+		// Инициализируем базу данных
+		log.Printf("Инициализация подключения к базе данных...")
+		db, err := database.InitDB(cfg.Database)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+
+		// Подкоманда migrate: go run main.go migrate up|down [N]|status
+		if len(cfg.Args) > 0 && cfg.Args[0] == "migrate" {
+			if err := runMigrate(db, cfg.Args[1:]); err != nil {
+				log.Fatalf("Ошибка миграции: %v", err)
+			}
+			return
+		}
+
+		if cfg.Database.AutoMigrate {
+			if err := runMigrate(db, []string{"up"}); err != nil {
+				log.Fatalf("Ошибка миграции: %v", err)
+			}
+		}
+		st = database.NewStore(db)
+	}
+
+	server := handlers.NewServer(st)
+	server.Auth = cfg.Auth
+	server.Grading = cfg.Grading
+	middleware.SetRevocationChecker(st.IsAccessTokenRevoked)
+	middleware.SetPermissionChecker(st.RoleHasPermission)
+
+	// Периодически удаляем истекшие записи о токенах и старые счетчики попыток входа
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := st.DeleteExpiredTokens(context.Background(), time.Now()); err != nil {
+				log.Printf("Ошибка при очистке истекших токенов: %v", err)
+			}
+			if err := st.DeleteStaleLoginThrottles(context.Background(), time.Now().Add(-cfg.Auth.LoginFailureWindow)); err != nil {
+				log.Printf("Ошибка при очистке счетчиков неудачных попыток входа: %v", err)
+			}
+		}
+	}()
 
 	// Настройка роутеров
 	log.Printf("Настройка маршрутов...")
-	r := mux.NewRouter()
+	r := server.Router()
 
 	// Настройка CORS
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3000"},
+		AllowedOrigins:   cfg.Server.CORSOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Content-Type", "Authorization", "Accept", "Origin", "X-Requested-With"},
 		ExposedHeaders:   []string{"Content-Length"},
 		AllowCredentials: true,
-		Debug:            true,
+		Debug:            cfg.Server.CORSDebug,
 	})
 
-	// ====== GET Requests ======
-	log.Printf("Регистрация GET маршрутов...")
-	r.HandleFunc("/students", handlers.GetStudents).Methods("GET")
-	r.HandleFunc("/teachers", handlers.GetTeachers).Methods("GET")
-	r.HandleFunc("/subjects", handlers.GetSubjects).Methods("GET")
-
-	// Получение оценок — только для авторизованных пользователей
-	r.Handle("/grades", middleware.AuthMiddleware(http.HandlerFunc(handlers.GetGrades))).Methods("GET")
-
-	// Получение оценок конкретного студента
-	r.Handle("/grades/student/{id}", middleware.AuthMiddleware(http.HandlerFunc(handlers.GetStudentGrades))).Methods("GET")
-
-	// Новые маршруты для завуча
-	r.Handle("/students/failing", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy")(handlers.GetFailingStudents),
-		),
-	)).Methods("GET")
-
-	r.Handle("/grades/average-by-class", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy")(handlers.GetAverageGradesByClass),
-		),
-	)).Methods("GET")
-
-	r.Handle("/stats/top-worst-classes", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy")(handlers.GetTopAndWorstClasses),
-		),
-	)).Methods("GET")
-
-	// Маршрут для учителя
-	r.Handle("/teacher/my-students", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("teacher")(handlers.GetMyStudents),
-		),
-	)).Methods("GET")
-
-	r.Handle("/teacher/my-students/grades", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("teacher")(handlers.GetMyStudentsGrades),
-		),
-	)).Methods("GET")
-
-	// Новые маршруты для статистики
-	r.Handle("/stats/students-count", middleware.AuthMiddleware(
-		http.HandlerFunc(handlers.GetStudentsCount),
-	)).Methods("GET")
-
-	r.Handle("/stats/teachers-count", middleware.AuthMiddleware(
-		http.HandlerFunc(handlers.GetTeachersCount),
-	)).Methods("GET")
-
-	r.Handle("/stats/average-grade", middleware.AuthMiddleware(
-		http.HandlerFunc(handlers.GetAverageGrade),
-	)).Methods("GET")
-
-	r.Handle("/stats/class-performance", middleware.AuthMiddleware(
-		http.HandlerFunc(handlers.GetClassPerformance),
-	)).Methods("GET")
-
-	r.Handle("/stats/average-grades", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy")(handlers.GetAverageGradesByClass),
-		),
-	)).Methods("GET")
-
-	r.Handle("/stats/failing-students", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy")(handlers.GetFailingStudents),
-		),
-	)).Methods("GET")
-
-	r.Handle("/stats/top-worst-classes", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy")(handlers.GetTopAndWorstClasses),
-		),
-	)).Methods("GET")
-
-	// ====== POST Requests ======
-	log.Printf("Регистрация POST маршрутов...")
-	r.HandleFunc("/students", handlers.CreateStudent).Methods("POST")
-	r.HandleFunc("/subjects", handlers.CreateSubject).Methods("POST")
-	r.Handle("/teachers", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy")(handlers.CreateTeacher),
-		),
-	)).Methods("POST")
-
-	// Создание оценки — только для ролей "deputy" и "teacher", с авторизацией
-	r.Handle("/grades", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy", "teacher")(handlers.CreateGrade),
-		),
-	)).Methods("POST")
-
-	// ====== PUT Requests ======
-	log.Printf("Регистрация PUT маршрутов...")
-	r.HandleFunc("/students/{id}", handlers.UpdateStudent).Methods("PUT")
-	r.HandleFunc("/teachers/{id}", handlers.UpdateTeacher).Methods("PUT")
-	r.HandleFunc("/subjects/{id}", handlers.UpdateSubject).Methods("PUT")
-	r.Handle("/grades/{id}", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy", "teacher")(handlers.UpdateGrade),
-		),
-	)).Methods("PUT")
-
-	// ====== DELETE Requests ======
-	log.Printf("Регистрация DELETE маршрутов...")
-	r.Handle("/students/{id}", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy")(handlers.DeleteStudent),
-		),
-	)).Methods("DELETE")
-	r.Handle("/teachers/{id}", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy")(handlers.DeleteTeacher),
-		),
-	)).Methods("DELETE")
-	r.HandleFunc("/subjects/{id}", handlers.DeleteSubject).Methods("DELETE")
-
-	// Удаление оценки — только для роли "deputy", с авторизацией
-	r.Handle("/grades/{id}", middleware.AuthMiddleware(
-		http.HandlerFunc(
-			middleware.RequireRole("deputy")(handlers.DeleteGrade),
-		),
-	)).Methods("DELETE")
-
-	// ====== Аутентификация ======
-	log.Printf("Регистрация маршрутов аутентификации...")
-	r.HandleFunc("/login", handlers.Login).Methods("POST")
-	r.HandleFunc("/register", handlers.Register).Methods("POST")
-	r.HandleFunc("/verify-token", handlers.VerifyToken).Methods("GET")
-
 	// Запуск сервера с CORS middleware
-	log.Printf("Сервер запущен на порту 8000")
+	log.Printf("Сервер запущен на порту %d", cfg.Server.Port)
 	handler := c.Handler(r)
-	log.Fatal(http.ListenAndServe(":8000", handler))
+	log.Fatal(http.ListenAndServe(cfg.Server.Addr(), handler))
+}
+
+// runMigrate выполняет подкоманду migrate
+func runMigrate(db *sqlx.DB, args []string) error {
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Применено миграций: %d", count)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("некорректное количество шагов отката: %s", args[1])
+			}
+		}
+		count, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("Откачено миграций: %d", count)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			state := "не применена"
+			if st.AppliedAt != nil {
+				state = "применена " + st.AppliedAt.Format(time.RFC3339)
+			}
+			if st.Modified {
+				state += " (ИЗМЕНЕНА)"
+			}
+			fmt.Printf("%04d_%s\t%s\n", st.Version, st.Name, state)
+		}
+	default:
+		return fmt.Errorf("неизвестная команда migrate %q, ожидается up, down или status", command)
+	}
+	return nil
 }
@@ -2,20 +2,41 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 var jwtSecret []byte // теперь будет задаваться извне через SetJWTSecret
 
+// RevocationChecker сообщает, отозван ли токен доступа с указанным jti
+type RevocationChecker func(ctx context.Context, jti string) (bool, error)
+
+var isRevoked RevocationChecker
+
 type contextKey string
 
 const (
 	ContextUserID contextKey = "userID"
 	ContextRole   contextKey = "role"
+	// ContextTokenID — идентификатор (jti) текущего токена доступа
+	ContextTokenID contextKey = "tokenID"
+	// ContextTokenExpiry — срок действия текущего токена доступа (time.Time)
+	ContextTokenExpiry contextKey = "tokenExpiry"
+	// ContextPending — обязательное действие, которое пользователь должен выполнить (claim "pending")
+	ContextPending contextKey = "pending"
+)
+
+// Обязательные действия, которые пользователь должен выполнить, прежде чем продолжить работу
+const (
+	// PendingPasswordChange — сменить временный пароль
+	PendingPasswordChange = "password_change"
+	// PendingTOTPEnrollment — настроить двухфакторную аутентификацию, обязательную для роли
+	PendingTOTPEnrollment = "totp_enrollment"
 )
 
 // Функция для установки секрета JWT
@@ -24,7 +45,57 @@ func SetJWTSecret(secret []byte) {
 	jwtSecret = secret
 }
 
+// GetJWTSecret возвращает текущий секрет JWT
+func GetJWTSecret() []byte {
+	return jwtSecret
+}
+
+// SetRevocationChecker задает функцию проверки отзыва токенов
+func SetRevocationChecker(checker RevocationChecker) {
+	isRevoked = checker
+}
+
+// ParseAccessToken проверяет подпись и срок действия токена доступа и возвращает его claims
+func ParseAccessToken(tokenStr string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("неожиданный метод подписи: %v", token.Header["alg"])
+		}
+		return jwtSecret, nil
+	}, jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("токен недействителен")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("неверный формат claims токена")
+	}
+	if typ, _ := claims["typ"].(string); typ != "access" {
+		return nil, fmt.Errorf("токен типа %q не является токеном доступа", typ)
+	}
+	if jti, _ := claims["jti"].(string); jti == "" {
+		return nil, fmt.Errorf("в токене отсутствует jti")
+	}
+	return claims, nil
+}
+
+// AuthMiddleware пропускает запрос только с действительным токеном доступа
+// без незавершенных обязательных действий
 func AuthMiddleware(next http.Handler) http.Handler {
+	return authenticate(next, false)
+}
+
+// AllowPending работает как AuthMiddleware, но пропускает и токены с claim "pending".
+// Используется для маршрутов, через которые это действие выполняется (смена пароля, выход).
+func AllowPending(next http.Handler) http.Handler {
+	return authenticate(next, true)
+}
+
+func authenticate(next http.Handler, allowPending bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Проверка аутентификации для запроса: %s %s", r.Method, r.URL.Path)
 
@@ -36,38 +107,62 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-		log.Printf("Получен токен: %s...", tokenStr[:10])
-
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-
+		claims, err := ParseAccessToken(tokenStr)
 		if err != nil {
 			log.Printf("Ошибка при проверке токена: %v", err)
 			http.Error(w, "Неверный токен", http.StatusUnauthorized)
 			return
 		}
 
-		if !token.Valid {
-			log.Printf("Токен недействителен")
-			http.Error(w, "Неверный токен", http.StatusUnauthorized)
-			return
+		jti, _ := claims["jti"].(string)
+		if isRevoked != nil {
+			revoked, err := isRevoked(r.Context(), jti)
+			if err != nil {
+				log.Printf("Ошибка при проверке отзыва токена: %v", err)
+				http.Error(w, "Ошибка проверки токена", http.StatusInternalServerError)
+				return
+			}
+			if revoked {
+				log.Printf("Токен %s отозван", jti)
+				http.Error(w, "Токен отозван", http.StatusUnauthorized)
+				return
+			}
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			log.Printf("Неверный формат claims токена")
-			http.Error(w, "Неверные claims", http.StatusUnauthorized)
+		pending, _ := claims["pending"].(string)
+		if pending != "" && !allowPending {
+			log.Printf("Запрос %s отклонен: требуется действие %q", r.URL.Path, pending)
+			http.Error(w, pendingMessage(pending), http.StatusForbidden)
 			return
 		}
 
+		var expiresAt time.Time
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			expiresAt = exp.Time
+		}
+
 		userID := claims["user_id"]
 		role := claims["role"]
 		log.Printf("Успешная аутентификация: user_id=%v, role=%v", userID, role)
 
-		// Добавим userID и role в context
+		// Добавим userID, role и данные токена в context
 		ctx := context.WithValue(r.Context(), ContextUserID, userID)
 		ctx = context.WithValue(ctx, ContextRole, role)
+		ctx = context.WithValue(ctx, ContextTokenID, jti)
+		ctx = context.WithValue(ctx, ContextTokenExpiry, expiresAt)
+		ctx = context.WithValue(ctx, ContextPending, pending)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// pendingMessage возвращает текст ошибки для незавершенного обязательного действия
+func pendingMessage(pending string) string {
+	switch pending {
+	case PendingPasswordChange:
+		return "Необходимо сменить пароль"
+	case PendingTOTPEnrollment:
+		return "Необходимо настроить двухфакторную аутентификацию"
+	default:
+		return "Требуется завершить вход в систему"
+	}
+}
@@ -1,38 +1,52 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
-	"strings"
 )
 
-func RequireRole(allowedRoles ...string) func(http.HandlerFunc) http.HandlerFunc {
+// PermissionChecker сообщает, есть ли у роли указанное право
+type PermissionChecker func(ctx context.Context, role, permission string) (bool, error)
+
+var hasPermission PermissionChecker
+
+// SetPermissionChecker задает функцию проверки прав ролей
+func SetPermissionChecker(checker PermissionChecker) {
+	hasPermission = checker
+}
+
+// RequirePermission пропускает запрос, только если у роли пользователя есть право permission.
+// Должен использоваться после AuthMiddleware.
+func RequirePermission(permission string) func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
-			log.Printf("Проверка роли для запроса: %s %s", r.Method, r.URL.Path)
-			log.Printf("Разрешенные роли: %v", allowedRoles)
-
 			role, ok := r.Context().Value(ContextRole).(string)
 			if !ok || role == "" {
 				log.Printf("Роль не найдена в контексте")
 				http.Error(w, "Нет роли в контексте", http.StatusUnauthorized)
 				return
 			}
-
-			log.Printf("Текущая роль пользователя: %s", role)
-
-			for _, allowed := range allowedRoles {
-				if strings.EqualFold(role, allowed) {
-					log.Printf("Доступ разрешен для роли: %s", role)
-					next.ServeHTTP(w, r)
-					return
-				}
+			if hasPermission == nil {
+				log.Printf("Проверка прав не настроена, доступ к %s запрещен", r.URL.Path)
+				http.Error(w, "Ошибка проверки прав", http.StatusInternalServerError)
+				return
 			}
 
-			log.Printf("Доступ запрещен для роли: %s", role)
-			w.WriteHeader(http.StatusForbidden)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Недостаточно прав"})
+			allowed, err := hasPermission(r.Context(), role, permission)
+			if err != nil {
+				log.Printf("Ошибка при проверке права %s для роли %s: %v", permission, role, err)
+				http.Error(w, "Ошибка проверки прав", http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				log.Printf("Доступ запрещен: у роли %s нет права %s", role, permission)
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(map[string]string{"error": "Недостаточно прав"})
+				return
+			}
+			next.ServeHTTP(w, r)
 		}
 	}
 }
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Виды учебных периодов
+const (
+	TermKindQuarter   = "quarter"
+	TermKindTrimester = "trimester"
+	TermKindSemester  = "semester"
+)
+
+// AcademicYear — учебный год и его периоды (четверти, триместры или полугодия)
+type AcademicYear struct {
+	ID int `json:"id" db:"id"`
+	// StartYear — год начала: 2025 означает 2025/2026
+	StartYear int    `json:"start_year" db:"start_year"`
+	Name      string `json:"name" db:"-"`
+	StartsOn  Date   `json:"starts_on" db:"starts_on"`
+	EndsOn    Date   `json:"ends_on" db:"ends_on"`
+	TermKind  string `json:"term_kind" db:"term_kind"`
+	// Archived — год закрыт: оценки, периоды и классы года доступны только для чтения
+	Archived bool   `json:"archived" db:"archived"`
+	Terms    []Term `json:"terms" db:"-"`
+}
+
+// Term — учебный период внутри года
+type Term struct {
+	ID             int    `json:"id" db:"id"`
+	AcademicYearID int    `json:"academic_year_id" db:"academic_year_id"`
+	Number         int    `json:"number" db:"number"`
+	Name           string `json:"name" db:"name"`
+	StartsOn       Date   `json:"starts_on" db:"starts_on"`
+	EndsOn         Date   `json:"ends_on" db:"ends_on"`
+}
+
+// Contains сообщает, попадает ли дата в период
+func (t Term) Contains(day Date) bool {
+	return !day.Before(t.StartsOn.Time) && !day.After(t.EndsOn.Time)
+}
+
+// Clamp возвращает ближайшую к day дату внутри периода
+func (t Term) Clamp(day Date) Date {
+	if day.Before(t.StartsOn.Time) {
+		return t.StartsOn
+	}
+	if day.After(t.EndsOn.Time) {
+		return t.EndsOn
+	}
+	return day
+}
+
+// Period — отбор данных по учебному году и, при необходимости, номеру периода
+type Period struct {
+	AcademicYear int // год начала учебного года
+	Term         int // номер периода; 0 — весь год
+}
+
+// AcademicYearName возвращает название учебного года, например "2025/2026"
+func AcademicYearName(startYear int) string {
+	return fmt.Sprintf("%d/%d", startYear, startYear+1)
+}
+
+// ValidTermKind сообщает, допустим ли вид учебных периодов
+func ValidTermKind(kind string) bool {
+	return kind == TermKindQuarter || kind == TermKindTrimester || kind == TermKindSemester
+}
+
+// DefaultTerms возвращает периоды учебного года с типовыми датами
+func DefaultTerms(startYear int, kind string) []Term {
+	d := func(year int, month time.Month, day int) Date {
+		return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+	}
+	next := startYear + 1
+	switch kind {
+	case TermKindTrimester:
+		return []Term{
+			{Number: 1, Name: "1 триместр", StartsOn: d(startYear, time.September, 1), EndsOn: d(startYear, time.November, 30)},
+			{Number: 2, Name: "2 триместр", StartsOn: d(startYear, time.December, 1), EndsOn: d(next, time.February, 28)},
+			{Number: 3, Name: "3 триместр", StartsOn: d(next, time.March, 1), EndsOn: d(next, time.May, 31)},
+		}
+	case TermKindSemester:
+		return []Term{
+			{Number: 1, Name: "1 полугодие", StartsOn: d(startYear, time.September, 1), EndsOn: d(startYear, time.December, 31)},
+			{Number: 2, Name: "2 полугодие", StartsOn: d(next, time.January, 1), EndsOn: d(next, time.May, 31)},
+		}
+	default:
+		return []Term{
+			{Number: 1, Name: "1 четверть", StartsOn: d(startYear, time.September, 1), EndsOn: d(startYear, time.October, 31)},
+			{Number: 2, Name: "2 четверть", StartsOn: d(startYear, time.November, 1), EndsOn: d(startYear, time.December, 31)},
+			{Number: 3, Name: "3 четверть", StartsOn: d(next, time.January, 1), EndsOn: d(next, time.March, 31)},
+			{Number: 4, Name: "4 четверть", StartsOn: d(next, time.April, 1), EndsOn: d(next, time.May, 31)},
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+// AnnualGrade — годовая оценка ученика по предмету, вычисленная по итоговым оценкам периодов
+type AnnualGrade struct {
+	StudentID   int    `json:"student_id" db:"student_id"`
+	SubjectID   int    `json:"subject_id" db:"subject_id"`
+	SubjectName string `json:"subject_name" db:"subject_name"`
+	// AcademicYear — год начала учебного года
+	AcademicYear int `json:"academic_year" db:"academic_year"`
+	// ClassID — класс ученика на конец года, до перевода
+	ClassID   int    `json:"class_id" db:"class_id"`
+	ClassName string `json:"class_name" db:"class_name"`
+	// Average — среднее итоговых оценок за периоды
+	Average float64 `json:"average" db:"average"`
+	Grade   int     `json:"grade" db:"grade"`
+	// Unsatisfactory — годовая оценка ниже порога успеваемости шкалы предмета
+	Unsatisfactory bool `json:"unsatisfactory" db:"unsatisfactory"`
+}
+
+// StudentAnnualGrades — годовые оценки ученика
+type StudentAnnualGrades struct {
+	Student
+	Grades         []AnnualGrade `json:"grades"`
+	Unsatisfactory bool          `json:"unsatisfactory"`
+}
+
+// ClassPromotion — перевод класса в следующий учебный год
+type ClassPromotion struct {
+	FromClassID int    `json:"from_class_id"`
+	From        string `json:"from"`
+	ToClassID   int    `json:"to_class_id"`
+	To          string `json:"to"`
+	Students    int    `json:"students"`
+}
+
+// YearEndResult — итог закрытия учебного года
+type YearEndResult struct {
+	AcademicYear int              `json:"academic_year"`
+	Promotions   []ClassPromotion `json:"promotions"`
+	// Graduates — количество выпускников
+	Graduates int `json:"graduates"`
+	// Unsatisfactory — ученики с неудовлетворительными годовыми оценками (переведены условно)
+	Unsatisfactory []int `json:"unsatisfactory_student_ids"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import "math"
+
+// Статусы посещаемости
+const (
+	AttendancePresent = "present"
+	AttendanceAbsent  = "absent"
+	AttendanceLate    = "late"
+	AttendanceExcused = "excused"
+)
+
+// AttendanceStatuses — все статусы посещаемости
+var AttendanceStatuses = []string{AttendancePresent, AttendanceAbsent, AttendanceLate, AttendanceExcused}
+
+// ValidAttendanceStatus сообщает, допустим ли статус посещаемости
+func ValidAttendanceStatus(status string) bool {
+	for _, s := range AttendanceStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Attendance — отметка посещаемости ученика на уроке
+type Attendance struct {
+	ID        int  `json:"id" db:"id"`
+	StudentID int  `json:"student_id" db:"student_id"`
+	SubjectID int  `json:"subject_id" db:"subject_id"`
+	Date      Date `json:"date" db:"date"`
+	// Lesson — номер урока в расписании дня
+	Lesson int `json:"lesson" db:"lesson"`
+	// TermID — период, в который попадает дата урока
+	TermID int    `json:"term_id" db:"term_id"`
+	Status string `json:"status" db:"status"`
+	// Reason — причина пропуска или опоздания
+	Reason string `json:"reason" db:"reason"`
+	// MarkedBy — пользователь, который отметил посещаемость
+	MarkedBy int `json:"marked_by" db:"marked_by"`
+}
+
+// AttendanceFilter — отбор отметок посещаемости; нулевые поля не ограничивают выборку
+type AttendanceFilter struct {
+	StudentID int
+	ClassID   int
+	SubjectID int
+	// Period — учебный год и период уроков; AcademicYear 0 — все годы
+	Period Period
+	// From и To ограничивают даты уроков включительно
+	From, To Date
+}
+
+// AttendanceSummary — счетчики посещаемости за выбранный срок
+type AttendanceSummary struct {
+	Lessons int `json:"lessons" db:"lessons"`
+	Absent  int `json:"absent" db:"absent"`
+	Excused int `json:"excused" db:"excused"`
+	Late    int `json:"late" db:"late"`
+	// AbsenceRate — доля пропущенных уроков (absent и excused) в процентах
+	AbsenceRate float64 `json:"absence_rate" db:"-"`
+}
+
+// Count учитывает отметку в счетчиках
+func (s *AttendanceSummary) Count(status string) {
+	s.Lessons++
+	switch status {
+	case AttendanceAbsent:
+		s.Absent++
+	case AttendanceExcused:
+		s.Excused++
+	case AttendanceLate:
+		s.Late++
+	}
+}
+
+// Rate возвращает долю пропущенных уроков в процентах с точностью до десятых
+func (s AttendanceSummary) Rate() float64 {
+	if s.Lessons == 0 {
+		return 0
+	}
+	return math.Round(float64(s.Absent+s.Excused)*1000/float64(s.Lessons)) / 10
+}
+
+// StudentAbsence — пропуски ученика
+type StudentAbsence struct {
+	StudentID int    `json:"student_id" db:"student_id"`
+	FullName  string `json:"full_name" db:"full_name"`
+	ClassName string `json:"class_name" db:"class_name"`
+	AttendanceSummary
+}
+
+// ClassAbsence — пропуски учеников класса
+type ClassAbsence struct {
+	Name string `json:"name" db:"class_name"`
+	AttendanceSummary
+}
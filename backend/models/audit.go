@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Действия в журнале изменений
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// Записи, изменения которых попадают в журнал
+const (
+	AuditGrade   = "grade"
+	AuditStudent = "student"
+	AuditTeacher = "teacher"
+	AuditSubject = "subject"
+	// AuditTermGrade — итоговая оценка за период
+	AuditTermGrade = "term_grade"
+)
+
+// AuditEntry — запись журнала изменений: кто, когда и с какого адреса изменил запись
+type AuditEntry struct {
+	ID int `json:"id" db:"id"`
+	// UserID — автор изменения; 0, если пользователь не определен
+	UserID   int    `json:"user_id" db:"user_id"`
+	Username string `json:"username" db:"username"`
+	Action   string `json:"action" db:"action"`
+	Entity   string `json:"entity" db:"entity"`
+	EntityID int    `json:"entity_id" db:"entity_id"`
+	// OldValue и NewValue — запись до и после изменения; пусты при создании и удалении соответственно
+	OldValue  json.RawMessage `json:"old_value,omitempty" db:"old_value"`
+	NewValue  json.RawMessage `json:"new_value,omitempty" db:"new_value"`
+	IP        string          `json:"ip" db:"ip"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter — отбор записей журнала; нулевые поля не ограничивают выборку
+type AuditFilter struct {
+	Entity   string
+	EntityID int
+	UserID   int
+	// From и To ограничивают дату изменения включительно
+	From, To Date
+	// Limit — наибольшее число записей, начиная с последних
+	Limit int
+}
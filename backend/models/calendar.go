@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// CalendarFeed — токен подписки пользователя на календарь в формате iCalendar
+type CalendarFeed struct {
+	UserID    int       `json:"user_id" db:"user_id"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Class — школьный класс в конкретном учебном году
+type Class struct {
+	ID         int    `json:"id" db:"id"`
+	GradeLevel int    `json:"grade_level" db:"grade_level"`
+	Letter     string `json:"letter" db:"letter"`
+	// AcademicYear — год начала учебного года: 2025 означает 2025/2026
+	AcademicYear      int    `json:"academic_year" db:"academic_year"`
+	HomeroomTeacherID int    `json:"homeroom_teacher_id" db:"homeroom_teacher_id"`
+	Name              string `json:"name" db:"name"`
+}
+
+// ClassName возвращает название класса, например "9А"
+func ClassName(gradeLevel int, letter string) string {
+	return strconv.Itoa(gradeLevel) + letter
+}
+
+// latinLookalikes заменяет латинские буквы, похожие на кириллические, и приводит
+// кириллицу к верхнему регистру. Так "9a", "9A" и "9а" дают один и тот же класс.
+var latinLookalikes = strings.NewReplacer(
+	"A", "А", "B", "В", "C", "С", "E", "Е", "H", "Н", "K", "К",
+	"M", "М", "O", "О", "P", "Р", "T", "Т", "X", "Х", "Y", "У",
+)
+
+var classNamePattern = regexp.MustCompile(`^([0-9]{1,2})([А-ЯЁ])$`)
+
+// NormalizeClassLetter приводит литеру класса к заглавной кириллической букве
+func NormalizeClassLetter(letter string) string {
+	return latinLookalikes.Replace(strings.ToUpper(strings.TrimSpace(letter)))
+}
+
+// ParseClassName разбирает название класса вида "9А", "9 а" или "10-B"
+func ParseClassName(name string) (gradeLevel int, letter string, err error) {
+	clean := strings.NewReplacer(" ", "", "-", "", "\t", "").Replace(name)
+	m := classNamePattern.FindStringSubmatch(NormalizeClassLetter(clean))
+	if m == nil {
+		return 0, "", fmt.Errorf("некорректное название класса %q", name)
+	}
+	gradeLevel, _ = strconv.Atoi(m[1])
+	if gradeLevel < 1 || gradeLevel > 11 {
+		return 0, "", fmt.Errorf("некорректная параллель в названии класса %q", name)
+	}
+	return gradeLevel, m[2], nil
+}
+
+// AcademicYearOf возвращает учебный год, к которому относится дата: учебный год начинается 1 сентября
+func AcademicYearOf(t time.Time) int {
+	if t.Month() >= time.September {
+		return t.Year()
+	}
+	return t.Year() - 1
+}
@@ -0,0 +1,26 @@
+package models
+
+// Enrollment — зачисление ученика на предмет к учителю в учебном году или периоде
+type Enrollment struct {
+	ID        int `json:"id" db:"id"`
+	StudentID int `json:"student_id" db:"student_id"`
+	SubjectID int `json:"subject_id" db:"subject_id"`
+	// TeacherID — учитель группы; 0 при создании — учитель, назначенный вести предмет в классе ученика
+	TeacherID int `json:"teacher_id" db:"teacher_id"`
+	// AcademicYear — год начала учебного года; при указании периода берется из него
+	AcademicYear int `json:"academic_year" db:"academic_year"`
+	// TermID — период зачисления; 0 — весь учебный год
+	TermID    int    `json:"term_id" db:"term_id"`
+	GroupName string `json:"group_name" db:"group_name"`
+}
+
+// EnrollmentFilter — отбор зачислений; нулевые поля не ограничивают выборку
+type EnrollmentFilter struct {
+	StudentID    int
+	SubjectID    int
+	TeacherID    int
+	ClassID      int
+	AcademicYear int
+	// TermID отбирает зачисления на этот период и на весь его учебный год
+	TermID int
+}
@@ -1,9 +1,139 @@
 package models
 
+import (
+	"math"
+	"time"
+)
+
+// Виды отметок
+const (
+	MarkKindClasswork = "classwork"
+	MarkKindHomework  = "homework"
+	MarkKindTest      = "test"
+	MarkKindExam      = "exam"
+)
+
+// MarkKinds — все виды отметок
+var MarkKinds = []string{MarkKindClasswork, MarkKindHomework, MarkKindTest, MarkKindExam}
+
+// ValidMarkKind сообщает, допустим ли вид отметки
+func ValidMarkKind(kind string) bool {
+	for _, k := range MarkKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Grade — отдельная отметка ученика по предмету
 type Grade struct {
 	ID        int `json:"id" db:"id"`
 	StudentID int `json:"student_id" db:"student_id"`
 	SubjectID int `json:"subject_id" db:"subject_id"`
 	Grade     int `json:"grade" db:"grade"`
-	Quarter   int `json:"quarter" db:"quarter"`
+	TermID    int `json:"term_id" db:"term_id"`
+	// Quarter — номер учебного периода (terms.number); заполняется хранилищем по term_id
+	Quarter int    `json:"quarter" db:"quarter"`
+	Date    Date   `json:"date" db:"date"`
+	Kind    string `json:"kind" db:"kind"`
+	// Weight — вес отметки; 0 — вес по умолчанию для её вида
+	Weight  float64 `json:"weight" db:"weight"`
+	Comment string  `json:"comment" db:"comment"`
+	// HomeworkID — домашнее задание, за работу по которому выставлена отметка; 0 — без задания.
+	// Заполняется только при оценке работы, при изменении отметки не меняется.
+	HomeworkID int `json:"homework_id,omitempty" db:"homework_id"`
+}
+
+// TermGrade — итоговая оценка за период, выставленная учителем вместо вычисленной
+type TermGrade struct {
+	ID          int       `json:"id" db:"id"`
+	StudentID   int       `json:"student_id" db:"student_id"`
+	SubjectID   int       `json:"subject_id" db:"subject_id"`
+	SubjectName string    `json:"subject_name" db:"subject_name"`
+	TermID      int       `json:"term_id" db:"term_id"`
+	Quarter     int       `json:"quarter" db:"quarter"`
+	Grade       int       `json:"grade" db:"grade"`
+	Comment     string    `json:"comment" db:"comment"`
+	SetBy       int       `json:"set_by" db:"set_by"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// GradingRules — правила вычисления итоговой оценки за период
+type GradingRules struct {
+	// KindWeights — вес отметок каждого вида, если у отметки не указан свой
+	KindWeights map[string]float64
+	// RoundingThreshold — дробная часть среднего, начиная с которой оценка округляется вверх:
+	// 0.5 — обычное округление, 0.6 — 3.6 дает 4, а 3.5 — 3
+	RoundingThreshold float64
+}
+
+// WeightOf возвращает вес отметки с учетом веса по умолчанию для её вида
+func (r GradingRules) WeightOf(g Grade) float64 {
+	if g.Weight > 0 {
+		return g.Weight
+	}
+	if w, ok := r.KindWeights[g.Kind]; ok {
+		return w
+	}
+	return 1
+}
+
+// Average возвращает средневзвешенный балл отметок, 0 — если отметок нет
+func (r GradingRules) Average(grades []Grade) float64 {
+	var sum, weights float64
+	for _, g := range grades {
+		w := r.WeightOf(g)
+		sum += float64(g.Grade) * w
+		weights += w
+	}
+	if weights == 0 {
+		return 0
+	}
+	return sum / weights
+}
+
+// Round округляет средний балл до итоговой оценки по порогу округления
+func (r GradingRules) Round(average float64) int {
+	whole := math.Floor(average)
+	// Сравнение с небольшим допуском, чтобы 3.6 не превращалось в 3.5999…
+	if average-whole >= r.RoundingThreshold-1e-9 {
+		return int(whole) + 1
+	}
+	return int(whole)
+}
+
+// GradeWithSubject — оценка вместе с названием предмета
+type GradeWithSubject struct {
+	Grade
+	SubjectName string `db:"subject_name" json:"subject_name"`
+}
+
+// QuarterGrades — оценки ученика по предмету за четверть и итоговая оценка
+type QuarterGrades struct {
+	Quarter int     `json:"quarter"`
+	Grades  []Grade `json:"grades"`
+	// Average — средневзвешенный балл отметок
+	Average float64 `json:"average"`
+	// Computed — итоговая оценка, вычисленная по отметкам (0 — отметок нет)
+	Computed int `json:"computed"`
+	// Final — итоговая оценка: выставленная учителем или вычисленная
+	Final      int  `json:"final"`
+	Overridden bool `json:"overridden"`
+	// Label — подпись итоговой оценки в шкале предмета, например "B"
+	Label string `json:"label,omitempty"`
+}
+
+// SubjectGrades — оценки ученика по предмету, сгруппированные по четвертям
+type SubjectGrades struct {
+	SubjectID   int             `json:"subject_id"`
+	SubjectName string          `json:"subject_name"`
+	Scale       GradingScale    `json:"scale"`
+	Quarters    []QuarterGrades `json:"quarters"`
+}
+
+// QuarterAverage — средний балл за четверть
+type QuarterAverage struct {
+	Quarter int     `json:"quarter"`
+	Average float64 `json:"average"`
 }
@@ -0,0 +1,58 @@
+package models
+
+// GradingScale — шкала оценивания: диапазон значений, порог успеваемости и подписи значений
+type GradingScale struct {
+	ID        int    `json:"id" db:"id"`
+	Name      string `json:"name" db:"name"`
+	MinValue  int    `json:"min_value" db:"min_value"`
+	MaxValue  int    `json:"max_value" db:"max_value"`
+	PassValue int    `json:"pass_value" db:"pass_value"`
+	// IsDefault — шкала школы: назначается новым предметам, к ней приводится статистика
+	IsDefault bool         `json:"is_default" db:"is_default"`
+	Labels    []ScaleLabel `json:"labels" db:"-"`
+}
+
+// ScaleLabel — подпись значения шкалы, например 5 — "A"
+type ScaleLabel struct {
+	Value int    `json:"value" db:"value"`
+	Label string `json:"label" db:"label"`
+}
+
+// Contains сообщает, входит ли оценка в диапазон шкалы
+func (s GradingScale) Contains(value int) bool {
+	return value >= s.MinValue && value <= s.MaxValue
+}
+
+// Passes сообщает, является ли средний балл удовлетворительным
+func (s GradingScale) Passes(average float64) bool {
+	return average >= float64(s.PassValue)
+}
+
+// Label возвращает подпись значения или пустую строку
+func (s GradingScale) Label(value int) string {
+	for _, l := range s.Labels {
+		if l.Value == value {
+			return l.Label
+		}
+	}
+	return ""
+}
+
+// Normalize переводит значение этой шкалы в шкалу target линейно по диапазонам
+func (s GradingScale) Normalize(value float64, target GradingScale) float64 {
+	if s.MaxValue == s.MinValue {
+		return float64(target.MinValue)
+	}
+	share := (value - float64(s.MinValue)) / float64(s.MaxValue-s.MinValue)
+	return float64(target.MinValue) + share*float64(target.MaxValue-target.MinValue)
+}
+
+// DefaultGradingScales — встроенные шкалы; первая — шкала школы по умолчанию
+var DefaultGradingScales = []GradingScale{
+	{Name: "5-балльная", MinValue: 1, MaxValue: 5, PassValue: 3, IsDefault: true},
+	{Name: "10-балльная", MinValue: 1, MaxValue: 10, PassValue: 4},
+	{Name: "100-балльная", MinValue: 0, MaxValue: 100, PassValue: 50},
+	{Name: "Буквенная", MinValue: 1, MaxValue: 5, PassValue: 2, Labels: []ScaleLabel{
+		{1, "F"}, {2, "D"}, {3, "C"}, {4, "B"}, {5, "A"},
+	}},
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Степень родства опекуна с учеником
+const (
+	RelationshipMother   = "mother"
+	RelationshipFather   = "father"
+	RelationshipGuardian = "guardian"
+	RelationshipOther    = "other"
+)
+
+// Guardian — связь учетной записи родителя (опекуна) с учеником
+type Guardian struct {
+	UserID       int       `json:"user_id" db:"user_id"`
+	Username     string    `json:"username" db:"username"`
+	StudentID    int       `json:"student_id" db:"student_id"`
+	Relationship string    `json:"relationship" db:"relationship"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// Child — ученик, к которому привязан родитель
+type Child struct {
+	Student
+	Relationship string `json:"relationship" db:"relationship"`
+}
+
+// ValidRelationship сообщает, допустима ли степень родства
+func ValidRelationship(r string) bool {
+	switch r {
+	case RelationshipMother, RelationshipFather, RelationshipGuardian, RelationshipOther:
+		return true
+	}
+	return false
+}
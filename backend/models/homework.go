@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Статусы работы ученика по домашнему заданию
+const (
+	// SubmissionDone — ученик отметил задание выполненным, ничего не сдавая
+	SubmissionDone = "done"
+	// SubmissionSubmitted — ученик сдал текст или файл
+	SubmissionSubmitted = "submitted"
+)
+
+// Homework — домашнее задание по предмету для класса
+type Homework struct {
+	ID        int `json:"id" db:"id"`
+	ClassID   int `json:"class_id" db:"class_id"`
+	SubjectID int `json:"subject_id" db:"subject_id"`
+	// TeacherID — учитель, выдавший задание; 0, если задание выдал не учитель
+	TeacherID   int       `json:"teacher_id" db:"teacher_id"`
+	Title       string    `json:"title" db:"title"`
+	Description string    `json:"description" db:"description"`
+	DueDate     Date      `json:"due_date" db:"due_date"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+
+	// Только для чтения: берутся из класса, предмета и учителя
+	ClassName   string `json:"class_name" db:"class_name"`
+	SubjectName string `json:"subject_name" db:"subject_name"`
+	TeacherName string `json:"teacher_name" db:"teacher_name"`
+}
+
+// HomeworkFilter — отбор домашних заданий; нулевые поля не ограничивают выборку
+type HomeworkFilter struct {
+	ClassID   int
+	SubjectID int
+	TeacherID int
+	// From и To ограничивают сроки сдачи включительно
+	From, To Date
+}
+
+// Submission — работа ученика по домашнему заданию
+type Submission struct {
+	ID          int    `json:"id" db:"id"`
+	HomeworkID  int    `json:"homework_id" db:"homework_id"`
+	StudentID   int    `json:"student_id" db:"student_id"`
+	StudentName string `json:"student_name" db:"student_name"`
+	Status      string `json:"status" db:"status"`
+	Text        string `json:"text" db:"text"`
+	FileName    string `json:"file_name,omitempty" db:"file_name"`
+	FileType    string `json:"file_type,omitempty" db:"file_type"`
+	FileSize    int    `json:"file_size,omitempty" db:"file_size"`
+	// File — содержимое файла; заполняется только при получении одной работы
+	File        []byte    `json:"-" db:"file"`
+	SubmittedAt time.Time `json:"submitted_at" db:"submitted_at"`
+	// GradeID и Grade — отметка за работу; 0 — работа не оценена
+	GradeID int `json:"grade_id,omitempty" db:"grade_id"`
+	Grade   int `json:"grade,omitempty" db:"grade"`
+}
+
+// StudentHomework — домашнее задание вместе с работой ученика (nil — работа не сдана)
+type StudentHomework struct {
+	Homework
+	Submission *Submission `json:"submission"`
+}
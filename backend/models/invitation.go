@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Invitation — приглашение на регистрацию учетной записи с заданной ролью
+type Invitation struct {
+	ID        int        `json:"id" db:"id"`
+	CodeHash  string     `json:"-" db:"code_hash"`
+	Role      string     `json:"role" db:"role"`
+	StudentID int        `json:"student_id,omitempty" db:"student_id"`
+	TeacherID int        `json:"teacher_id,omitempty" db:"teacher_id"`
+	CreatedBy int        `json:"created_by" db:"created_by"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	UsedBy    int        `json:"used_by,omitempty" db:"used_by"`
+}
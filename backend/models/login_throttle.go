@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Области счетчиков неудачных попыток входа
+const (
+	ThrottleScopeUser = "user"
+	ThrottleScopeIP   = "ip"
+)
+
+// LoginThrottle — счетчик неудачных попыток входа для логина или IP-адреса
+type LoginThrottle struct {
+	Scope         string     `json:"scope" db:"scope"`
+	Key           string     `json:"key" db:"key"`
+	Failures      int        `json:"failures" db:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at" db:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+}
@@ -0,0 +1,92 @@
+package models
+
+// Права доступа. Список должен совпадать с миграциями 0007_permissions и следующими.
+const (
+	PermStudentsWrite     = "students:write"
+	PermStudentsDelete    = "students:delete"
+	PermTeachersWrite     = "teachers:write"
+	PermTeachersDelete    = "teachers:delete"
+	PermSubjectsWrite     = "subjects:write"
+	PermSubjectsDelete    = "subjects:delete"
+	PermClassesWrite      = "classes:write"
+	PermClassesDelete     = "classes:delete"
+	PermAcademicYears     = "academic_years:manage"
+	PermGradingScales     = "grading_scales:manage"
+	PermEnrollments       = "enrollments:manage"
+	PermAssignments       = "teaching_assignments:manage"
+	PermTimetable         = "timetable:manage"
+	PermHomework          = "homework:manage"
+	PermGradesWrite       = "grades:write"
+	PermGradesDelete      = "grades:delete"
+	PermGradesAll         = "grades:all"
+	PermAttendanceWrite   = "attendance:write"
+	PermStatsRead         = "stats:read"
+	PermAuditRead         = "audit:read"
+	PermUsersManage       = "users:manage"
+	PermPermissionsManage = "permissions:manage"
+)
+
+// Permission — право доступа
+type Permission struct {
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+}
+
+// Role — роль пользователя и ее права
+type Role struct {
+	Name        string   `json:"name" db:"name"`
+	Description string   `json:"description" db:"description"`
+	Permissions []string `json:"permissions" db:"-"`
+}
+
+// Permissions — все права, известные приложению
+var Permissions = []Permission{
+	{PermStudentsWrite, "Добавление и изменение учеников"},
+	{PermStudentsDelete, "Удаление учеников"},
+	{PermTeachersWrite, "Добавление и изменение учителей"},
+	{PermTeachersDelete, "Удаление учителей"},
+	{PermSubjectsWrite, "Добавление и изменение предметов"},
+	{PermSubjectsDelete, "Удаление предметов"},
+	{PermClassesWrite, "Добавление и изменение классов"},
+	{PermClassesDelete, "Удаление классов"},
+	{PermAcademicYears, "Учебные годы и периоды"},
+	{PermGradingScales, "Шкалы оценивания"},
+	{PermEnrollments, "Зачисление учеников на предметы"},
+	{PermAssignments, "Назначение учителей на предметы в классах"},
+	{PermTimetable, "Расписание звонков и уроков"},
+	{PermHomework, "Домашние задания"},
+	{PermGradesWrite, "Выставление и изменение оценок"},
+	{PermGradesDelete, "Удаление оценок"},
+	{PermGradesAll, "Оценки по всем предметам, а не только по своим"},
+	{PermAttendanceWrite, "Отметка посещаемости уроков"},
+	{PermStatsRead, "Просмотр статистики успеваемости по школе"},
+	{PermAuditRead, "Просмотр журнала изменений"},
+	{PermUsersManage, "Приглашения, сброс паролей и 2FA, блокировки входа"},
+	{PermPermissionsManage, "Управление ролями и правами"},
+}
+
+// DefaultRoles — встроенные роли, которые нельзя удалить
+var DefaultRoles = []Role{
+	{Name: "student", Description: "Ученик"},
+	{Name: "teacher", Description: "Учитель", Permissions: []string{PermGradesWrite, PermAttendanceWrite, PermHomework}},
+	{Name: "deputy", Description: "Завуч", Permissions: allPermissionNames()},
+	{Name: "parent", Description: "Родитель"},
+}
+
+// IsDefaultRole сообщает, является ли роль встроенной
+func IsDefaultRole(name string) bool {
+	for _, role := range DefaultRoles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func allPermissionNames() []string {
+	names := make([]string, 0, len(Permissions))
+	for _, p := range Permissions {
+		names = append(names, p.Name)
+	}
+	return names
+}
@@ -1,8 +1,10 @@
 package models
 
 type User struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"password"` // здесь будет храниться хэш пароля
-	Role     string `json:"role"`     // student, teacher, deputy
+	ID       int    `json:"id" db:"id"`
+	Username string `json:"username" db:"username"`
+	Password string `json:"-" db:"password"` // здесь будет храниться хэш пароля
+	Role     string `json:"role" db:"role"`  // student, teacher, deputy
+	// MustChangePassword требует сменить пароль при следующем входе
+	MustChangePassword bool `json:"must_change_password" db:"must_change_password"`
 }
@@ -0,0 +1,22 @@
+package models
+
+// SubjectAverage — средний балл ученика по предмету за четверть
+type SubjectAverage struct {
+	SubjectName string  `db:"subject_name" json:"subject_name"`
+	Quarter     int     `db:"quarter" json:"quarter"`
+	Average     float64 `db:"average" json:"average"`
+	// Grade — итоговая оценка за четверть: выставленная учителем или вычисленная по отметкам
+	Grade int `db:"-" json:"grade,omitempty"`
+}
+
+// FailingStudent — неуспевающий ученик с его средними баллами по предметам
+type FailingStudent struct {
+	Student
+	SubjectAverages []SubjectAverage `json:"subject_averages"`
+}
+
+// ClassPerformance — средний балл класса
+type ClassPerformance struct {
+	Name  string  `json:"name" db:"class_name"`
+	Value float64 `json:"value" db:"average"`
+}
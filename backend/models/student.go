@@ -1,8 +1,13 @@
 package models
 
 type Student struct {
-	ID        int    `json:"id" db:"id"`
-	FullName  string `json:"full_name" db:"full_name"`
+	ID       int    `json:"id" db:"id"`
+	FullName string `json:"full_name" db:"full_name"`
+	ClassID  int    `json:"class_id" db:"class_id"`
+	// ClassName — название класса ("9А"); заполняется хранилищем по class_id.
+	// При создании и изменении ученика можно передать его вместо class_id.
 	ClassName string `json:"class_name" db:"class_name"`
 	UserID    int    `json:"user_id" db:"user_id"`
+	// GraduatedYear — год начала учебного года, в котором ученик окончил школу; 0 — учится
+	GraduatedYear int `json:"graduated_year" db:"graduated_year"`
 }
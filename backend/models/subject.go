@@ -1,7 +1,8 @@
 package models
 
 type Subject struct {
-	ID        int    `json:"id" db:"id"`
-	Name      string `json:"name" db:"name"`
-	TeacherID int    `json:"teacher_id" db:"teacher_id"`
+	ID   int    `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+	// GradingScaleID — шкала оценивания предмета; 0 при создании — шкала школы
+	GradingScaleID int `json:"grading_scale_id" db:"grading_scale_id"`
 }
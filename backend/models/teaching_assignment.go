@@ -0,0 +1,21 @@
+package models
+
+// TeachingAssignment — учитель ведет предмет в классе весь учебный год или в отдельном периоде
+type TeachingAssignment struct {
+	ID        int `json:"id" db:"id"`
+	TeacherID int `json:"teacher_id" db:"teacher_id"`
+	SubjectID int `json:"subject_id" db:"subject_id"`
+	ClassID   int `json:"class_id" db:"class_id"`
+	// TermID — период назначения; 0 — весь учебный год класса
+	TermID int `json:"term_id" db:"term_id"`
+	// AcademicYear — учебный год класса, только для чтения
+	AcademicYear int `json:"academic_year" db:"academic_year"`
+}
+
+// TeachingAssignmentFilter — отбор назначений; нулевые поля не ограничивают выборку
+type TeachingAssignmentFilter struct {
+	TeacherID    int
+	SubjectID    int
+	ClassID      int
+	AcademicYear int
+}
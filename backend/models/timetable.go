@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// ClockLayout — формат времени звонков
+const ClockLayout = "15:04"
+
+// BellPeriod — номер урока и его время по расписанию звонков
+type BellPeriod struct {
+	Number int `json:"number" db:"number"`
+	// StartsAt и EndsAt — время начала и конца урока, например "08:30"
+	StartsAt string `json:"starts_at" db:"starts_at"`
+	EndsAt   string `json:"ends_at" db:"ends_at"`
+}
+
+// DefaultBellPeriods — расписание звонков по умолчанию, как в миграции 0018_timetable
+var DefaultBellPeriods = []BellPeriod{
+	{1, "08:30", "09:15"},
+	{2, "09:25", "10:10"},
+	{3, "10:30", "11:15"},
+	{4, "11:35", "12:20"},
+	{5, "12:30", "13:15"},
+	{6, "13:25", "14:10"},
+	{7, "14:20", "15:05"},
+	{8, "15:15", "16:00"},
+}
+
+// Lesson — урок в недельном расписании класса
+type Lesson struct {
+	ID        int    `json:"id" db:"id"`
+	ClassID   int    `json:"class_id" db:"class_id"`
+	SubjectID int    `json:"subject_id" db:"subject_id"`
+	TeacherID int    `json:"teacher_id" db:"teacher_id"`
+	Room      string `json:"room" db:"room"`
+	// Weekday — день недели: 1 — понедельник, 7 — воскресенье
+	Weekday int `json:"weekday" db:"weekday"`
+	// Period — номер урока по расписанию звонков
+	Period int `json:"period" db:"period"`
+
+	// Заполняются хранилищем по классу, предмету и учителю
+	AcademicYear int    `json:"academic_year" db:"academic_year"`
+	ClassName    string `json:"class_name" db:"class_name"`
+	SubjectName  string `json:"subject_name" db:"subject_name"`
+	TeacherName  string `json:"teacher_name" db:"teacher_name"`
+}
+
+// LessonFilter — отбор уроков расписания; нулевые поля не ограничивают выборку
+type LessonFilter struct {
+	ClassID      int
+	TeacherID    int
+	AcademicYear int
+	Weekday      int
+}
+
+// ScheduleLesson — урок расписания на конкретный день со временем по звонкам
+type ScheduleLesson struct {
+	Lesson
+	StartsAt string `json:"starts_at"`
+	EndsAt   string `json:"ends_at"`
+}
+
+// ScheduleDay — уроки одного дня; в каникулы и выходные список пуст
+type ScheduleDay struct {
+	Date    Date             `json:"date"`
+	Weekday int              `json:"weekday"`
+	Lessons []ScheduleLesson `json:"lessons"`
+}
+
+// ISOWeekday возвращает день недели даты: 1 — понедельник, 7 — воскресенье
+func ISOWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TOTP — настройки двухфакторной аутентификации пользователя
+type TOTP struct {
+	UserID      int        `json:"user_id" db:"user_id"`
+	Secret      string     `json:"-" db:"secret"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	LastStep    int64      `json:"-" db:"last_step"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Enabled сообщает, подтверждена ли настройка двухфакторной аутентификации
+func (t *TOTP) Enabled() bool {
+	return t != nil && t.ConfirmedAt != nil
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) ListAcademicYears(ctx context.Context) ([]models.AcademicYear, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	years := make([]models.AcademicYear, 0, len(s.academicYears))
+	for _, year := range s.academicYears {
+		years = append(years, s.withTerms(year))
+	}
+	sort.Slice(years, func(i, j int) bool { return years[i].StartYear > years[j].StartYear })
+	return years, nil
+}
+
+func (s *Store) GetAcademicYear(ctx context.Context, startYear int) (*models.AcademicYear, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, year := range s.academicYears {
+		if year.StartYear == startYear {
+			year = s.withTerms(year)
+			return &year, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+// withTerms дополняет учебный год его периодами по порядку номеров
+func (s *Store) withTerms(year models.AcademicYear) models.AcademicYear {
+	year.Name = models.AcademicYearName(year.StartYear)
+	year.Terms = []models.Term{}
+	for _, term := range s.terms {
+		if term.AcademicYearID == year.ID {
+			year.Terms = append(year.Terms, term)
+		}
+	}
+	sort.Slice(year.Terms, func(i, j int) bool { return year.Terms[i].Number < year.Terms[j].Number })
+	return year
+}
+
+func (s *Store) CreateAcademicYear(ctx context.Context, year *models.AcademicYear) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.academicYears {
+		if existing.StartYear == year.StartYear {
+			return store.ErrConflict
+		}
+	}
+	year.ID = s.newID("academic_years")
+	year.Name = models.AcademicYearName(year.StartYear)
+	stored := *year
+	stored.Terms = nil
+	s.academicYears[year.ID] = stored
+	for i := range year.Terms {
+		term := &year.Terms[i]
+		term.ID = s.newID("terms")
+		term.AcademicYearID = year.ID
+		s.terms[term.ID] = *term
+	}
+	return nil
+}
+
+func (s *Store) DeleteAcademicYear(ctx context.Context, startYear int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, year := range s.academicYears {
+		if year.StartYear != startYear {
+			continue
+		}
+		if year.Archived {
+			return store.ErrArchived
+		}
+		for _, grade := range s.grades {
+			if s.terms[grade.TermID].AcademicYearID == id {
+				return store.ErrReference
+			}
+		}
+		for key := range s.termGrades {
+			if s.terms[key.termID].AcademicYearID == id {
+				return store.ErrReference
+			}
+		}
+		for _, a := range s.attendance {
+			if s.terms[a.TermID].AcademicYearID == id {
+				return store.ErrReference
+			}
+		}
+		for termID, term := range s.terms {
+			if term.AcademicYearID == id {
+				delete(s.terms, termID)
+			}
+		}
+		for enrollmentID, e := range s.enrollments {
+			if _, ok := s.terms[e.TermID]; e.TermID != 0 && !ok {
+				delete(s.enrollments, enrollmentID)
+			}
+		}
+		for assignmentID, a := range s.teachingAssignments {
+			if _, ok := s.terms[a.TermID]; a.TermID != 0 && !ok {
+				delete(s.teachingAssignments, assignmentID)
+			}
+		}
+		delete(s.academicYears, id)
+		return nil
+	}
+	return store.ErrNotFound
+}
+
+func (s *Store) GetTerm(ctx context.Context, id int) (*models.Term, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	term, ok := s.terms[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &term, nil
+}
+
+func (s *Store) UpdateTerm(ctx context.Context, term *models.Term) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.terms[term.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.termArchived(existing.ID) {
+		return store.ErrArchived
+	}
+	existing.Name = term.Name
+	existing.StartsOn = term.StartsOn
+	existing.EndsOn = term.EndsOn
+	s.terms[term.ID] = existing
+	*term = existing
+	return nil
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// annualGradeKey — первичный ключ годовой оценки
+type annualGradeKey struct {
+	studentID, subjectID, startYear int
+}
+
+// yearArchived сообщает, закрыт ли учебный год; вызывается под блокировкой
+func (s *Store) yearArchived(startYear int) bool {
+	for _, year := range s.academicYears {
+		if year.StartYear == startYear {
+			return year.Archived
+		}
+	}
+	return false
+}
+
+// termArchived сообщает, относится ли период к закрытому году; вызывается под блокировкой
+func (s *Store) termArchived(termID int) bool {
+	term, ok := s.terms[termID]
+	return ok && s.academicYears[term.AcademicYearID].Archived
+}
+
+func (s *Store) ListAnnualGrades(ctx context.Context, startYear, studentID int) ([]models.AnnualGrade, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var grades []models.AnnualGrade
+	for key, grade := range s.annualGrades {
+		if key.startYear != startYear || (studentID != 0 && key.studentID != studentID) {
+			continue
+		}
+		grade.SubjectName = s.subjects[key.subjectID].Name
+		if class, ok := s.classes[grade.ClassID]; ok {
+			grade.ClassName = class.Name
+		}
+		grades = append(grades, grade)
+	}
+	sort.Slice(grades, func(i, j int) bool {
+		if grades[i].StudentID != grades[j].StudentID {
+			return grades[i].StudentID < grades[j].StudentID
+		}
+		return grades[i].SubjectName < grades[j].SubjectName
+	})
+	return grades, nil
+}
+
+// classStudents возвращает учеников класса, еще не окончивших школу, по порядку ID;
+// вызывается под блокировкой
+func (s *Store) classStudents(classID int) []models.Student {
+	var students []models.Student
+	for _, student := range s.students {
+		if student.ClassID == classID && student.GraduatedYear == 0 {
+			students = append(students, student)
+		}
+	}
+	sort.Slice(students, func(i, j int) bool { return students[i].ID < students[j].ID })
+	return students
+}
+
+func (s *Store) CloseAcademicYear(ctx context.Context, startYear int, annual []models.AnnualGrade, graduationLevel int) (*models.YearEndResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	yearID := 0
+	nextExists := false
+	for id, year := range s.academicYears {
+		switch year.StartYear {
+		case startYear:
+			yearID = id
+		case startYear + 1:
+			nextExists = true
+		}
+	}
+	if yearID == 0 {
+		return nil, store.ErrNotFound
+	}
+	if s.academicYears[yearID].Archived {
+		return nil, store.ErrArchived
+	}
+	if !nextExists {
+		return nil, store.ErrReference
+	}
+	for _, g := range annual {
+		if _, ok := s.students[g.StudentID]; !ok {
+			return nil, store.ErrReference
+		}
+		if _, ok := s.subjects[g.SubjectID]; !ok {
+			return nil, store.ErrReference
+		}
+	}
+
+	result := &models.YearEndResult{AcademicYear: startYear, Promotions: []models.ClassPromotion{}, Unsatisfactory: []int{}}
+	unsatisfactory := make(map[int]bool)
+	for _, g := range annual {
+		g.AcademicYear = startYear
+		g.ClassID = s.students[g.StudentID].ClassID
+		s.annualGrades[annualGradeKey{g.StudentID, g.SubjectID, startYear}] = g
+		if g.Unsatisfactory && !unsatisfactory[g.StudentID] {
+			unsatisfactory[g.StudentID] = true
+			result.Unsatisfactory = append(result.Unsatisfactory, g.StudentID)
+		}
+	}
+	sort.Ints(result.Unsatisfactory)
+
+	var classes []models.Class
+	for _, class := range s.classes {
+		if class.AcademicYear == startYear {
+			classes = append(classes, class)
+		}
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if classes[i].GradeLevel != classes[j].GradeLevel {
+			return classes[i].GradeLevel < classes[j].GradeLevel
+		}
+		return classes[i].Letter < classes[j].Letter
+	})
+	// Записи журнала о переводе и выпуске добавляются после всех изменений
+	var entries []*models.AuditEntry
+	for _, class := range classes {
+		students := s.classStudents(class.ID)
+		if class.GradeLevel >= graduationLevel {
+			for _, old := range students {
+				student := old
+				student.GraduatedYear = startYear
+				entry, err := store.NewAuditEntry(ctx, models.AuditUpdate, models.AuditStudent, student.ID, old, student)
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, entry)
+				s.students[student.ID] = student
+				result.Graduates++
+			}
+			continue
+		}
+
+		next := models.Class{GradeLevel: class.GradeLevel + 1, Letter: class.Letter, AcademicYear: startYear + 1,
+			HomeroomTeacherID: class.HomeroomTeacherID}
+		for _, existing := range s.classes {
+			if existing.GradeLevel == next.GradeLevel && existing.Letter == next.Letter && existing.AcademicYear == next.AcademicYear {
+				next = existing
+			}
+		}
+		if next.ID == 0 {
+			next.ID = s.newID("classes")
+			next.Name = models.ClassName(next.GradeLevel, next.Letter)
+			s.classes[next.ID] = next
+		}
+		promotion := models.ClassPromotion{FromClassID: class.ID, From: class.Name, ToClassID: next.ID, To: next.Name}
+		for _, old := range students {
+			student := old
+			student.ClassID, student.ClassName = next.ID, next.Name
+			entry, err := store.NewAuditEntry(ctx, models.AuditUpdate, models.AuditStudent, student.ID, old, student)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+			s.students[student.ID] = student
+			promotion.Students++
+		}
+		result.Promotions = append(result.Promotions, promotion)
+	}
+	for _, entry := range entries {
+		s.appendAudit(entry)
+	}
+
+	year := s.academicYears[yearID]
+	year.Archived = true
+	s.academicYears[yearID] = year
+	return result, nil
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// attendanceMatches сообщает, подходит ли отметка под фильтр. Вызывается под блокировкой.
+func (s *Store) attendanceMatches(a models.Attendance, f models.AttendanceFilter) bool {
+	term := s.terms[a.TermID]
+	switch {
+	case f.StudentID != 0 && a.StudentID != f.StudentID,
+		f.ClassID != 0 && s.students[a.StudentID].ClassID != f.ClassID,
+		f.SubjectID != 0 && a.SubjectID != f.SubjectID,
+		f.Period.AcademicYear != 0 && s.academicYears[term.AcademicYearID].StartYear != f.Period.AcademicYear,
+		f.Period.Term != 0 && term.Number != f.Period.Term,
+		!f.From.IsZero() && a.Date.Before(f.From.Time),
+		!f.To.IsZero() && a.Date.After(f.To.Time):
+		return false
+	}
+	return true
+}
+
+func (s *Store) ListAttendance(ctx context.Context, f models.AttendanceFilter) ([]models.Attendance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var records []models.Attendance
+	for _, a := range s.attendance {
+		if s.attendanceMatches(a, f) {
+			records = append(records, a)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if !a.Date.Equal(b.Date.Time) {
+			return a.Date.Before(b.Date.Time)
+		}
+		if a.Lesson != b.Lesson {
+			return a.Lesson < b.Lesson
+		}
+		return s.students[a.StudentID].FullName < s.students[b.StudentID].FullName
+	})
+	return records, nil
+}
+
+func (s *Store) GetAttendance(ctx context.Context, id int) (*models.Attendance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.attendance[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &record, nil
+}
+
+// MarkAttendance проверяет все отметки до сохранения, чтобы ошибка не оставила урок
+// отмеченным частично, как транзакция в PostgreSQL
+func (s *Store) MarkAttendance(ctx context.Context, records []models.Attendance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing := make([]int, len(records))
+	for i, r := range records {
+		if _, ok := s.students[r.StudentID]; !ok {
+			return store.ErrReference
+		}
+		if _, ok := s.subjects[r.SubjectID]; !ok {
+			return store.ErrReference
+		}
+		if _, ok := s.terms[r.TermID]; !ok {
+			return store.ErrReference
+		}
+		if s.termArchived(r.TermID) {
+			return store.ErrArchived
+		}
+		for id, a := range s.attendance {
+			if a.StudentID == r.StudentID && a.Date.Equal(r.Date.Time) && a.Lesson == r.Lesson {
+				if s.termArchived(a.TermID) {
+					return store.ErrArchived
+				}
+				existing[i] = id
+			}
+		}
+	}
+	for i := range records {
+		records[i].ID = existing[i]
+		if records[i].ID == 0 {
+			records[i].ID = s.newID("attendance")
+		}
+		s.attendance[records[i].ID] = records[i]
+	}
+	return nil
+}
+
+func (s *Store) UpdateAttendance(ctx context.Context, record *models.Attendance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.attendance[record.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.termArchived(existing.TermID) {
+		return store.ErrArchived
+	}
+	existing.Status, existing.Reason, existing.MarkedBy = record.Status, record.Reason, record.MarkedBy
+	s.attendance[record.ID] = existing
+	return nil
+}
+
+func (s *Store) DeleteAttendance(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.attendance[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.termArchived(record.TermID) {
+		return store.ErrArchived
+	}
+	delete(s.attendance, id)
+	return nil
+}
+
+func (s *Store) GetAbsenceByStudent(ctx context.Context, f models.AttendanceFilter) ([]models.StudentAbsence, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	byStudent := make(map[int]*models.StudentAbsence)
+	for _, a := range s.attendance {
+		if !s.attendanceMatches(a, f) {
+			continue
+		}
+		if byStudent[a.StudentID] == nil {
+			student := s.students[a.StudentID]
+			byStudent[a.StudentID] = &models.StudentAbsence{
+				StudentID: student.ID, FullName: student.FullName, ClassName: student.ClassName,
+			}
+		}
+		byStudent[a.StudentID].Count(a.Status)
+	}
+
+	result := []models.StudentAbsence{}
+	for _, absence := range byStudent {
+		absence.AbsenceRate = absence.Rate()
+		result = append(result, *absence)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		// Сравнение долей без деления: (a.Absent+a.Excused)/a.Lessons > (b.Absent+b.Excused)/b.Lessons
+		left, right := (a.Absent+a.Excused)*b.Lessons, (b.Absent+b.Excused)*a.Lessons
+		if left != right {
+			return left > right
+		}
+		return a.FullName < b.FullName
+	})
+	return result, nil
+}
+
+func (s *Store) GetAbsenceByClass(ctx context.Context, f models.AttendanceFilter) ([]models.ClassAbsence, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	byClass := make(map[int]*models.ClassAbsence)
+	for _, a := range s.attendance {
+		classID := s.students[a.StudentID].ClassID
+		if classID == 0 || !s.attendanceMatches(a, f) {
+			continue
+		}
+		if byClass[classID] == nil {
+			class := s.classes[classID]
+			byClass[classID] = &models.ClassAbsence{Name: models.ClassName(class.GradeLevel, class.Letter)}
+		}
+		byClass[classID].Count(a.Status)
+	}
+
+	result := []models.ClassAbsence{}
+	classIDs := make([]int, 0, len(byClass))
+	for id := range byClass {
+		classIDs = append(classIDs, id)
+	}
+	sort.Slice(classIDs, func(i, j int) bool {
+		a, b := s.classes[classIDs[i]], s.classes[classIDs[j]]
+		if a.GradeLevel != b.GradeLevel {
+			return a.GradeLevel < b.GradeLevel
+		}
+		return a.Letter < b.Letter
+	})
+	for _, id := range classIDs {
+		absence := byClass[id]
+		absence.AbsenceRate = absence.Rate()
+		result = append(result, *absence)
+	}
+	return result, nil
+}
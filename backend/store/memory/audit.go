@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"school-system/backend/models"
+)
+
+func (s *Store) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appendAudit(entry)
+	return nil
+}
+
+// appendAudit добавляет запись, подготовленную store.NewAuditEntry; nil пропускается.
+// Запись готовится до изменения, а добавляется после него, чтобы изменение
+// не осталось без записи. Вызывается под блокировкой.
+func (s *Store) appendAudit(entry *models.AuditEntry) {
+	if entry == nil {
+		return
+	}
+	entry.ID = s.newID("audit_log")
+	entry.CreatedAt = time.Now()
+	e := *entry
+	e.Username = ""
+	s.auditLog = append(s.auditLog, e)
+}
+
+func (s *Store) ListAudit(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var entries []models.AuditEntry
+	// Записи добавляются по порядку, поэтому последние — в конце
+	for i := len(s.auditLog) - 1; i >= 0; i-- {
+		if f.Limit > 0 && len(entries) == f.Limit {
+			break
+		}
+		e := s.auditLog[i]
+		day := models.NewDate(e.CreatedAt)
+		if (f.Entity != "" && e.Entity != f.Entity) || (f.EntityID != 0 && e.EntityID != f.EntityID) ||
+			(f.UserID != 0 && e.UserID != f.UserID) ||
+			(!f.From.IsZero() && day.Before(f.From.Time)) || (!f.To.IsZero() && day.After(f.To.Time)) {
+			continue
+		}
+		e.Username = s.users[e.UserID].Username
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
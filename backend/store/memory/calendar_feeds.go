@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) SetCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[feed.UserID]; !ok {
+		return store.ErrReference
+	}
+	for _, f := range s.calendarFeeds {
+		if f.TokenHash == feed.TokenHash && f.UserID != feed.UserID {
+			return store.ErrConflict
+		}
+	}
+	feed.CreatedAt = time.Now()
+	s.calendarFeeds[feed.UserID] = *feed
+	return nil
+}
+
+func (s *Store) GetCalendarFeed(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, f := range s.calendarFeeds {
+		if f.TokenHash == tokenHash {
+			return &f, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *Store) DeleteCalendarFeed(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.calendarFeeds[userID]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.calendarFeeds, userID)
+	return nil
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) ListClasses(ctx context.Context, academicYear int) ([]models.Class, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var classes []models.Class
+	for _, class := range s.classes {
+		if academicYear == 0 || class.AcademicYear == academicYear {
+			classes = append(classes, class)
+		}
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		a, b := classes[i], classes[j]
+		if a.AcademicYear != b.AcademicYear {
+			return a.AcademicYear > b.AcademicYear
+		}
+		if a.GradeLevel != b.GradeLevel {
+			return a.GradeLevel < b.GradeLevel
+		}
+		return a.Letter < b.Letter
+	})
+	return classes, nil
+}
+
+func (s *Store) GetClass(ctx context.Context, id int) (*models.Class, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	class, ok := s.classes[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &class, nil
+}
+
+func (s *Store) FindClass(ctx context.Context, gradeLevel int, letter string, academicYear int) (*models.Class, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, class := range s.classes {
+		if class.GradeLevel == gradeLevel && class.Letter == letter && class.AcademicYear == academicYear {
+			return &class, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+// checkClass проверяет уникальность класса в учебном году и классного руководителя;
+// классы закрытого года не меняются
+func (s *Store) checkClass(class *models.Class) error {
+	if s.yearArchived(class.AcademicYear) {
+		return store.ErrArchived
+	}
+	if class.HomeroomTeacherID != 0 {
+		if _, ok := s.teachers[class.HomeroomTeacherID]; !ok {
+			return store.ErrReference
+		}
+	}
+	for _, existing := range s.classes {
+		if existing.ID != class.ID && existing.GradeLevel == class.GradeLevel &&
+			existing.Letter == class.Letter && existing.AcademicYear == class.AcademicYear {
+			return store.ErrConflict
+		}
+	}
+	return nil
+}
+
+func (s *Store) CreateClass(ctx context.Context, class *models.Class) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkClass(class); err != nil {
+		return err
+	}
+	class.ID = s.newID("classes")
+	class.Name = models.ClassName(class.GradeLevel, class.Letter)
+	s.classes[class.ID] = *class
+	return nil
+}
+
+func (s *Store) UpdateClass(ctx context.Context, class *models.Class) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.classes[class.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.yearArchived(existing.AcademicYear) {
+		return store.ErrArchived
+	}
+	if err := s.checkClass(class); err != nil {
+		return err
+	}
+	class.Name = models.ClassName(class.GradeLevel, class.Letter)
+	s.classes[class.ID] = *class
+
+	// Название класса хранится у учеников, как его вычисляет SQL-версия
+	for id, student := range s.students {
+		if student.ClassID == class.ID {
+			student.ClassName = class.Name
+			s.students[id] = student
+		}
+	}
+	return nil
+}
+
+func (s *Store) DeleteClass(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	class, ok := s.classes[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.yearArchived(class.AcademicYear) {
+		return store.ErrArchived
+	}
+	for _, student := range s.students {
+		if student.ClassID == id {
+			return store.ErrReference
+		}
+	}
+	for assignmentID, a := range s.teachingAssignments {
+		if a.ClassID == id {
+			delete(s.teachingAssignments, assignmentID)
+		}
+	}
+	for lessonID, l := range s.lessons {
+		if l.ClassID == id {
+			delete(s.lessons, lessonID)
+		}
+	}
+	for homeworkID, h := range s.homework {
+		if h.ClassID == id {
+			s.deleteHomework(homeworkID)
+		}
+	}
+	delete(s.classes, id)
+	return nil
+}
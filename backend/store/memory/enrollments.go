@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) ListEnrollments(ctx context.Context, f models.EnrollmentFilter) ([]models.Enrollment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var enrollments []models.Enrollment
+	for _, e := range s.enrollments {
+		switch {
+		case f.StudentID != 0 && e.StudentID != f.StudentID,
+			f.SubjectID != 0 && e.SubjectID != f.SubjectID,
+			f.TeacherID != 0 && e.TeacherID != f.TeacherID,
+			f.ClassID != 0 && s.students[e.StudentID].ClassID != f.ClassID,
+			f.AcademicYear != 0 && e.AcademicYear != f.AcademicYear:
+			continue
+		}
+		if f.TermID != 0 && e.TermID != f.TermID {
+			term, ok := s.terms[f.TermID]
+			if e.TermID != 0 || !ok || s.academicYears[term.AcademicYearID].StartYear != e.AcademicYear {
+				continue
+			}
+		}
+		enrollments = append(enrollments, e)
+	}
+	sort.Slice(enrollments, func(i, j int) bool {
+		a, b := enrollments[i], enrollments[j]
+		if a.AcademicYear != b.AcademicYear {
+			return a.AcademicYear > b.AcademicYear
+		}
+		if sa, sb := s.subjects[a.SubjectID].Name, s.subjects[b.SubjectID].Name; sa != sb {
+			return sa < sb
+		}
+		if a.GroupName != b.GroupName {
+			return a.GroupName < b.GroupName
+		}
+		if na, nb := s.students[a.StudentID].FullName, s.students[b.StudentID].FullName; na != nb {
+			return na < nb
+		}
+		return a.ID < b.ID
+	})
+	return enrollments, nil
+}
+
+func (s *Store) GetEnrollment(ctx context.Context, id int) (*models.Enrollment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.enrollments[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &e, nil
+}
+
+// resolveEnrollment проверяет ссылки зачисления, подставляет учебный год периода
+// и учителя, который ведет предмет в классе classID; зачисления закрытого года не меняются
+func (s *Store) resolveEnrollment(e *models.Enrollment, classID int) error {
+	if _, ok := s.subjects[e.SubjectID]; !ok {
+		return store.ErrReference
+	}
+	if e.TermID != 0 {
+		term, ok := s.terms[e.TermID]
+		if !ok {
+			return store.ErrReference
+		}
+		e.AcademicYear = s.academicYears[term.AcademicYearID].StartYear
+	}
+	if e.TeacherID == 0 {
+		e.TeacherID = s.assignedTeacher(classID, e.SubjectID, e.AcademicYear, e.TermID)
+	} else if _, ok := s.teachers[e.TeacherID]; !ok {
+		return store.ErrReference
+	}
+	if s.yearArchived(e.AcademicYear) {
+		return store.ErrArchived
+	}
+	return nil
+}
+
+// enrollmentExists сообщает, зачислен ли ученик на предмет на тот же год и период
+func (s *Store) enrollmentExists(e models.Enrollment) bool {
+	for _, existing := range s.enrollments {
+		if existing.ID != e.ID && existing.StudentID == e.StudentID && existing.SubjectID == e.SubjectID &&
+			existing.AcademicYear == e.AcademicYear && existing.TermID == e.TermID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) CreateEnrollment(ctx context.Context, enrollment *models.Enrollment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	student, ok := s.students[enrollment.StudentID]
+	if !ok {
+		return store.ErrReference
+	}
+	if err := s.resolveEnrollment(enrollment, student.ClassID); err != nil {
+		return err
+	}
+	if s.enrollmentExists(*enrollment) {
+		return store.ErrConflict
+	}
+	enrollment.ID = s.newID("enrollments")
+	s.enrollments[enrollment.ID] = *enrollment
+	return nil
+}
+
+func (s *Store) UpdateEnrollment(ctx context.Context, enrollment *models.Enrollment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.enrollments[enrollment.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	existing.TeacherID, existing.GroupName = enrollment.TeacherID, enrollment.GroupName
+	if err := s.resolveEnrollment(&existing, s.students[existing.StudentID].ClassID); err != nil {
+		return err
+	}
+	s.enrollments[existing.ID] = existing
+	*enrollment = existing
+	return nil
+}
+
+func (s *Store) DeleteEnrollment(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.enrollments[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.yearArchived(e.AcademicYear) {
+		return store.ErrArchived
+	}
+	delete(s.enrollments, id)
+	return nil
+}
+
+func (s *Store) EnrollClass(ctx context.Context, classID int, enrollment models.Enrollment) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	class, ok := s.classes[classID]
+	if !ok {
+		return 0, store.ErrNotFound
+	}
+	enrollment.AcademicYear = class.AcademicYear
+	if err := s.resolveEnrollment(&enrollment, classID); err != nil {
+		return 0, err
+	}
+	if enrollment.AcademicYear != class.AcademicYear {
+		return 0, store.ErrReference
+	}
+
+	// Порядок зачисления не важен, но ID выдаются детерминированно
+	var students []models.Student
+	for _, student := range s.students {
+		if student.ClassID == classID && student.GraduatedYear == 0 {
+			students = append(students, student)
+		}
+	}
+	sort.Slice(students, func(i, j int) bool { return students[i].ID < students[j].ID })
+
+	count := 0
+	for _, student := range students {
+		e := enrollment
+		e.StudentID = student.ID
+		if s.enrollmentExists(e) {
+			continue
+		}
+		e.ID = s.newID("enrollments")
+		s.enrollments[e.ID] = e
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) ListGrades(ctx context.Context) ([]models.Grade, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	grades := make([]models.Grade, 0, len(s.grades))
+	for _, grade := range s.grades {
+		grades = append(grades, grade)
+	}
+	sort.Slice(grades, func(i, j int) bool { return grades[i].ID < grades[j].ID })
+	return grades, nil
+}
+
+func (s *Store) GetGrade(ctx context.Context, id int) (*models.Grade, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	grade, ok := s.grades[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &grade, nil
+}
+
+// checkGradeRefs проверяет ссылки оценки, как это делают внешние ключи в PostgreSQL,
+// и заполняет номер периода, а также вид и дату по умолчанию
+func (s *Store) checkGradeRefs(grade *models.Grade) error {
+	if _, ok := s.students[grade.StudentID]; !ok {
+		return store.ErrReference
+	}
+	if _, ok := s.subjects[grade.SubjectID]; !ok {
+		return store.ErrReference
+	}
+	term, ok := s.terms[grade.TermID]
+	if !ok {
+		return store.ErrReference
+	}
+	if s.termArchived(term.ID) {
+		return store.ErrArchived
+	}
+	grade.Quarter = term.Number
+	if grade.Kind == "" {
+		grade.Kind = models.MarkKindClasswork
+	}
+	if grade.Date.IsZero() {
+		grade.Date = term.Clamp(models.NewDate(time.Now()))
+	}
+	return nil
+}
+
+// inPeriod сообщает, относится ли оценка к учебному году и периоду
+func (s *Store) inPeriod(grade models.Grade, period models.Period) bool {
+	term := s.terms[grade.TermID]
+	if s.academicYears[term.AcademicYearID].StartYear != period.AcademicYear {
+		return false
+	}
+	return period.Term == 0 || term.Number == period.Term
+}
+
+// gradesIn возвращает оценки учебного года и периода
+func (s *Store) gradesIn(period models.Period) []models.Grade {
+	var grades []models.Grade
+	for _, grade := range s.grades {
+		if s.inPeriod(grade, period) {
+			grades = append(grades, grade)
+		}
+	}
+	return grades
+}
+
+func (s *Store) CreateGrade(ctx context.Context, grade *models.Grade) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkGradeRefs(grade); err != nil {
+		return err
+	}
+	if grade.HomeworkID != 0 {
+		if _, ok := s.homework[grade.HomeworkID]; !ok {
+			return store.ErrReference
+		}
+		for _, g := range s.grades {
+			if g.HomeworkID == grade.HomeworkID && g.StudentID == grade.StudentID {
+				return store.ErrConflict
+			}
+		}
+	}
+	grade.ID = s.newID("grades")
+	entry, err := store.NewAuditEntry(ctx, models.AuditCreate, models.AuditGrade, grade.ID, nil, *grade)
+	if err != nil {
+		return err
+	}
+	s.grades[grade.ID] = *grade
+	s.appendAudit(entry)
+	return nil
+}
+
+func (s *Store) UpdateGrade(ctx context.Context, grade *models.Grade) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.grades[grade.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.termArchived(existing.TermID) {
+		return store.ErrArchived
+	}
+	if err := s.checkGradeRefs(grade); err != nil {
+		return err
+	}
+	grade.HomeworkID = existing.HomeworkID
+	entry, err := store.NewAuditEntry(ctx, models.AuditUpdate, models.AuditGrade, grade.ID, existing, *grade)
+	if err != nil {
+		return err
+	}
+	s.grades[grade.ID] = *grade
+	s.appendAudit(entry)
+	return nil
+}
+
+func (s *Store) DeleteGrade(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	grade, ok := s.grades[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.termArchived(grade.TermID) {
+		return store.ErrArchived
+	}
+	entry, err := store.NewAuditEntry(ctx, models.AuditDelete, models.AuditGrade, id, grade, nil)
+	if err != nil {
+		return err
+	}
+	delete(s.grades, id)
+	s.appendAudit(entry)
+	return nil
+}
+
+func (s *Store) GetStudentGrades(ctx context.Context, studentID int, period models.Period) ([]models.GradeWithSubject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var grades []models.GradeWithSubject
+	for _, grade := range s.gradesIn(period) {
+		if studentID == 0 || grade.StudentID == studentID {
+			grades = append(grades, models.GradeWithSubject{Grade: grade, SubjectName: s.subjects[grade.SubjectID].Name})
+		}
+	}
+	sort.Slice(grades, func(i, j int) bool {
+		if grades[i].SubjectName != grades[j].SubjectName {
+			return grades[i].SubjectName < grades[j].SubjectName
+		}
+		if grades[i].Quarter != grades[j].Quarter {
+			return grades[i].Quarter < grades[j].Quarter
+		}
+		if !grades[i].Date.Equal(grades[j].Date.Time) {
+			return grades[i].Date.Before(grades[j].Date.Time)
+		}
+		return grades[i].ID < grades[j].ID
+	})
+	return grades, nil
+}
+
+func (s *Store) GetGradesByTeacher(ctx context.Context, teacherID int, period models.Period) (map[int][]models.Grade, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var grades []models.Grade
+	for _, grade := range s.gradesIn(period) {
+		term := s.terms[grade.TermID]
+		gradePeriod := models.Period{AcademicYear: s.academicYears[term.AcademicYearID].StartYear, Term: term.Number}
+		if s.teaches(teacherID, s.students[grade.StudentID], grade.SubjectID, gradePeriod) {
+			grades = append(grades, grade)
+		}
+	}
+	sort.Slice(grades, func(i, j int) bool {
+		if grades[i].Quarter != grades[j].Quarter {
+			return grades[i].Quarter < grades[j].Quarter
+		}
+		return grades[i].ID < grades[j].ID
+	})
+
+	studentGrades := make(map[int][]models.Grade)
+	for _, grade := range grades {
+		studentGrades[grade.StudentID] = append(studentGrades[grade.StudentID], grade)
+	}
+	return studentGrades, nil
+}
@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// seedGradingScales заполняет встроенные шкалы, как миграция 0013_grading_scales
+func (s *Store) seedGradingScales() {
+	for _, scale := range models.DefaultGradingScales {
+		scale.ID = s.newID("grading_scales")
+		scale.Labels = append([]models.ScaleLabel{}, scale.Labels...)
+		s.gradingScales[scale.ID] = scale
+	}
+}
+
+// scaleOf возвращает шкалу предмета; вызывается под блокировкой
+func (s *Store) scaleOf(subjectID int) models.GradingScale {
+	return s.gradingScales[s.subjects[subjectID].GradingScaleID]
+}
+
+// defaultScale возвращает шкалу школы; вызывается под блокировкой
+func (s *Store) defaultScale() models.GradingScale {
+	for _, scale := range s.gradingScales {
+		if scale.IsDefault {
+			return scale
+		}
+	}
+	return models.GradingScale{}
+}
+
+// normalized возвращает оценку, приведенную к шкале школы; вызывается под блокировкой
+func (s *Store) normalized(grade models.Grade) float64 {
+	return s.scaleOf(grade.SubjectID).Normalize(float64(grade.Grade), s.defaultScale())
+}
+
+func copyScale(scale models.GradingScale) models.GradingScale {
+	scale.Labels = append([]models.ScaleLabel{}, scale.Labels...)
+	return scale
+}
+
+func (s *Store) ListGradingScales(ctx context.Context) ([]models.GradingScale, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	scales := make([]models.GradingScale, 0, len(s.gradingScales))
+	for _, scale := range s.gradingScales {
+		scales = append(scales, copyScale(scale))
+	}
+	sort.Slice(scales, func(i, j int) bool { return scales[i].ID < scales[j].ID })
+	return scales, nil
+}
+
+func (s *Store) GetGradingScale(ctx context.Context, id int) (*models.GradingScale, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	scale, ok := s.gradingScales[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	scale = copyScale(scale)
+	return &scale, nil
+}
+
+func (s *Store) GetDefaultGradingScale(ctx context.Context) (*models.GradingScale, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	scale := s.defaultScale()
+	if scale.ID == 0 {
+		return nil, store.ErrNotFound
+	}
+	scale = copyScale(scale)
+	return &scale, nil
+}
+
+func (s *Store) CreateGradingScale(ctx context.Context, scale *models.GradingScale) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.gradingScales {
+		if existing.Name == scale.Name {
+			return store.ErrConflict
+		}
+	}
+	if scale.IsDefault {
+		s.clearDefaultScale()
+	}
+	scale.ID = s.newID("grading_scales")
+	s.gradingScales[scale.ID] = copyScale(*scale)
+	return nil
+}
+
+func (s *Store) UpdateGradingScale(ctx context.Context, scale *models.GradingScale) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.gradingScales[scale.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if current.IsDefault && !scale.IsDefault {
+		return store.ErrConflict
+	}
+	for _, other := range s.gradingScales {
+		if other.ID != scale.ID && other.Name == scale.Name {
+			return store.ErrConflict
+		}
+	}
+	if scale.IsDefault {
+		s.clearDefaultScale()
+	}
+	scale.MinValue, scale.MaxValue = current.MinValue, current.MaxValue
+	s.gradingScales[scale.ID] = copyScale(*scale)
+	return nil
+}
+
+func (s *Store) DeleteGradingScale(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scale, ok := s.gradingScales[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if scale.IsDefault {
+		return store.ErrConflict
+	}
+	for _, subject := range s.subjects {
+		if subject.GradingScaleID == id {
+			return store.ErrReference
+		}
+	}
+	delete(s.gradingScales, id)
+	return nil
+}
+
+func (s *Store) clearDefaultScale() {
+	for id, scale := range s.gradingScales {
+		if scale.IsDefault {
+			scale.IsDefault = false
+			s.gradingScales[id] = scale
+		}
+	}
+}
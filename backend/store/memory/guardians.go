@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// guardianKey — ключ связи родителя с учеником
+type guardianKey struct {
+	userID    int
+	studentID int
+}
+
+func (s *Store) AddGuardian(ctx context.Context, guardian *models.Guardian) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[guardian.UserID]; !ok {
+		return store.ErrReference
+	}
+	if _, ok := s.students[guardian.StudentID]; !ok {
+		return store.ErrReference
+	}
+	key := guardianKey{guardian.UserID, guardian.StudentID}
+	if _, ok := s.guardians[key]; ok {
+		return store.ErrConflict
+	}
+	guardian.CreatedAt = time.Now()
+	s.guardians[key] = *guardian
+	return nil
+}
+
+func (s *Store) RemoveGuardian(ctx context.Context, userID, studentID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := guardianKey{userID, studentID}
+	if _, ok := s.guardians[key]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.guardians, key)
+	return nil
+}
+
+func (s *Store) ListGuardians(ctx context.Context, studentID int) ([]models.Guardian, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var guardians []models.Guardian
+	for key, g := range s.guardians {
+		if key.studentID == studentID {
+			g.Username = s.users[key.userID].Username
+			guardians = append(guardians, g)
+		}
+	}
+	sort.Slice(guardians, func(i, j int) bool { return guardians[i].Username < guardians[j].Username })
+	return guardians, nil
+}
+
+func (s *Store) ListChildren(ctx context.Context, userID int) ([]models.Child, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var children []models.Child
+	for key, g := range s.guardians {
+		if key.userID == userID {
+			children = append(children, models.Child{Student: s.students[key.studentID], Relationship: g.Relationship})
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].FullName < children[j].FullName })
+	return children, nil
+}
+
+func (s *Store) IsGuardian(ctx context.Context, userID, studentID int) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.guardians[guardianKey{userID, studentID}]
+	return ok, nil
+}
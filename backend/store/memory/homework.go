@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// withHomeworkNames заполняет названия класса, предмета и учителя; вызывается под блокировкой
+func (s *Store) withHomeworkNames(h models.Homework) models.Homework {
+	class := s.classes[h.ClassID]
+	h.ClassName = models.ClassName(class.GradeLevel, class.Letter)
+	h.SubjectName = s.subjects[h.SubjectID].Name
+	h.TeacherName = s.teachers[h.TeacherID].FullName
+	return h
+}
+
+func (s *Store) ListHomework(ctx context.Context, f models.HomeworkFilter) ([]models.Homework, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var homework []models.Homework
+	for _, h := range s.homework {
+		if (f.ClassID != 0 && h.ClassID != f.ClassID) || (f.SubjectID != 0 && h.SubjectID != f.SubjectID) ||
+			(f.TeacherID != 0 && h.TeacherID != f.TeacherID) ||
+			(!f.From.IsZero() && h.DueDate.Before(f.From.Time)) || (!f.To.IsZero() && h.DueDate.After(f.To.Time)) {
+			continue
+		}
+		homework = append(homework, s.withHomeworkNames(h))
+	}
+	sort.Slice(homework, func(i, j int) bool {
+		if !homework[i].DueDate.Equal(homework[j].DueDate.Time) {
+			return homework[i].DueDate.Before(homework[j].DueDate.Time)
+		}
+		return homework[i].ID < homework[j].ID
+	})
+	return homework, nil
+}
+
+func (s *Store) GetHomework(ctx context.Context, id int) (*models.Homework, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	h, ok := s.homework[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	h = s.withHomeworkNames(h)
+	return &h, nil
+}
+
+func (s *Store) CreateHomework(ctx context.Context, homework *models.Homework) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	class, ok := s.classes[homework.ClassID]
+	if !ok {
+		return store.ErrReference
+	}
+	if _, ok := s.subjects[homework.SubjectID]; !ok {
+		return store.ErrReference
+	}
+	if _, ok := s.teachers[homework.TeacherID]; homework.TeacherID != 0 && !ok {
+		return store.ErrReference
+	}
+	if s.yearArchived(class.AcademicYear) {
+		return store.ErrArchived
+	}
+	homework.ID = s.newID("homework")
+	homework.CreatedAt = time.Now()
+	s.homework[homework.ID] = *homework
+	return nil
+}
+
+func (s *Store) UpdateHomework(ctx context.Context, homework *models.Homework) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.homework[homework.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.yearArchived(s.classes[existing.ClassID].AcademicYear) {
+		return store.ErrArchived
+	}
+	existing.Title = homework.Title
+	existing.Description = homework.Description
+	existing.DueDate = homework.DueDate
+	s.homework[homework.ID] = existing
+	return nil
+}
+
+func (s *Store) DeleteHomework(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.homework[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.yearArchived(s.classes[h.ClassID].AcademicYear) {
+		return store.ErrArchived
+	}
+	s.deleteHomework(id)
+	return nil
+}
+
+// deleteHomework удаляет задание с работами и снимает ссылку на него с отметок,
+// как ON DELETE CASCADE и ON DELETE SET NULL в SQL-версии; вызывается под блокировкой
+func (s *Store) deleteHomework(id int) {
+	delete(s.homework, id)
+	for submissionID, sub := range s.submissions {
+		if sub.HomeworkID == id {
+			delete(s.submissions, submissionID)
+		}
+	}
+	for gradeID, g := range s.grades {
+		if g.HomeworkID == id {
+			g.HomeworkID = 0
+			s.grades[gradeID] = g
+		}
+	}
+}
+
+// withSubmissionGrade заполняет имя ученика, размер файла и отметку за работу; вызывается под блокировкой
+func (s *Store) withSubmissionGrade(sub models.Submission) models.Submission {
+	sub.StudentName = s.students[sub.StudentID].FullName
+	sub.FileSize = len(sub.File)
+	for _, g := range s.grades {
+		if g.HomeworkID == sub.HomeworkID && g.StudentID == sub.StudentID {
+			sub.GradeID, sub.Grade = g.ID, g.Grade
+		}
+	}
+	return sub
+}
+
+func (s *Store) ListSubmissions(ctx context.Context, homeworkID, studentID int) ([]models.Submission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var submissions []models.Submission
+	for _, sub := range s.submissions {
+		if sub.HomeworkID != homeworkID || (studentID != 0 && sub.StudentID != studentID) {
+			continue
+		}
+		sub = s.withSubmissionGrade(sub)
+		sub.File = nil
+		submissions = append(submissions, sub)
+	}
+	sort.Slice(submissions, func(i, j int) bool {
+		if submissions[i].StudentName != submissions[j].StudentName {
+			return submissions[i].StudentName < submissions[j].StudentName
+		}
+		return submissions[i].StudentID < submissions[j].StudentID
+	})
+	return submissions, nil
+}
+
+func (s *Store) GetSubmission(ctx context.Context, homeworkID, studentID int) (*models.Submission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, sub := range s.submissions {
+		if sub.HomeworkID == homeworkID && sub.StudentID == studentID {
+			sub = s.withSubmissionGrade(sub)
+			return &sub, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *Store) SaveSubmission(ctx context.Context, submission *models.Submission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.homework[submission.HomeworkID]; !ok {
+		return store.ErrReference
+	}
+	if _, ok := s.students[submission.StudentID]; !ok {
+		return store.ErrReference
+	}
+	submission.ID = 0
+	for _, sub := range s.submissions {
+		if sub.HomeworkID == submission.HomeworkID && sub.StudentID == submission.StudentID {
+			submission.ID = sub.ID
+		}
+	}
+	if submission.ID == 0 {
+		submission.ID = s.newID("homework_submissions")
+	}
+	submission.SubmittedAt = time.Now()
+	submission.FileSize = len(submission.File)
+	stored := *submission
+	stored.StudentName, stored.GradeID, stored.Grade = "", 0, 0
+	s.submissions[submission.ID] = stored
+	return nil
+}
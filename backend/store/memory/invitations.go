@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) CreateInvitation(ctx context.Context, inv *models.Invitation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.roles[inv.Role]; !ok {
+		return store.ErrReference
+	}
+	if inv.StudentID != 0 {
+		if _, ok := s.students[inv.StudentID]; !ok {
+			return store.ErrReference
+		}
+	}
+	if inv.TeacherID != 0 {
+		if _, ok := s.teachers[inv.TeacherID]; !ok {
+			return store.ErrReference
+		}
+	}
+	for _, existing := range s.invitations {
+		if existing.CodeHash == inv.CodeHash {
+			return store.ErrConflict
+		}
+	}
+	inv.ID = s.newID("invitations")
+	inv.CreatedAt = time.Now()
+	s.invitations[inv.ID] = *inv
+	return nil
+}
+
+func (s *Store) ListInvitations(ctx context.Context) ([]models.Invitation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	invitations := make([]models.Invitation, 0, len(s.invitations))
+	for _, inv := range s.invitations {
+		invitations = append(invitations, inv)
+	}
+	sort.Slice(invitations, func(i, j int) bool { return invitations[i].ID < invitations[j].ID })
+	return invitations, nil
+}
+
+func (s *Store) DeleteInvitation(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inv, ok := s.invitations[id]
+	if !ok || inv.UsedAt != nil {
+		return store.ErrNotFound
+	}
+	delete(s.invitations, id)
+	return nil
+}
+
+func (s *Store) RegisterWithInvitation(ctx context.Context, codeHash string, user *models.User, now time.Time) (*models.Invitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var inv models.Invitation
+	found := false
+	for _, candidate := range s.invitations {
+		if candidate.CodeHash == codeHash && candidate.UsedAt == nil && candidate.ExpiresAt.After(now) {
+			inv, found = candidate, true
+			break
+		}
+	}
+	if !found {
+		return nil, store.ErrNotFound
+	}
+
+	for _, existing := range s.users {
+		if existing.Username == user.Username {
+			return nil, store.ErrConflict
+		}
+	}
+	if inv.StudentID != 0 && s.students[inv.StudentID].UserID != 0 {
+		return nil, store.ErrConflict
+	}
+	if inv.TeacherID != 0 && s.teachers[inv.TeacherID].UserID != 0 {
+		return nil, store.ErrConflict
+	}
+
+	user.Role = inv.Role
+	user.ID = s.newID("users")
+	s.users[user.ID] = *user
+	if inv.StudentID != 0 {
+		student := s.students[inv.StudentID]
+		student.UserID = user.ID
+		s.students[student.ID] = student
+	}
+	if inv.TeacherID != 0 {
+		teacher := s.teachers[inv.TeacherID]
+		teacher.UserID = user.ID
+		s.teachers[teacher.ID] = teacher
+	}
+
+	inv.UsedAt = &now
+	inv.UsedBy = user.ID
+	s.invitations[inv.ID] = inv
+	return &inv, nil
+}
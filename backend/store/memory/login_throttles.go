@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// throttleKey — ключ счетчика в карте, аналог первичного ключа (scope, key)
+type throttleKey struct {
+	scope string
+	key   string
+}
+
+func (s *Store) GetLoginThrottle(ctx context.Context, scope, key string) (*models.LoginThrottle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	throttle, ok := s.loginThrottles[throttleKey{scope, key}]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &throttle, nil
+}
+
+func (s *Store) RecordLoginFailure(ctx context.Context, scope, key string, now time.Time, window time.Duration) (*models.LoginThrottle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := throttleKey{scope, key}
+	throttle, ok := s.loginThrottles[k]
+	if !ok || throttle.LastFailureAt.Before(now.Add(-window)) {
+		throttle = models.LoginThrottle{Scope: scope, Key: key, LockedUntil: throttle.LockedUntil}
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = now
+	s.loginThrottles[k] = throttle
+	return &throttle, nil
+}
+
+func (s *Store) LockLogin(ctx context.Context, scope, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := throttleKey{scope, key}
+	throttle, ok := s.loginThrottles[k]
+	if !ok {
+		return store.ErrNotFound
+	}
+	throttle.LockedUntil = &until
+	s.loginThrottles[k] = throttle
+	return nil
+}
+
+func (s *Store) ListLoginThrottles(ctx context.Context) ([]models.LoginThrottle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	throttles := make([]models.LoginThrottle, 0, len(s.loginThrottles))
+	for _, throttle := range s.loginThrottles {
+		throttles = append(throttles, throttle)
+	}
+	sort.Slice(throttles, func(i, j int) bool { return throttles[i].LastFailureAt.After(throttles[j].LastFailureAt) })
+	return throttles, nil
+}
+
+func (s *Store) ClearLoginThrottle(ctx context.Context, scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := throttleKey{scope, key}
+	if _, ok := s.loginThrottles[k]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.loginThrottles, k)
+	return nil
+}
+
+func (s *Store) DeleteStaleLoginThrottles(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, throttle := range s.loginThrottles {
+		if throttle.LastFailureAt.Before(before) && (throttle.LockedUntil == nil || throttle.LockedUntil.Before(before)) {
+			delete(s.loginThrottles, k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Package memory реализует хранилище в памяти процесса. Используется в тестах
+// и в демонстрационном режиме, когда PostgreSQL недоступен.
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// Store — потокобезопасное хранилище в памяти
+type Store struct {
+	mu sync.RWMutex
+
+	nextID map[string]int
+
+	users    map[int]models.User
+	students map[int]models.Student
+	classes  map[int]models.Class
+	teachers map[int]models.Teacher
+	subjects map[int]models.Subject
+	grades   map[int]models.Grade
+
+	termGrades    map[termGradeKey]models.TermGrade
+	academicYears map[int]models.AcademicYear // без периодов, они хранятся в terms
+	terms         map[int]models.Term
+	gradingScales map[int]models.GradingScale
+	annualGrades  map[annualGradeKey]models.AnnualGrade
+	enrollments   map[int]models.Enrollment
+	attendance    map[int]models.Attendance
+
+	teachingAssignments map[int]models.TeachingAssignment // academic_year не хранится, берется из класса
+	bellPeriods         map[int]models.BellPeriod         // номер урока -> время
+	lessons             map[int]models.Lesson             // без названий и учебного года, они берутся по ссылкам
+	homework            map[int]models.Homework           // без названий, они берутся по ссылкам
+	submissions         map[int]models.Submission         // без имени ученика и отметки
+
+	refreshTokens map[int]models.RefreshToken
+	revokedTokens map[string]time.Time // jti -> срок действия токена
+	resetTokens   map[int]models.PasswordReset
+	invitations   map[int]models.Invitation
+
+	loginThrottles map[throttleKey]models.LoginThrottle
+
+	totp          map[int]models.TOTP // user_id -> настройки TOTP
+	recoveryCodes map[int][]recoveryCode
+
+	calendarFeeds map[int]models.CalendarFeed // user_id -> подписка на календарь
+
+	auditLog []models.AuditEntry // без логинов, они берутся по user_id
+
+	permissions     map[string]models.Permission
+	roles           map[string]string // название -> описание
+	rolePermissions map[string]map[string]bool
+
+	guardians map[guardianKey]models.Guardian
+}
+
+var _ store.Store = (*Store)(nil)
+
+// New создает хранилище, в котором есть только встроенные роли и права
+func New() *Store {
+	s := &Store{
+		nextID:   make(map[string]int),
+		users:    make(map[int]models.User),
+		students: make(map[int]models.Student),
+		classes:  make(map[int]models.Class),
+		teachers: make(map[int]models.Teacher),
+		subjects: make(map[int]models.Subject),
+		grades:   make(map[int]models.Grade),
+
+		termGrades:    make(map[termGradeKey]models.TermGrade),
+		academicYears: make(map[int]models.AcademicYear),
+		terms:         make(map[int]models.Term),
+		gradingScales: make(map[int]models.GradingScale),
+		annualGrades:  make(map[annualGradeKey]models.AnnualGrade),
+		enrollments:   make(map[int]models.Enrollment),
+		attendance:    make(map[int]models.Attendance),
+
+		teachingAssignments: make(map[int]models.TeachingAssignment),
+		bellPeriods:         make(map[int]models.BellPeriod),
+		lessons:             make(map[int]models.Lesson),
+		homework:            make(map[int]models.Homework),
+		submissions:         make(map[int]models.Submission),
+
+		refreshTokens: make(map[int]models.RefreshToken),
+		revokedTokens: make(map[string]time.Time),
+		resetTokens:   make(map[int]models.PasswordReset),
+		invitations:   make(map[int]models.Invitation),
+
+		loginThrottles: make(map[throttleKey]models.LoginThrottle),
+
+		totp:          make(map[int]models.TOTP),
+		recoveryCodes: make(map[int][]recoveryCode),
+
+		calendarFeeds: make(map[int]models.CalendarFeed),
+
+		permissions:     make(map[string]models.Permission),
+		roles:           make(map[string]string),
+		rolePermissions: make(map[string]map[string]bool),
+
+		guardians: make(map[guardianKey]models.Guardian),
+	}
+	s.seedPermissions()
+	s.seedGradingScales()
+	s.seedBellPeriods()
+	return s
+}
+
+// newID выдает следующий идентификатор для таблицы, аналог SERIAL
+func (s *Store) newID(table string) int {
+	s.nextID[table]++
+	return s.nextID[table]
+}
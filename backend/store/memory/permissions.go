@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// seedPermissions заполняет права и встроенные роли, как миграция 0007_permissions
+func (s *Store) seedPermissions() {
+	for _, p := range models.Permissions {
+		s.permissions[p.Name] = p
+	}
+	for _, role := range models.DefaultRoles {
+		s.roles[role.Name] = role.Description
+		s.rolePermissions[role.Name] = make(map[string]bool)
+		for _, p := range role.Permissions {
+			s.rolePermissions[role.Name][p] = true
+		}
+	}
+}
+
+func (s *Store) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	permissions := make([]models.Permission, 0, len(s.permissions))
+	for _, p := range s.permissions {
+		permissions = append(permissions, p)
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+	return permissions, nil
+}
+
+func (s *Store) ListRoles(ctx context.Context) ([]models.Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	roles := make([]models.Role, 0, len(s.roles))
+	for name := range s.roles {
+		roles = append(roles, s.role(name))
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (s *Store) GetRole(ctx context.Context, name string) (*models.Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.roles[name]; !ok {
+		return nil, store.ErrNotFound
+	}
+	role := s.role(name)
+	return &role, nil
+}
+
+// role собирает роль с отсортированным списком прав
+func (s *Store) role(name string) models.Role {
+	role := models.Role{Name: name, Description: s.roles[name], Permissions: []string{}}
+	for p := range s.rolePermissions[name] {
+		role.Permissions = append(role.Permissions, p)
+	}
+	sort.Strings(role.Permissions)
+	return role
+}
+
+func (s *Store) CreateRole(ctx context.Context, role *models.Role) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.roles[role.Name]; ok {
+		return store.ErrConflict
+	}
+	granted, err := s.permissionSet(role.Permissions)
+	if err != nil {
+		return err
+	}
+	s.roles[role.Name] = role.Description
+	s.rolePermissions[role.Name] = granted
+	return nil
+}
+
+func (s *Store) DeleteRole(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.roles[name]; !ok {
+		return store.ErrNotFound
+	}
+	for _, user := range s.users {
+		if user.Role == name {
+			return store.ErrReference
+		}
+	}
+	delete(s.roles, name)
+	delete(s.rolePermissions, name)
+	for id, inv := range s.invitations {
+		if inv.Role == name {
+			delete(s.invitations, id)
+		}
+	}
+	return nil
+}
+
+func (s *Store) SetRolePermissions(ctx context.Context, role string, permissions []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.roles[role]; !ok {
+		return store.ErrNotFound
+	}
+	granted, err := s.permissionSet(permissions)
+	if err != nil {
+		return err
+	}
+	s.rolePermissions[role] = granted
+	return nil
+}
+
+// permissionSet проверяет, что все права существуют, аналог внешнего ключа
+func (s *Store) permissionSet(permissions []string) (map[string]bool, error) {
+	granted := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		if _, ok := s.permissions[p]; !ok {
+			return nil, store.ErrReference
+		}
+		granted[p] = true
+	}
+	return granted, nil
+}
+
+func (s *Store) RoleHasPermission(ctx context.Context, role, permission string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rolePermissions[role][permission], nil
+}
@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"school-system/backend/models"
+)
+
+// mean накапливает сумму и количество для вычисления среднего
+type mean struct {
+	sum   float64
+	count int
+}
+
+func (m *mean) add(v float64) {
+	m.sum += v
+	m.count++
+}
+
+func (m mean) value() float64 {
+	if m.count == 0 {
+		return 0
+	}
+	return m.sum / float64(m.count)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// gradeClass возвращает класс ученика в учебном году оценки: для закрытого года —
+// класс на конец года из годовых оценок, иначе текущий класс, если он относится
+// к этому году; вызывается под блокировкой
+func (s *Store) gradeClass(grade models.Grade) (models.Class, bool) {
+	startYear := s.academicYears[s.terms[grade.TermID].AcademicYearID].StartYear
+	classID := s.students[grade.StudentID].ClassID
+	if annual, ok := s.annualGrades[annualGradeKey{grade.StudentID, grade.SubjectID, startYear}]; ok {
+		classID = annual.ClassID
+	}
+	class, ok := s.classes[classID]
+	if !ok || class.AcademicYear != startYear {
+		return models.Class{}, false
+	}
+	return class, true
+}
+
+func (s *Store) GetAverageGrade(ctx context.Context, period models.Period) (float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var m mean
+	for _, grade := range s.gradesIn(period) {
+		m.add(s.normalized(grade))
+	}
+	return m.value(), nil
+}
+
+func (s *Store) GetAverageGradesByClass(ctx context.Context, period models.Period) (map[string]map[string]float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Сначала средние за четверть, затем среднее этих средних — как в SQL-версии
+	type quarterKey struct {
+		class, subject string
+		quarter        int
+	}
+	type subjectKey struct{ class, subject string }
+
+	quarters := make(map[quarterKey]*mean)
+	for _, grade := range s.gradesIn(period) {
+		class, ok := s.gradeClass(grade)
+		if !ok {
+			continue
+		}
+		k := quarterKey{class.Name, s.subjects[grade.SubjectID].Name, grade.Quarter}
+		if quarters[k] == nil {
+			quarters[k] = &mean{}
+		}
+		quarters[k].add(float64(grade.Grade))
+	}
+
+	subjects := make(map[subjectKey]*mean)
+	for k, m := range quarters {
+		sk := subjectKey{k.class, k.subject}
+		if subjects[sk] == nil {
+			subjects[sk] = &mean{}
+		}
+		subjects[sk].add(m.value())
+	}
+
+	result := make(map[string]map[string]float64)
+	for k, m := range subjects {
+		if result[k.class] == nil {
+			result[k.class] = make(map[string]float64)
+		}
+		result[k.class][k.subject] = round2(m.value())
+	}
+	return result, nil
+}
+
+func (s *Store) GetTopAndWorstClasses(ctx context.Context, period models.Period) (string, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	classes := make(map[string]*mean)
+	for _, grade := range s.gradesIn(period) {
+		class, ok := s.gradeClass(grade)
+		if !ok {
+			continue
+		}
+		if classes[class.Name] == nil {
+			classes[class.Name] = &mean{}
+		}
+		classes[class.Name].add(s.normalized(grade))
+	}
+	if len(classes) == 0 {
+		return "", "", nil
+	}
+
+	names := make([]string, 0, len(classes))
+	for name := range classes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := classes[names[i]].value(), classes[names[j]].value()
+		if a != b {
+			return a > b
+		}
+		return names[i] < names[j]
+	})
+	return names[0], names[len(names)-1], nil
+}
+
+func (s *Store) GetClassPerformance(ctx context.Context, period models.Period) ([]models.ClassPerformance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type quarterKey struct {
+		class   string
+		quarter int
+	}
+	quarters := make(map[quarterKey]*mean)
+	for _, grade := range s.gradesIn(period) {
+		class, ok := s.gradeClass(grade)
+		if !ok {
+			continue
+		}
+		k := quarterKey{class.Name, grade.Quarter}
+		if quarters[k] == nil {
+			quarters[k] = &mean{}
+		}
+		quarters[k].add(s.normalized(grade))
+	}
+
+	classes := make(map[string]*mean)
+	for k, m := range quarters {
+		if classes[k.class] == nil {
+			classes[k.class] = &mean{}
+		}
+		classes[k.class].add(m.value())
+	}
+
+	performances := []models.ClassPerformance{}
+	for name, m := range classes {
+		performances = append(performances, models.ClassPerformance{Name: name, Value: round2(m.value())})
+	}
+	sort.Slice(performances, func(i, j int) bool { return performances[i].Name < performances[j].Name })
+	return performances, nil
+}
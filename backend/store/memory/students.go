@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) ListStudents(ctx context.Context) ([]models.Student, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	students := make([]models.Student, 0, len(s.students))
+	for _, student := range s.students {
+		students = append(students, student)
+	}
+	sort.Slice(students, func(i, j int) bool { return students[i].ID < students[j].ID })
+	return students, nil
+}
+
+func (s *Store) GetStudent(ctx context.Context, id int) (*models.Student, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	student, ok := s.students[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &student, nil
+}
+
+func (s *Store) GetStudentByUserID(ctx context.Context, userID int) (*models.Student, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, student := range s.students {
+		if userID != 0 && student.UserID == userID {
+			return &student, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *Store) CreateStudent(ctx context.Context, student *models.Student) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if student.UserID != 0 {
+		for _, existing := range s.students {
+			if existing.UserID == student.UserID {
+				return store.ErrConflict
+			}
+		}
+	}
+	if err := s.setClassName(student); err != nil {
+		return err
+	}
+	student.ID = s.newID("students")
+	entry, err := store.NewAuditEntry(ctx, models.AuditCreate, models.AuditStudent, student.ID, nil, *student)
+	if err != nil {
+		return err
+	}
+	s.students[student.ID] = *student
+	s.appendAudit(entry)
+	return nil
+}
+
+// setClassName заполняет название класса ученика; ErrReference, если класса нет
+func (s *Store) setClassName(student *models.Student) error {
+	student.ClassName = ""
+	if student.ClassID == 0 {
+		return nil
+	}
+	class, ok := s.classes[student.ClassID]
+	if !ok {
+		return store.ErrReference
+	}
+	student.ClassName = models.ClassName(class.GradeLevel, class.Letter)
+	return nil
+}
+
+func (s *Store) UpdateStudent(ctx context.Context, student *models.Student) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.students[student.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if err := s.setClassName(student); err != nil {
+		return err
+	}
+	saved := old
+	saved.FullName = student.FullName
+	saved.ClassID = student.ClassID
+	saved.ClassName = student.ClassName
+	entry, err := store.NewAuditEntry(ctx, models.AuditUpdate, models.AuditStudent, student.ID, old, saved)
+	if err != nil {
+		return err
+	}
+	s.students[student.ID] = saved
+	s.appendAudit(entry)
+	return nil
+}
+
+func (s *Store) DeleteStudent(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.students[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	entry, err := store.NewAuditEntry(ctx, models.AuditDelete, models.AuditStudent, id, old, nil)
+	if err != nil {
+		return err
+	}
+	s.appendAudit(entry)
+	delete(s.students, id)
+	for gradeID, grade := range s.grades {
+		if grade.StudentID == id {
+			delete(s.grades, gradeID)
+		}
+	}
+	for key := range s.termGrades {
+		if key.studentID == id {
+			delete(s.termGrades, key)
+		}
+	}
+	for invID, inv := range s.invitations {
+		if inv.StudentID == id {
+			delete(s.invitations, invID)
+		}
+	}
+	for key := range s.guardians {
+		if key.studentID == id {
+			delete(s.guardians, key)
+		}
+	}
+	for enrollmentID, e := range s.enrollments {
+		if e.StudentID == id {
+			delete(s.enrollments, enrollmentID)
+		}
+	}
+	for attendanceID, a := range s.attendance {
+		if a.StudentID == id {
+			delete(s.attendance, attendanceID)
+		}
+	}
+	for submissionID, sub := range s.submissions {
+		if sub.StudentID == id {
+			delete(s.submissions, submissionID)
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetStudentsByClass(ctx context.Context, classID int) ([]models.Student, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var students []models.Student
+	for _, student := range s.students {
+		if student.ClassID == classID {
+			students = append(students, student)
+		}
+	}
+	sort.Slice(students, func(i, j int) bool { return students[i].FullName < students[j].FullName })
+	return students, nil
+}
+
+func (s *Store) CountStudents(ctx context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int64(len(s.students)), nil
+}
+
+func (s *Store) GetStudentsByTeacher(ctx context.Context, teacherID int, period models.Period) ([]models.Student, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := make(map[int]bool)
+	var students []models.Student
+	for _, student := range s.students {
+		if !seen[student.ID] && s.teaches(teacherID, student, 0, period) {
+			seen[student.ID] = true
+			students = append(students, student)
+		}
+	}
+	sort.Slice(students, func(i, j int) bool {
+		if students[i].FullName != students[j].FullName {
+			return students[i].FullName < students[j].FullName
+		}
+		return students[i].ID < students[j].ID
+	})
+	return students, nil
+}
@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) ListSubjects(ctx context.Context) ([]models.Subject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subjects := make([]models.Subject, 0, len(s.subjects))
+	for _, subject := range s.subjects {
+		subjects = append(subjects, subject)
+	}
+	sort.Slice(subjects, func(i, j int) bool { return subjects[i].Name < subjects[j].Name })
+	return subjects, nil
+}
+
+func (s *Store) GetSubject(ctx context.Context, id int) (*models.Subject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subject, ok := s.subjects[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &subject, nil
+}
+
+func (s *Store) CreateSubject(ctx context.Context, subject *models.Subject) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.subjects {
+		if existing.Name == subject.Name {
+			return store.ErrConflict
+		}
+	}
+	if subject.GradingScaleID == 0 {
+		subject.GradingScaleID = s.defaultScale().ID
+	} else if _, ok := s.gradingScales[subject.GradingScaleID]; !ok {
+		return store.ErrReference
+	}
+	subject.ID = s.newID("subjects")
+	entry, err := store.NewAuditEntry(ctx, models.AuditCreate, models.AuditSubject, subject.ID, nil, *subject)
+	if err != nil {
+		return err
+	}
+	s.subjects[subject.ID] = *subject
+	s.appendAudit(entry)
+	return nil
+}
+
+func (s *Store) UpdateSubject(ctx context.Context, subject *models.Subject) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.subjects[subject.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	for _, other := range s.subjects {
+		if other.ID != subject.ID && other.Name == subject.Name {
+			return store.ErrConflict
+		}
+	}
+	if subject.GradingScaleID == 0 {
+		subject.GradingScaleID = existing.GradingScaleID
+	}
+	if subject.GradingScaleID != existing.GradingScaleID {
+		if _, ok := s.gradingScales[subject.GradingScaleID]; !ok {
+			return store.ErrReference
+		}
+		if s.subjectGraded(subject.ID) {
+			return store.ErrConflict
+		}
+	}
+	saved := existing
+	saved.Name = subject.Name
+	saved.GradingScaleID = subject.GradingScaleID
+	entry, err := store.NewAuditEntry(ctx, models.AuditUpdate, models.AuditSubject, subject.ID, existing, saved)
+	if err != nil {
+		return err
+	}
+	s.subjects[subject.ID] = saved
+	s.appendAudit(entry)
+	return nil
+}
+
+// subjectGraded сообщает, есть ли по предмету отметки или итоговые оценки
+func (s *Store) subjectGraded(id int) bool {
+	for _, grade := range s.grades {
+		if grade.SubjectID == id {
+			return true
+		}
+	}
+	for key := range s.termGrades {
+		if key.subjectID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) DeleteSubject(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.subjects[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	entry, err := store.NewAuditEntry(ctx, models.AuditDelete, models.AuditSubject, id, old, nil)
+	if err != nil {
+		return err
+	}
+	s.appendAudit(entry)
+	delete(s.subjects, id)
+	for gradeID, grade := range s.grades {
+		if grade.SubjectID == id {
+			delete(s.grades, gradeID)
+		}
+	}
+	for key := range s.termGrades {
+		if key.subjectID == id {
+			delete(s.termGrades, key)
+		}
+	}
+	for enrollmentID, e := range s.enrollments {
+		if e.SubjectID == id {
+			delete(s.enrollments, enrollmentID)
+		}
+	}
+	for assignmentID, a := range s.teachingAssignments {
+		if a.SubjectID == id {
+			delete(s.teachingAssignments, assignmentID)
+		}
+	}
+	for attendanceID, a := range s.attendance {
+		if a.SubjectID == id {
+			delete(s.attendance, attendanceID)
+		}
+	}
+	for lessonID, l := range s.lessons {
+		if l.SubjectID == id {
+			delete(s.lessons, lessonID)
+		}
+	}
+	for homeworkID, h := range s.homework {
+		if h.SubjectID == id {
+			s.deleteHomework(homeworkID)
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetSubjectsByTeacher(ctx context.Context, teacherID int) ([]models.Subject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	taught := make(map[int]bool)
+	for _, a := range s.teachingAssignments {
+		if a.TeacherID == teacherID {
+			taught[a.SubjectID] = true
+		}
+	}
+	for _, e := range s.enrollments {
+		if e.TeacherID == teacherID {
+			taught[e.SubjectID] = true
+		}
+	}
+	var subjects []models.Subject
+	for _, subject := range s.subjects {
+		if taught[subject.ID] {
+			subjects = append(subjects, subject)
+		}
+	}
+	sort.Slice(subjects, func(i, j int) bool { return subjects[i].Name < subjects[j].Name })
+	return subjects, nil
+}
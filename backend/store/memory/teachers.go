@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) ListTeachers(ctx context.Context) ([]models.Teacher, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	teachers := make([]models.Teacher, 0, len(s.teachers))
+	for _, teacher := range s.teachers {
+		teachers = append(teachers, teacher)
+	}
+	sort.Slice(teachers, func(i, j int) bool { return teachers[i].ID < teachers[j].ID })
+	return teachers, nil
+}
+
+func (s *Store) GetTeacher(ctx context.Context, id int) (*models.Teacher, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	teacher, ok := s.teachers[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &teacher, nil
+}
+
+func (s *Store) GetTeacherByUserID(ctx context.Context, userID int) (*models.Teacher, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, teacher := range s.teachers {
+		if userID != 0 && teacher.UserID == userID {
+			return &teacher, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *Store) CreateTeacher(ctx context.Context, teacher *models.Teacher) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if teacher.UserID != 0 {
+		for _, existing := range s.teachers {
+			if existing.UserID == teacher.UserID {
+				return store.ErrConflict
+			}
+		}
+	}
+	teacher.ID = s.newID("teachers")
+	entry, err := store.NewAuditEntry(ctx, models.AuditCreate, models.AuditTeacher, teacher.ID, nil, *teacher)
+	if err != nil {
+		return err
+	}
+	s.teachers[teacher.ID] = *teacher
+	s.appendAudit(entry)
+	return nil
+}
+
+func (s *Store) UpdateTeacher(ctx context.Context, teacher *models.Teacher) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.teachers[teacher.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	saved := old
+	saved.FullName = teacher.FullName
+	saved.RoomNumber = teacher.RoomNumber
+	entry, err := store.NewAuditEntry(ctx, models.AuditUpdate, models.AuditTeacher, teacher.ID, old, saved)
+	if err != nil {
+		return err
+	}
+	s.teachers[teacher.ID] = saved
+	s.appendAudit(entry)
+	return nil
+}
+
+func (s *Store) DeleteTeacher(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.teachers[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	entry, err := store.NewAuditEntry(ctx, models.AuditDelete, models.AuditTeacher, id, old, nil)
+	if err != nil {
+		return err
+	}
+	s.appendAudit(entry)
+	delete(s.teachers, id)
+	for assignmentID, a := range s.teachingAssignments {
+		if a.TeacherID == id {
+			delete(s.teachingAssignments, assignmentID)
+		}
+	}
+	for lessonID, l := range s.lessons {
+		if l.TeacherID == id {
+			delete(s.lessons, lessonID)
+		}
+	}
+	for enrollmentID, e := range s.enrollments {
+		if e.TeacherID == id {
+			e.TeacherID = 0
+			s.enrollments[enrollmentID] = e
+		}
+	}
+	for homeworkID, h := range s.homework {
+		if h.TeacherID == id {
+			h.TeacherID = 0
+			s.homework[homeworkID] = h
+		}
+	}
+	for classID, class := range s.classes {
+		if class.HomeroomTeacherID == id {
+			class.HomeroomTeacherID = 0
+			s.classes[classID] = class
+		}
+	}
+	for invID, inv := range s.invitations {
+		if inv.TeacherID == id {
+			delete(s.invitations, invID)
+		}
+	}
+	return nil
+}
+
+func (s *Store) CountTeachers(ctx context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int64(len(s.teachers)), nil
+}
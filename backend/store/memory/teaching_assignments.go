@@ -0,0 +1,190 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// termInPeriod сообщает, подходит ли период зачисления или назначения (0 — весь год)
+// к номеру периода из period; учебный год проверяет вызывающий
+func (s *Store) termInPeriod(termID int, period models.Period) bool {
+	return termID == 0 || period.Term == 0 || s.terms[termID].Number == period.Term
+}
+
+// teaches сообщает, ведет ли учитель у ученика предмет (0 — любой) в учебном году и периоде:
+// ученик зачислен к учителю или учится в классе, где учитель ведет предмет, и не зачислен
+// на этот предмет к другому учителю. Назначение на период (замена) действует и для учеников,
+// зачисленных к другому учителю на весь год. Вызывается под блокировкой.
+func (s *Store) teaches(teacherID int, student models.Student, subjectID int, period models.Period) bool {
+	for _, e := range s.enrollments {
+		if e.StudentID == student.ID && e.TeacherID == teacherID && (subjectID == 0 || e.SubjectID == subjectID) &&
+			e.AcademicYear == period.AcademicYear && s.termInPeriod(e.TermID, period) {
+			return true
+		}
+	}
+	for _, a := range s.teachingAssignments {
+		if a.TeacherID != teacherID || a.ClassID != student.ClassID || (subjectID != 0 && a.SubjectID != subjectID) ||
+			s.classes[a.ClassID].AcademicYear != period.AcademicYear || !s.termInPeriod(a.TermID, period) {
+			continue
+		}
+		if !s.enrolledElsewhere(teacherID, student.ID, a.SubjectID, a.TermID != 0, period) {
+			return true
+		}
+	}
+	return false
+}
+
+// enrolledElsewhere сообщает, зачислен ли ученик на предмет к другому учителю;
+// при termOnly учитываются только зачисления на отдельный период
+func (s *Store) enrolledElsewhere(teacherID, studentID, subjectID int, termOnly bool, period models.Period) bool {
+	for _, e := range s.enrollments {
+		if e.StudentID == studentID && e.SubjectID == subjectID && e.TeacherID != teacherID && (!termOnly || e.TermID != 0) &&
+			e.AcademicYear == period.AcademicYear && s.termInPeriod(e.TermID, period) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignedTeacher возвращает учителя, который ведет предмет в классе в учебном году
+// и периоде (назначение на период важнее назначения на весь год), или 0
+func (s *Store) assignedTeacher(classID, subjectID, startYear, termID int) int {
+	best := models.TeachingAssignment{}
+	for _, a := range s.teachingAssignments {
+		if a.ClassID != classID || a.SubjectID != subjectID || s.classes[a.ClassID].AcademicYear != startYear ||
+			(a.TermID != 0 && a.TermID != termID) {
+			continue
+		}
+		better := best.ID == 0 || (a.TermID != 0 && best.TermID == 0) ||
+			((a.TermID != 0) == (best.TermID != 0) && a.ID < best.ID)
+		if better {
+			best = a
+		}
+	}
+	return best.TeacherID
+}
+
+// withYear заполняет учебный год назначения по его классу
+func (s *Store) withYear(a models.TeachingAssignment) models.TeachingAssignment {
+	a.AcademicYear = s.classes[a.ClassID].AcademicYear
+	return a
+}
+
+func (s *Store) ListTeachingAssignments(ctx context.Context, f models.TeachingAssignmentFilter) ([]models.TeachingAssignment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var assignments []models.TeachingAssignment
+	for _, a := range s.teachingAssignments {
+		a = s.withYear(a)
+		if (f.TeacherID != 0 && a.TeacherID != f.TeacherID) || (f.SubjectID != 0 && a.SubjectID != f.SubjectID) ||
+			(f.ClassID != 0 && a.ClassID != f.ClassID) || (f.AcademicYear != 0 && a.AcademicYear != f.AcademicYear) {
+			continue
+		}
+		assignments = append(assignments, a)
+	}
+	sort.Slice(assignments, func(i, j int) bool {
+		a, b := assignments[i], assignments[j]
+		ca, cb := s.classes[a.ClassID], s.classes[b.ClassID]
+		if ca.AcademicYear != cb.AcademicYear {
+			return ca.AcademicYear > cb.AcademicYear
+		}
+		if ca.GradeLevel != cb.GradeLevel {
+			return ca.GradeLevel < cb.GradeLevel
+		}
+		if ca.Letter != cb.Letter {
+			return ca.Letter < cb.Letter
+		}
+		if sa, sb := s.subjects[a.SubjectID].Name, s.subjects[b.SubjectID].Name; sa != sb {
+			return sa < sb
+		}
+		return a.ID < b.ID
+	})
+	return assignments, nil
+}
+
+func (s *Store) GetTeachingAssignment(ctx context.Context, id int) (*models.TeachingAssignment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.teachingAssignments[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	a = s.withYear(a)
+	return &a, nil
+}
+
+func (s *Store) CreateTeachingAssignment(ctx context.Context, assignment *models.TeachingAssignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	class, ok := s.classes[assignment.ClassID]
+	if !ok {
+		return store.ErrReference
+	}
+	if _, ok := s.teachers[assignment.TeacherID]; !ok {
+		return store.ErrReference
+	}
+	if _, ok := s.subjects[assignment.SubjectID]; !ok {
+		return store.ErrReference
+	}
+	if assignment.TermID != 0 {
+		term, ok := s.terms[assignment.TermID]
+		if !ok || s.academicYears[term.AcademicYearID].StartYear != class.AcademicYear {
+			return store.ErrReference
+		}
+	}
+	if s.yearArchived(class.AcademicYear) {
+		return store.ErrArchived
+	}
+	for _, a := range s.teachingAssignments {
+		if a.TeacherID == assignment.TeacherID && a.SubjectID == assignment.SubjectID &&
+			a.ClassID == assignment.ClassID && a.TermID == assignment.TermID {
+			return store.ErrConflict
+		}
+	}
+	assignment.ID = s.newID("teaching_assignments")
+	assignment.AcademicYear = class.AcademicYear
+	s.teachingAssignments[assignment.ID] = *assignment
+	return nil
+}
+
+func (s *Store) UpdateTeachingAssignment(ctx context.Context, assignment *models.TeachingAssignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.teachingAssignments[assignment.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if _, ok := s.teachers[assignment.TeacherID]; !ok {
+		return store.ErrReference
+	}
+	if s.yearArchived(s.classes[existing.ClassID].AcademicYear) {
+		return store.ErrArchived
+	}
+	for _, a := range s.teachingAssignments {
+		if a.ID != existing.ID && a.TeacherID == assignment.TeacherID && a.SubjectID == existing.SubjectID &&
+			a.ClassID == existing.ClassID && a.TermID == existing.TermID {
+			return store.ErrConflict
+		}
+	}
+	existing.TeacherID = assignment.TeacherID
+	s.teachingAssignments[existing.ID] = existing
+	*assignment = s.withYear(existing)
+	return nil
+}
+
+func (s *Store) DeleteTeachingAssignment(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.teachingAssignments[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.yearArchived(s.classes[a.ClassID].AcademicYear) {
+		return store.ErrArchived
+	}
+	delete(s.teachingAssignments, id)
+	return nil
+}
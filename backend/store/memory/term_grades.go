@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// termGradeKey — первичный ключ итоговой оценки
+type termGradeKey struct {
+	studentID, subjectID, termID int
+}
+
+func (s *Store) ListTermGrades(ctx context.Context, studentID int, period models.Period) ([]models.TermGrade, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var grades []models.TermGrade
+	for key, grade := range s.termGrades {
+		if (studentID != 0 && key.studentID != studentID) || !s.inPeriod(models.Grade{TermID: key.termID}, period) {
+			continue
+		}
+		grade.SubjectName = s.subjects[key.subjectID].Name
+		grade.Quarter = s.terms[key.termID].Number
+		grades = append(grades, grade)
+	}
+	sort.Slice(grades, func(i, j int) bool {
+		if grades[i].SubjectName != grades[j].SubjectName {
+			return grades[i].SubjectName < grades[j].SubjectName
+		}
+		return grades[i].Quarter < grades[j].Quarter
+	})
+	return grades, nil
+}
+
+func (s *Store) GetTermGrade(ctx context.Context, studentID, subjectID, termID int) (*models.TermGrade, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	grade, ok := s.termGrades[termGradeKey{studentID, subjectID, termID}]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	grade.SubjectName = s.subjects[subjectID].Name
+	grade.Quarter = s.terms[termID].Number
+	return &grade, nil
+}
+
+func (s *Store) SetTermGrade(ctx context.Context, grade *models.TermGrade) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.students[grade.StudentID]; !ok {
+		return store.ErrReference
+	}
+	subject, ok := s.subjects[grade.SubjectID]
+	if !ok {
+		return store.ErrReference
+	}
+	term, ok := s.terms[grade.TermID]
+	if !ok {
+		return store.ErrReference
+	}
+	if s.termArchived(term.ID) {
+		return store.ErrArchived
+	}
+	grade.SubjectName = subject.Name
+	grade.Quarter = term.Number
+	grade.UpdatedAt = time.Now()
+	key := termGradeKey{grade.StudentID, grade.SubjectID, grade.TermID}
+	var entry *models.AuditEntry
+	var err error
+	if old, ok := s.termGrades[key]; ok {
+		grade.ID = old.ID
+		old.SubjectName = subject.Name
+		old.Quarter = term.Number
+		entry, err = store.NewAuditEntry(ctx, models.AuditUpdate, models.AuditTermGrade, grade.ID, old, *grade)
+	} else {
+		grade.ID = s.newID("term_grades")
+		entry, err = store.NewAuditEntry(ctx, models.AuditCreate, models.AuditTermGrade, grade.ID, nil, *grade)
+	}
+	if err != nil {
+		return err
+	}
+	s.termGrades[key] = *grade
+	s.appendAudit(entry)
+	return nil
+}
+
+func (s *Store) DeleteTermGrade(ctx context.Context, studentID, subjectID, termID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := termGradeKey{studentID, subjectID, termID}
+	old, ok := s.termGrades[key]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.termArchived(termID) {
+		return store.ErrArchived
+	}
+	old.SubjectName = s.subjects[subjectID].Name
+	old.Quarter = s.terms[termID].Number
+	entry, err := store.NewAuditEntry(ctx, models.AuditDelete, models.AuditTermGrade, old.ID, old, nil)
+	if err != nil {
+		return err
+	}
+	delete(s.termGrades, key)
+	s.appendAudit(entry)
+	return nil
+}
@@ -0,0 +1,179 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) seedBellPeriods() {
+	for _, p := range models.DefaultBellPeriods {
+		s.bellPeriods[p.Number] = p
+	}
+}
+
+func (s *Store) ListBellPeriods(ctx context.Context) ([]models.BellPeriod, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	periods := make([]models.BellPeriod, 0, len(s.bellPeriods))
+	for _, p := range s.bellPeriods {
+		periods = append(periods, p)
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Number < periods[j].Number })
+	return periods, nil
+}
+
+func (s *Store) SetBellPeriods(ctx context.Context, periods []models.BellPeriod) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keep := make(map[int]bool, len(periods))
+	for _, p := range periods {
+		keep[p.Number] = true
+	}
+	for _, l := range s.lessons {
+		if !keep[l.Period] {
+			return store.ErrReference
+		}
+	}
+	s.bellPeriods = make(map[int]models.BellPeriod, len(periods))
+	for _, p := range periods {
+		s.bellPeriods[p.Number] = p
+	}
+	return nil
+}
+
+// withLessonNames заполняет учебный год и названия урока; вызывается под блокировкой
+func (s *Store) withLessonNames(l models.Lesson) models.Lesson {
+	class := s.classes[l.ClassID]
+	l.AcademicYear = class.AcademicYear
+	l.ClassName = models.ClassName(class.GradeLevel, class.Letter)
+	l.SubjectName = s.subjects[l.SubjectID].Name
+	l.TeacherName = s.teachers[l.TeacherID].FullName
+	return l
+}
+
+func (s *Store) ListLessons(ctx context.Context, f models.LessonFilter) ([]models.Lesson, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var lessons []models.Lesson
+	for _, l := range s.lessons {
+		l = s.withLessonNames(l)
+		if (f.ClassID != 0 && l.ClassID != f.ClassID) || (f.TeacherID != 0 && l.TeacherID != f.TeacherID) ||
+			(f.AcademicYear != 0 && l.AcademicYear != f.AcademicYear) || (f.Weekday != 0 && l.Weekday != f.Weekday) {
+			continue
+		}
+		lessons = append(lessons, l)
+	}
+	sort.Slice(lessons, func(i, j int) bool {
+		a, b := lessons[i], lessons[j]
+		if a.Weekday != b.Weekday {
+			return a.Weekday < b.Weekday
+		}
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		ca, cb := s.classes[a.ClassID], s.classes[b.ClassID]
+		if ca.GradeLevel != cb.GradeLevel {
+			return ca.GradeLevel < cb.GradeLevel
+		}
+		return ca.Letter < cb.Letter
+	})
+	return lessons, nil
+}
+
+func (s *Store) GetLesson(ctx context.Context, id int) (*models.Lesson, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lesson, ok := s.lessons[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	lesson = s.withLessonNames(lesson)
+	return &lesson, nil
+}
+
+// checkLesson проверяет ссылки урока, архив и занятость класса, учителя и кабинета
+// в учебном году класса, как это делает SQL-версия
+func (s *Store) checkLesson(l *models.Lesson) error {
+	class, ok := s.classes[l.ClassID]
+	if !ok {
+		return store.ErrReference
+	}
+	if _, ok := s.subjects[l.SubjectID]; !ok {
+		return store.ErrReference
+	}
+	if _, ok := s.teachers[l.TeacherID]; !ok {
+		return store.ErrReference
+	}
+	if _, ok := s.bellPeriods[l.Period]; !ok {
+		return store.ErrReference
+	}
+	if s.yearArchived(class.AcademicYear) {
+		return store.ErrArchived
+	}
+
+	var busy error
+	for _, other := range s.lessons {
+		if other.ID == l.ID || other.Weekday != l.Weekday || other.Period != l.Period ||
+			s.classes[other.ClassID].AcademicYear != class.AcademicYear {
+			continue
+		}
+		switch {
+		case other.ClassID == l.ClassID:
+			return store.ErrClassBusy
+		case other.TeacherID == l.TeacherID:
+			busy = store.ErrTeacherBusy
+		case busy == nil && l.Room != "" && other.Room == l.Room:
+			busy = store.ErrRoomBusy
+		}
+	}
+	if busy != nil {
+		return busy
+	}
+	l.AcademicYear = class.AcademicYear
+	return nil
+}
+
+func (s *Store) CreateLesson(ctx context.Context, lesson *models.Lesson) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkLesson(lesson); err != nil {
+		return err
+	}
+	lesson.ID = s.newID("lessons")
+	s.lessons[lesson.ID] = *lesson
+	return nil
+}
+
+func (s *Store) UpdateLesson(ctx context.Context, lesson *models.Lesson) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.lessons[lesson.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.yearArchived(s.classes[existing.ClassID].AcademicYear) {
+		return store.ErrArchived
+	}
+	if err := s.checkLesson(lesson); err != nil {
+		return err
+	}
+	s.lessons[lesson.ID] = *lesson
+	return nil
+}
+
+func (s *Store) DeleteLesson(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lesson, ok := s.lessons[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if s.yearArchived(s.classes[lesson.ClassID].AcademicYear) {
+		return store.ErrArchived
+	}
+	delete(s.lessons, id)
+	return nil
+}
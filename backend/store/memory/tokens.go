@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) insertRefreshToken(token *models.RefreshToken) {
+	token.ID = s.newID("refresh_tokens")
+	token.CreatedAt = time.Now()
+	s.refreshTokens[token.ID] = *token
+}
+
+func (s *Store) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.insertRefreshToken(token)
+	return nil
+}
+
+func (s *Store) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, token := range s.refreshTokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *Store) RotateRefreshToken(ctx context.Context, oldID int, next *models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.refreshTokens[oldID]
+	if !ok || old.RevokedAt != nil {
+		return store.ErrConflict
+	}
+	now := time.Now()
+	old.RevokedAt = &now
+	s.refreshTokens[oldID] = old
+	s.insertRefreshToken(next)
+	return nil
+}
+
+func (s *Store) RevokeSession(ctx context.Context, userID int, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revokedTokens[jti] = expiresAt
+	now := time.Now()
+	for id, token := range s.refreshTokens {
+		if token.UserID == userID && token.AccessJTI == jti && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			s.refreshTokens[id] = token
+		}
+	}
+	return nil
+}
+
+func (s *Store) RevokeUserTokens(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revokeUserTokens(userID)
+	return nil
+}
+
+func (s *Store) revokeUserTokens(userID int) {
+	now := time.Now()
+	for id, token := range s.refreshTokens {
+		if token.UserID != userID || token.RevokedAt != nil {
+			continue
+		}
+		if token.AccessExpiresAt.After(now) {
+			s.revokedTokens[token.AccessJTI] = token.AccessExpiresAt
+		}
+		token.RevokedAt = &now
+		s.refreshTokens[id] = token
+	}
+}
+
+func (s *Store) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, revoked := s.revokedTokens[jti]
+	return revoked, nil
+}
+
+func (s *Store) DeleteExpiredTokens(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for jti, expiresAt := range s.revokedTokens {
+		if expiresAt.Before(now) {
+			delete(s.revokedTokens, jti)
+		}
+	}
+	for id, token := range s.refreshTokens {
+		if token.ExpiresAt.Before(now) {
+			delete(s.refreshTokens, id)
+		}
+	}
+	for id, reset := range s.resetTokens {
+		if reset.ExpiresAt.Before(now) {
+			delete(s.resetTokens, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+// recoveryCode — код восстановления, аналог строки таблицы recovery_codes
+type recoveryCode struct {
+	hash string
+	used bool
+}
+
+func (s *Store) GetTOTP(ctx context.Context, userID int) (*models.TOTP, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.totp[userID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &t, nil
+}
+
+func (s *Store) SaveTOTPSecret(ctx context.Context, userID int, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[userID]; !ok {
+		return store.ErrReference
+	}
+	s.totp[userID] = models.TOTP{UserID: userID, Secret: secret, CreatedAt: time.Now()}
+	return nil
+}
+
+func (s *Store) ConfirmTOTP(ctx context.Context, userID int, step int64, recoveryCodeHashes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.totp[userID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	now := time.Now()
+	t.ConfirmedAt = &now
+	t.LastStep = step
+	s.totp[userID] = t
+
+	codes := make([]recoveryCode, 0, len(recoveryCodeHashes))
+	for _, hash := range recoveryCodeHashes {
+		codes = append(codes, recoveryCode{hash: hash})
+	}
+	s.recoveryCodes[userID] = codes
+	return nil
+}
+
+func (s *Store) UseTOTPStep(ctx context.Context, userID int, step int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.totp[userID]
+	if !ok || t.LastStep >= step {
+		return store.ErrConflict
+	}
+	t.LastStep = step
+	s.totp[userID] = t
+	return nil
+}
+
+func (s *Store) UseRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, code := range s.recoveryCodes[userID] {
+		if code.hash == codeHash && !code.used {
+			s.recoveryCodes[userID][i].used = true
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s *Store) DeleteTOTP(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.totp[userID]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.totp, userID)
+	delete(s.recoveryCodes, userID)
+	return nil
+}
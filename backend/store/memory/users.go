@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"school-system/backend/models"
+	"school-system/backend/store"
+)
+
+func (s *Store) GetUser(ctx context.Context, id int) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	user, ok := s.users[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, user := range s.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *Store) CreateUser(ctx context.Context, user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.roles[user.Role]; !ok {
+		return store.ErrReference
+	}
+	for _, existing := range s.users {
+		if existing.Username == user.Username {
+			return store.ErrConflict
+		}
+	}
+	user.ID = s.newID("users")
+	s.users[user.ID] = *user
+	return nil
+}
+
+func (s *Store) UpdatePassword(ctx context.Context, userID int, passwordHash string, mustChange bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[userID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	user.Password = passwordHash
+	user.MustChangePassword = mustChange
+	s.users[userID] = user
+	return nil
+}
+
+func (s *Store) CreatePasswordReset(ctx context.Context, reset *models.PasswordReset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[reset.UserID]; !ok {
+		return store.ErrReference
+	}
+	now := time.Now()
+	for id, existing := range s.resetTokens {
+		if existing.UserID == reset.UserID && existing.UsedAt == nil {
+			existing.UsedAt = &now
+			s.resetTokens[id] = existing
+		}
+	}
+	reset.ID = s.newID("password_reset_tokens")
+	reset.CreatedAt = now
+	s.resetTokens[reset.ID] = *reset
+	return nil
+}
+
+func (s *Store) ResetPassword(ctx context.Context, tokenHash, passwordHash string, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, reset := range s.resetTokens {
+		if reset.TokenHash != tokenHash || reset.UsedAt != nil || !reset.ExpiresAt.After(now) {
+			continue
+		}
+		reset.UsedAt = &now
+		s.resetTokens[id] = reset
+
+		user := s.users[reset.UserID]
+		user.Password = passwordHash
+		user.MustChangePassword = false
+		s.users[user.ID] = user
+		s.revokeUserTokens(user.ID)
+		return user.ID, nil
+	}
+	return 0, store.ErrNotFound
+}
@@ -0,0 +1,443 @@
+// Package store описывает интерфейсы хранилища, через которые обработчики
+// работают с данными. Реализация для PostgreSQL находится в пакете database,
+// реализация в памяти — в пакете store/memory.
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"school-system/backend/models"
+)
+
+var (
+	// ErrNotFound возвращается, если запись не найдена
+	ErrNotFound = errors.New("запись не найдена")
+	// ErrConflict возвращается при нарушении уникальности
+	ErrConflict = errors.New("запись уже существует")
+	// ErrReference возвращается, если запись ссылается на несуществующую
+	ErrReference = errors.New("ссылка на несуществующую запись")
+	// ErrArchived возвращается при изменении данных закрытого учебного года
+	ErrArchived = fmt.Errorf("учебный год в архиве: %w", ErrConflict)
+	// ErrTeacherBusy, ErrClassBusy и ErrRoomBusy возвращаются, если у учителя, класса
+	// или в кабинете уже есть урок в это время
+	ErrTeacherBusy = fmt.Errorf("учитель занят: %w", ErrConflict)
+	ErrClassBusy   = fmt.Errorf("класс занят: %w", ErrConflict)
+	ErrRoomBusy    = fmt.Errorf("кабинет занят: %w", ErrConflict)
+)
+
+// UserStore — учетные записи пользователей
+type UserStore interface {
+	GetUser(ctx context.Context, id int) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	// UpdatePassword сохраняет новый хеш пароля и признак обязательной смены
+	UpdatePassword(ctx context.Context, userID int, passwordHash string, mustChange bool) error
+	// CreatePasswordReset сохраняет токен сброса пароля; прежние неиспользованные токены пользователя аннулируются
+	CreatePasswordReset(ctx context.Context, reset *models.PasswordReset) error
+	// ResetPassword погашает действующий токен сброса, устанавливает новый пароль
+	// и отзывает все сессии пользователя. Возвращает ErrNotFound, если токен
+	// не найден, уже использован или истек.
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string, now time.Time) (userID int, err error)
+}
+
+// StudentStore — ученики
+type StudentStore interface {
+	ListStudents(ctx context.Context) ([]models.Student, error)
+	GetStudent(ctx context.Context, id int) (*models.Student, error)
+	// GetStudentByUserID возвращает карточку ученика, привязанную к учетной записи
+	GetStudentByUserID(ctx context.Context, userID int) (*models.Student, error)
+	CreateStudent(ctx context.Context, student *models.Student) error
+	UpdateStudent(ctx context.Context, student *models.Student) error
+	DeleteStudent(ctx context.Context, id int) error
+	CountStudents(ctx context.Context) (int64, error)
+	// GetStudentsByTeacher возвращает учеников учителя в учебном году или периоде: зачисленных
+	// к нему и учеников классов, где он ведет предмет, если они не зачислены к другому учителю
+	GetStudentsByTeacher(ctx context.Context, teacherID int, period models.Period) ([]models.Student, error)
+	// GetStudentsByClass возвращает учеников класса
+	GetStudentsByClass(ctx context.Context, classID int) ([]models.Student, error)
+}
+
+// ClassStore — школьные классы
+type ClassStore interface {
+	// ListClasses возвращает классы учебного года; 0 — все годы
+	ListClasses(ctx context.Context, academicYear int) ([]models.Class, error)
+	GetClass(ctx context.Context, id int) (*models.Class, error)
+	// FindClass ищет класс по параллели, литере и учебному году
+	FindClass(ctx context.Context, gradeLevel int, letter string, academicYear int) (*models.Class, error)
+	// CreateClass добавляет класс; ErrConflict, если такой класс в этом году уже есть
+	CreateClass(ctx context.Context, class *models.Class) error
+	UpdateClass(ctx context.Context, class *models.Class) error
+	// DeleteClass удаляет класс; ErrReference, если в нем есть ученики
+	DeleteClass(ctx context.Context, id int) error
+}
+
+// TeacherStore — учителя
+type TeacherStore interface {
+	ListTeachers(ctx context.Context) ([]models.Teacher, error)
+	GetTeacher(ctx context.Context, id int) (*models.Teacher, error)
+	GetTeacherByUserID(ctx context.Context, userID int) (*models.Teacher, error)
+	CreateTeacher(ctx context.Context, teacher *models.Teacher) error
+	UpdateTeacher(ctx context.Context, teacher *models.Teacher) error
+	DeleteTeacher(ctx context.Context, id int) error
+	CountTeachers(ctx context.Context) (int64, error)
+}
+
+// SubjectStore — предметы
+type SubjectStore interface {
+	ListSubjects(ctx context.Context) ([]models.Subject, error)
+	GetSubject(ctx context.Context, id int) (*models.Subject, error)
+	// CreateSubject добавляет предмет; без шкалы назначается шкала школы
+	CreateSubject(ctx context.Context, subject *models.Subject) error
+	// UpdateSubject меняет название и шкалу предмета (0 — шкала не меняется);
+	// ErrConflict, если шкала меняется, а по предмету уже есть оценки
+	UpdateSubject(ctx context.Context, subject *models.Subject) error
+	DeleteSubject(ctx context.Context, id int) error
+	// GetSubjectsByTeacher возвращает предметы, которые ведет учитель: по назначениям в классы
+	// или по зачислениям учеников к нему
+	GetSubjectsByTeacher(ctx context.Context, teacherID int) ([]models.Subject, error)
+}
+
+// GradeStore — оценки и статистика успеваемости. Выборки по ученикам и статистика
+// ограничиваются учебным годом (и периодом) из models.Period.
+type GradeStore interface {
+	ListGrades(ctx context.Context) ([]models.Grade, error)
+	GetGrade(ctx context.Context, id int) (*models.Grade, error)
+	// CreateGrade добавляет оценку; ErrReference, если нет ученика, предмета, периода
+	// или домашнего задания, ErrConflict, если за работу по заданию уже есть отметка
+	CreateGrade(ctx context.Context, grade *models.Grade) error
+	UpdateGrade(ctx context.Context, grade *models.Grade) error
+	DeleteGrade(ctx context.Context, id int) error
+	// GetStudentGrades возвращает оценки ученика с названиями предметов; 0 — всех учеников
+	GetStudentGrades(ctx context.Context, studentID int, period models.Period) ([]models.GradeWithSubject, error)
+	// GetGradesByTeacher возвращает оценки по предметам, которые учитель ведет у ученика
+	// (как в GetStudentsByTeacher), сгруппированные по ученикам
+	GetGradesByTeacher(ctx context.Context, teacherID int, period models.Period) (map[int][]models.Grade, error)
+
+	GetAverageGrade(ctx context.Context, period models.Period) (float64, error)
+	GetAverageGradesByClass(ctx context.Context, period models.Period) (map[string]map[string]float64, error)
+	GetTopAndWorstClasses(ctx context.Context, period models.Period) (top string, worst string, err error)
+	GetClassPerformance(ctx context.Context, period models.Period) ([]models.ClassPerformance, error)
+}
+
+// TermGradeStore — итоговые оценки за период, выставленные учителем
+type TermGradeStore interface {
+	// ListTermGrades возвращает итоговые оценки ученика за учебный год или период; 0 — всех учеников
+	ListTermGrades(ctx context.Context, studentID int, period models.Period) ([]models.TermGrade, error)
+	// GetTermGrade возвращает итоговую оценку ученика по предмету за период; ErrNotFound, если ее нет
+	GetTermGrade(ctx context.Context, studentID, subjectID, termID int) (*models.TermGrade, error)
+	// SetTermGrade выставляет или заменяет итоговую оценку; ErrReference, если нет ученика, предмета или периода
+	SetTermGrade(ctx context.Context, grade *models.TermGrade) error
+	DeleteTermGrade(ctx context.Context, studentID, subjectID, termID int) error
+}
+
+// AttendanceStore — посещаемость уроков
+type AttendanceStore interface {
+	// ListAttendance возвращает отметки посещаемости, подходящие под фильтр
+	ListAttendance(ctx context.Context, filter models.AttendanceFilter) ([]models.Attendance, error)
+	GetAttendance(ctx context.Context, id int) (*models.Attendance, error)
+	// MarkAttendance сохраняет отметки одной операцией и заполняет их ID; прежняя отметка
+	// ученика на том же уроке (дата и номер урока) заменяется. ErrReference, если нет
+	// ученика, предмета или периода
+	MarkAttendance(ctx context.Context, records []models.Attendance) error
+	// UpdateAttendance меняет статус и причину отметки
+	UpdateAttendance(ctx context.Context, record *models.Attendance) error
+	DeleteAttendance(ctx context.Context, id int) error
+	// GetAbsenceByStudent возвращает пропуски учеников, у которых есть отметки,
+	// по убыванию доли пропущенных уроков
+	GetAbsenceByStudent(ctx context.Context, filter models.AttendanceFilter) ([]models.StudentAbsence, error)
+	// GetAbsenceByClass возвращает пропуски по классам учеников
+	GetAbsenceByClass(ctx context.Context, filter models.AttendanceFilter) ([]models.ClassAbsence, error)
+}
+
+// TimetableStore — расписание звонков и уроков
+type TimetableStore interface {
+	// ListBellPeriods возвращает расписание звонков по порядку уроков
+	ListBellPeriods(ctx context.Context) ([]models.BellPeriod, error)
+	// SetBellPeriods заменяет расписание звонков; ErrReference, если удаляемый номер урока есть в расписании уроков
+	SetBellPeriods(ctx context.Context, periods []models.BellPeriod) error
+	// ListLessons возвращает уроки, подходящие под фильтр, по дням недели и номерам уроков
+	ListLessons(ctx context.Context, filter models.LessonFilter) ([]models.Lesson, error)
+	GetLesson(ctx context.Context, id int) (*models.Lesson, error)
+	// CreateLesson добавляет урок. ErrReference, если нет класса, предмета, учителя
+	// или номера урока в расписании звонков; ErrClassBusy, ErrTeacherBusy или ErrRoomBusy,
+	// если в это время в том же учебном году у класса, учителя или в кабинете уже есть урок
+	CreateLesson(ctx context.Context, lesson *models.Lesson) error
+	// UpdateLesson меняет урок с теми же проверками, что и CreateLesson
+	UpdateLesson(ctx context.Context, lesson *models.Lesson) error
+	DeleteLesson(ctx context.Context, id int) error
+}
+
+// HomeworkStore — домашние задания и работы учеников
+type HomeworkStore interface {
+	// ListHomework возвращает задания, подходящие под фильтр, по срокам сдачи
+	ListHomework(ctx context.Context, filter models.HomeworkFilter) ([]models.Homework, error)
+	GetHomework(ctx context.Context, id int) (*models.Homework, error)
+	// CreateHomework добавляет задание; ErrReference, если нет класса, предмета или учителя
+	CreateHomework(ctx context.Context, homework *models.Homework) error
+	// UpdateHomework меняет название, описание и срок сдачи задания
+	UpdateHomework(ctx context.Context, homework *models.Homework) error
+	// DeleteHomework удаляет задание вместе с работами; отметки за работы остаются
+	DeleteHomework(ctx context.Context, id int) error
+	// ListSubmissions возвращает работы по заданию (studentID 0 — всех учеников) без содержимого файлов
+	ListSubmissions(ctx context.Context, homeworkID, studentID int) ([]models.Submission, error)
+	// GetSubmission возвращает работу ученика вместе с файлом
+	GetSubmission(ctx context.Context, homeworkID, studentID int) (*models.Submission, error)
+	// SaveSubmission сохраняет работу ученика, заменяя прежнюю; ErrReference, если нет задания или ученика
+	SaveSubmission(ctx context.Context, submission *models.Submission) error
+}
+
+// AcademicYearStore — учебные годы и периоды
+type AcademicYearStore interface {
+	// ListAcademicYears возвращает учебные годы вместе с периодами
+	ListAcademicYears(ctx context.Context) ([]models.AcademicYear, error)
+	// GetAcademicYear возвращает учебный год по году начала вместе с периодами
+	GetAcademicYear(ctx context.Context, startYear int) (*models.AcademicYear, error)
+	// CreateAcademicYear добавляет год вместе с периодами; ErrConflict, если год уже есть
+	CreateAcademicYear(ctx context.Context, year *models.AcademicYear) error
+	// DeleteAcademicYear удаляет год; ErrReference, если по его периодам есть оценки или посещаемость
+	DeleteAcademicYear(ctx context.Context, startYear int) error
+	GetTerm(ctx context.Context, id int) (*models.Term, error)
+	UpdateTerm(ctx context.Context, term *models.Term) error
+}
+
+// GradingScaleStore — шкалы оценивания
+type GradingScaleStore interface {
+	// ListGradingScales возвращает шкалы вместе с подписями значений
+	ListGradingScales(ctx context.Context) ([]models.GradingScale, error)
+	GetGradingScale(ctx context.Context, id int) (*models.GradingScale, error)
+	// GetDefaultGradingScale возвращает шкалу школы
+	GetDefaultGradingScale(ctx context.Context) (*models.GradingScale, error)
+	// CreateGradingScale добавляет шкалу; если она отмечена как шкала школы, отметка снимается с прежней
+	CreateGradingScale(ctx context.Context, scale *models.GradingScale) error
+	// UpdateGradingScale меняет название, порог, подписи и отметку шкалы школы; диапазон не меняется
+	UpdateGradingScale(ctx context.Context, scale *models.GradingScale) error
+	// DeleteGradingScale удаляет шкалу; ErrReference, если она назначена предметам
+	DeleteGradingScale(ctx context.Context, id int) error
+}
+
+// AnnualGradeStore — годовые оценки и закрытие учебного года
+type AnnualGradeStore interface {
+	// ListAnnualGrades возвращает сохраненные годовые оценки закрытого года; studentID 0 — всех учеников
+	ListAnnualGrades(ctx context.Context, startYear, studentID int) ([]models.AnnualGrade, error)
+	// CloseAcademicYear сохраняет годовые оценки, переводит классы в следующий учебный год
+	// (он должен существовать, иначе ErrReference), отмечает выпускников классов с параллелью
+	// graduationLevel и выше и переводит год в архив; ErrArchived, если год уже закрыт.
+	// Перевод и выпуск учеников записываются в журнал, если в контексте указан автор изменения.
+	CloseAcademicYear(ctx context.Context, startYear int, annual []models.AnnualGrade, graduationLevel int) (*models.YearEndResult, error)
+}
+
+// TeachingAssignmentStore — назначения учителей на предметы в классах
+type TeachingAssignmentStore interface {
+	// ListTeachingAssignments возвращает назначения, подходящие под фильтр
+	ListTeachingAssignments(ctx context.Context, filter models.TeachingAssignmentFilter) ([]models.TeachingAssignment, error)
+	GetTeachingAssignment(ctx context.Context, id int) (*models.TeachingAssignment, error)
+	// CreateTeachingAssignment добавляет назначение; ErrReference, если нет учителя, предмета
+	// или класса либо период из другого учебного года, ErrConflict, если такое назначение уже есть
+	CreateTeachingAssignment(ctx context.Context, assignment *models.TeachingAssignment) error
+	// UpdateTeachingAssignment передает назначение другому учителю
+	UpdateTeachingAssignment(ctx context.Context, assignment *models.TeachingAssignment) error
+	DeleteTeachingAssignment(ctx context.Context, id int) error
+}
+
+// EnrollmentStore — зачисление учеников на предметы
+type EnrollmentStore interface {
+	// ListEnrollments возвращает зачисления, подходящие под фильтр
+	ListEnrollments(ctx context.Context, filter models.EnrollmentFilter) ([]models.Enrollment, error)
+	GetEnrollment(ctx context.Context, id int) (*models.Enrollment, error)
+	// CreateEnrollment зачисляет ученика на предмет; без учителя назначается учитель, ведущий
+	// предмет в классе ученика, учебный год периода заменяет указанный. ErrReference, если нет
+	// ученика, предмета, учителя или периода, ErrConflict, если ученик уже зачислен на этот год или период
+	CreateEnrollment(ctx context.Context, enrollment *models.Enrollment) error
+	// UpdateEnrollment меняет учителя и группу; ученик, предмет и период не меняются
+	UpdateEnrollment(ctx context.Context, enrollment *models.Enrollment) error
+	DeleteEnrollment(ctx context.Context, id int) error
+	// EnrollClass зачисляет всех учеников класса на предмет в учебном году класса
+	// с учителем (0 — ведущий предмет в классе), периодом и группой из enrollment;
+	// уже зачисленные пропускаются.
+	// Возвращает число новых зачислений; ErrNotFound, если класса нет
+	EnrollClass(ctx context.Context, classID int, enrollment models.Enrollment) (int, error)
+}
+
+// TokenStore — токены обновления и отзыв токенов доступа
+type TokenStore interface {
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// RotateRefreshToken отзывает старый токен и сохраняет новый в одной операции.
+	// Возвращает ErrConflict, если старый токен уже отозван.
+	RotateRefreshToken(ctx context.Context, oldID int, next *models.RefreshToken) error
+	// RevokeSession отзывает токен доступа и связанный с ним токен обновления
+	RevokeSession(ctx context.Context, userID int, jti string, expiresAt time.Time) error
+	// RevokeUserTokens отзывает все токены обновления пользователя и выпущенные с ними токены доступа
+	RevokeUserTokens(ctx context.Context, userID int) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	// DeleteExpiredTokens удаляет записи, срок действия которых истек
+	DeleteExpiredTokens(ctx context.Context, now time.Time) error
+}
+
+// InvitationStore — приглашения на регистрацию
+type InvitationStore interface {
+	CreateInvitation(ctx context.Context, inv *models.Invitation) error
+	ListInvitations(ctx context.Context) ([]models.Invitation, error)
+	// DeleteInvitation удаляет неиспользованное приглашение
+	DeleteInvitation(ctx context.Context, id int) error
+	// RegisterWithInvitation создает пользователя по действующему приглашению:
+	// роль берется из приглашения, связанная карточка ученика или учителя
+	// привязывается к новой учетной записи, приглашение погашается.
+	// Возвращает ErrNotFound, если приглашение не найдено, использовано или истекло,
+	// и ErrConflict, если логин занят или карточка уже привязана к другому пользователю.
+	RegisterWithInvitation(ctx context.Context, codeHash string, user *models.User, now time.Time) (*models.Invitation, error)
+}
+
+// LoginThrottleStore — счетчики неудачных попыток входа
+type LoginThrottleStore interface {
+	// GetLoginThrottle возвращает счетчик или ErrNotFound, если неудачных попыток не было
+	GetLoginThrottle(ctx context.Context, scope, key string) (*models.LoginThrottle, error)
+	// RecordLoginFailure увеличивает счетчик неудачных попыток. Если с прошлой
+	// неудачной попытки прошло больше window, счет начинается заново.
+	RecordLoginFailure(ctx context.Context, scope, key string, now time.Time, window time.Duration) (*models.LoginThrottle, error)
+	// LockLogin блокирует вход до указанного момента
+	LockLogin(ctx context.Context, scope, key string, until time.Time) error
+	ListLoginThrottles(ctx context.Context) ([]models.LoginThrottle, error)
+	// ClearLoginThrottle удаляет счетчик и снимает блокировку
+	ClearLoginThrottle(ctx context.Context, scope, key string) error
+	// DeleteStaleLoginThrottles удаляет счетчики без активной блокировки,
+	// последняя неудачная попытка в которых была раньше before
+	DeleteStaleLoginThrottles(ctx context.Context, before time.Time) error
+}
+
+// TwoFactorStore — двухфакторная аутентификация (TOTP) и коды восстановления
+type TwoFactorStore interface {
+	// GetTOTP возвращает настройки TOTP пользователя или ErrNotFound
+	GetTOTP(ctx context.Context, userID int) (*models.TOTP, error)
+	// SaveTOTPSecret сохраняет новый неподтвержденный секрет, заменяя прежний
+	SaveTOTPSecret(ctx context.Context, userID int, secret string) error
+	// ConfirmTOTP подтверждает настройку и заменяет коды восстановления
+	ConfirmTOTP(ctx context.Context, userID int, step int64, recoveryCodeHashes []string) error
+	// UseTOTPStep запоминает принятый интервал. Возвращает ErrConflict,
+	// если код этого или более позднего интервала уже использовался.
+	UseTOTPStep(ctx context.Context, userID int, step int64) error
+	// UseRecoveryCode погашает код восстановления или возвращает ErrNotFound
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) error
+	// DeleteTOTP отключает двухфакторную аутентификацию и удаляет коды восстановления
+	DeleteTOTP(ctx context.Context, userID int) error
+}
+
+// CalendarFeedStore — токены подписки на календарь
+type CalendarFeedStore interface {
+	// SetCalendarFeed сохраняет токен подписки пользователя, заменяя прежний
+	SetCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error
+	// GetCalendarFeed возвращает подписку по хешу токена или ErrNotFound
+	GetCalendarFeed(ctx context.Context, tokenHash string) (*models.CalendarFeed, error)
+	DeleteCalendarFeed(ctx context.Context, userID int) error
+}
+
+// AuditStore — журнал изменений; записи только добавляются
+type AuditStore interface {
+	AppendAudit(ctx context.Context, entry *models.AuditEntry) error
+	// ListAudit возвращает записи, подходящие под фильтр, начиная с последних
+	ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+// auditActorKey — ключ автора изменений в контексте
+type auditActorKey struct{}
+
+type auditActor struct {
+	userID int
+	ip     string
+}
+
+// WithAuditActor возвращает контекст, в котором хранилище записывает изменения учеников,
+// учителей, предметов, оценок и итоговых оценок в журнал от имени пользователя userID
+// с адреса ip. Запись журнала сохраняется вместе с изменением: если ее не удалось
+// добавить, изменение отменяется.
+func WithAuditActor(ctx context.Context, userID int, ip string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, auditActor{userID: userID, ip: ip})
+}
+
+// NewAuditEntry готовит запись журнала об изменении от имени автора из контекста;
+// nil, если автор не указан и изменение в журнал не записывается.
+// oldValue равен nil при создании, newValue — при удалении.
+func NewAuditEntry(ctx context.Context, action, entity string, entityID int, oldValue, newValue interface{}) (*models.AuditEntry, error) {
+	actor, ok := ctx.Value(auditActorKey{}).(auditActor)
+	if !ok {
+		return nil, nil
+	}
+	entry := &models.AuditEntry{
+		UserID:   actor.userID,
+		Action:   action,
+		Entity:   entity,
+		EntityID: entityID,
+		IP:       actor.ip,
+	}
+	var err error
+	if oldValue != nil {
+		if entry.OldValue, err = json.Marshal(oldValue); err != nil {
+			return nil, err
+		}
+	}
+	if newValue != nil {
+		if entry.NewValue, err = json.Marshal(newValue); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+// PermissionStore — роли и права доступа
+type PermissionStore interface {
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	// ListRoles возвращает роли вместе с их правами
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	GetRole(ctx context.Context, name string) (*models.Role, error)
+	// CreateRole создает роль с правами; ErrReference, если право неизвестно
+	CreateRole(ctx context.Context, role *models.Role) error
+	// DeleteRole удаляет роль; ErrReference, если у роли есть пользователи
+	DeleteRole(ctx context.Context, name string) error
+	// SetRolePermissions заменяет набор прав роли
+	SetRolePermissions(ctx context.Context, role string, permissions []string) error
+	RoleHasPermission(ctx context.Context, role, permission string) (bool, error)
+}
+
+// GuardianStore — связи родителей (опекунов) с учениками
+type GuardianStore interface {
+	// AddGuardian привязывает родителя к ученику; ErrReference, если пользователя
+	// или ученика нет, ErrConflict, если связь уже есть
+	AddGuardian(ctx context.Context, guardian *models.Guardian) error
+	RemoveGuardian(ctx context.Context, userID, studentID int) error
+	// ListGuardians возвращает родителей ученика
+	ListGuardians(ctx context.Context, studentID int) ([]models.Guardian, error)
+	// ListChildren возвращает учеников, к которым привязан родитель
+	ListChildren(ctx context.Context, userID int) ([]models.Child, error)
+	IsGuardian(ctx context.Context, userID, studentID int) (bool, error)
+}
+
+// Store объединяет все хранилища
+type Store interface {
+	UserStore
+	StudentStore
+	ClassStore
+	TeacherStore
+	SubjectStore
+	GradeStore
+	TermGradeStore
+	AttendanceStore
+	TimetableStore
+	HomeworkStore
+	AcademicYearStore
+	GradingScaleStore
+	AnnualGradeStore
+	TeachingAssignmentStore
+	EnrollmentStore
+	TokenStore
+	InvitationStore
+	LoginThrottleStore
+	TwoFactorStore
+	CalendarFeedStore
+	AuditStore
+	PermissionStore
+	GuardianStore
+}
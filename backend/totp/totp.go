@@ -0,0 +1,110 @@
+// Package totp реализует одноразовые пароли на основе времени (RFC 6238)
+// для двухфакторной аутентификации через приложения вроде Google Authenticator.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits — количество цифр в коде
+	Digits = 6
+	// Period — интервал смены кода
+	Period = 30 * time.Second
+	// secretSize — длина секрета в байтах (160 бит, как рекомендует RFC 4226)
+	secretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret возвращает новый случайный секрет в кодировке base32
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Step возвращает номер временного интервала для момента t
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code возвращает код для секрета и номера интервала
+func Code(secret string, step int64) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, step, Digits), nil
+}
+
+// Validate проверяет код с допуском ±skew интервалов на расхождение часов.
+// Возвращает номер интервала, которому соответствует код: его нужно сохранить,
+// чтобы не принять тот же код повторно.
+func Validate(secret, candidate string, t time.Time, skew int) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+	candidate = strings.ReplaceAll(candidate, " ", "")
+	if len(candidate) != Digits {
+		return 0, false
+	}
+	current := Step(t)
+	for i := -skew; i <= skew; i++ {
+		step := current + int64(i)
+		if subtle.ConstantTimeCompare([]byte(code(key, step, Digits)), []byte(candidate)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// URI возвращает ссылку otpauth:// для QR-кода
+func URI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(int(Period/time.Second)))
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return nil, fmt.Errorf("некорректный секрет TOTP: %w", err)
+	}
+	return key, nil
+}
+
+// code вычисляет HOTP (RFC 4226) для счетчика step
+func code(key []byte, step int64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
@@ -0,0 +1,52 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Контрольные значения из приложения B RFC 6238 (SHA-1, 8 цифр)
+func TestRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	vectors := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+	for unix, want := range vectors {
+		if got := code(key, Step(time.Unix(unix, 0)), 8); got != want {
+			t.Errorf("T=%d: получен код %s, ожидался %s", unix, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	previous, _ := Code(secret, Step(now)-1)
+
+	step, ok := Validate(secret, previous, now, 1)
+	if !ok || step != Step(now)-1 {
+		t.Errorf("Код предыдущего интервала должен приниматься с допуском 1: ok=%v step=%d", ok, step)
+	}
+	if _, ok := Validate(secret, previous, now, 0); ok {
+		t.Error("Без допуска код предыдущего интервала не должен приниматься")
+	}
+	if _, ok := Validate(secret, "12345", now, 1); ok {
+		t.Error("Код неверной длины не должен приниматься")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("School System", "deputy", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/School%20System:deputy?") || !strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") {
+		t.Errorf("Некорректная ссылка otpauth: %s", uri)
+	}
+}
@@ -8,10 +8,6 @@ import {
   Typography,
   Box,
   Link,
-  FormControl,
-  InputLabel,
-  Select,
-  MenuItem,
 } from '@mui/material';
 import axios from 'axios';
 
@@ -20,8 +16,10 @@ function Login({ setIsAuthenticated }) {
   const [credentials, setCredentials] = useState({
     username: '',
     password: '',
-    role: 'student', // По умолчанию роль student
+    invite_code: '', // Код приглашения от завуча, нужен только для регистрации
   });
+  const [challengeToken, setChallengeToken] = useState(''); // Второй шаг входа при включенной 2FA
+  const [code, setCode] = useState('');
   const [error, setError] = useState('');
   const navigate = useNavigate();
 
@@ -39,12 +37,22 @@ function Login({ setIsAuthenticated }) {
 
     try {
       if (isLogin) {
-        // Логика входа
-        const response = await axios.post('http://localhost:8000/login', {
-          username: credentials.username,
-          password: credentials.password,
-        });
+        // Логика входа: пароль, затем код подтверждения, если включена 2FA
+        const response = challengeToken
+          ? await axios.post('http://localhost:8000/login/2fa', {
+              challenge_token: challengeToken,
+              code: code,
+            })
+          : await axios.post('http://localhost:8000/login', {
+              username: credentials.username,
+              password: credentials.password,
+            });
+        if (response.data.mfa_required) {
+          setChallengeToken(response.data.challenge_token);
+          return;
+        }
         localStorage.setItem('token', response.data.token);
+        localStorage.setItem('refresh_token', response.data.refresh_token);
         setIsAuthenticated(true);
         navigate('/');
       } else {
@@ -106,20 +114,30 @@ function Login({ setIsAuthenticated }) {
               value={credentials.password}
               onChange={handleChange}
             />
+            {isLogin && challengeToken && (
+              <TextField
+                margin="normal"
+                required
+                fullWidth
+                name="code"
+                label="Код из приложения-аутентификатора"
+                id="code"
+                autoComplete="one-time-code"
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
+              />
+            )}
             {!isLogin && (
-              <FormControl fullWidth margin="normal">
-                <InputLabel>Роль</InputLabel>
-                <Select
-                  name="role"
-                  value={credentials.role}
-                  onChange={handleChange}
-                  label="Роль"
-                >
-                  <MenuItem value="student">Ученик</MenuItem>
-                  <MenuItem value="teacher">Учитель</MenuItem>
-                  <MenuItem value="deputy">Завуч</MenuItem>
-                </Select>
-              </FormControl>
+              <TextField
+                margin="normal"
+                required
+                fullWidth
+                name="invite_code"
+                label="Код приглашения"
+                id="invite_code"
+                value={credentials.invite_code}
+                onChange={handleChange}
+              />
             )}
             {error && (
               <Typography color={error.includes('успешна') ? 'success' : 'error'} sx={{ mt: 2 }}>
@@ -141,7 +159,9 @@ function Login({ setIsAuthenticated }) {
                 onClick={() => {
                   setIsLogin(!isLogin);
                   setError('');
-                  setCredentials({ username: '', password: '', role: 'student' });
+                  setCredentials({ username: '', password: '', invite_code: '' });
+                  setChallengeToken('');
+                  setCode('');
                 }}
               >
                 {isLogin ? 'Нет аккаунта? Зарегистрируйтесь' : 'Уже есть аккаунт? Войдите'}
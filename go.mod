@@ -13,6 +13,8 @@ require github.com/golang-jwt/jwt/v5 v5.2.2
 require github.com/joho/godotenv v1.5.1
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/jmoiron/sqlx v1.4.0
 	github.com/rs/cors v1.11.1
+	gopkg.in/yaml.v3 v3.0.1
 )